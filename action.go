@@ -0,0 +1,89 @@
+package jwt
+
+import (
+	"crypto/sha256"
+	"crypto/subtle"
+	"encoding/base64"
+	"errors"
+	"time"
+)
+
+// Claim names of ActionToken.
+const (
+	PurposeClaim     = "purpose"
+	TargetClaim      = "target"
+	PayloadHashClaim = "payload_hash"
+)
+
+var (
+	errActionPurpose = errors.New(`jwt: action purpose ["purpose"] mismatch`)
+	errActionTarget  = errors.New(`jwt: action target ["target"] mismatch`)
+	errActionPayload = errors.New(`jwt: action payload hash ["payload_hash"] mismatch`)
+	errActionNoExp   = errors.New(`jwt: action token without expiration time ["exp"]`)
+	errActionLife    = errors.New(`jwt: action token lifetime exceeds Lifetime`)
+)
+
+// ActionToken binds a one-time action, such as a password reset or an e-mail
+// confirmation, to its purpose, its target resource and optionally a payload.
+// A token for one action can not be used for another. Acceptance is limited to
+// once per token. See LinkProfile for delivery in URLs.
+type ActionToken struct {
+	// Purpose identifies the action, e.g., "reset-password".
+	Purpose string
+
+	// Target is the resource identifier, e.g., an account ID.
+	Target string
+
+	// Payload is bound by its SHA-256 hash, if any. Use it for data
+	// which must not change between issue and use, such as the new
+	// e-mail address of a change request.
+	Payload []byte
+
+	// Lifetime is the validity of tokens, with 15 minutes for zero.
+	// Accept rejects tokens with a longer validity.
+	Lifetime time.Duration
+}
+
+func (a *ActionToken) lifetime() time.Duration {
+	if a.Lifetime == 0 {
+		return 15 * time.Minute
+	}
+	return a.Lifetime
+}
+
+// PayloadHash returns the claim value for Payload, with false for none.
+func (a *ActionToken) payloadHash() (string, bool) {
+	if a.Payload == nil {
+		return "", false
+	}
+	sum := sha256.Sum256(a.Payload)
+	return base64.RawURLEncoding.EncodeToString(sum[:]), true
+}
+
+// Accept verifies that c is a valid token for the action, as of Clock. The
+// expiry must not exceed Lifetime, and the JWT ID must be new to store, i.e.,
+// tokens are single use. Purpose and Target must match exactly. The payload
+// hash must match Payload, with absence matching a nil Payload only.
+func (a *ActionToken) Accept(c *Claims, store IDStore) error {
+	if c.Expires == nil {
+		return errActionNoExp
+	}
+	if err := c.AcceptAge(Clock(), a.lifetime(), 0); err != nil {
+		return err
+	}
+	if c.Expires.Time().Sub(c.Issued.Time()) > a.lifetime() {
+		return errActionLife
+	}
+	if s, ok := c.String(PurposeClaim); !ok || s != a.Purpose {
+		return errActionPurpose
+	}
+	if s, ok := c.String(TargetClaim); !ok || s != a.Target {
+		return errActionTarget
+	}
+	got, gotOK := c.String(PayloadHashClaim)
+	want, wantOK := a.payloadHash()
+	if gotOK != wantOK || subtle.ConstantTimeCompare([]byte(got), []byte(want)) != 1 {
+		return errActionPayload
+	}
+	return c.AcceptOnce(store)
+}
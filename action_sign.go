@@ -0,0 +1,19 @@
+//go:build !jwtverifyonly
+
+package jwt
+
+// Issue returns a new token for the action. The claims have an expiry after
+// Lifetime and a unique JWT ID. See Claims.Stamp.
+func (a *ActionToken) Issue(sign SignFunc) ([]byte, error) {
+	c := &Claims{Set: map[string]interface{}{
+		PurposeClaim: a.Purpose,
+		TargetClaim:  a.Target,
+	}}
+	if hash, ok := a.payloadHash(); ok {
+		c.Set[PayloadHashClaim] = hash
+	}
+	if err := c.Stamp(a.lifetime()); err != nil {
+		return nil, err
+	}
+	return sign(c)
+}
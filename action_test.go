@@ -0,0 +1,61 @@
+package jwt
+
+import (
+	"testing"
+	"time"
+)
+
+func TestActionToken(t *testing.T) {
+	reset := ActionToken{Purpose: "reset-password", Target: "user-42"}
+	change := ActionToken{Purpose: "change-email", Target: "user-42", Payload: []byte("alice@example.com")}
+
+	resetToken, err := reset.Issue(hmacSignFunc)
+	if err != nil {
+		t.Fatal(err)
+	}
+	changeToken, err := change.Issue(hmacSignFunc)
+	if err != nil {
+		t.Fatal(err)
+	}
+	long := reset
+	long.Lifetime = time.Hour
+	longToken, err := long.Issue(hmacSignFunc)
+	if err != nil {
+		t.Fatal(err)
+	}
+	noExpToken, err := hmacSignFunc(&Claims{
+		Registered: Registered{Issued: NewNumericTime(Clock())},
+		Set:        map[string]interface{}{PurposeClaim: reset.Purpose, TargetClaim: reset.Target},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	golden := []struct {
+		token  []byte
+		action ActionToken
+		want   error
+	}{
+		{resetToken, ActionToken{Purpose: "reset-password", Target: "user-43"}, errActionTarget},
+		{resetToken, ActionToken{Purpose: "change-email", Target: "user-42"}, errActionPurpose},
+		{resetToken, ActionToken{Purpose: "reset-password", Target: "user-42", Payload: []byte{}}, errActionPayload},
+		{changeToken, ActionToken{Purpose: "change-email", Target: "user-42", Payload: []byte("mallory@example.com")}, errActionPayload},
+		{changeToken, ActionToken{Purpose: "change-email", Target: "user-42"}, errActionPayload},
+		{longToken, reset, errActionLife},
+		{noExpToken, reset, errActionNoExp},
+		{longToken, long, nil},
+		{resetToken, reset, nil},
+		{resetToken, reset, errReplay},
+		{changeToken, change, nil},
+	}
+	store := new(MemoryIDStore)
+	for i, gold := range golden {
+		c, err := HMACCheck(gold.token, []byte("guest"))
+		if err != nil {
+			t.Fatal(err)
+		}
+		if err := gold.action.Accept(c, store); err != gold.want {
+			t.Errorf("%d: got error %v, want %v", i, err, gold.want)
+		}
+	}
+}
@@ -0,0 +1,62 @@
+package jwt
+
+import "context"
+
+// Algorithm is a signature scheme bound to a key, for algorithms other than
+// the ones of this package, such as ES256K or post-quantum schemes. Multiple
+// goroutines may invoke methods on an Algorithm simultaneously.
+type Algorithm interface {
+	// Name returns the "alg" value of the JOSE header.
+	Name() string
+
+	// Sign returns the signature of body, i.e., the first two parts of
+	// a token. Verification-only implementations return an error.
+	Sign(body []byte) ([]byte, error)
+
+	// Verify returns nil when sig is a signature of body, and ErrSigMiss
+	// when it is not. Any other error aborts the verification.
+	Verify(body, sig []byte) error
+}
+
+// CheckAlgorithms verifies body with the Algorithms of the name. Algorithms of
+// this package take precedence, i.e., the register consults Algorithms for the
+// names which are unknown only. The FIPS policy permits none of them.
+func (keys *KeyRegister) checkAlgorithms(ctx context.Context, claims *Claims, alg string, body, sig []byte) (*Claims, interface{}, error) {
+	if alg == "" || FIPS != nil || !hasAlgorithm(keys.Algorithms, alg) {
+		return nil, nil, AlgError(alg)
+	}
+
+	options, ok := byKID(keys.Algorithms, keys.AlgorithmIDs, claims.KeyID)
+	if !ok {
+		if err := keys.kidMiss(claims.KeyID); err != nil {
+			return nil, nil, err
+		}
+	}
+	for _, a := range options {
+		if err := ctx.Err(); err != nil {
+			return nil, nil, err
+		}
+		if a.Name() != alg {
+			continue
+		}
+		switch err := a.Verify(body, sig); err {
+		case nil:
+			// algorithms have no identity for KeyUsage
+			return claims, nil, claims.applyPayload()
+		case ErrSigMiss:
+			continue
+		default:
+			return nil, nil, err
+		}
+	}
+	return nil, nil, ErrSigMiss
+}
+
+func hasAlgorithm(algs []Algorithm, name string) bool {
+	for _, a := range algs {
+		if a.Name() == name {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,28 @@
+//go:build !jwtverifyonly
+
+package jwt
+
+import "encoding/json"
+
+// AlgorithmSign updates the Raw fields and returns a new JWT, signed with a
+// custom Algorithm. The FIPS policy permits no custom algorithms.
+func (c *Claims) AlgorithmSign(a Algorithm, extraHeaders ...json.RawMessage) (token []byte, err error) {
+	alg := a.Name()
+	if alg == "" || FIPS != nil {
+		return nil, AlgError(alg)
+	}
+	body, err := c.newToken(alg, 0, extraHeaders)
+	if err != nil {
+		return nil, err
+	}
+	sig, err := a.Sign(body)
+	if err != nil {
+		return nil, err
+	}
+
+	token = make([]byte, len(body)+1+encoding.EncodedLen(len(sig)))
+	i := copy(token, body)
+	token[i] = '.'
+	encoding.Encode(token[i+1:], sig)
+	return token, nil
+}
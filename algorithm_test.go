@@ -0,0 +1,110 @@
+package jwt
+
+import (
+	"crypto/ed25519"
+	"errors"
+	"testing"
+)
+
+// TestAlgorithm is Ed25519 under another name.
+type testAlgorithm struct {
+	name string
+	key  ed25519.PrivateKey
+}
+
+func (a testAlgorithm) Name() string { return a.name }
+
+func (a testAlgorithm) Sign(body []byte) ([]byte, error) {
+	return ed25519.Sign(a.key, body), nil
+}
+
+func (a testAlgorithm) Verify(body, sig []byte) error {
+	if len(sig) != ed25519.SignatureSize {
+		return ErrSigSize
+	}
+	if !ed25519.Verify(a.key.Public().(ed25519.PublicKey), body, sig) {
+		return ErrSigMiss
+	}
+	return nil
+}
+
+func TestAlgorithm(t *testing.T) {
+	alg := testAlgorithm{"X-Ed25519", testKeyEd25519Private}
+	var c Claims
+	c.Subject = "alice"
+	c.KeyID = "x1"
+	token, err := c.AlgorithmSign(alg)
+	if err != nil {
+		t.Fatal("sign error:", err)
+	}
+
+	keys := &KeyRegister{
+		Algorithms:   []Algorithm{testAlgorithm{"X-Ed25519", ed25519.NewKeyFromSeed(make([]byte, 32))}, alg},
+		AlgorithmIDs: []string{"x0", "x1"},
+	}
+	got, err := keys.Check(token)
+	if err != nil {
+		t.Fatal("check error:", err)
+	}
+	if got.Subject != "alice" || got.KeyID != "x1" {
+		t.Errorf("got subject %q and key ID %q, want alice and x1", got.Subject, got.KeyID)
+	}
+
+	// key ID mismatch
+	keys.AlgorithmIDs = []string{"x1", "x0"}
+	if _, err := keys.Check(token); err != ErrSigMiss {
+		t.Errorf("key ID mismatch got error %v, want %v", err, ErrSigMiss)
+	}
+
+	// other name
+	var algErr AlgError
+	if _, err := (&KeyRegister{Algorithms: []Algorithm{testAlgorithm{"X-Other", testKeyEd25519Private}}}).Check(token); !errors.As(err, &algErr) {
+		t.Errorf("other name got error %v, want an AlgError", err)
+	}
+
+	// errors other than ErrSigMiss abort
+	hsmDown := errors.New("HSM unavailable")
+	keys = &KeyRegister{Algorithms: []Algorithm{failAlgorithm{"X-Ed25519", hsmDown}, alg}}
+	if _, err := keys.Check(token); err != hsmDown {
+		t.Errorf("verification failure got error %v, want %v", err, hsmDown)
+	}
+}
+
+// FailAlgorithm has an error on each use.
+type failAlgorithm struct {
+	name string
+	err  error
+}
+
+func (a failAlgorithm) Name() string                     { return a.name }
+func (a failAlgorithm) Sign(body []byte) ([]byte, error) { return nil, a.err }
+func (a failAlgorithm) Verify(body, sig []byte) error    { return a.err }
+
+func TestAlgorithmPrecedence(t *testing.T) {
+	// custom algorithms can not override the ones of this package
+	token, err := new(Claims).HMACSign(HS256, []byte("guest"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	keys := &KeyRegister{Algorithms: []Algorithm{testAlgorithm{HS256, testKeyEd25519Private}}}
+	if _, err := keys.Check(token); err != ErrSigMiss {
+		t.Errorf("got error %v, want %v", err, ErrSigMiss)
+	}
+}
+
+func TestAlgorithmFIPS(t *testing.T) {
+	defer func() { FIPS = nil }()
+	alg := testAlgorithm{"X-Ed25519", testKeyEd25519Private}
+	token, err := new(Claims).AlgorithmSign(alg)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	FIPS = new(FIPSPolicy)
+	if _, err := new(Claims).AlgorithmSign(alg); err != AlgError("X-Ed25519") {
+		t.Errorf("sign got error %v, want AlgError", err)
+	}
+	if _, err := (&KeyRegister{Algorithms: []Algorithm{alg}}).Check(token); err != AlgError("X-Ed25519") {
+		t.Errorf("check got error %v, want AlgError", err)
+	}
+}
@@ -0,0 +1,233 @@
+// Package authz connects verified claims to policy engines, like Casbin and
+// the Open Policy Agent (OPA), without any dependency on them.
+package authz
+
+import (
+	"bytes"
+	"context"
+	"crypto/sha256"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"sync"
+	"time"
+
+	"github.com/pascaldekloe/jwt"
+)
+
+// Decider makes authorization decisions.
+type Decider interface {
+	Decide(ctx context.Context, input *Input) (allow bool, err error)
+}
+
+// Input is the document for policy evaluation.
+type Input struct {
+	Method string                 `json:"method"`
+	Path   string                 `json:"path"`
+	Claims map[string]interface{} `json:"claims"` // see jwt.Claims.All
+}
+
+// NewInput returns the evaluation document for a request with its claims.
+func NewInput(r *http.Request, c *jwt.Claims) *Input {
+	return &Input{
+		Method: r.Method,
+		Path:   r.URL.Path,
+		Claims: c.All(),
+	}
+}
+
+// HandlerFunc returns a function for jwt.Handler.Func which passes requests
+// only when allowed by d. Denials get status code 403 (Forbidden), and errors
+// get status code 503 (Service Unavailable).
+func HandlerFunc(d Decider) func(http.ResponseWriter, *http.Request, *jwt.Claims) bool {
+	return func(w http.ResponseWriter, r *http.Request, c *jwt.Claims) bool {
+		allow, err := d.Decide(r.Context(), NewInput(r, c))
+		switch {
+		case err != nil:
+			http.Error(w, "authz: "+err.Error(), http.StatusServiceUnavailable)
+			return false
+		case !allow:
+			http.Error(w, "authz: request denied by policy", http.StatusForbidden)
+			return false
+		}
+		return true
+	}
+}
+
+// CasbinEnforcer matches the Enforce method of “github.com/casbin/casbin/v2”
+// Enforcer.
+type CasbinEnforcer interface {
+	Enforce(rvals ...interface{}) (bool, error)
+}
+
+// Casbin is a Decider for the (subject, object, action) request definition,
+// with the subject from the claims, the request path as object, and the HTTP
+// method as action.
+type Casbin struct {
+	Enforcer CasbinEnforcer
+
+	// Subject selects the subject from the claims. Nil defaults to the
+	// "sub" claim.
+	Subject func(claims map[string]interface{}) interface{}
+}
+
+// Decide honors the Decider interface.
+func (c *Casbin) Decide(ctx context.Context, input *Input) (bool, error) {
+	var sub interface{} = input.Claims["sub"]
+	if c.Subject != nil {
+		sub = c.Subject(input.Claims)
+	}
+	return c.Enforcer.Enforce(sub, input.Path, input.Method)
+}
+
+// OPA is a Decider with the REST API of the Open Policy Agent.
+type OPA struct {
+	// URL locates a boolean rule in the Data API, e.g.,
+	// "http://localhost:8181/v1/data/httpapi/authz/allow".
+	URL string
+
+	// Client is used for queries. Nil defaults to http.DefaultClient.
+	Client *http.Client
+}
+
+var errOPAUndefined = errors.New("authz: OPA decision undefined")
+
+// Decide honors the Decider interface. An undefined result is an error.
+func (o *OPA) Decide(ctx context.Context, input *Input) (bool, error) {
+	body, err := json.Marshal(map[string]interface{}{"input": input})
+	if err != nil {
+		return false, err
+	}
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, o.URL, bytes.NewReader(body))
+	if err != nil {
+		return false, err
+	}
+	req.Header.Set("Content-Type", "application/json")
+
+	client := o.Client
+	if client == nil {
+		client = http.DefaultClient
+	}
+	resp, err := client.Do(req)
+	if err != nil {
+		return false, err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		io.Copy(io.Discard, io.LimitReader(resp.Body, 4096))
+		return false, fmt.Errorf("authz: OPA query got HTTP %q", resp.Status)
+	}
+
+	var result struct {
+		Result *bool `json:"result"`
+	}
+	if err := json.NewDecoder(resp.Body).Decode(&result); err != nil {
+		return false, fmt.Errorf("authz: OPA response: %w", err)
+	}
+	if result.Result == nil {
+		return false, errOPAUndefined
+	}
+	return *result.Result, nil
+}
+
+// Cache memoizes the decisions of a Decider. Decisions are shared between
+// inputs with the same method, path and key claims, regardless of the token.
+// Errors are not cached. Cache is ready for use once Decider is set.
+//
+// Multiple goroutines may invoke methods on a Cache simultaneously.
+type Cache struct {
+	Decider Decider
+
+	// TTL is the validity of decisions. Zero defaults to one minute.
+	TTL time.Duration
+
+	// Claims selects the claims in the cache key. Nil defaults to all
+	// claims except for the ones which differ per token, i.e., "iat",
+	// "exp", "nbf" and "jti". Policies which depend on claims outside
+	// of the selection get the decision of another input.
+	Claims []string
+
+	mutex     sync.Mutex
+	entries   map[[sha256.Size]byte]cacheEntry
+	nextSweep time.Time
+}
+
+// PerToken are the claims excluded from the cache key by default.
+var perToken = map[string]bool{"iat": true, "exp": true, "nbf": true, "jti": true}
+
+func (c *Cache) ttl() time.Duration {
+	if c.TTL == 0 {
+		return time.Minute
+	}
+	return c.TTL
+}
+
+// Key returns the cache index of input.
+func (c *Cache) key(input *Input) ([sha256.Size]byte, error) {
+	claims := make(map[string]interface{}, len(input.Claims))
+	if c.Claims == nil {
+		for name, value := range input.Claims {
+			if !perToken[name] {
+				claims[name] = value
+			}
+		}
+	} else {
+		for _, name := range c.Claims {
+			if value, ok := input.Claims[name]; ok {
+				claims[name] = value
+			}
+		}
+	}
+
+	// map members are sorted by encoding/json
+	doc, err := json.Marshal(&Input{Method: input.Method, Path: input.Path, Claims: claims})
+	if err != nil {
+		return [sha256.Size]byte{}, err
+	}
+	return sha256.Sum256(doc), nil
+}
+
+type cacheEntry struct {
+	expires time.Time
+	allow   bool
+}
+
+// Decide honors the Decider interface.
+func (c *Cache) Decide(ctx context.Context, input *Input) (bool, error) {
+	key, err := c.key(input)
+	if err != nil {
+		return false, err
+	}
+	now := time.Now()
+	ttl := c.ttl()
+
+	c.mutex.Lock()
+	entry, ok := c.entries[key]
+	c.mutex.Unlock()
+	if ok && now.Before(entry.expires) {
+		return entry.allow, nil
+	}
+
+	allow, err := c.Decider.Decide(ctx, input)
+	if err != nil {
+		return false, err
+	}
+
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+	if c.entries == nil {
+		c.entries = make(map[[sha256.Size]byte]cacheEntry)
+	}
+	if now.After(c.nextSweep) {
+		for k, e := range c.entries {
+			if !now.Before(e.expires) {
+				delete(c.entries, k)
+			}
+		}
+		c.nextSweep = now.Add(ttl)
+	}
+	c.entries[key] = cacheEntry{expires: now.Add(ttl), allow: allow}
+	return allow, nil
+}
@@ -0,0 +1,130 @@
+package authz
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/pascaldekloe/jwt"
+)
+
+type testEnforcer struct {
+	calls int
+}
+
+func (e *testEnforcer) Enforce(rvals ...interface{}) (bool, error) {
+	e.calls++
+	return rvals[0] == "alice" && rvals[1] == "/data" && rvals[2] == "GET", nil
+}
+
+func TestCasbin(t *testing.T) {
+	enforcer := new(testEnforcer)
+	f := HandlerFunc(&Cache{Decider: &Casbin{Enforcer: enforcer}, TTL: time.Minute})
+
+	golden := []struct {
+		subject, method string
+		code            int
+	}{
+		{"alice", "GET", http.StatusOK},
+		{"alice", "GET", http.StatusOK},
+		{"alice", "DELETE", http.StatusForbidden},
+		{"bob", "GET", http.StatusForbidden},
+	}
+	for _, gold := range golden {
+		c := &jwt.Claims{Registered: jwt.Registered{Subject: gold.subject}}
+		resp := httptest.NewRecorder()
+		if f(resp, httptest.NewRequest(gold.method, "/data", nil), c) {
+			resp.WriteHeader(http.StatusOK)
+		}
+		if resp.Code != gold.code {
+			t.Errorf("%s %s got HTTP %d, want %d", gold.subject, gold.method, resp.Code, gold.code)
+		}
+	}
+	if enforcer.calls != 3 {
+		t.Errorf("got %d enforcer calls, want 3 with caching", enforcer.calls)
+	}
+}
+
+func TestCacheKey(t *testing.T) {
+	var calls int
+	decider := deciderFunc(func(ctx context.Context, input *Input) (bool, error) {
+		calls++
+		return input.Claims["role"] == "admin", nil
+	})
+	req := httptest.NewRequest("GET", "/data", nil)
+	claims := func(issued int64, role string) *jwt.Claims {
+		c := &jwt.Claims{Set: map[string]interface{}{"role": role}}
+		c.Subject = "alice"
+		c.ID = fmt.Sprint("token-", issued)
+		c.Issued = jwt.NewNumericTime(time.Unix(issued, 0))
+		return c
+	}
+
+	// per-token claims excluded
+	cache := &Cache{Decider: decider}
+	for i, role := range []string{"admin", "admin", "guest"} {
+		cache.Decide(context.Background(), NewInput(req, claims(int64(i), role)))
+	}
+	if calls != 2 {
+		t.Errorf("got %d decider calls with default key, want 2", calls)
+	}
+
+	// selected claims only
+	calls = 0
+	cache = &Cache{Decider: decider, Claims: []string{"sub"}}
+	for i, role := range []string{"admin", "guest"} {
+		allow, err := cache.Decide(context.Background(), NewInput(req, claims(int64(i), role)))
+		if err != nil || !allow {
+			t.Errorf("%s got (%t, %v), want the cached allow", role, allow, err)
+		}
+	}
+	if calls != 1 {
+		t.Errorf("got %d decider calls with sub key, want 1", calls)
+	}
+}
+
+type deciderFunc func(ctx context.Context, input *Input) (bool, error)
+
+func (f deciderFunc) Decide(ctx context.Context, input *Input) (bool, error) {
+	return f(ctx, input)
+}
+
+func TestOPA(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var body struct {
+			Input Input `json:"input"`
+		}
+		if err := json.NewDecoder(r.Body).Decode(&body); err != nil {
+			t.Error("OPA request body:", err)
+		}
+		switch body.Input.Claims["sub"] {
+		case "alice":
+			w.Write([]byte(`{"result": true}`))
+		case "bob":
+			w.Write([]byte(`{"result": false}`))
+		default:
+			w.Write([]byte(`{}`))
+		}
+	}))
+	defer srv.Close()
+
+	opa := &OPA{URL: srv.URL + "/v1/data/httpapi/authz/allow"}
+	for subject, want := range map[string]bool{"alice": true, "bob": false} {
+		c := &jwt.Claims{Registered: jwt.Registered{Subject: subject}}
+		got, err := opa.Decide(context.Background(), NewInput(httptest.NewRequest("GET", "/", nil), c))
+		if err != nil {
+			t.Errorf("%s got error: %v", subject, err)
+		} else if got != want {
+			t.Errorf("%s got %t, want %t", subject, got, want)
+		}
+	}
+
+	_, err := opa.Decide(context.Background(), NewInput(httptest.NewRequest("GET", "/", nil), new(jwt.Claims)))
+	if err != errOPAUndefined {
+		t.Errorf("undefined got error %v, want %v", err, errOPAUndefined)
+	}
+}
@@ -0,0 +1,81 @@
+// Package aws provides token issuance with keys from the AWS Key Management
+// Service (KMS), without any dependency on the AWS SDK. Private keys never
+// leave KMS. Implement the KMS interface with a thin wrapper around the client
+// of choice, e.g., “github.com/aws/aws-sdk-go-v2/service/kms”.
+package aws
+
+import (
+	"context"
+	"crypto"
+	"crypto/ecdsa"
+	"crypto/rsa"
+	"crypto/x509"
+	"fmt"
+
+	"github.com/pascaldekloe/jwt"
+)
+
+// KMS is the subset of the AWS Key Management Service API in use.
+type KMS interface {
+	// Sign returns the signature of a digest with the KMS signing
+	// algorithm, e.g., "ECDSA_SHA_256", as in a SignInput with
+	// MessageType "DIGEST".
+	Sign(ctx context.Context, keyID string, digest []byte, signingAlgorithm string) (signature []byte, err error)
+
+	// GetPublicKey returns the DER-encoded X.509 SubjectPublicKeyInfo
+	// of the key, as in the PublicKey of a GetPublicKeyOutput.
+	GetPublicKey(ctx context.Context, keyID string) (der []byte, err error)
+}
+
+// SigningAlgorithms maps the JWT algorithms to their KMS equivalent.
+var SigningAlgorithms = map[string]string{
+	jwt.ES256: "ECDSA_SHA_256",
+	jwt.ES384: "ECDSA_SHA_384",
+	jwt.ES512: "ECDSA_SHA_512",
+	jwt.PS256: "RSASSA_PSS_SHA_256",
+	jwt.PS384: "RSASSA_PSS_SHA_384",
+	jwt.PS512: "RSASSA_PSS_SHA_512",
+	jwt.RS256: "RSASSA_PKCS1_V1_5_SHA_256",
+	jwt.RS384: "RSASSA_PKCS1_V1_5_SHA_384",
+	jwt.RS512: "RSASSA_PKCS1_V1_5_SHA_512",
+}
+
+// LoadKeys adds the public keys of each KMS key to the register, with their
+// respective keyID as the key ID. Keys which are present already, with the same
+// key ID, are skipped, as with jwt.KeyRegister.Merge.
+func LoadKeys(ctx context.Context, client KMS, keys *jwt.KeyRegister, keyIDs ...string) (keysAdded int, err error) {
+	var staged jwt.KeyRegister
+	for _, id := range keyIDs {
+		pub, err := publicKey(ctx, client, id)
+		if err != nil {
+			return 0, err
+		}
+		switch t := pub.(type) {
+		case *ecdsa.PublicKey:
+			staged.ECDSAs = append(staged.ECDSAs, t)
+			staged.ECDSAIDs = append(staged.ECDSAIDs, id)
+		case *rsa.PublicKey:
+			staged.RSAs = append(staged.RSAs, t)
+			staged.RSAIDs = append(staged.RSAIDs, id)
+		}
+	}
+	return keys.Merge(&staged)
+}
+
+// PublicKey retrieves either an *ecdsa.PublicKey or an *rsa.PublicKey.
+func publicKey(ctx context.Context, client KMS, keyID string) (crypto.PublicKey, error) {
+	der, err := client.GetPublicKey(ctx, keyID)
+	if err != nil {
+		return nil, fmt.Errorf("aws: KMS public key of %q: %w", keyID, err)
+	}
+	pub, err := x509.ParsePKIXPublicKey(der)
+	if err != nil {
+		return nil, fmt.Errorf("aws: KMS public key of %q: %w", keyID, err)
+	}
+	switch pub.(type) {
+	case *ecdsa.PublicKey, *rsa.PublicKey:
+		return pub, nil
+	default:
+		return nil, fmt.Errorf("aws: KMS key %q of unsupported type %T", keyID, pub)
+	}
+}
@@ -0,0 +1,116 @@
+package aws
+
+import (
+	"context"
+	"crypto"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/x509"
+	"errors"
+	"testing"
+
+	"github.com/pascaldekloe/jwt"
+)
+
+// FakeKMS serves private keys by key ID.
+type fakeKMS map[string]crypto.Signer
+
+func (f fakeKMS) GetPublicKey(ctx context.Context, keyID string) ([]byte, error) {
+	key, ok := f[keyID]
+	if !ok {
+		return nil, errors.New("NotFoundException")
+	}
+	return x509.MarshalPKIXPublicKey(key.Public())
+}
+
+func (f fakeKMS) Sign(ctx context.Context, keyID string, digest []byte, signingAlgorithm string) ([]byte, error) {
+	key, ok := f[keyID]
+	if !ok {
+		return nil, errors.New("NotFoundException")
+	}
+	switch signingAlgorithm {
+	case "ECDSA_SHA_256":
+		return ecdsa.SignASN1(rand.Reader, key.(*ecdsa.PrivateKey), digest)
+	case "ECDSA_SHA_384":
+		return ecdsa.SignASN1(rand.Reader, key.(*ecdsa.PrivateKey), digest)
+	case "RSASSA_PKCS1_V1_5_SHA_256":
+		return rsa.SignPKCS1v15(rand.Reader, key.(*rsa.PrivateKey), crypto.SHA256, digest)
+	case "RSASSA_PSS_SHA_256":
+		return rsa.SignPSS(rand.Reader, key.(*rsa.PrivateKey), crypto.SHA256, digest, &rsa.PSSOptions{SaltLength: rsa.PSSSaltLengthEqualsHash})
+	}
+	return nil, errors.New("UnsupportedOperationException")
+}
+
+func TestSignAndLoadKeys(t *testing.T) {
+	ec256, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	if err != nil {
+		t.Fatal(err)
+	}
+	ec384, err := ecdsa.GenerateKey(elliptic.P384(), rand.Reader)
+	if err != nil {
+		t.Fatal(err)
+	}
+	rsaKey, err := rsa.GenerateKey(rand.Reader, 2048)
+	if err != nil {
+		t.Fatal(err)
+	}
+	client := fakeKMS{"alias/ec256": ec256, "alias/ec384": ec384, "alias/rsa": rsaKey}
+
+	var keys jwt.KeyRegister
+	n, err := LoadKeys(context.Background(), client, &keys, "alias/ec256", "alias/ec384", "alias/rsa")
+	if err != nil {
+		t.Fatal("load error:", err)
+	}
+	if n != 3 {
+		t.Errorf("got %d keys added, want 3", n)
+	}
+
+	golden := []struct{ keyID, alg string }{
+		{"alias/ec256", jwt.ES256},
+		{"alias/ec384", jwt.ES384},
+		{"alias/rsa", jwt.RS256},
+		{"alias/rsa", jwt.PS256},
+	}
+	for _, gold := range golden {
+		signer, err := NewSigner(context.Background(), client, gold.keyID)
+		if err != nil {
+			t.Fatal(err)
+		}
+		var c jwt.Claims
+		c.Subject = "test"
+		token, err := signer.Sign(context.Background(), &c, gold.alg)
+		if err != nil {
+			t.Fatalf("%s %s: sign error: %s", gold.keyID, gold.alg, err)
+		}
+		got, err := keys.Check(token)
+		if err != nil {
+			t.Fatalf("%s %s: check error: %s", gold.keyID, gold.alg, err)
+		}
+		if got.KeyID != gold.keyID || got.Subject != "test" {
+			t.Errorf("%s %s: got key ID %q and subject %q", gold.keyID, gold.alg, got.KeyID, got.Subject)
+		}
+	}
+}
+
+func TestSignUnsupported(t *testing.T) {
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	if err != nil {
+		t.Fatal(err)
+	}
+	signer, err := NewSigner(context.Background(), fakeKMS{"k": key}, "k")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := signer.Sign(context.Background(), new(jwt.Claims), jwt.HS256); err != jwt.AlgError(jwt.HS256) {
+		t.Errorf("got error %v, want %v", err, jwt.AlgError(jwt.HS256))
+	}
+	if _, err := signer.Sign(context.Background(), new(jwt.Claims), jwt.RS256); err != jwt.AlgError(jwt.RS256) {
+		t.Errorf("got error %v, want %v", err, jwt.AlgError(jwt.RS256))
+	}
+
+	if _, err := NewSigner(context.Background(), fakeKMS{}, "absent"); err == nil {
+		t.Error("no error for absent key")
+	}
+}
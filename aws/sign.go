@@ -0,0 +1,72 @@
+//go:build !jwtverifyonly
+
+package aws
+
+import (
+	"context"
+	"crypto"
+	"encoding/json"
+	"fmt"
+	"io"
+
+	"github.com/pascaldekloe/jwt"
+)
+
+// Signer issues tokens with a KMS key. Multiple goroutines may invoke methods
+// on a Signer simultaneously.
+type Signer struct {
+	client KMS
+	keyID  string
+	public crypto.PublicKey
+}
+
+// NewSigner returns a Signer for the KMS key with keyID, which is either a key
+// ID, a key ARN, an alias name or an alias ARN. The public key is retrieved
+// once.
+func NewSigner(ctx context.Context, client KMS, keyID string) (*Signer, error) {
+	pub, err := publicKey(ctx, client, keyID)
+	if err != nil {
+		return nil, err
+	}
+	return &Signer{client: client, keyID: keyID, public: pub}, nil
+}
+
+// KeyID returns the identifier in use for KMS.
+func (s *Signer) KeyID() string { return s.keyID }
+
+// Public returns the public key, which is either an *ecdsa.PublicKey or an
+// *rsa.PublicKey.
+func (s *Signer) Public() crypto.PublicKey { return s.public }
+
+// Sign updates the Raw fields and returns a new JWT, signed by KMS. Claims
+// without a KeyID get the one from Signer, which matches the key ID from
+// LoadKeys. See jwt.Claims.SignWith for details.
+func (s *Signer) Sign(ctx context.Context, c *jwt.Claims, alg string, extraHeaders ...json.RawMessage) (token []byte, err error) {
+	spec, ok := SigningAlgorithms[alg]
+	if !ok {
+		return nil, jwt.AlgError(alg)
+	}
+	if c.KeyID == "" {
+		c.KeyID = s.keyID
+	}
+	return c.SignWith(alg, &kmsSigner{ctx: ctx, signer: s, spec: spec}, extraHeaders...)
+}
+
+// KMSSigner binds a context and a signing algorithm to crypto.Signer.
+type kmsSigner struct {
+	ctx    context.Context
+	signer *Signer
+	spec   string
+}
+
+// Public implements crypto.Signer.
+func (k *kmsSigner) Public() crypto.PublicKey { return k.signer.public }
+
+// Sign implements crypto.Signer.
+func (k *kmsSigner) Sign(_ io.Reader, digest []byte, _ crypto.SignerOpts) ([]byte, error) {
+	sig, err := k.signer.client.Sign(k.ctx, k.signer.keyID, digest, k.spec)
+	if err != nil {
+		return nil, fmt.Errorf("aws: KMS sign with %q: %w", k.signer.keyID, err)
+	}
+	return sig, nil
+}
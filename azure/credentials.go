@@ -0,0 +1,171 @@
+package azure
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/url"
+	"os"
+	"strings"
+	"time"
+)
+
+// DefaultAuthorityHost is the Microsoft Entra ID endpoint of the public cloud.
+const DefaultAuthorityHost = "https://login.microsoftonline.com/"
+
+// DefaultIMDSEndpoint is the token endpoint of the Azure Instance Metadata
+// Service.
+const DefaultIMDSEndpoint = "http://169.254.169.254/metadata/identity/oauth2/token"
+
+// Key Vault access as an OAuth 2.0 scope, and as a resource for IMDS.
+const (
+	vaultScope    = "https://vault.azure.net/.default"
+	vaultResource = "https://vault.azure.net"
+)
+
+var (
+	errNoToken           = errors.New("azure: access token absent in response")
+	errWorkloadIdentity  = errors.New("azure: workload identity environment incomplete")
+	errNoAssertionSource = errors.New("azure: workload identity without token file")
+)
+
+// Credentials obtain access tokens for Key Vault.
+type Credentials interface {
+	// AccessToken returns a new token with its lifetime.
+	AccessToken(ctx context.Context, client *http.Client) (token string, lifetime time.Duration, err error)
+}
+
+// ClientSecret authenticates an app registration with a client secret.
+type ClientSecret struct {
+	TenantID string // directory identifier
+	ClientID string // application identifier
+	Secret   string // client secret value
+
+	// AuthorityHost defaults to DefaultAuthorityHost when empty.
+	AuthorityHost string
+}
+
+// AccessToken honors the Credentials interface.
+func (cred *ClientSecret) AccessToken(ctx context.Context, client *http.Client) (token string, lifetime time.Duration, err error) {
+	return requestToken(ctx, client, cred.AuthorityHost, cred.TenantID, url.Values{
+		"grant_type":    {"client_credentials"},
+		"client_id":     {cred.ClientID},
+		"client_secret": {cred.Secret},
+		"scope":         {vaultScope},
+	})
+}
+
+// ManagedIdentity authenticates with the identity of the Azure resource, as
+// provided by the Instance Metadata Service (IMDS). No secrets are involved.
+type ManagedIdentity struct {
+	// ClientID selects a user-assigned identity. The system-assigned
+	// identity applies when empty.
+	ClientID string
+
+	// Endpoint defaults to DefaultIMDSEndpoint when empty.
+	Endpoint string
+}
+
+// AccessToken honors the Credentials interface.
+func (cred *ManagedIdentity) AccessToken(ctx context.Context, client *http.Client) (token string, lifetime time.Duration, err error) {
+	endpoint := cred.Endpoint
+	if endpoint == "" {
+		endpoint = DefaultIMDSEndpoint
+	}
+	params := url.Values{
+		"api-version": {"2018-02-01"},
+		"resource":    {vaultResource},
+	}
+	if cred.ClientID != "" {
+		params.Set("client_id", cred.ClientID)
+	}
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, endpoint+"?"+params.Encode(), nil)
+	if err != nil {
+		return "", 0, err
+	}
+	req.Header.Set("Metadata", "true")
+	return tokenResponse(client, req)
+}
+
+// WorkloadIdentity authenticates with a federated token from Kubernetes, as
+// with Microsoft Entra Workload ID on AKS. No secrets are distributed.
+type WorkloadIdentity struct {
+	TenantID string // directory identifier
+	ClientID string // application or user-assigned identity
+
+	// TokenFile has the federated token. The file is read on each
+	// access token request, as Kubernetes rotates its content.
+	TokenFile string
+
+	// AuthorityHost defaults to DefaultAuthorityHost when empty.
+	AuthorityHost string
+}
+
+// WorkloadIdentityFromEnv returns the configuration of the AKS workload
+// identity webhook, which are the environment variables AZURE_TENANT_ID,
+// AZURE_CLIENT_ID, AZURE_FEDERATED_TOKEN_FILE and AZURE_AUTHORITY_HOST.
+func WorkloadIdentityFromEnv() (*WorkloadIdentity, error) {
+	cred := &WorkloadIdentity{
+		TenantID:      os.Getenv("AZURE_TENANT_ID"),
+		ClientID:      os.Getenv("AZURE_CLIENT_ID"),
+		TokenFile:     os.Getenv("AZURE_FEDERATED_TOKEN_FILE"),
+		AuthorityHost: os.Getenv("AZURE_AUTHORITY_HOST"),
+	}
+	if cred.TenantID == "" || cred.ClientID == "" || cred.TokenFile == "" {
+		return nil, errWorkloadIdentity
+	}
+	return cred, nil
+}
+
+// AccessToken honors the Credentials interface.
+func (cred *WorkloadIdentity) AccessToken(ctx context.Context, client *http.Client) (token string, lifetime time.Duration, err error) {
+	if cred.TokenFile == "" {
+		return "", 0, errNoAssertionSource
+	}
+	assertion, err := os.ReadFile(cred.TokenFile)
+	if err != nil {
+		return "", 0, err
+	}
+	return requestToken(ctx, client, cred.AuthorityHost, cred.TenantID, url.Values{
+		"grant_type":            {"client_credentials"},
+		"client_id":             {cred.ClientID},
+		"client_assertion_type": {"urn:ietf:params:oauth:client-assertion-type:jwt-bearer"},
+		"client_assertion":      {strings.TrimSpace(string(assertion))},
+		"scope":                 {vaultScope},
+	})
+}
+
+// RequestToken posts to the token endpoint of Microsoft Entra ID.
+func requestToken(ctx context.Context, client *http.Client, host, tenantID string, form url.Values) (token string, lifetime time.Duration, err error) {
+	if host == "" {
+		host = DefaultAuthorityHost
+	}
+	tokenURL := strings.TrimRight(host, "/") + "/" + url.PathEscape(tenantID) + "/oauth2/v2.0/token"
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, tokenURL, strings.NewReader(form.Encode()))
+	if err != nil {
+		return "", 0, err
+	}
+	req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+	return tokenResponse(client, req)
+}
+
+// TokenResponse reads an access token from either Entra ID or IMDS.
+func tokenResponse(client *http.Client, req *http.Request) (token string, lifetime time.Duration, err error) {
+	var resp struct {
+		AccessToken string `json:"access_token"`
+		// number from Entra ID, and string from IMDS
+		ExpiresIn json.Number `json:"expires_in"`
+	}
+	if _, err := send(client, req, &resp); err != nil {
+		return "", 0, err
+	}
+	if resp.AccessToken == "" {
+		return "", 0, errNoToken
+	}
+	seconds, err := resp.ExpiresIn.Int64()
+	if err != nil {
+		return "", 0, errors.New("azure: access token with malformed expires_in")
+	}
+	return resp.AccessToken, time.Duration(seconds) * time.Second, nil
+}
@@ -0,0 +1,386 @@
+// Package azure provides token issuance with keys from Azure Key Vault, without
+// any dependency on the Azure SDK. Private keys never leave Key Vault.
+package azure
+
+import (
+	"bytes"
+	"context"
+	"crypto"
+	"crypto/ecdsa"
+	"crypto/rsa"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"path"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/pascaldekloe/jwt"
+)
+
+// Access tokens are renewed before expiry with at least this margin.
+const renewMargin = 5 * time.Minute
+
+// Key lookups are cached for this duration.
+const keyCacheTTL = 10 * time.Minute
+
+// DefaultAPIVersion is the Key Vault REST API version in use by default.
+const DefaultAPIVersion = "7.4"
+
+// KeyVaultClient accesses the keys of an Azure Key Vault. OAuth 2.0 access
+// tokens are obtained with Credentials. Tokens are renewed before expiry, and
+// rejections with status code 401 (Unauthorized) are retried once with a new
+// token.
+//
+// Multiple goroutines may invoke methods on a KeyVaultClient simultaneously.
+type KeyVaultClient struct {
+	// VaultURL is the base URL of the vault, e.g.,
+	// "https://example.vault.azure.net".
+	VaultURL string
+
+	// Credentials authenticate the client, e.g., ManagedIdentity.
+	Credentials Credentials
+
+	// HTTPClient defaults to http.DefaultClient when nil.
+	HTTPClient *http.Client
+
+	// APIVersion defaults to DefaultAPIVersion when empty.
+	APIVersion string
+
+	// RequestTimeout limits each HTTP exchange, including the ones for
+	// access tokens, when non-zero. Context deadlines apply regardless.
+	RequestTimeout time.Duration
+
+	tokenMutex   sync.Mutex
+	token        string
+	tokenExpires time.Time
+
+	keyMutex sync.Mutex
+	keys     map[string]*vaultKey // by name and optional version
+}
+
+// NewKeyVaultClient returns a new client for the vault, which authenticates
+// as the app registration appID in tenant tenantID. See Credentials for other
+// means of authentication.
+func NewKeyVaultClient(vaultURL, tenantID, appID, appSecret string) *KeyVaultClient {
+	return &KeyVaultClient{
+		VaultURL: vaultURL,
+		Credentials: &ClientSecret{
+			TenantID: tenantID,
+			ClientID: appID,
+			Secret:   appSecret,
+		},
+	}
+}
+
+// VaultKey is the public side of a key version.
+type vaultKey struct {
+	kid     string // key identifier URL
+	public  crypto.PublicKey
+	fetched time.Time
+}
+
+// Key returns the public side of a key with its name, optionally followed by
+// a slash and the version. The latest version applies when absent.
+func (c *KeyVaultClient) key(ctx context.Context, name string) (*vaultKey, error) {
+	now := jwt.Clock()
+	c.keyMutex.Lock()
+	k, ok := c.keys[name]
+	c.keyMutex.Unlock()
+	if ok && now.Sub(k.fetched) < keyCacheTTL {
+		return k, nil
+	}
+
+	var bundle struct {
+		Key json.RawMessage `json:"key"`
+	}
+	if err := c.do(ctx, http.MethodGet, c.keyURL(name, ""), nil, &bundle); err != nil {
+		return nil, err
+	}
+	kid, public, err := parseJWK(bundle.Key)
+	if err != nil {
+		return nil, fmt.Errorf("azure: key %q: %w", name, err)
+	}
+	k = &vaultKey{kid: kid, public: public, fetched: now}
+
+	c.keyMutex.Lock()
+	if c.keys == nil {
+		c.keys = make(map[string]*vaultKey)
+	}
+	c.keys[name] = k
+	c.keyMutex.Unlock()
+	return k, nil
+}
+
+// LoadKeys adds the public keys of each enabled version of each key in the
+// vault to the register, with the key identifier URL as the key ID, which
+// matches Sign. Symmetric keys are skipped. Keys which are present already,
+// with the same key ID, are skipped, as with jwt.KeyRegister.Merge.
+func (c *KeyVaultClient) LoadKeys(register *jwt.KeyRegister) (keysAdded int, err error) {
+	return c.LoadKeysContext(context.Background(), register)
+}
+
+// LoadKeysContext is like LoadKeys, yet bound to ctx.
+func (c *KeyVaultClient) LoadKeysContext(ctx context.Context, register *jwt.KeyRegister) (keysAdded int, err error) {
+	keyIDs, err := c.list(ctx, strings.TrimRight(c.VaultURL, "/")+"/keys?api-version="+url.QueryEscape(c.apiVersion()))
+	if err != nil {
+		return 0, err
+	}
+
+	var staged jwt.KeyRegister
+	for _, keyID := range keyIDs {
+		name := path.Base(keyID)
+		versionIDs, err := c.list(ctx, c.keyURL(name, "versions"))
+		if err != nil {
+			return 0, err
+		}
+		for _, versionID := range versionIDs {
+			var bundle struct {
+				Key json.RawMessage `json:"key"`
+			}
+			err := c.do(ctx, http.MethodGet, c.keyURL(name+"/"+path.Base(versionID), ""), nil, &bundle)
+			if err != nil {
+				return 0, err
+			}
+			var kty struct {
+				Kty string `json:"kty"`
+			}
+			if err := json.Unmarshal(bundle.Key, &kty); err != nil {
+				return 0, fmt.Errorf("azure: key %q: %w", versionID, err)
+			}
+			if strings.HasPrefix(kty.Kty, "oct") {
+				continue // symmetric
+			}
+
+			kid, public, err := parseJWK(bundle.Key)
+			if err != nil {
+				return 0, fmt.Errorf("azure: key %q: %w", versionID, err)
+			}
+			switch t := public.(type) {
+			case *ecdsa.PublicKey:
+				staged.ECDSAs = append(staged.ECDSAs, t)
+				staged.ECDSAIDs = append(staged.ECDSAIDs, kid)
+			case *rsa.PublicKey:
+				staged.RSAs = append(staged.RSAs, t)
+				staged.RSAIDs = append(staged.RSAIDs, kid)
+			}
+		}
+	}
+	return register.Merge(&staged)
+}
+
+// List returns the identifiers of the enabled items from a collection, with
+// all of its pages.
+func (c *KeyVaultClient) list(ctx context.Context, location string) (ids []string, err error) {
+	for location != "" {
+		var page struct {
+			Value []struct {
+				Kid        string `json:"kid"`
+				Attributes struct {
+					Enabled *bool `json:"enabled"`
+				} `json:"attributes"`
+			} `json:"value"`
+			NextLink string `json:"nextLink"`
+		}
+		if err := c.do(ctx, http.MethodGet, location, nil, &page); err != nil {
+			return nil, err
+		}
+		for _, item := range page.Value {
+			if item.Attributes.Enabled == nil || *item.Attributes.Enabled {
+				ids = append(ids, item.Kid)
+			}
+		}
+		location = page.NextLink
+	}
+	return ids, nil
+}
+
+// ParseJWK reads a JSON Web Key from Key Vault.
+func parseJWK(data json.RawMessage) (kid string, public crypto.PublicKey, err error) {
+	var m map[string]interface{}
+	if err := json.Unmarshal(data, &m); err != nil {
+		return "", nil, err
+	}
+	// hardware protection as in "EC-HSM" and "RSA-HSM"
+	if kty, ok := m["kty"].(string); ok {
+		m["kty"] = strings.TrimSuffix(kty, "-HSM")
+	}
+	kid, _ = m["kid"].(string)
+	if kid == "" {
+		return "", nil, errors.New("key identifier absent")
+	}
+	normalized, err := json.Marshal(m)
+	if err != nil {
+		return "", nil, err
+	}
+
+	var keys jwt.KeyRegister
+	if _, err := keys.LoadJWK(normalized); err != nil {
+		return "", nil, err
+	}
+	switch {
+	case len(keys.ECDSAs) == 1:
+		return kid, keys.ECDSAs[0], nil
+	case len(keys.RSAs) == 1:
+		return kid, keys.RSAs[0], nil
+	}
+	return "", nil, fmt.Errorf("unsupported key type %q", m["kty"])
+}
+
+// KeyURL returns the location of a key with an optional operation.
+func (c *KeyVaultClient) keyURL(name, operation string) string {
+	var buf strings.Builder
+	buf.WriteString(strings.TrimRight(c.VaultURL, "/"))
+	buf.WriteString("/keys/")
+	for i, s := range strings.SplitN(name, "/", 2) {
+		if i != 0 {
+			buf.WriteByte('/')
+		}
+		buf.WriteString(url.PathEscape(s))
+	}
+	if operation != "" {
+		buf.WriteByte('/')
+		buf.WriteString(operation)
+	}
+	buf.WriteString("?api-version=")
+	buf.WriteString(url.QueryEscape(c.apiVersion()))
+	return buf.String()
+}
+
+func (c *KeyVaultClient) apiVersion() string {
+	if c.APIVersion != "" {
+		return c.APIVersion
+	}
+	return DefaultAPIVersion
+}
+
+// WithTimeout applies the RequestTimeout, if any.
+func (c *KeyVaultClient) withTimeout(ctx context.Context) (context.Context, context.CancelFunc) {
+	if c.RequestTimeout <= 0 {
+		return ctx, func() {}
+	}
+	return context.WithTimeout(ctx, c.RequestTimeout)
+}
+
+// AccessToken returns a cached access token, or a new one when the cache is
+// about to expire, or when renew is set.
+func (c *KeyVaultClient) accessToken(ctx context.Context, renew bool) (string, error) {
+	c.tokenMutex.Lock()
+	defer c.tokenMutex.Unlock()
+
+	now := jwt.Clock()
+	if !renew && c.token != "" && now.Before(c.tokenExpires) {
+		return c.token, nil
+	}
+
+	ctx, cancel := c.withTimeout(ctx)
+	defer cancel()
+	token, lifetime, err := c.Credentials.AccessToken(ctx, c.httpClient())
+	if err != nil {
+		return "", err
+	}
+	if token == "" {
+		return "", errNoToken
+	}
+
+	margin := renewMargin
+	if margin > lifetime/2 {
+		margin = lifetime / 2
+	}
+	c.token = token
+	c.tokenExpires = now.Add(lifetime - margin)
+	return c.token, nil
+}
+
+// Do exchanges JSON with Key Vault. Status code 401 gets one retry with a new
+// access token.
+func (c *KeyVaultClient) do(ctx context.Context, method, location string, body, dst interface{}) error {
+	var payload []byte
+	if body != nil {
+		var err error
+		payload, err = json.Marshal(body)
+		if err != nil {
+			return err
+		}
+	}
+
+	for attempt := 0; ; attempt++ {
+		token, err := c.accessToken(ctx, attempt != 0)
+		if err != nil {
+			return err
+		}
+		status, err := c.exchange(ctx, method, location, token, payload, dst)
+		if status == http.StatusUnauthorized && attempt == 0 {
+			continue
+		}
+		return err
+	}
+}
+
+// Exchange sends one request with the RequestTimeout applied.
+func (c *KeyVaultClient) exchange(ctx context.Context, method, location, token string, payload []byte, dst interface{}) (status int, err error) {
+	ctx, cancel := c.withTimeout(ctx)
+	defer cancel()
+	req, err := http.NewRequestWithContext(ctx, method, location, bytes.NewReader(payload))
+	if err != nil {
+		return 0, err
+	}
+	req.Header.Set("Authorization", "Bearer "+token)
+	if payload != nil {
+		req.Header.Set("Content-Type", "application/json")
+	}
+	return send(c.httpClient(), req, dst)
+}
+
+func (c *KeyVaultClient) httpClient() *http.Client {
+	if c.HTTPClient != nil {
+		return c.HTTPClient
+	}
+	return http.DefaultClient
+}
+
+// Send reads the JSON response into dst. The status code is zero when no
+// response was received.
+func send(client *http.Client, req *http.Request, dst interface{}) (status int, err error) {
+	resp, err := client.Do(req)
+	if err != nil {
+		return 0, fmt.Errorf("azure: %s %s: %w", req.Method, req.URL.Path, err)
+	}
+	defer resp.Body.Close()
+	data, err := io.ReadAll(io.LimitReader(resp.Body, 1<<20))
+	if err != nil {
+		return resp.StatusCode, fmt.Errorf("azure: %s %s: %w", req.Method, req.URL.Path, err)
+	}
+
+	if resp.StatusCode/100 != 2 {
+		// Key Vault has an object and Entra ID has a string
+		var e struct {
+			Error       json.RawMessage `json:"error"`
+			Description string          `json:"error_description"`
+		}
+		json.Unmarshal(data, &e)
+		var vaultErr struct {
+			Code    string `json:"code"`
+			Message string `json:"message"`
+		}
+		var msg string
+		switch {
+		case json.Unmarshal(e.Error, &vaultErr) == nil && vaultErr.Message != "":
+			msg = vaultErr.Code + ": " + vaultErr.Message
+		case e.Description != "":
+			msg = e.Description
+		}
+		if msg != "" {
+			return resp.StatusCode, fmt.Errorf("azure: %s %s: %s: %s", req.Method, req.URL.Path, resp.Status, msg)
+		}
+		return resp.StatusCode, fmt.Errorf("azure: %s %s: %s", req.Method, req.URL.Path, resp.Status)
+	}
+	if err := json.Unmarshal(data, dst); err != nil {
+		return resp.StatusCode, fmt.Errorf("azure: %s %s: malformed response: %w", req.Method, req.URL.Path, err)
+	}
+	return resp.StatusCode, nil
+}
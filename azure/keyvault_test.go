@@ -0,0 +1,425 @@
+package azure
+
+import (
+	"context"
+	"crypto"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/rsa"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"math/big"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/pascaldekloe/jwt"
+)
+
+// FakeAzure serves both Entra ID and Key Vault.
+type fakeAzure struct {
+	t    *testing.T
+	url  string
+	keys map[string]crypto.Signer // by name
+
+	mutex      sync.Mutex
+	issued     int           // access token count
+	valid      string        // accepted access token
+	apiVersion string        // expected
+	delay      time.Duration // Key Vault latency
+}
+
+func newFakeAzure(t *testing.T) (*fakeAzure, *httptest.Server) {
+	ec, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	if err != nil {
+		t.Fatal(err)
+	}
+	rsaKey, err := rsa.GenerateKey(rand.Reader, 2048)
+	if err != nil {
+		t.Fatal(err)
+	}
+	f := &fakeAzure{t: t, keys: map[string]crypto.Signer{"ec": ec, "rsa": rsaKey}, apiVersion: "7.4"}
+	srv := httptest.NewServer(f)
+	f.url = srv.URL
+	return f, srv
+}
+
+func (f *fakeAzure) revoke() {
+	f.mutex.Lock()
+	defer f.mutex.Unlock()
+	f.valid = ""
+}
+
+func (f *fakeAzure) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	f.mutex.Lock()
+	defer f.mutex.Unlock()
+
+	if r.URL.Path == "/metadata/identity/oauth2/token" {
+		if r.Header.Get("Metadata") != "true" || r.URL.Query().Get("resource") != "https://vault.azure.net" || r.URL.Query().Get("client_id") != "uami1" {
+			w.WriteHeader(http.StatusBadRequest)
+			w.Write([]byte(`{"error":"invalid_request","error_description":"Required metadata header not specified"}`))
+			return
+		}
+		f.issued++
+		f.valid = "token" + strconv.Itoa(f.issued)
+		// IMDS has strings for numbers
+		json.NewEncoder(w).Encode(map[string]interface{}{
+			"access_token": f.valid, "expires_in": "86399", "token_type": "Bearer",
+		})
+		return
+	}
+
+	if r.URL.Path == "/tenant1/oauth2/v2.0/token" {
+		secretOK := r.PostFormValue("client_id") == "app1" && r.PostFormValue("client_secret") == "secret1"
+		assertionOK := r.PostFormValue("client_id") == "wid1" && r.PostFormValue("client_assertion") == "federated.token.k8s" &&
+			r.PostFormValue("client_assertion_type") == "urn:ietf:params:oauth:client-assertion-type:jwt-bearer"
+		if !(secretOK || assertionOK) || r.PostFormValue("grant_type") != "client_credentials" || r.PostFormValue("scope") != "https://vault.azure.net/.default" {
+			w.WriteHeader(http.StatusUnauthorized)
+			w.Write([]byte(`{"error":"invalid_client","error_description":"AADSTS7000215: Invalid client secret provided."}`))
+			return
+		}
+		f.issued++
+		f.valid = "token" + strconv.Itoa(f.issued)
+		json.NewEncoder(w).Encode(map[string]interface{}{
+			"access_token": f.valid, "expires_in": 3600, "token_type": "Bearer",
+		})
+		return
+	}
+
+	if got := r.URL.Query().Get("api-version"); got != f.apiVersion {
+		f.t.Errorf("got API version %q, want %q", got, f.apiVersion)
+	}
+	if f.delay != 0 {
+		select {
+		case <-time.After(f.delay):
+			break
+		case <-r.Context().Done():
+			return
+		}
+	}
+	if f.valid == "" || r.Header.Get("Authorization") != "Bearer "+f.valid {
+		w.WriteHeader(http.StatusUnauthorized)
+		w.Write([]byte(`{"error":{"code":"Unauthorized","message":"AKV10000: Request is missing a Bearer or PoP token."}}`))
+		return
+	}
+
+	if r.URL.Path == "/keys" {
+		// two pages, with a symmetric key
+		if r.URL.Query().Get("$skiptoken") == "" {
+			json.NewEncoder(w).Encode(map[string]interface{}{
+				"value":    []interface{}{map[string]interface{}{"kid": f.url + "/keys/ec", "attributes": map[string]bool{"enabled": true}}},
+				"nextLink": f.url + "/keys?api-version=7.4&$skiptoken=2",
+			})
+		} else {
+			json.NewEncoder(w).Encode(map[string]interface{}{
+				"value": []interface{}{
+					map[string]interface{}{"kid": f.url + "/keys/rsa"},
+					map[string]interface{}{"kid": f.url + "/keys/sym"},
+				},
+			})
+		}
+		return
+	}
+
+	path := strings.Split(strings.TrimPrefix(r.URL.Path, "/keys/"), "/")
+	if path[0] == "sym" {
+		switch {
+		case len(path) == 2 && path[1] == "versions":
+			w.Write([]byte(`{"value":[{"kid":"` + f.url + `/keys/sym/v1"}]}`))
+		default:
+			w.Write([]byte(`{"key":{"kid":"` + f.url + `/keys/sym/v1","kty":"oct-HSM"}}`))
+		}
+		return
+	}
+	key, ok := f.keys[path[0]]
+	if !ok {
+		w.WriteHeader(http.StatusNotFound)
+		w.Write([]byte(`{"error":{"code":"KeyNotFound","message":"A key with (name/id) absent was not found in this key vault."}}`))
+		return
+	}
+	kid := f.url + "/keys/" + path[0] + "/v1"
+
+	switch {
+	case r.Method == http.MethodGet && len(path) == 2 && path[1] == "versions":
+		// disabled version v0 is not served
+		json.NewEncoder(w).Encode(map[string]interface{}{
+			"value": []interface{}{
+				map[string]interface{}{"kid": f.url + "/keys/" + path[0] + "/v0", "attributes": map[string]bool{"enabled": false}},
+				map[string]interface{}{"kid": kid, "attributes": map[string]bool{"enabled": true}},
+			},
+		})
+
+	case r.Method == http.MethodGet && (len(path) == 1 || len(path) == 2 && path[1] == "v1"):
+		jwk := map[string]interface{}{"kid": kid}
+		switch pub := key.Public().(type) {
+		case *ecdsa.PublicKey:
+			jwk["kty"], jwk["crv"] = "EC-HSM", "P-256"
+			jwk["x"] = base64.RawURLEncoding.EncodeToString(pub.X.FillBytes(make([]byte, 32)))
+			jwk["y"] = base64.RawURLEncoding.EncodeToString(pub.Y.FillBytes(make([]byte, 32)))
+		case *rsa.PublicKey:
+			jwk["kty"] = "RSA"
+			jwk["n"] = base64.RawURLEncoding.EncodeToString(pub.N.Bytes())
+			jwk["e"] = base64.RawURLEncoding.EncodeToString(big.NewInt(int64(pub.E)).Bytes())
+		}
+		json.NewEncoder(w).Encode(map[string]interface{}{"key": jwk})
+
+	case r.Method == http.MethodPost && len(path) == 3 && path[1] == "v1" && path[2] == "sign":
+		var req struct{ Alg, Value string }
+		if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+			f.t.Fatal(err)
+		}
+		digest, err := base64.RawURLEncoding.DecodeString(req.Value)
+		if err != nil {
+			f.t.Fatal(err)
+		}
+		var sig []byte
+		switch req.Alg {
+		case jwt.ES256:
+			r, s, err := ecdsa.Sign(rand.Reader, key.(*ecdsa.PrivateKey), digest)
+			if err != nil {
+				f.t.Fatal(err)
+			}
+			sig = append(r.FillBytes(make([]byte, 32)), s.FillBytes(make([]byte, 32))...)
+		case jwt.RS256:
+			sig, err = rsa.SignPKCS1v15(rand.Reader, key.(*rsa.PrivateKey), crypto.SHA256, digest)
+		case jwt.PS256:
+			sig, err = rsa.SignPSS(rand.Reader, key.(*rsa.PrivateKey), crypto.SHA256, digest, &rsa.PSSOptions{SaltLength: rsa.PSSSaltLengthEqualsHash})
+		default:
+			w.WriteHeader(http.StatusBadRequest)
+			return
+		}
+		if err != nil {
+			f.t.Fatal(err)
+		}
+		json.NewEncoder(w).Encode(map[string]string{
+			"kid": kid, "value": base64.RawURLEncoding.EncodeToString(sig),
+		})
+
+	default:
+		http.NotFound(w, r)
+	}
+}
+
+func newTestClient(f *fakeAzure, srv *httptest.Server) *KeyVaultClient {
+	client := NewKeyVaultClient(srv.URL, "tenant1", "app1", "secret1")
+	client.Credentials.(*ClientSecret).AuthorityHost = srv.URL
+	client.HTTPClient = srv.Client()
+	return client
+}
+
+func TestSign(t *testing.T) {
+	f, srv := newFakeAzure(t)
+	defer srv.Close()
+	client := newTestClient(f, srv)
+
+	keys := &jwt.KeyRegister{
+		ECDSAs:   []*ecdsa.PublicKey{&f.keys["ec"].(*ecdsa.PrivateKey).PublicKey},
+		ECDSAIDs: []string{srv.URL + "/keys/ec/v1"},
+		RSAs:     []*rsa.PublicKey{&f.keys["rsa"].(*rsa.PrivateKey).PublicKey},
+		RSAIDs:   []string{srv.URL + "/keys/rsa/v1"},
+	}
+
+	golden := []struct{ alg, keyName string }{
+		{jwt.ES256, "ec"},
+		{jwt.RS256, "rsa"},
+		{jwt.PS256, "rsa"},
+	}
+	for _, gold := range golden {
+		var c jwt.Claims
+		c.Subject = "test"
+		token, err := client.Sign(&c, gold.alg, gold.keyName)
+		if err != nil {
+			t.Fatalf("%s with %q: sign error: %s", gold.alg, gold.keyName, err)
+		}
+		got, err := keys.Check(token)
+		if err != nil {
+			t.Fatalf("%s with %q: check error: %s", gold.alg, gold.keyName, err)
+		}
+		if got.Subject != "test" {
+			t.Errorf("%s with %q: got subject %q", gold.alg, gold.keyName, got.Subject)
+		}
+	}
+	if f.issued != 1 {
+		t.Errorf("got %d access tokens issued, want 1 cached", f.issued)
+	}
+
+	_, err := client.Sign(new(jwt.Claims), jwt.ES256, "absent")
+	want := "azure: GET /keys/absent: 404 Not Found: KeyNotFound: A key with (name/id) absent was not found in this key vault."
+	if err == nil || err.Error() != want {
+		t.Errorf("got error %v, want %q", err, want)
+	}
+}
+
+func TestLoadKeys(t *testing.T) {
+	f, srv := newFakeAzure(t)
+	defer srv.Close()
+	client := newTestClient(f, srv)
+
+	var keys jwt.KeyRegister
+	n, err := client.LoadKeys(&keys)
+	if err != nil {
+		t.Fatal("load error:", err)
+	}
+	if n != 2 {
+		t.Errorf("got %d keys added, want 2", n)
+	}
+	if len(keys.ECDSAIDs) != 1 || keys.ECDSAIDs[0] != srv.URL+"/keys/ec/v1" {
+		t.Errorf("got ECDSA key IDs %q", keys.ECDSAIDs)
+	}
+	if len(keys.RSAIDs) != 1 || keys.RSAIDs[0] != srv.URL+"/keys/rsa/v1" {
+		t.Errorf("got RSA key IDs %q", keys.RSAIDs)
+	}
+
+	var c jwt.Claims
+	token, err := client.Sign(&c, jwt.PS256, "rsa")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := keys.Check(token); err != nil {
+		t.Error("check error:", err)
+	}
+
+	// idempotent
+	n, err = client.LoadKeys(&keys)
+	if err != nil || n != 0 {
+		t.Errorf("reload got %d keys added, error %v, want 0 and none", n, err)
+	}
+}
+
+func TestTokenRenewal(t *testing.T) {
+	defer func() { jwt.Clock = time.Now }()
+	now := time.Unix(1e9, 0)
+	jwt.Clock = func() time.Time { return now }
+
+	f, srv := newFakeAzure(t)
+	defer srv.Close()
+	client := newTestClient(f, srv)
+
+	sign := func() {
+		t.Helper()
+		if _, err := client.Sign(new(jwt.Claims), jwt.ES256, "ec"); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	sign()
+	now = now.Add(54 * time.Minute)
+	sign()
+	if f.issued != 1 {
+		t.Errorf("got %d access tokens before the renewal margin, want 1", f.issued)
+	}
+	now = now.Add(time.Minute)
+	sign()
+	if f.issued != 2 {
+		t.Errorf("got %d access tokens within the renewal margin, want 2", f.issued)
+	}
+
+	// retry on 401
+	f.revoke()
+	sign()
+	if f.issued != 3 {
+		t.Errorf("got %d access tokens after revoke, want 3", f.issued)
+	}
+}
+
+func TestAuthError(t *testing.T) {
+	f, srv := newFakeAzure(t)
+	defer srv.Close()
+	client := newTestClient(f, srv)
+	client.Credentials.(*ClientSecret).Secret = "wrong"
+
+	_, err := client.Sign(new(jwt.Claims), jwt.ES256, "ec")
+	want := "azure: POST /tenant1/oauth2/v2.0/token: 401 Unauthorized: AADSTS7000215: Invalid client secret provided."
+	if err == nil || err.Error() != want {
+		t.Errorf("got error %v, want %q", err, want)
+	}
+}
+
+func TestManagedIdentity(t *testing.T) {
+	f, srv := newFakeAzure(t)
+	defer srv.Close()
+	client := &KeyVaultClient{
+		VaultURL:    srv.URL,
+		Credentials: &ManagedIdentity{ClientID: "uami1", Endpoint: srv.URL + "/metadata/identity/oauth2/token"},
+		HTTPClient:  srv.Client(),
+	}
+	if _, err := client.Sign(new(jwt.Claims), jwt.ES256, "ec"); err != nil {
+		t.Fatal(err)
+	}
+	if f.issued != 1 {
+		t.Errorf("got %d access tokens, want 1", f.issued)
+	}
+
+	client.Credentials = &ManagedIdentity{Endpoint: srv.URL + "/metadata/identity/oauth2/token"}
+	f.revoke()
+	_, err := client.Sign(new(jwt.Claims), jwt.ES256, "ec")
+	want := "azure: GET /metadata/identity/oauth2/token: 400 Bad Request: Required metadata header not specified"
+	if err == nil || err.Error() != want {
+		t.Errorf("got error %v, want %q", err, want)
+	}
+}
+
+func TestWorkloadIdentity(t *testing.T) {
+	f, srv := newFakeAzure(t)
+	defer srv.Close()
+
+	tokenFile := filepath.Join(t.TempDir(), "azure-identity-token")
+	if err := os.WriteFile(tokenFile, []byte("federated.token.k8s\n"), 0o600); err != nil {
+		t.Fatal(err)
+	}
+	t.Setenv("AZURE_TENANT_ID", "tenant1")
+	t.Setenv("AZURE_CLIENT_ID", "wid1")
+	t.Setenv("AZURE_FEDERATED_TOKEN_FILE", tokenFile)
+	t.Setenv("AZURE_AUTHORITY_HOST", srv.URL)
+	cred, err := WorkloadIdentityFromEnv()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	client := &KeyVaultClient{VaultURL: srv.URL, Credentials: cred, HTTPClient: srv.Client()}
+	if _, err := client.Sign(new(jwt.Claims), jwt.ES256, "ec"); err != nil {
+		t.Fatal(err)
+	}
+	if f.issued != 1 {
+		t.Errorf("got %d access tokens, want 1", f.issued)
+	}
+
+	t.Setenv("AZURE_FEDERATED_TOKEN_FILE", "")
+	if _, err := WorkloadIdentityFromEnv(); err != errWorkloadIdentity {
+		t.Errorf("got error %v, want %v", err, errWorkloadIdentity)
+	}
+}
+
+func TestSignContext(t *testing.T) {
+	f, srv := newFakeAzure(t)
+	defer srv.Close()
+	client := newTestClient(f, srv)
+
+	f.apiVersion = "7.5"
+	client.APIVersion = "7.5"
+	if _, err := client.SignContext(context.Background(), new(jwt.Claims), jwt.ES256, "ec"); err != nil {
+		t.Fatal(err)
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	if _, err := client.SignContext(ctx, new(jwt.Claims), jwt.RS256, "rsa"); !errors.Is(err, context.Canceled) {
+		t.Errorf("got error %v, want %v", err, context.Canceled)
+	}
+
+	f.mutex.Lock()
+	f.delay = time.Second
+	f.mutex.Unlock()
+	client.RequestTimeout = 10 * time.Millisecond
+	if _, err := client.SignContext(context.Background(), new(jwt.Claims), jwt.RS256, "rsa"); !errors.Is(err, context.DeadlineExceeded) {
+		t.Errorf("got error %v, want %v", err, context.DeadlineExceeded)
+	}
+}
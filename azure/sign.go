@@ -0,0 +1,83 @@
+//go:build !jwtverifyonly
+
+package azure
+
+import (
+	"context"
+	"crypto"
+	"crypto/ecdsa"
+	"encoding/asn1"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"io"
+	"math/big"
+	"net/http"
+	"net/url"
+
+	"github.com/pascaldekloe/jwt"
+)
+
+var errSigSize = errors.New("azure: signature of malformed size")
+
+// Sign updates the Raw fields and returns a new JWT, signed by Key Vault with
+// the key of keyName, which is the name optionally followed by a slash and the
+// version. The latest version applies when absent. Claims get the key
+// identifier URL of the version as their KeyID. See jwt.Claims.SignWith for
+// details.
+func (c *KeyVaultClient) Sign(claims *jwt.Claims, alg, keyName string, extraHeaders ...json.RawMessage) (token []byte, err error) {
+	return c.SignContext(context.Background(), claims, alg, keyName, extraHeaders...)
+}
+
+// SignContext is like Sign, yet bound to ctx, which includes the access token
+// acquisition, if any.
+func (c *KeyVaultClient) SignContext(ctx context.Context, claims *jwt.Claims, alg, keyName string, extraHeaders ...json.RawMessage) (token []byte, err error) {
+	k, err := c.key(ctx, keyName)
+	if err != nil {
+		return nil, err
+	}
+	claims.KeyID = k.kid
+	return claims.SignWith(alg, &vaultSigner{ctx: ctx, client: c, key: k, alg: alg}, extraHeaders...)
+}
+
+// VaultSigner binds a key version and an algorithm to crypto.Signer.
+type vaultSigner struct {
+	ctx    context.Context
+	client *KeyVaultClient
+	key    *vaultKey
+	alg    string
+}
+
+// Public implements crypto.Signer.
+func (s *vaultSigner) Public() crypto.PublicKey { return s.key.public }
+
+// Sign implements crypto.Signer.
+func (s *vaultSigner) Sign(_ io.Reader, digest []byte, _ crypto.SignerOpts) ([]byte, error) {
+	req := struct {
+		Alg   string `json:"alg"`
+		Value string `json:"value"`
+	}{s.alg, base64.RawURLEncoding.EncodeToString(digest)}
+	var resp struct {
+		Value string `json:"value"`
+	}
+	location := s.key.kid + "/sign?api-version=" + url.QueryEscape(s.client.apiVersion())
+	if err := s.client.do(s.ctx, http.MethodPost, location, &req, &resp); err != nil {
+		return nil, err
+	}
+	sig, err := base64.RawURLEncoding.DecodeString(resp.Value)
+	if err != nil {
+		return nil, err
+	}
+
+	if _, ok := s.key.public.(*ecdsa.PublicKey); ok {
+		// Key Vault has the JWS format, while crypto.Signer has ASN.1
+		if len(sig) == 0 || len(sig)%2 != 0 {
+			return nil, errSigSize
+		}
+		return asn1.Marshal(struct{ R, S *big.Int }{
+			R: new(big.Int).SetBytes(sig[:len(sig)/2]),
+			S: new(big.Int).SetBytes(sig[len(sig)/2:]),
+		})
+	}
+	return sig, nil
+}
@@ -116,6 +116,41 @@ func BenchmarkHMAC(b *testing.B) {
 			}
 			b.ReportMetric(float64(tokenLen)/float64(b.N), "B/token")
 		})
+
+		b.Run("sign-"+alg+"-append", func(b *testing.B) {
+			hmac, err := NewHMAC(alg, secret)
+			if err != nil {
+				b.Fatal(err)
+			}
+			buf := make([]byte, 0, 512)
+			var tokenLen int
+			for i := 0; i < b.N; i++ {
+				token, err := hmac.AppendSign(buf[:0], benchClaims)
+				if err != nil {
+					b.Fatal(err)
+				}
+				tokenLen += len(token)
+			}
+			b.ReportMetric(float64(tokenLen)/float64(b.N), "B/token")
+		})
+
+		b.Run("sign-"+alg+"-reuse-kid", func(b *testing.B) {
+			hmac, err := NewHMAC(alg, secret)
+			if err != nil {
+				b.Fatal(err)
+			}
+			c := *benchClaims
+			c.KeyID = "2024-q3"
+			var tokenLen int
+			for i := 0; i < b.N; i++ {
+				token, err := hmac.Sign(&c)
+				if err != nil {
+					b.Fatal(err)
+				}
+				tokenLen += len(token)
+			}
+			b.ReportMetric(float64(tokenLen)/float64(b.N), "B/token")
+		})
 	}
 
 	for _, alg := range algs {
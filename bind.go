@@ -0,0 +1,55 @@
+package jwt
+
+import "encoding/json"
+
+// As decodes the claims into a custom type with encoding/json. Structs may
+// embed Registered for the standard claims. The audience is always presented
+// as a JSON array, i.e., a single string is normalized as with Check. Claims
+// need not originate from a token, as Raw is not used.
+func As[T any](c *Claims) (T, error) {
+	var v T
+	bytes, err := json.Marshal(c.All())
+	if err != nil {
+		return v, err
+	}
+	err = json.Unmarshal(bytes, &v)
+	return v, err
+}
+
+// All returns the complete claims set as a new map. Each non-zero Registered
+// value is added to a copy of Set, in the JSON representation of Set. The map
+// is useful for generic processing, as Check moves the registered claims out
+// of Set. Modifications to the return have no effect on c.
+func (c *Claims) All() map[string]interface{} {
+	m := make(map[string]interface{}, len(c.Set)+7)
+	for name, v := range c.Set {
+		m[name] = v
+	}
+
+	if c.Issuer != "" {
+		m[issuer] = c.Issuer
+	}
+	if c.Subject != "" {
+		m[subject] = c.Subject
+	}
+	if len(c.Audiences) != 0 {
+		array := make([]interface{}, len(c.Audiences))
+		for i, s := range c.Audiences {
+			array[i] = s
+		}
+		m[audience] = array
+	}
+	if c.Expires != nil {
+		m[expires] = float64(*c.Expires)
+	}
+	if c.NotBefore != nil {
+		m[notBefore] = float64(*c.NotBefore)
+	}
+	if c.Issued != nil {
+		m[issued] = float64(*c.Issued)
+	}
+	if c.ID != "" {
+		m[id] = c.ID
+	}
+	return m
+}
@@ -0,0 +1,60 @@
+package jwt
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestAs(t *testing.T) {
+	type custom struct {
+		Registered
+		Roles []string `json:"roles"`
+		Admin bool     `json:"admin"`
+	}
+
+	token := []byte("eyJhbGciOiJIUzI1NiJ9.eyJzdWIiOiJhbGljZSIsImF1ZCI6ImFwaSIsImV4cCI6MTUzNzYyMjc5NCwicm9sZXMiOlsiZGV2IiwicWEiXSwiYWRtaW4iOnRydWV9.")
+	c, err := ParseWithoutCheck(token)
+	if err != nil {
+		t.Fatal("parse error:", err)
+	}
+
+	got, err := As[custom](c)
+	if err != nil {
+		t.Fatal("As error:", err)
+	}
+	exp := NumericTime(1537622794)
+	want := custom{
+		Registered: Registered{Subject: "alice", Audiences: []string{"api"}, Expires: &exp},
+		Roles:      []string{"dev", "qa"},
+		Admin:      true,
+	}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("got %+v, want %+v", got, want)
+	}
+
+	if _, err := As[struct {
+		Admin string `json:"admin"`
+	}](c); err == nil {
+		t.Error("type mismatch got no error")
+	}
+}
+
+func TestAll(t *testing.T) {
+	exp := NumericTime(1537622794)
+	c := &Claims{
+		Registered: Registered{Issuer: "a", Audiences: []string{"b"}, Expires: &exp},
+		Set:        map[string]interface{}{"iss": "ignored", "scope": "read"},
+	}
+	want := map[string]interface{}{
+		"iss":   "a",
+		"aud":   []interface{}{"b"},
+		"exp":   1537622794.0,
+		"scope": "read",
+	}
+	if got := c.All(); !reflect.DeepEqual(got, want) {
+		t.Errorf("got %#v, want %#v", got, want)
+	}
+	if c.Set["iss"] != "ignored" {
+		t.Error("Set modified")
+	}
+}
@@ -9,13 +9,16 @@ import (
 	"encoding/json"
 	"errors"
 	"fmt"
-	"hash"
 	"math/big"
 )
 
 // ErrSigMiss means the signature check failed.
 var ErrSigMiss = errors.New("jwt: signature mismatch")
 
+// ErrSigSize means the signature length does not fit the algorithm, or the
+// key. Such tokens are rejected before any verification attempt.
+var ErrSigSize = errors.New("jwt: malformed signature: unexpected size")
+
 var errNoPayload = errors.New("jwt: one part only—payload absent")
 
 // “Producers MUST NOT use the empty list "[]" as the "crit" value.”
@@ -43,6 +46,20 @@ func ParseWithoutCheck(token []byte) (*Claims, error) {
 	return &c, c.applyPayload()
 }
 
+// Split decodes the three parts of a token in compact serialization, without
+// any validation of the content. The signature is empty for unsecured tokens.
+func Split(token []byte) (header, payload, signature []byte, err error) {
+	if n := bytes.Count(token, []byte{'.'}); n != 2 {
+		return nil, nil, nil, fmt.Errorf("jwt: compact serialization with %d dots, want 2", n)
+	}
+	var c Claims
+	_, signature, err = c.decodeParts(token)
+	if err != nil {
+		return nil, nil, nil, err
+	}
+	return c.RawHeader, c.Raw, signature, nil
+}
+
 // ECDSACheck parses a JWT if, and only if, the signature checks out.
 // The return is an AlgError when the algorithm is not in ECDSAAlgs.
 // Use Valid to complete the verification.
@@ -57,6 +74,12 @@ func ECDSACheck(token []byte, key *ecdsa.PublicKey) (*Claims, error) {
 	if err != nil {
 		return nil, err
 	}
+	if !ecdsaSigSizeOK(len(sig), key) {
+		return nil, ErrSigSize
+	}
+	if len(sig) != ecdsaSigSize(key) {
+		return nil, ErrSigMiss // other curve
+	}
 	digest := hash.New()
 	digest.Write(token[:bodyLen])
 
@@ -82,6 +105,12 @@ func EdDSACheck(token []byte, key ed25519.PublicKey) (*Claims, error) {
 	if alg != EdDSA {
 		return nil, AlgError(alg)
 	}
+	if err := fipsAlg(alg, 0); err != nil {
+		return nil, err
+	}
+	if len(sig) != ed25519.SignatureSize {
+		return nil, ErrSigSize
+	}
 
 	if !ed25519.Verify(key, token[:bodyLen], sig) {
 		return nil, ErrSigMiss
@@ -94,8 +123,8 @@ func EdDSACheck(token []byte, key ed25519.PublicKey) (*Claims, error) {
 // The return is an AlgError when the algorithm is not in HMACAlgs.
 // Use Valid to complete the verification.
 func HMACCheck(token, secret []byte) (*Claims, error) {
-	if len(secret) == 0 {
-		return nil, errNoSecret
+	if err := checkSecret(secret); err != nil {
+		return nil, err
 	}
 
 	var c Claims
@@ -108,6 +137,9 @@ func HMACCheck(token, secret []byte) (*Claims, error) {
 	if err != nil {
 		return nil, err
 	}
+	if len(sig) != hash.Size() {
+		return nil, ErrSigSize
+	}
 	digest := hmac.New(hash.New, secret)
 	digest.Write(token[:bodyLen])
 
@@ -131,10 +163,15 @@ func (h *HMAC) Check(token []byte) (*Claims, error) {
 	if alg != h.alg {
 		return nil, AlgError(alg)
 	}
+	if len(sig) != h.size {
+		return nil, ErrSigSize
+	}
 
-	digest := h.digests.Get().(hash.Hash)
-	defer h.digests.Put(digest)
-	digest.Reset()
+	digest, err := h.digest()
+	if err != nil {
+		return nil, err
+	}
+	defer h.release(digest)
 	digest.Write(token[:bodyLen])
 
 	buf := sig[len(sig):]
@@ -159,6 +196,9 @@ func RSACheck(token []byte, key *rsa.PublicKey) (*Claims, error) {
 	if err != nil {
 		return nil, err
 	}
+	if err := fipsRSA(key); err != nil {
+		return nil, err
+	}
 	digest := hash.New()
 	digest.Write(token[:bodyLen])
 
@@ -175,6 +215,28 @@ func RSACheck(token []byte, key *rsa.PublicKey) (*Claims, error) {
 	return &c, c.applyPayload()
 }
 
+// EcdsaSigSize returns the signature size for key, which is twice the size of
+// an integer modulo the curve order, as in RFC 7518, subsection 3.4.
+func ecdsaSigSize(key *ecdsa.PublicKey) int {
+	return 2 * ((key.Curve.Params().BitSize + 7) / 8)
+}
+
+// EcdsaSigSizeOK returns whether n is a signature size for either one of the
+// curves from RFC 7518, subsection 3.4, or for the curve of any of the keys.
+// Sizes which pass may still not fit a specific key.
+func ecdsaSigSizeOK(n int, keys ...*ecdsa.PublicKey) bool {
+	switch n {
+	case 2 * 32, 2 * 48, 2 * 66: // P-256, P-384, P-521
+		return true
+	}
+	for _, key := range keys {
+		if n == ecdsaSigSize(key) {
+			return true
+		}
+	}
+	return false
+}
+
 // DecodeParts reads up to three base64 parts. The result goes in c.RawHeader, c.Raw and sig.
 func (c *Claims) decodeParts(token []byte) (bodyLen int, sig []byte, err error) {
 	// fits all 3 parts decoded + buffer space for Hash.Sum.
@@ -317,5 +379,5 @@ func (c *Claims) applyPayload() error {
 		c.ID = s
 	}
 
-	return nil
+	return c.checkTimes()
 }
@@ -185,6 +185,42 @@ func TestAcceptAudiences(t *testing.T) {
 	}
 }
 
+func TestAcceptAudienceMatch(t *testing.T) {
+	golden := []struct {
+		aud      string
+		resource string
+		m        AudienceMatch
+		want     bool
+	}{
+		{"https://api.example.com", "https://api.example.com", AudienceExact, true},
+		{"https://api.example.com", "https://api.example.com/v2/users", AudienceExact, false},
+		{"https://api.example.com", "https://api.example.com/v2/users", AudiencePrefix, true},
+		{"https://api.example.com", "https://api.example.com?q", AudiencePrefix, true},
+		{"https://api.example.com/", "https://api.example.com/v2", AudiencePrefix, true},
+		{"https://api.example.com", "https://api.example.com.evil.net", AudiencePrefix, false},
+		{"https://api.example.com/v2", "https://api.example.com/v22", AudiencePrefix, false},
+		{"api", "api/v2", AudiencePrefix, false},
+		{"https://api.example.com", "https://api.example.com/v2/users", AudienceWildcard, false},
+		{"https://*.example.com", "https://api.example.com", AudienceWildcard, true},
+		{"https://*.example.com", "https://evil.net/.example.com", AudienceWildcard, false},
+		{"https://*.example.com", "https://api.example.com", AudiencePrefix, false},
+		{"https://api.example.com/*", "https://api.example.com/v2", AudienceWildcard, true},
+		{"https://api.example.com/*", "https://api.example.com/v2/users", AudienceWildcard, false},
+		{"https://api.example.com/*/users", "https://api.example.com/v2/users", AudienceWildcard, true},
+		{"*", "", AudienceWildcard, true},
+	}
+	for _, gold := range golden {
+		r := Registered{Audiences: []string{gold.aud}}
+		if got := r.AcceptAudienceMatch(gold.resource, gold.m); got != gold.want {
+			t.Errorf("audience %q with mode %d got %t for %q, want %t", gold.aud, gold.m, got, gold.resource, gold.want)
+		}
+	}
+
+	if !new(Registered).AcceptAudienceMatch("any", AudiencePrefix) {
+		t.Error("absent audience not accepted")
+	}
+}
+
 func TestCheckAlgError(t *testing.T) {
 	const token = "eyJhbGciOiJkb2VzbnRleGlzdCJ9.e30.e30"
 	const want = AlgError("doesntexist")
@@ -325,8 +361,8 @@ func TestCheckPart(t *testing.T) {
 	}
 
 	_, err = ECDSACheck([]byte("eyJhbGciOiJFUzI1NiJ9.e30"), &testKeyEC384.PublicKey)
-	if err != ErrSigMiss {
-		t.Errorf("no signature got error %v, want %v", err, ErrSigMiss)
+	if err != ErrSigSize {
+		t.Errorf("no signature got error %v, want %v", err, ErrSigSize)
 	}
 	// none alg needs leading dot (for some reason)
 	_, err = HMACCheck([]byte("eyJhbGciOiJub25lIn0.e30"), []byte("guest"))
@@ -449,3 +485,85 @@ OAbhk3Xu+bHsp+AO+FwC7hNjRGTGnvf3E/BmdGZWaXyKbR7Gj3MXg4UCAwEAAQ==
 		}
 	})
 }
+
+func TestSplit(t *testing.T) {
+	header, payload, sig, err := Split([]byte("eyJhbGciOiJIUzI1NiJ9.eyJzdWIiOiJhbGljZSJ9.AQID"))
+	if err != nil {
+		t.Fatal("split error:", err)
+	}
+	if string(header) != `{"alg":"HS256"}` {
+		t.Errorf("got header %q", header)
+	}
+	if string(payload) != `{"sub":"alice"}` {
+		t.Errorf("got payload %q", payload)
+	}
+	if string(sig) != "\x01\x02\x03" {
+		t.Errorf("got signature %#x", sig)
+	}
+
+	for _, token := range []string{
+		"eyJhbGciOiJIUzI1NiJ9.eyJzdWIiOiJhbGljZSJ9",
+		"eyJhbGciOiJIUzI1NiJ9.eyJzdWIiOiJhbGljZSJ9.AQID.AQID",
+		"eyJhbGciOiJIUzI1NiJ9.eyJzdWIiOiJhbGljZSJ9.A",
+		"eyJhbGciOiJIUzI1NiJ9=.eyJzdWIiOiJhbGljZSJ9.AQID",
+	} {
+		if _, _, _, err := Split([]byte(token)); err == nil {
+			t.Errorf("%q got no error", token)
+		}
+	}
+}
+
+func TestCheckSigSize(t *testing.T) {
+	keys := &KeyRegister{
+		ECDSAs:  []*ecdsa.PublicKey{&testKeyEC256.PublicKey},
+		EdDSAs:  []ed25519.PublicKey{testKeyEd25519Public},
+		Secrets: [][]byte{[]byte("guest")},
+	}
+	h, err := NewHMAC(HS256, []byte("guest"))
+	if err != nil {
+		t.Fatal("NewHMAC error:", err)
+	}
+
+	// 4-byte signatures
+	const ecToken = "eyJhbGciOiJFUzI1NiJ9.e30.AQIDBA"
+	if _, err := ECDSACheck([]byte(ecToken), &testKeyEC256.PublicKey); err != ErrSigSize {
+		t.Errorf("ECDSA got error %v, want %v", err, ErrSigSize)
+	}
+	const edToken = "eyJhbGciOiJFZERTQSJ9.e30.AQIDBA"
+	if _, err := EdDSACheck([]byte(edToken), testKeyEd25519Public); err != ErrSigSize {
+		t.Errorf("EdDSA got error %v, want %v", err, ErrSigSize)
+	}
+	const hsToken = "eyJhbGciOiJIUzI1NiJ9.e30.AQIDBA"
+	if _, err := HMACCheck([]byte(hsToken), []byte("guest")); err != ErrSigSize {
+		t.Errorf("HMAC got error %v, want %v", err, ErrSigSize)
+	}
+	if _, err := h.Check([]byte(hsToken)); err != ErrSigSize {
+		t.Errorf("HMAC reuse got error %v, want %v", err, ErrSigSize)
+	}
+	for _, token := range []string{ecToken, edToken, hsToken} {
+		if _, err := keys.Check([]byte(token)); err != ErrSigSize {
+			t.Errorf("KeyRegister %q got error %v, want %v", token, err, ErrSigSize)
+		}
+	}
+}
+
+func TestCheckECDSASigSizePerKey(t *testing.T) {
+	// P-384 signature size with a P-521 key
+	var c Claims
+	token, err := c.ECDSASign(ES512, testKeyEC384)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := ECDSACheck(token, &testKeyEC521.PublicKey); err != ErrSigMiss {
+		t.Errorf("ECDSA got error %v, want %v", err, ErrSigMiss)
+	}
+	keys := &KeyRegister{ECDSAs: []*ecdsa.PublicKey{&testKeyEC256.PublicKey, &testKeyEC521.PublicKey}}
+	if _, err := keys.Check(token); err != ErrSigMiss {
+		t.Errorf("KeyRegister got error %v, want %v", err, ErrSigMiss)
+	}
+
+	keys.ECDSAs = append(keys.ECDSAs, &testKeyEC384.PublicKey)
+	if _, err := keys.Check(token); err != nil {
+		t.Error("KeyRegister with P-384 key got error:", err)
+	}
+}
@@ -0,0 +1,44 @@
+package jwt
+
+import (
+	"crypto/rand"
+	"encoding/hex"
+	"fmt"
+	"io"
+	"time"
+)
+
+// Clock is the time source for token issuance, and for validation by Handler
+// and SessionManager. Tests may replace it for reproducible results.
+var Clock = time.Now
+
+// Entropy is the source of randomness for NewID. Tests may replace it for
+// reproducible results.
+var Entropy io.Reader = rand.Reader
+
+// NewID returns a random JWT ID with 128 bits from Entropy.
+func NewID() (string, error) {
+	var buf [16]byte
+	if _, err := io.ReadFull(Entropy, buf[:]); err != nil {
+		return "", fmt.Errorf("jwt: JWT ID generation: %w", err)
+	}
+	return hex.EncodeToString(buf[:]), nil
+}
+
+// Stamp sets the issue time to Clock, with the expiry lifetime later, when
+// lifetime is positive. The ID is set with NewID, when absent.
+func (c *Claims) Stamp(lifetime time.Duration) error {
+	if c.ID == "" {
+		id, err := NewID()
+		if err != nil {
+			return err
+		}
+		c.ID = id
+	}
+	now := Clock().Truncate(time.Second)
+	c.Issued = NewNumericTime(now)
+	if lifetime > 0 {
+		c.Expires = NewNumericTime(now.Add(lifetime))
+	}
+	return nil
+}
@@ -0,0 +1,52 @@
+package jwt
+
+import (
+	"bytes"
+	"io"
+	"testing"
+	"time"
+)
+
+func TestStamp(t *testing.T) {
+	defer func(clock func() time.Time) { Clock = clock }(Clock)
+	defer func(entropy io.Reader) { Entropy = entropy }(Entropy)
+	Clock = func() time.Time { return time.Unix(1537622794, 5e8) }
+	Entropy = bytes.NewReader(make([]byte, 32))
+
+	var c Claims
+	if err := c.Stamp(time.Hour); err != nil {
+		t.Fatal("stamp error:", err)
+	}
+	if want := "00000000000000000000000000000000"; c.ID != want {
+		t.Errorf("got ID %q, want %q", c.ID, want)
+	}
+	if c.Issued == nil || *c.Issued != 1537622794 {
+		t.Errorf("got issue time %v, want 1537622794", c.Issued)
+	}
+	if c.Expires == nil || *c.Expires != 1537622794+3600 {
+		t.Errorf("got expiry %v, want 1537626394", c.Expires)
+	}
+
+	// reproducible
+	a, err := c.HMACSign(HS256, []byte("guest"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	c = Claims{}
+	if err := c.Stamp(time.Hour); err != nil {
+		t.Fatal("stamp error:", err)
+	}
+	b, err := c.HMACSign(HS256, []byte("guest"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !bytes.Equal(a, b) {
+		t.Errorf("got tokens %q and %q, want equal", a, b)
+	}
+
+	// entropy exhausted
+	c = Claims{}
+	if err := c.Stamp(0); err == nil {
+		t.Error("got no error with exhausted entropy")
+	}
+}
@@ -0,0 +1,268 @@
+//go:build !jwtverifyonly
+
+// Command jwtvectors generates a JSON corpus of tokens for the validation of
+// other implementations against package jwt. The tokens cover all algorithms,
+// with edge cases in the claims, such as Unicode, large numbers and fractional
+// times. The keys are committed in the keys directory, and the corpus includes
+// their public JWKS and the HMAC secret.
+//
+// Usage:
+//
+//	jwtvectors [-o file]
+//
+// Note that ECDSA and RSASSA-PSS signatures are randomized, i.e., the tokens
+// differ per run for such algorithms.
+package main
+
+import (
+	"crypto/ecdsa"
+	"crypto/ed25519"
+	"crypto/rsa"
+	"crypto/x509"
+	"embed"
+	"encoding/base64"
+	"encoding/json"
+	"encoding/pem"
+	"errors"
+	"flag"
+	"fmt"
+	"math"
+	"os"
+	"time"
+
+	"github.com/pascaldekloe/jwt"
+)
+
+//go:embed keys/*.pem
+var keyFiles embed.FS
+
+// Secret is the HMAC key. Never use it outside of tests.
+var secret = []byte("jwtvectors HMAC secret, do not use")
+
+// Corpus is the output document.
+type corpus struct {
+	JWKS    json.RawMessage `json:"jwks"`        // public keys
+	Secret  string          `json:"hmac_secret"` // base64url without padding
+	Vectors []vector        `json:"vectors"`
+}
+
+// Vector is a token with its expected content.
+type vector struct {
+	Name   string          `json:"name"`
+	Alg    string          `json:"alg"`
+	Token  string          `json:"token"`
+	Header json.RawMessage `json:"header"`
+	Claims json.RawMessage `json:"claims"`
+}
+
+func main() {
+	output := flag.String("o", "", "write to `file` instead of standard output")
+	flag.Usage = func() {
+		fmt.Fprintln(flag.CommandLine.Output(), "Usage: jwtvectors [-o file]")
+		flag.PrintDefaults()
+	}
+	flag.Parse()
+
+	doc, err := generate()
+	if err != nil {
+		fmt.Fprintln(os.Stderr, "jwtvectors:", err)
+		os.Exit(1)
+	}
+	bytes, err := json.MarshalIndent(doc, "", "\t")
+	if err != nil {
+		fmt.Fprintln(os.Stderr, "jwtvectors:", err)
+		os.Exit(1)
+	}
+	bytes = append(bytes, '\n')
+
+	if *output == "" {
+		_, err = os.Stdout.Write(bytes)
+	} else {
+		err = os.WriteFile(*output, bytes, 0o644)
+	}
+	if err != nil {
+		fmt.Fprintln(os.Stderr, "jwtvectors:", err)
+		os.Exit(1)
+	}
+}
+
+// Signers has the sign function per algorithm.
+type signers map[string]func(c *jwt.Claims, extraHeaders ...json.RawMessage) ([]byte, error)
+
+// LoadSigners reads the committed keys. The register gets the public keys,
+// with their file name as the key ID.
+func loadSigners(keys *jwt.KeyRegister) (signers, error) {
+	s := make(signers)
+	for _, name := range []string{"ed25519", "ec256", "ec384", "ec521", "rsa2048"} {
+		text, err := keyFiles.ReadFile("keys/" + name + ".pem")
+		if err != nil {
+			return nil, err
+		}
+		block, _ := pem.Decode(text)
+		if block == nil {
+			return nil, fmt.Errorf("keys/%s.pem: no PEM", name)
+		}
+		key, err := x509.ParsePKCS8PrivateKey(block.Bytes)
+		if err != nil {
+			return nil, fmt.Errorf("keys/%s.pem: %w", name, err)
+		}
+		kid := name
+
+		switch key := key.(type) {
+		case ed25519.PrivateKey:
+			keys.EdDSAs = append(keys.EdDSAs, key.Public().(ed25519.PublicKey))
+			keys.EdDSAIDs = append(keys.EdDSAIDs, kid)
+			s[jwt.EdDSA] = func(c *jwt.Claims, extra ...json.RawMessage) ([]byte, error) {
+				c.KeyID = kid
+				return c.EdDSASign(key, extra...)
+			}
+		case *ecdsa.PrivateKey:
+			alg := map[string]string{"ec256": jwt.ES256, "ec384": jwt.ES384, "ec521": jwt.ES512}[name]
+			keys.ECDSAs = append(keys.ECDSAs, &key.PublicKey)
+			keys.ECDSAIDs = append(keys.ECDSAIDs, kid)
+			s[alg] = func(c *jwt.Claims, extra ...json.RawMessage) ([]byte, error) {
+				c.KeyID = kid
+				return c.ECDSASign(alg, key, extra...)
+			}
+		case *rsa.PrivateKey:
+			keys.RSAs = append(keys.RSAs, &key.PublicKey)
+			keys.RSAIDs = append(keys.RSAIDs, kid)
+			for _, alg := range []string{jwt.PS256, jwt.PS384, jwt.PS512, jwt.RS256, jwt.RS384, jwt.RS512} {
+				alg := alg
+				s[alg] = func(c *jwt.Claims, extra ...json.RawMessage) ([]byte, error) {
+					c.KeyID = kid
+					return c.RSASign(alg, key, extra...)
+				}
+			}
+		default:
+			return nil, fmt.Errorf("keys/%s.pem: unsupported key type %T", name, key)
+		}
+	}
+
+	for _, alg := range []string{jwt.HS256, jwt.HS384, jwt.HS512} {
+		alg := alg
+		s[alg] = func(c *jwt.Claims, extra ...json.RawMessage) ([]byte, error) {
+			c.KeyID = "" // none for the secret
+			return c.HMACSign(alg, secret, extra...)
+		}
+	}
+	return s, nil
+}
+
+// Algs has the algorithms in output order.
+var algs = []string{
+	jwt.EdDSA,
+	jwt.ES256, jwt.ES384, jwt.ES512,
+	jwt.HS256, jwt.HS384, jwt.HS512,
+	jwt.PS256, jwt.PS384, jwt.PS512,
+	jwt.RS256, jwt.RS384, jwt.RS512,
+}
+
+// Case is a claims set for all algorithms.
+type testCase struct {
+	name   string
+	claims func() *jwt.Claims
+	extra  []json.RawMessage
+}
+
+var testCases = []testCase{
+	{"empty", func() *jwt.Claims { return new(jwt.Claims) }, nil},
+
+	{"registered", func() *jwt.Claims {
+		c := new(jwt.Claims)
+		c.Issuer = "https://issuer.example.com"
+		c.Subject = "alice"
+		c.Audiences = []string{"https://api.example.com", "urn:example:other"}
+		c.Expires = jwt.NewNumericTime(time.Unix(2000000000, 0))
+		c.NotBefore = jwt.NewNumericTime(time.Unix(1500000000, 0))
+		c.Issued = jwt.NewNumericTime(time.Unix(1500000000, 0))
+		c.ID = "c2d1f7a4-7e1b-4b3c-9a55-0d4f1d1b2c3e"
+		return c
+	}, nil},
+
+	{"unicode", func() *jwt.Claims {
+		c := new(jwt.Claims)
+		c.Subject = "ünïcödé 🙃"
+		c.Set = map[string]interface{}{
+			"名前":         "値",
+			"separators": "line \u2028 paragraph \u2029 end",
+			"escapes":    "quote \" backslash \\ tab \t nul \u0000",
+			"html":       "<script>&amp;</script>",
+		}
+		return c
+	}, nil},
+
+	{"numbers", func() *jwt.Claims {
+		c := new(jwt.Claims)
+		c.Set = map[string]interface{}{
+			"max_safe":  json.Number("9007199254740991"),
+			"above":     json.Number("9007199254740993"),
+			"int64_max": json.Number("9223372036854775807"),
+			"negative":  json.Number("-1"),
+			"fraction":  0.1,
+			"exponent":  json.Number("1e300"),
+			"max_float": math.MaxFloat64,
+		}
+		c.Expires = jwt.NewNumericTime(time.Unix(253402300799, 0)) // 9999-12-31T23:59:59Z
+		c.Issued = jwt.NewNumericTime(time.Unix(1500000000, 500000000))
+		return c
+	}, nil},
+
+	{"structures", func() *jwt.Claims {
+		c := new(jwt.Claims)
+		c.Audiences = []string{"single"}
+		c.Set = map[string]interface{}{
+			"null":   nil,
+			"bool":   false,
+			"array":  []interface{}{1.0, "two", nil, []interface{}{}},
+			"object": map[string]interface{}{"nested": map[string]interface{}{"deep": true}},
+			"empty":  "",
+		}
+		return c
+	}, nil},
+
+	{"header", func() *jwt.Claims {
+		c := new(jwt.Claims)
+		c.Subject = "bob"
+		return c
+	}, []json.RawMessage{json.RawMessage(`{"typ":"JWT","cty":"example","x-trace":["a",1]}`)}},
+}
+
+// Generate signs each test case with each algorithm.
+func generate() (*corpus, error) {
+	keys := new(jwt.KeyRegister)
+	sign, err := loadSigners(keys)
+	if err != nil {
+		return nil, err
+	}
+	jwks, err := keys.JWKS()
+	if err != nil {
+		return nil, err
+	}
+
+	doc := &corpus{
+		JWKS:   jwks,
+		Secret: base64.RawURLEncoding.EncodeToString(secret),
+	}
+	for _, tc := range testCases {
+		for _, alg := range algs {
+			f, ok := sign[alg]
+			if !ok {
+				return nil, errors.New("no key for " + alg)
+			}
+			c := tc.claims()
+			token, err := f(c, tc.extra...)
+			if err != nil {
+				return nil, fmt.Errorf("%s with %s: %w", tc.name, alg, err)
+			}
+			doc.Vectors = append(doc.Vectors, vector{
+				Name:   tc.name,
+				Alg:    alg,
+				Token:  string(token),
+				Header: c.RawHeader,
+				Claims: c.Raw,
+			})
+		}
+	}
+	return doc, nil
+}
@@ -0,0 +1,45 @@
+//go:build !jwtverifyonly
+
+package main
+
+import (
+	"encoding/base64"
+	"testing"
+
+	"github.com/pascaldekloe/jwt"
+)
+
+func TestGenerate(t *testing.T) {
+	doc, err := generate()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got, want := len(doc.Vectors), len(testCases)*len(algs); got != want {
+		t.Errorf("got %d vectors, want %d", got, want)
+	}
+
+	// verify as a consumer would
+	var keys jwt.KeyRegister
+	if _, err := keys.LoadJWK(doc.JWKS); err != nil {
+		t.Fatal("JWKS:", err)
+	}
+	secret, err := base64.RawURLEncoding.DecodeString(doc.Secret)
+	if err != nil {
+		t.Fatal("HMAC secret:", err)
+	}
+	keys.Secrets = [][]byte{secret}
+
+	for _, v := range doc.Vectors {
+		c, err := keys.Check([]byte(v.Token))
+		if err != nil {
+			t.Errorf("%s with %s: %s", v.Name, v.Alg, err)
+			continue
+		}
+		if string(c.RawHeader) != string(v.Header) {
+			t.Errorf("%s with %s: got header %s, want %s", v.Name, v.Alg, c.RawHeader, v.Header)
+		}
+		if string(c.Raw) != string(v.Claims) {
+			t.Errorf("%s with %s: got claims %s, want %s", v.Name, v.Alg, c.Raw, v.Claims)
+		}
+	}
+}
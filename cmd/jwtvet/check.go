@@ -0,0 +1,301 @@
+package main
+
+import (
+	"go/ast"
+	"go/token"
+	"go/types"
+	"strings"
+)
+
+const jwtPath = "github.com/pascaldekloe/jwt"
+
+// Diagnostic is a finding at a source position.
+type diagnostic struct {
+	pos token.Pos
+	msg string
+}
+
+// ValidationMethods apply the time constraints of claims.
+var validationMethods = map[string]bool{
+	"Valid":          true,
+	"AcceptTemporal": true,
+	"AcceptAge":      true,
+	"TTL":            true,
+}
+
+// Check returns the diagnostics for a type-checked file.
+func check(f *ast.File, info *types.Info) []diagnostic {
+	var diags []diagnostic
+	for _, decl := range f.Decls {
+		fn, ok := decl.(*ast.FuncDecl)
+		if !ok || fn.Body == nil {
+			continue
+		}
+		c := funcChecker{info: info, parents: make(map[ast.Node]ast.Node), defs: make(map[types.Object]ast.Expr)}
+		c.index(fn.Body)
+		diags = append(diags, c.run(fn.Body)...)
+	}
+
+	// secrets in composite literals may reside outside functions too
+	ast.Inspect(f, func(n ast.Node) bool {
+		lit, ok := n.(*ast.CompositeLit)
+		if !ok || !isJWTType(info.TypeOf(lit), "KeyRegister") {
+			return true
+		}
+		for _, e := range lit.Elts {
+			kv, ok := e.(*ast.KeyValueExpr)
+			if !ok {
+				continue
+			}
+			if key, ok := kv.Key.(*ast.Ident); !ok || key.Name != "Secrets" {
+				continue
+			}
+			if secrets, ok := kv.Value.(*ast.CompositeLit); ok {
+				for _, secret := range secrets.Elts {
+					if isLiteralBytes(info, secret) {
+						diags = append(diags, diagnostic{secret.Pos(), "HMAC secret from string literal; load secrets from configuration"})
+					}
+				}
+			}
+		}
+		return true
+	})
+	return diags
+}
+
+// FuncChecker applies the checks on a function body.
+type funcChecker struct {
+	info    *types.Info
+	parents map[ast.Node]ast.Node
+	defs    map[types.Object]ast.Expr // single assignments only
+}
+
+// Index records the parent of each node, and the assignments to variables.
+func (c *funcChecker) index(body *ast.BlockStmt) {
+	var stack []ast.Node
+	assigned := make(map[types.Object]int)
+	ast.Inspect(body, func(n ast.Node) bool {
+		if n == nil {
+			stack = stack[:len(stack)-1]
+			return false
+		}
+		if len(stack) != 0 {
+			c.parents[n] = stack[len(stack)-1]
+		}
+		stack = append(stack, n)
+
+		if assign, ok := n.(*ast.AssignStmt); ok && (len(assign.Rhs) == 1 || len(assign.Lhs) == len(assign.Rhs)) {
+			for i, lhs := range assign.Lhs {
+				id, ok := lhs.(*ast.Ident)
+				if !ok {
+					continue
+				}
+				obj := c.info.ObjectOf(id)
+				if obj == nil {
+					continue
+				}
+				assigned[obj]++
+				if len(assign.Rhs) == 1 {
+					c.defs[obj] = assign.Rhs[0]
+				} else {
+					c.defs[obj] = assign.Rhs[i]
+				}
+			}
+		}
+		return true
+	})
+	for obj, n := range assigned {
+		if n > 1 {
+			delete(c.defs, obj)
+		}
+	}
+}
+
+func (c *funcChecker) run(body *ast.BlockStmt) []diagnostic {
+	var diags []diagnostic
+	ast.Inspect(body, func(n ast.Node) bool {
+		switch n := n.(type) {
+		case *ast.AssignStmt:
+			if len(n.Rhs) != 1 || len(n.Lhs) == 0 {
+				break
+			}
+			call, ok := n.Rhs[0].(*ast.CallExpr)
+			if !ok {
+				break
+			}
+			f := jwtFunc(c.info, call)
+			if f == nil || !isCheckFunc(f) {
+				break
+			}
+			id, ok := n.Lhs[0].(*ast.Ident)
+			if !ok || id.Name == "_" {
+				break
+			}
+			if obj := c.info.ObjectOf(id); obj != nil && !c.validated(obj, body) {
+				diags = append(diags, diagnostic{call.Pos(), "claims from " + f.Name() + " are used without Valid, AcceptTemporal or AcceptAge"})
+			}
+
+		case *ast.CallExpr:
+			f := jwtFunc(c.info, n)
+			if f == nil {
+				break
+			}
+			params := f.Type().(*types.Signature).Params()
+			for i := 0; i < params.Len() && i < len(n.Args); i++ {
+				arg := n.Args[i]
+				switch params.At(i).Name() {
+				case "secret", "master":
+					if isLiteralBytes(c.info, arg) {
+						diags = append(diags, diagnostic{arg.Pos(), "HMAC secret from string literal; load secrets from configuration"})
+					}
+				case "alg":
+					if strings.Contains(f.Name(), "Sign") || f.Name() == "NewHMAC" {
+						if c.tainted(arg, 3) {
+							diags = append(diags, diagnostic{arg.Pos(), "algorithm for " + f.Name() + " from request or token input; use a constant"})
+						}
+					}
+				}
+			}
+		}
+		return true
+	})
+	return diags
+}
+
+// Validated returns whether the claims in obj either get a time check, or
+// whether they escape the function (unknown).
+func (c *funcChecker) validated(obj types.Object, body *ast.BlockStmt) bool {
+	var validated bool
+	ast.Inspect(body, func(n ast.Node) bool {
+		id, ok := n.(*ast.Ident)
+		if validated || !ok || c.info.Uses[id] != obj {
+			return !validated
+		}
+		sel, ok := c.parents[id].(*ast.SelectorExpr)
+		if !ok || sel.X != id {
+			if _, ok := c.parents[id].(*ast.AssignStmt); ok && isLHS(c.parents[id].(*ast.AssignStmt), id) {
+				return true // reassignment
+			}
+			validated = true // escapes
+			return false
+		}
+		if sel.Sel.Name == "Registered" {
+			if outer, ok := c.parents[sel].(*ast.SelectorExpr); ok {
+				sel = outer
+			}
+		}
+		if validationMethods[sel.Sel.Name] {
+			validated = true
+		}
+		return !validated
+	})
+	return validated
+}
+
+func isLHS(assign *ast.AssignStmt, id *ast.Ident) bool {
+	for _, lhs := range assign.Lhs {
+		if lhs == id {
+			return true
+		}
+	}
+	return false
+}
+
+// Tainted returns whether expr contains request or token input. Local
+// variables are followed up to depth assignments.
+func (c *funcChecker) tainted(expr ast.Expr, depth int) bool {
+	if depth <= 0 {
+		return false
+	}
+	var tainted bool
+	ast.Inspect(expr, func(n ast.Node) bool {
+		switch n := n.(type) {
+		case *ast.SelectorExpr:
+			if isInputType(c.info.TypeOf(n.X)) {
+				tainted = true
+			}
+			if isJWTType(c.info.TypeOf(n.X), "Claims") {
+				switch n.Sel.Name {
+				case "RawHeader", "Raw", "Set", "KeyID", "String", "Number":
+					tainted = true
+				}
+			}
+		case *ast.Ident:
+			obj := c.info.Uses[n]
+			if def, ok := c.defs[obj]; ok && c.tainted(def, depth-1) {
+				tainted = true
+			}
+		}
+		return !tainted
+	})
+	return tainted
+}
+
+// JWTFunc returns the function or method from package jwt, if any.
+func jwtFunc(info *types.Info, call *ast.CallExpr) *types.Func {
+	var id *ast.Ident
+	switch fun := call.Fun.(type) {
+	case *ast.Ident:
+		id = fun
+	case *ast.SelectorExpr:
+		id = fun.Sel
+	default:
+		return nil
+	}
+	f, ok := info.Uses[id].(*types.Func)
+	if !ok || f.Pkg() == nil || f.Pkg().Path() != jwtPath {
+		return nil
+	}
+	return f
+}
+
+// IsCheckFunc returns whether f verifies a token, with claims as a result.
+func isCheckFunc(f *types.Func) bool {
+	name := f.Name()
+	if name == "ParseWithoutCheck" || !strings.Contains(name, "Check") {
+		return false
+	}
+	results := f.Type().(*types.Signature).Results()
+	return results.Len() != 0 && isJWTType(results.At(0).Type(), "Claims")
+}
+
+// IsJWTType returns whether t is (a pointer to) the named type from package
+// jwt.
+func isJWTType(t types.Type, name string) bool {
+	if p, ok := t.(*types.Pointer); ok {
+		t = p.Elem()
+	}
+	named, ok := t.(*types.Named)
+	if !ok {
+		return false
+	}
+	obj := named.Obj()
+	return obj.Pkg() != nil && obj.Pkg().Path() == jwtPath && obj.Name() == name
+}
+
+// IsInputType returns whether t carries request input.
+func isInputType(t types.Type) bool {
+	if p, ok := t.(*types.Pointer); ok {
+		t = p.Elem()
+	}
+	named, ok := t.(*types.Named)
+	if !ok || named.Obj().Pkg() == nil {
+		return false
+	}
+	switch named.Obj().Pkg().Path() + "." + named.Obj().Name() {
+	case "net/http.Request", "net/http.Header", "net/http.Cookie", "net/url.URL", "net/url.Values":
+		return true
+	}
+	return false
+}
+
+// IsLiteralBytes returns whether expr is a conversion of a constant string to
+// a byte slice.
+func isLiteralBytes(info *types.Info, expr ast.Expr) bool {
+	call, ok := expr.(*ast.CallExpr)
+	if !ok || len(call.Args) != 1 || !info.Types[call.Fun].IsType() {
+		return false
+	}
+	tv := info.Types[call.Args[0]]
+	return tv.Value != nil
+}
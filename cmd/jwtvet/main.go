@@ -0,0 +1,202 @@
+// Command jwtvet reports suspicious use of package jwt. The checks are:
+//
+//   - claims from a Check function or method without any Valid,
+//     AcceptTemporal or AcceptAge call on them;
+//   - HMAC secrets from string literals;
+//   - algorithms for signing from request or token input.
+//
+// Usage:
+//
+//	jwtvet [packages]
+//
+// Packages default to "./...". The exit status is 1 when any of the checks
+// apply, and 2 on failure. The command also runs as a tool for go vet.
+//
+//	go vet -vettool=$(which jwtvet) [packages]
+package main
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"flag"
+	"fmt"
+	"go/ast"
+	"go/importer"
+	"go/parser"
+	"go/token"
+	"go/types"
+	"io"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"sort"
+)
+
+func main() {
+	version := flag.String("V", "", "print version for go vet, with \"full\"")
+	printsFlags := flag.Bool("flags", false, "print flags for go vet, as JSON")
+	asJSON := flag.Bool("json", false, "emit JSON output, for go vet")
+	flag.Usage = func() {
+		fmt.Fprintln(flag.CommandLine.Output(), "Usage: jwtvet [packages]")
+		flag.PrintDefaults()
+	}
+	flag.Parse()
+	switch {
+	case *version != "":
+		if err := printVersion(); err != nil {
+			fmt.Fprintln(os.Stderr, "jwtvet:", err)
+			os.Exit(2)
+		}
+		return
+	case *printsFlags:
+		printFlags()
+		return
+	}
+
+	args := flag.Args()
+	if isVetConfig(args) {
+		reported, err := vetUnit(args[0], *asJSON)
+		if err != nil {
+			fmt.Fprintln(os.Stderr, "jwtvet:", err)
+			os.Exit(2)
+		}
+		if reported {
+			os.Exit(1)
+		}
+		return
+	}
+
+	if len(args) == 0 {
+		args = []string{"./..."}
+	}
+	diags, err := vet(args)
+	if err != nil {
+		fmt.Fprintln(os.Stderr, "jwtvet:", err)
+		os.Exit(2)
+	}
+	for _, d := range diags {
+		fmt.Println(d)
+	}
+	if len(diags) != 0 {
+		os.Exit(1)
+	}
+}
+
+// ListedPackage is the subset of “go list -json” in use.
+type listedPackage struct {
+	Dir        string
+	ImportPath string
+	Export     string
+	GoFiles    []string
+	ImportMap  map[string]string
+	DepOnly    bool
+	Error      *struct{ Err string }
+}
+
+// Vet loads the packages which match patterns, and it returns the diagnostics
+// in order of appearance.
+func vet(patterns []string) ([]string, error) {
+	args := append([]string{"list", "-e", "-json", "-export", "-deps", "--"}, patterns...)
+	cmd := exec.Command("go", args...)
+	var stderr bytes.Buffer
+	cmd.Stderr = &stderr
+	out, err := cmd.Output()
+	if err != nil {
+		return nil, fmt.Errorf("go list: %w; %s", err, bytes.TrimSpace(stderr.Bytes()))
+	}
+
+	exports := make(map[string]string)
+	var targets []*listedPackage
+	dec := json.NewDecoder(bytes.NewReader(out))
+	for {
+		p := new(listedPackage)
+		err := dec.Decode(p)
+		if errors.Is(err, io.EOF) {
+			break
+		}
+		if err != nil {
+			return nil, fmt.Errorf("go list output: %w", err)
+		}
+		if p.Error != nil {
+			return nil, fmt.Errorf("package %s: %s", p.ImportPath, p.Error.Err)
+		}
+		exports[p.ImportPath] = p.Export
+		if !p.DepOnly {
+			targets = append(targets, p)
+		}
+	}
+
+	fset := token.NewFileSet()
+	var diags []diagnostic
+	for _, p := range targets {
+		importMap := p.ImportMap
+		lookup := func(path string) (io.ReadCloser, error) {
+			if mapped, ok := importMap[path]; ok {
+				path = mapped
+			}
+			file, ok := exports[path]
+			if !ok || file == "" {
+				return nil, fmt.Errorf("no export data for %q", path)
+			}
+			return os.Open(file)
+		}
+
+		paths := make([]string, len(p.GoFiles))
+		for i, name := range p.GoFiles {
+			paths[i] = filepath.Join(p.Dir, name)
+		}
+		found, err := checkPackage(fset, p.ImportPath, paths, lookup)
+		if err != nil {
+			return nil, err
+		}
+		diags = append(diags, found...)
+	}
+
+	return lines(fset, diags), nil
+}
+
+// Lines formats diagnostics in order of appearance.
+func lines(fset *token.FileSet, diags []diagnostic) []string {
+	sort.SliceStable(diags, func(i, j int) bool {
+		a, b := fset.Position(diags[i].pos), fset.Position(diags[j].pos)
+		if a.Filename != b.Filename {
+			return a.Filename < b.Filename
+		}
+		return a.Offset < b.Offset
+	})
+	lines := make([]string, len(diags))
+	for i, d := range diags {
+		lines[i] = fmt.Sprintf("%s: %s", fset.Position(d.pos), d.msg)
+	}
+	return lines
+}
+
+// CheckPackage type-checks the Go files of a package, with export data from
+// lookup, and it returns the diagnostics.
+func checkPackage(fset *token.FileSet, importPath string, paths []string, lookup importer.Lookup) ([]diagnostic, error) {
+	var files []*ast.File
+	for _, path := range paths {
+		f, err := parser.ParseFile(fset, path, nil, 0)
+		if err != nil {
+			return nil, err
+		}
+		files = append(files, f)
+	}
+	info := &types.Info{
+		Types:      make(map[ast.Expr]types.TypeAndValue),
+		Defs:       make(map[*ast.Ident]types.Object),
+		Uses:       make(map[*ast.Ident]types.Object),
+		Selections: make(map[*ast.SelectorExpr]*types.Selection),
+	}
+	conf := types.Config{Importer: importer.ForCompiler(fset, "gc", lookup)}
+	if _, err := conf.Check(importPath, fset, files, info); err != nil {
+		return nil, fmt.Errorf("package %s: %w", importPath, err)
+	}
+
+	var diags []diagnostic
+	for _, f := range files {
+		diags = append(diags, check(f, info)...)
+	}
+	return diags, nil
+}
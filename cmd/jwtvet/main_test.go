@@ -0,0 +1,93 @@
+package main
+
+import (
+	"bufio"
+	"bytes"
+	"fmt"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"regexp"
+	"strings"
+	"testing"
+)
+
+var wantComment = regexp.MustCompile(`// want "([^"]+)"`)
+
+func TestVet(t *testing.T) {
+	diags, err := vet([]string{"./testdata/bad"})
+	if err != nil {
+		t.Fatal("vet error:", err)
+	}
+
+	// collect expectations from the source
+	path, err := filepath.Abs("testdata/bad/bad.go")
+	if err != nil {
+		t.Fatal(err)
+	}
+	f, err := os.Open(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer f.Close()
+	want := make(map[int]string)
+	lines := bufio.NewScanner(f)
+	for n := 1; lines.Scan(); n++ {
+		if m := wantComment.FindStringSubmatch(lines.Text()); m != nil {
+			want[n] = m[1]
+		}
+	}
+
+	for _, d := range diags {
+		var line, col int
+		var msg string
+		i := strings.Index(d, ".go:")
+		if i < 0 || d[:i+3] != path {
+			t.Errorf("diagnostic outside test file: %s", d)
+			continue
+		}
+		if _, err := fmt.Sscanf(d[i+4:], "%d:%d:", &line, &col); err != nil {
+			t.Errorf("malformed diagnostic %q: %s", d, err)
+			continue
+		}
+		msg = d[strings.Index(d[i+4:], ": ")+i+6:]
+		expect, ok := want[line]
+		if !ok {
+			t.Errorf("unexpected diagnostic: %s", d)
+			continue
+		}
+		if !strings.HasPrefix(msg, expect) {
+			t.Errorf("line %d: got %q, want %q", line, msg, expect)
+		}
+		delete(want, line)
+	}
+	for line, msg := range want {
+		t.Errorf("line %d: no diagnostic, want %q", line, msg)
+	}
+}
+
+func TestVetTool(t *testing.T) {
+	tool := filepath.Join(t.TempDir(), "jwtvet")
+	if out, err := exec.Command("go", "build", "-o", tool, ".").CombinedOutput(); err != nil {
+		t.Fatalf("build error: %s; %s", err, out)
+	}
+
+	want, err := vet([]string{"./testdata/bad"})
+	if err != nil {
+		t.Fatal("vet error:", err)
+	}
+	cmd := exec.Command("go", "vet", "-vettool="+tool, "./testdata/bad")
+	var stderr bytes.Buffer
+	cmd.Stderr = &stderr
+	if err := cmd.Run(); err == nil {
+		t.Error("go vet passed, want diagnostics")
+	}
+	got := stderr.String()
+	for _, d := range want {
+		// go vet shortens the path
+		i := strings.Index(d, "testdata")
+		if !strings.Contains(got, d[i:]) {
+			t.Errorf("go vet output lacks %q:\n%s", d[i:], got)
+		}
+	}
+}
@@ -0,0 +1,61 @@
+// Package bad has misuse of package jwt, with the expected diagnostics as
+// line comments.
+package bad
+
+import (
+	"net/http"
+	"time"
+
+	"github.com/pascaldekloe/jwt"
+)
+
+var keys = jwt.KeyRegister{Secrets: [][]byte{[]byte("guest")}} // want "HMAC secret from string literal"
+
+func NoValidation(token []byte) string {
+	claims, err := keys.Check(token) // want "claims from Check are used without"
+	if err != nil {
+		return ""
+	}
+	return claims.Subject
+}
+
+func Validation(token []byte) string {
+	claims, err := keys.Check(token)
+	if err != nil || !claims.Valid(time.Now()) {
+		return ""
+	}
+	return claims.Subject
+}
+
+func Escape(token []byte) (*jwt.Claims, error) {
+	claims, err := jwt.EdDSACheck(token, nil)
+	return claims, err
+}
+
+func NoValidationHeader(r *http.Request) bool {
+	claims, err := keys.CheckHeader(r) // want "claims from CheckHeader are used without"
+	return err == nil && claims.Subject != ""
+}
+
+func LiteralSecret(c *jwt.Claims) ([]byte, error) {
+	return c.HMACSign(jwt.HS256, []byte("secret")) // want "HMAC secret from string literal"
+}
+
+func RequestAlg(c *jwt.Claims, r *http.Request, secret []byte) ([]byte, error) {
+	alg := r.URL.Query().Get("alg")
+	return c.HMACSign(alg, secret) // want "algorithm for HMACSign from request or token input"
+}
+
+func TokenAlg(token []byte, secret []byte) ([]byte, error) {
+	c, err := jwt.ParseWithoutCheck(token)
+	if err != nil {
+		return nil, err
+	}
+	alg, _ := c.String("alg")
+	return c.HMACSign(alg, secret) // want "algorithm for HMACSign from request or token input"
+}
+
+func ConstAlg(c *jwt.Claims, secret []byte) ([]byte, error) {
+	alg := jwt.HS512
+	return c.HMACSign(alg, secret)
+}
@@ -0,0 +1,143 @@
+package main
+
+import (
+	"crypto/sha256"
+	"encoding/json"
+	"fmt"
+	"go/token"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+// The go vet command runs tools with the unitchecker protocol of
+// “golang.org/x/tools/go/analysis”. This module has no dependencies,
+// so the protocol is implemented here, with the standard library only.
+
+// VetConfig is the subset of the unit description from go vet in use.
+type vetConfig struct {
+	ID          string // package ID, e.g., "fmt [fmt.test]"
+	ImportPath  string
+	GoFiles     []string          // absolute paths
+	ImportMap   map[string]string // import path to package path
+	PackageFile map[string]string // package path to export data
+	VetxOnly    bool              // only facts; no diagnostics
+	VetxOutput  string            // facts file
+	Stdout      string            // standard output file, if any
+
+	SucceedOnTypecheckFailure bool
+}
+
+// JSONDiagnostic is the unitchecker representation of a diagnostic.
+type jsonDiagnostic struct {
+	Posn    string `json:"posn"` // e.g., "file.go:line:column"
+	Message string `json:"message"`
+}
+
+// IsVetConfig returns whether the command-line arguments are an invocation
+// from go vet.
+func isVetConfig(args []string) bool {
+	return len(args) == 1 && strings.HasSuffix(args[0], ".cfg")
+}
+
+// PrintVersion answers go vet's -V=full request. The build ID is the hash of
+// the executable, which invalidates cached results on change.
+func printVersion() error {
+	path, err := os.Executable()
+	if err != nil {
+		return err
+	}
+	f, err := os.Open(path)
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+	h := sha256.New()
+	if _, err := io.Copy(h, f); err != nil {
+		return err
+	}
+	fmt.Printf("%s version devel buildID=%x\n", filepath.Base(os.Args[0]), h.Sum(nil))
+	return nil
+}
+
+// PrintFlags answers go vet's -flags request.
+func printFlags() {
+	fmt.Println(`[{"Name":"json","Bool":true,"Usage":"emit JSON output"}]`)
+}
+
+// VetUnit checks the package described by the configuration file at path.
+// Diagnostics go to standard error, or to the standard output of go vet as
+// JSON. The return is whether any diagnostics were reported in plain text.
+func vetUnit(path string, asJSON bool) (reported bool, err error) {
+	text, err := os.ReadFile(path)
+	if err != nil {
+		return false, err
+	}
+	var cfg vetConfig
+	if err := json.Unmarshal(text, &cfg); err != nil {
+		return false, fmt.Errorf("vet configuration %s: %w", path, err)
+	}
+
+	// go vet requires the facts file, even without any facts
+	if cfg.VetxOutput != "" {
+		if err := os.WriteFile(cfg.VetxOutput, nil, 0o666); err != nil {
+			return false, err
+		}
+	}
+	if cfg.VetxOnly {
+		return false, nil
+	}
+
+	lookup := func(path string) (io.ReadCloser, error) {
+		if mapped, ok := cfg.ImportMap[path]; ok {
+			path = mapped
+		}
+		file, ok := cfg.PackageFile[path]
+		if !ok || file == "" {
+			return nil, fmt.Errorf("no export data for %q", path)
+		}
+		return os.Open(file)
+	}
+	fset := token.NewFileSet()
+	diags, err := checkPackage(fset, cfg.ImportPath, cfg.GoFiles, lookup)
+	if err != nil {
+		if cfg.SucceedOnTypecheckFailure {
+			return false, nil
+		}
+		return false, err
+	}
+
+	if !asJSON {
+		for _, line := range lines(fset, diags) {
+			fmt.Fprintln(os.Stderr, line)
+		}
+		return len(diags) != 0, nil
+	}
+
+	if len(diags) == 0 {
+		return false, nil
+	}
+	list := make([]jsonDiagnostic, len(diags))
+	for i, d := range diags {
+		list[i] = jsonDiagnostic{
+			Posn:    fset.Position(d.pos).String(),
+			Message: d.msg,
+		}
+	}
+	tree := map[string]map[string][]jsonDiagnostic{
+		cfg.ID: {"jwtvet": list},
+	}
+	if cfg.Stdout == "" {
+		return false, json.NewEncoder(os.Stdout).Encode(tree)
+	}
+	f, err := os.Create(cfg.Stdout)
+	if err != nil {
+		return false, err
+	}
+	if err := json.NewEncoder(f).Encode(tree); err != nil {
+		f.Close()
+		return false, err
+	}
+	return false, f.Close()
+}
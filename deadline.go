@@ -0,0 +1,24 @@
+package jwt
+
+import (
+	"context"
+	"time"
+)
+
+// DeadlineLeeway is the margin before expiry for ContextWithTokenDeadline. A
+// positive value ends contexts ahead of the expiration time, which leaves room
+// for any cleanup while the credential is still valid.
+var DeadlineLeeway time.Duration
+
+// ContextWithTokenDeadline returns a copy of ctx with a deadline no later than
+// the expiration time of c, minus DeadlineLeeway. Operations on behalf of an
+// expired token thus get cancelled. Any earlier deadline of ctx remains in
+// effect. Claims without an expiration time get no deadline. Canceling the
+// context releases resources, so call cancel as soon as the operations are
+// done, as with context.WithDeadline.
+func ContextWithTokenDeadline(ctx context.Context, c *Claims) (context.Context, context.CancelFunc) {
+	if c.Expires == nil {
+		return context.WithCancel(ctx)
+	}
+	return context.WithDeadline(ctx, c.Expires.Time().Add(-DeadlineLeeway))
+}
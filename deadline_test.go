@@ -0,0 +1,74 @@
+package jwt
+
+import (
+	"context"
+	"crypto/ed25519"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+)
+
+func TestContextWithTokenDeadline(t *testing.T) {
+	defer func() { DeadlineLeeway = 0 }()
+	DeadlineLeeway = 10 * time.Second
+	exp := time.Now().Add(time.Hour).Truncate(time.Second)
+
+	c := new(Claims)
+	c.Expires = NewNumericTime(exp)
+	ctx, cancel := ContextWithTokenDeadline(context.Background(), c)
+	defer cancel()
+	if got, ok := ctx.Deadline(); !ok || !got.Equal(exp.Add(-10*time.Second)) {
+		t.Errorf("got deadline %s (set %t), want %s", got, ok, exp.Add(-10*time.Second))
+	}
+
+	// earlier parent deadline wins
+	parent, cancelParent := context.WithTimeout(context.Background(), time.Minute)
+	defer cancelParent()
+	ctx, cancel = ContextWithTokenDeadline(parent, c)
+	defer cancel()
+	if got, _ := ctx.Deadline(); got.After(time.Now().Add(time.Minute)) {
+		t.Errorf("got deadline %s beyond parent", got)
+	}
+
+	// expired
+	c.Expires = NewNumericTime(time.Now().Add(-time.Minute))
+	ctx, cancel = ContextWithTokenDeadline(context.Background(), c)
+	defer cancel()
+	if ctx.Err() != context.DeadlineExceeded {
+		t.Errorf("expired claims got context error %v, want %v", ctx.Err(), context.DeadlineExceeded)
+	}
+
+	// no expiry
+	ctx, cancel = ContextWithTokenDeadline(context.Background(), new(Claims))
+	defer cancel()
+	if _, ok := ctx.Deadline(); ok {
+		t.Error("deadline set without expiry")
+	}
+}
+
+func TestHandlerTokenDeadline(t *testing.T) {
+	req := httptest.NewRequest("GET", "/", nil)
+	exp := time.Now().Add(time.Hour).Truncate(time.Second)
+	var c Claims
+	c.Expires = NewNumericTime(exp)
+	if err := c.EdDSASignHeader(req, testKeyEd25519Private); err != nil {
+		t.Fatal(err)
+	}
+
+	var reached bool
+	handler := Handler{
+		Keys:          &KeyRegister{EdDSAs: []ed25519.PublicKey{testKeyEd25519Public}},
+		TokenDeadline: true,
+		Target: http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			reached = true
+			if got, ok := r.Context().Deadline(); !ok || !got.Equal(exp) {
+				t.Errorf("got deadline %s (set %t), want %s", got, ok, exp)
+			}
+		}),
+	}
+	handler.ServeHTTP(httptest.NewRecorder(), req)
+	if !reached {
+		t.Error("target not reached")
+	}
+}
@@ -0,0 +1,38 @@
+package jwt
+
+import (
+	"crypto"
+	"crypto/hmac"
+)
+
+// DeriveSecret returns the “HMAC-based Extract-and-Expand Key Derivation
+// Function (HKDF)” RFC 5869 output for audience, with master as the input
+// keying material, without salt, and with the audience as the info. The length
+// equals the hash size.
+func DeriveSecret(hash crypto.Hash, master []byte, audience string) []byte {
+	// extract with the default salt of hash length zeros
+	extract := hmac.New(hash.New, make([]byte, hash.Size()))
+	extract.Write(master)
+	pseudoRandomKey := extract.Sum(nil)
+
+	// expand to a single block
+	expand := hmac.New(hash.New, pseudoRandomKey)
+	expand.Write([]byte(audience))
+	expand.Write([]byte{1})
+	return expand.Sum(nil)
+}
+
+// NewAudienceHMAC returns a new reusable instance with a secret derived from
+// master for audience. See DeriveSecret for details. Tokens for one audience
+// fail to verify at any other audience, even when they share the master secret.
+// Verifiers should still apply AcceptAudience on the claims.
+func NewAudienceHMAC(alg string, master []byte, audience string) (*HMAC, error) {
+	if err := checkSecret(master); err != nil {
+		return nil, err
+	}
+	hash, err := hashLookup(alg, HMACAlgs)
+	if err != nil {
+		return nil, err
+	}
+	return NewHMAC(alg, DeriveSecret(hash, master, audience))
+}
@@ -0,0 +1,28 @@
+//go:build !jwtverifyonly
+
+package jwt
+
+import (
+	"encoding/json"
+	"errors"
+)
+
+var errAudienceCount = errors.New("jwt: derived secret needs exactly one audience")
+
+// AudienceHMACSign updates the Raw fields and returns a new JWT, signed with a
+// secret derived from master for the audience. See NewAudienceHMAC for details.
+// The claims must have exactly one audience.
+// The return is an AlgError when alg is not in HMACAlgs.
+//
+// The JOSE header (content) can be extended with extraHeaders, in the form of
+// JSON objects. Overrides of "alg" or "kid" fail with ErrHeaderOverride.
+func (c *Claims) AudienceHMACSign(alg string, master []byte, extraHeaders ...json.RawMessage) (token []byte, err error) {
+	if len(c.Audiences) != 1 {
+		return nil, errAudienceCount
+	}
+	h, err := NewAudienceHMAC(alg, master, c.Audiences[0])
+	if err != nil {
+		return nil, err
+	}
+	return h.Sign(c, extraHeaders...)
+}
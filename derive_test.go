@@ -0,0 +1,65 @@
+package jwt
+
+import (
+	"bytes"
+	"crypto"
+	"encoding/hex"
+	"testing"
+)
+
+func TestDeriveSecret(t *testing.T) {
+	// test case 3 from RFC 5869, appendix A.3, truncated to hash size
+	master := bytes.Repeat([]byte{0x0b}, 22)
+	const want = "8da4e775a563c18f715f802a063c5a31b8a11f5c5ee1879ec3454e5f3c738d2d"
+	if got := hex.EncodeToString(DeriveSecret(crypto.SHA256, master, "")); got != want {
+		t.Errorf("got %s, want %s", got, want)
+	}
+}
+
+func TestAudienceHMAC(t *testing.T) {
+	master := []byte("shared master secret")
+
+	var c Claims
+	c.Audiences = []string{"service-a"}
+	token, err := c.AudienceHMACSign(HS256, master)
+	if err != nil {
+		t.Fatal("sign error:", err)
+	}
+
+	a, err := NewAudienceHMAC(HS256, master, "service-a")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := a.Check(token); err != nil {
+		t.Error("check at audience error:", err)
+	}
+
+	b, err := NewAudienceHMAC(HS256, master, "service-b")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := b.Check(token); err != ErrSigMiss {
+		t.Errorf("check at other audience got error %v, want %v", err, ErrSigMiss)
+	}
+	if _, err := HMACCheck(token, master); err != ErrSigMiss {
+		t.Errorf("check with master got error %v, want %v", err, ErrSigMiss)
+	}
+}
+
+func TestAudienceHMACErrors(t *testing.T) {
+	var c Claims
+	if _, err := c.AudienceHMACSign(HS256, []byte("guest")); err != errAudienceCount {
+		t.Errorf("no audience got error %v, want %v", err, errAudienceCount)
+	}
+	c.Audiences = []string{"a", "b"}
+	if _, err := c.AudienceHMACSign(HS256, []byte("guest")); err != errAudienceCount {
+		t.Errorf("two audiences got error %v, want %v", err, errAudienceCount)
+	}
+	c.Audiences = []string{"a"}
+	if _, err := c.AudienceHMACSign(HS256, nil); err != errNoSecret {
+		t.Errorf("no master got error %v, want %v", err, errNoSecret)
+	}
+	if _, err := c.AudienceHMACSign(RS256, []byte("guest")); err != AlgError(RS256) {
+		t.Errorf("RSA algorithm got error %v, want %v", err, AlgError(RS256))
+	}
+}
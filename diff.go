@@ -0,0 +1,142 @@
+package jwt
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"sort"
+	"strings"
+)
+
+// DiffNoise has the claim names which CompareTokens ignores, as their values
+// differ per issue.
+var DiffNoise = map[string]bool{"iat": true, "exp": true, "jti": true}
+
+// Delta is a difference between two JSON objects. Absent values are nil.
+type Delta struct {
+	Name string
+	A, B json.RawMessage
+}
+
+// String returns a description.
+func (d Delta) String() string {
+	a, b := string(d.A), string(d.B)
+	if a == "" {
+		a = "<absent>"
+	}
+	if b == "" {
+		b = "<absent>"
+	}
+	return fmt.Sprintf("%q: %s → %s", d.Name, a, b)
+}
+
+// Diff is a CompareTokens result.
+type Diff struct {
+	Header []Delta // JOSE header differences
+	Claims []Delta // payload differences, except for DiffNoise
+	Err    error   // decoding failure
+}
+
+// Equal returns whether both tokens decoded without any differences.
+func (d Diff) Equal() bool {
+	return d.Err == nil && len(d.Header) == 0 && len(d.Claims) == 0
+}
+
+// String returns a description with one difference per line.
+func (d Diff) String() string {
+	if d.Err != nil {
+		return d.Err.Error()
+	}
+	var buf strings.Builder
+	for _, delta := range d.Header {
+		buf.WriteString("header ")
+		buf.WriteString(delta.String())
+		buf.WriteByte('\n')
+	}
+	for _, delta := range d.Claims {
+		buf.WriteString("claim ")
+		buf.WriteString(delta.String())
+		buf.WriteByte('\n')
+	}
+	return buf.String()
+}
+
+// CompareTokens reports the differences between the JOSE headers and between
+// the claims of two tokens, with names in alphabetical order. Signatures are
+// not verified. Use case is the verification of equivalence during migrations
+// from one issuer (implementation) to another.
+func CompareTokens(a, b []byte) Diff {
+	headerA, payloadA, err := diffDecode(a)
+	if err != nil {
+		return Diff{Err: fmt.Errorf("jwt: token A: %w", err)}
+	}
+	headerB, payloadB, err := diffDecode(b)
+	if err != nil {
+		return Diff{Err: fmt.Errorf("jwt: token B: %w", err)}
+	}
+
+	var d Diff
+	d.Header = diffObjects(headerA, headerB, nil)
+	d.Claims = diffObjects(payloadA, payloadB, DiffNoise)
+	return d
+}
+
+func diffDecode(token []byte) (header, payload map[string]json.RawMessage, err error) {
+	headerJSON, payloadJSON, _, err := Split(token)
+	if err != nil {
+		return nil, nil, err
+	}
+	if err := json.Unmarshal(headerJSON, &header); err != nil {
+		return nil, nil, fmt.Errorf("malformed header: %w", err)
+	}
+	if err := json.Unmarshal(payloadJSON, &payload); err != nil {
+		return nil, nil, fmt.Errorf("malformed payload: %w", err)
+	}
+	return header, payload, nil
+}
+
+// DiffObjects compares the values per name, without any whitespace or member
+// order sensitivity.
+func diffObjects(a, b map[string]json.RawMessage, ignore map[string]bool) []Delta {
+	names := make([]string, 0, len(a)+len(b))
+	for name := range a {
+		names = append(names, name)
+	}
+	for name := range b {
+		if _, ok := a[name]; !ok {
+			names = append(names, name)
+		}
+	}
+	sort.Strings(names)
+
+	var deltas []Delta
+	for _, name := range names {
+		if ignore[name] {
+			continue
+		}
+		valueA, valueB := canonicalJSON(a[name]), canonicalJSON(b[name])
+		if !bytes.Equal(valueA, valueB) {
+			deltas = append(deltas, Delta{name, valueA, valueB})
+		}
+	}
+	return deltas
+}
+
+// CanonicalJSON normalizes the formatting, with object members sorted by name.
+// Numbers retain their text, so "1" and "1.0" are a difference.
+func canonicalJSON(raw json.RawMessage) json.RawMessage {
+	if raw == nil {
+		return nil
+	}
+	dec := json.NewDecoder(bytes.NewReader(raw))
+	dec.UseNumber()
+	var v interface{}
+	if err := dec.Decode(&v); err != nil {
+		return raw
+	}
+	out, err := json.Marshal(v)
+	if err != nil {
+		return raw
+	}
+	return out
+}
@@ -0,0 +1,56 @@
+package jwt
+
+import (
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestCompareTokens(t *testing.T) {
+	var a Claims
+	a.Issuer = "old"
+	a.Subject = "alice"
+	a.Audiences = []string{"x", "y"}
+	a.Issued = NewNumericTime(time.Now())
+	a.ID = "1"
+	a.Set = map[string]interface{}{"roles": []interface{}{"admin"}, "ext": map[string]interface{}{"a": 1.0, "b": 2.0}}
+	tokenA, err := a.HMACSign(HS256, []byte("guest"))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	var b Claims
+	b.Issuer = "new"
+	b.Subject = "alice"
+	b.Audiences = []string{"x", "y"}
+	b.Issued = NewNumericTime(time.Now().Add(time.Hour))
+	b.ID = "2"
+	b.Set = map[string]interface{}{"ext": map[string]interface{}{"b": 2.0, "a": 1.0}, "tenant": "t"}
+	tokenB, err := b.EdDSASign(testKeyEd25519Private)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	d := CompareTokens(tokenA, tokenB)
+	if d.Err != nil {
+		t.Fatal("compare error:", d.Err)
+	}
+	if d.Equal() {
+		t.Error("got equal")
+	}
+	const want = `header "alg": "HS256" → "EdDSA"
+claim "iss": "old" → "new"
+claim "roles": ["admin"] → <absent>
+claim "tenant": <absent> → "t"
+`
+	if got := d.String(); got != want {
+		t.Errorf("got diff:\n%s\nwant:\n%s", got, want)
+	}
+
+	if d := CompareTokens(tokenA, tokenA); !d.Equal() {
+		t.Errorf("got difference with itself:\n%s", d)
+	}
+	if d := CompareTokens(tokenA, []byte("broken")); d.Err == nil || !strings.Contains(d.Err.Error(), "token B") {
+		t.Errorf("got error %v for broken token B", d.Err)
+	}
+}
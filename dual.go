@@ -0,0 +1,67 @@
+package jwt
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+)
+
+// DualSigHeader is the JOSE header parameter with the secondary signature of
+// DualSign, in the form of a JWS with detached content, i.e., the compact
+// serialization with an empty payload part (RFC 7515, appendix F).
+const DualSigHeader = "dual_sig"
+
+var errDualSigMiss = errors.New("jwt: dual signature absent")
+
+// CheckDual parses a JWT if, and only if, either the signature or the
+// DualSigHeader signature checks out. The error of the primary signature
+// check is returned when both fail. See Claims.DualSign.
+// Use Claims.Valid to complete the verification.
+func (keys *KeyRegister) CheckDual(token []byte) (*Claims, error) {
+	c, err := keys.Check(token)
+	if err == nil {
+		return c, nil
+	}
+
+	second, dualErr := dualToken(token)
+	if dualErr != nil {
+		return nil, err
+	}
+	c, dualErr = keys.Check(second)
+	if dualErr != nil {
+		return nil, err
+	}
+	return c, nil
+}
+
+// DualToken returns the secondary signature of a DualSign token, with the
+// payload attached.
+func dualToken(token []byte) ([]byte, error) {
+	header, _, _, err := Split(token)
+	if err != nil {
+		return nil, err
+	}
+	var fields struct {
+		DualSig *string `json:"dual_sig"`
+	}
+	if err := json.Unmarshal(header, &fields); err != nil {
+		return nil, fmt.Errorf("jwt: malformed JOSE header: %w", err)
+	}
+	if fields.DualSig == nil {
+		return nil, errDualSigMiss
+	}
+	detached := *fields.DualSig
+	dot := bytes.IndexByte([]byte(detached), '.')
+	if dot < 0 || len(detached) < dot+2 || detached[dot+1] != '.' {
+		return nil, errors.New("jwt: malformed dual signature")
+	}
+
+	firstDot := bytes.IndexByte(token, '.')
+	lastDot := bytes.LastIndexByte(token, '.')
+	second := make([]byte, 0, len(detached)+lastDot-firstDot)
+	second = append(second, detached[:dot]...)
+	second = append(second, token[firstDot:lastDot]...)
+	second = append(second, detached[dot+1:]...)
+	return second, nil
+}
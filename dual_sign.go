@@ -0,0 +1,61 @@
+//go:build !jwtverifyonly
+
+package jwt
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+)
+
+// SignFunc produces a token. Function values like (*HMAC).Sign match, and so
+// do closures around Claims methods, such as
+//
+//	func(c *Claims, extraHeaders ...json.RawMessage) ([]byte, error) {
+//		return c.EdDSASign(key, extraHeaders...)
+//	}
+type SignFunc func(c *Claims, extraHeaders ...json.RawMessage) (token []byte, err error)
+
+// DualSign updates the Raw fields and returns a new JWT signed with primary.
+// The JOSE header gets the DualSigHeader with a detached signature from
+// secondary on the same payload. Use case is a migration from one key (or
+// algorithm) to another, with the old key as secondary, such that verifiers
+// can accept either during the migration window. See KeyRegister.CheckDual.
+// The KeyID applies to the primary signature only. Secondary can set its own
+// with an extra header.
+//
+// The JOSE header (content) can be extended with extraHeaders, in the form of
+// JSON objects. Overrides of "alg" or "kid" fail with ErrHeaderOverride.
+func (c *Claims) DualSign(primary, secondary SignFunc, extraHeaders ...json.RawMessage) (token []byte, err error) {
+	kid := c.KeyID
+	c.KeyID = ""
+	second, err := secondary(c)
+	c.KeyID = kid
+	if err != nil {
+		return nil, err
+	}
+	firstDot := bytes.IndexByte(second, '.')
+	lastDot := bytes.LastIndexByte(second, '.')
+	if firstDot < 0 || lastDot == firstDot {
+		return nil, errors.New("jwt: secondary signature not in compact serialization")
+	}
+	payload := second[firstDot+1 : lastDot]
+	detached := append(second[:firstDot+1:firstDot+1], second[lastDot:]...)
+
+	header, err := json.Marshal(map[string]string{DualSigHeader: string(detached)})
+	if err != nil {
+		return nil, err
+	}
+	token, err = primary(c, append(extraHeaders, header)...)
+	if err != nil {
+		return nil, err
+	}
+
+	// payload must match for the detached signature to apply
+	firstDot = bytes.IndexByte(token, '.')
+	lastDot = bytes.LastIndexByte(token, '.')
+	if firstDot < 0 || lastDot <= firstDot || !bytes.Equal(token[firstDot+1:lastDot], payload) {
+		return nil, errors.New("jwt: payload of primary and secondary signature differ")
+	}
+	return token, nil
+}
@@ -0,0 +1,56 @@
+package jwt
+
+import (
+	"crypto/ed25519"
+	"crypto/rsa"
+	"encoding/json"
+	"errors"
+	"testing"
+)
+
+func TestDualSign(t *testing.T) {
+	oldKey := func(c *Claims, extraHeaders ...json.RawMessage) ([]byte, error) {
+		return c.RSASign(RS256, testKeyRSA2048, extraHeaders...)
+	}
+	newKey := func(c *Claims, extraHeaders ...json.RawMessage) ([]byte, error) {
+		return c.EdDSASign(testKeyEd25519Private, extraHeaders...)
+	}
+
+	var c Claims
+	c.Subject = "alice"
+	c.KeyID = "new"
+	c.Set = map[string]interface{}{"scope": "read"}
+	token, err := c.DualSign(newKey, oldKey)
+	if err != nil {
+		t.Fatal("sign error:", err)
+	}
+
+	golden := []struct {
+		name string
+		keys KeyRegister
+	}{
+		{"old", KeyRegister{RSAs: []*rsa.PublicKey{&testKeyRSA2048.PublicKey}}},
+		{"new", KeyRegister{EdDSAs: []ed25519.PublicKey{testKeyEd25519Public}}},
+	}
+	for _, gold := range golden {
+		got, err := gold.keys.CheckDual(token)
+		if err != nil {
+			t.Errorf("%s key: check error: %s", gold.name, err)
+			continue
+		}
+		if got.Subject != "alice" || got.Set["scope"] != "read" {
+			t.Errorf("%s key: got claims %q", gold.name, got.Raw)
+		}
+	}
+
+	keys := KeyRegister{RSAs: []*rsa.PublicKey{&testKeyRSA1024.PublicKey}}
+	if _, err := keys.CheckDual(token); err != ErrSigMiss {
+		t.Errorf("unknown key: got error %v, want %v", err, ErrSigMiss)
+	}
+
+	// plain Check ignores the dual signature
+	keys = KeyRegister{RSAs: []*rsa.PublicKey{&testKeyRSA2048.PublicKey}}
+	if _, err := keys.Check(token); !errors.Is(err, ErrSigMiss) && !errors.As(err, new(AlgError)) {
+		t.Errorf("plain check on old key got error %v", err)
+	}
+}
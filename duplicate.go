@@ -0,0 +1,123 @@
+package jwt
+
+import (
+	"crypto/ecdsa"
+	"crypto/ed25519"
+	"crypto/hmac"
+	"crypto/rsa"
+	"fmt"
+)
+
+// DuplicateKeyError is a load rejection due RejectDuplicates.
+type DuplicateKeyError struct {
+	// Source is the position of the key in the load input,
+	// e.g., "PEM block 2" or "JWKS key 3".
+	Source string
+
+	// Present identifies the key in the register. Duplicates within
+	// one load input have the Index which the key would have had.
+	Present KeyStat
+}
+
+// Error honors the error interface.
+func (e *DuplicateKeyError) Error() string {
+	if e.Present.KeyID != "" {
+		return fmt.Sprintf("jwt: %s duplicates %s key %d with key ID %q", e.Source, e.Present.Type, e.Present.Index, e.Present.KeyID)
+	}
+	return fmt.Sprintf("jwt: %s duplicates %s key %d", e.Source, e.Present.Type, e.Present.Index)
+}
+
+// LoadKey adds a key from source, unless the same key with the same key ID is
+// present in keys or in staged already. The key goes in staged when not nil.
+func (keys *KeyRegister) loadKey(staged *KeyRegister, key interface{}, kid, source string) (added bool, err error) {
+	present, dup := keys.duplicate(key, kid)
+	if !dup && staged != nil {
+		present, dup = staged.duplicate(key, kid)
+		if dup {
+			present.Index += keys.count(present.Type)
+		}
+	}
+	if dup {
+		if keys.RejectDuplicates {
+			return false, &DuplicateKeyError{Source: source, Present: present}
+		}
+		return false, nil
+	}
+
+	if staged != nil {
+		return true, staged.add(key, kid)
+	}
+	return true, keys.add(key, kid)
+}
+
+// Duplicate returns the KeyStat identification of the key with kid, if any.
+// Private keys match on their public counterpart.
+func (keys *KeyRegister) duplicate(key interface{}, kid string) (stat KeyStat, ok bool) {
+	var i int
+	switch t := key.(type) {
+	case *ecdsa.PrivateKey:
+		return keys.duplicate(&t.PublicKey, kid)
+	case ed25519.PrivateKey:
+		return keys.duplicate(t.Public(), kid)
+	case *rsa.PrivateKey:
+		return keys.duplicate(&t.PublicKey, kid)
+
+	case *ecdsa.PublicKey:
+		stat.Type = "ECDSA"
+		i, ok = indexKey(keys.ECDSAs, keys.ECDSAIDs, kid, func(present *ecdsa.PublicKey) bool {
+			return present.Equal(t)
+		})
+	case ed25519.PublicKey:
+		stat.Type = "EdDSA"
+		i, ok = indexKey(keys.EdDSAs, keys.EdDSAIDs, kid, func(present ed25519.PublicKey) bool {
+			return present.Equal(t)
+		})
+	case *rsa.PublicKey:
+		stat.Type = "RSA"
+		i, ok = indexKey(keys.RSAs, keys.RSAIDs, kid, func(present *rsa.PublicKey) bool {
+			return present.Equal(t)
+		})
+	case *HMAC:
+		stat.Type = "HMAC"
+		i, ok = indexKey(keys.HMACs, keys.HMACIDs, kid, func(present *HMAC) bool {
+			return present == t
+		})
+	case []byte:
+		stat.Type = "secret"
+		i, ok = indexKey(keys.Secrets, keys.SecretIDs, kid, func(present []byte) bool {
+			return hmac.Equal(present, t)
+		})
+	}
+	if !ok {
+		return KeyStat{}, false
+	}
+	stat.Index, stat.KeyID = i, kid
+	return stat, true
+}
+
+// IndexKey returns the position of the key with kid for which equal is true.
+func indexKey[T any](keys []T, ids []string, kid string, equal func(T) bool) (int, bool) {
+	for i, key := range keys {
+		if kidAt(ids, i) == kid && equal(key) {
+			return i, true
+		}
+	}
+	return 0, false
+}
+
+// Count returns the number of keys of a KeyStat type.
+func (keys *KeyRegister) count(typ string) int {
+	switch typ {
+	case "ECDSA":
+		return len(keys.ECDSAs)
+	case "EdDSA":
+		return len(keys.EdDSAs)
+	case "RSA":
+		return len(keys.RSAs)
+	case "HMAC":
+		return len(keys.HMACs)
+	case "secret":
+		return len(keys.Secrets)
+	}
+	return 0
+}
@@ -0,0 +1,48 @@
+package jwt
+
+import (
+	"crypto/ed25519"
+	"errors"
+)
+
+var errEdDSACtxSize = errors.New("jwt: Ed25519ctx context string not within 1–255 bytes")
+
+// EdDSACtxCheck is like EdDSACheck, yet it verifies as Ed25519ctx with the
+// context string. Ed25519ctx from “Edwards-Curve Digital Signature Algorithm
+// (EdDSA)” RFC 8032, subsection 5.1, binds signatures to a context string. The
+// JOSE header still has "EdDSA" as the algorithm, yet signatures of one context
+// never verify in another context, nor as plain Ed25519, even with the same
+// key. Applications can thus separate the tokens of internal protocols by
+// domain. The context string must be 1–255 bytes. Ed25519ctx requires Go 1.20
+// or later.
+func EdDSACtxCheck(token []byte, key ed25519.PublicKey, context string) (*Claims, error) {
+	var c Claims
+	bodyLen, sig, alg, err := c.scan(token)
+	if err != nil {
+		return nil, err
+	}
+
+	if alg != EdDSA {
+		return nil, AlgError(alg)
+	}
+	if err := fipsAlg(alg, 0); err != nil {
+		return nil, err
+	}
+	if len(sig) != ed25519.SignatureSize {
+		return nil, ErrSigSize
+	}
+
+	if err := ed25519ctxVerify(key, token[:bodyLen], sig, context); err != nil {
+		return nil, err
+	}
+
+	return &c, c.applyPayload()
+}
+
+// CheckEdDSAContext validates an Ed25519ctx context string.
+func checkEdDSAContext(context string) error {
+	if len(context) == 0 || len(context) > 255 {
+		return errEdDSACtxSize
+	}
+	return nil
+}
@@ -0,0 +1,20 @@
+//go:build !go1.20
+
+package jwt
+
+import (
+	"crypto/ed25519"
+	"errors"
+)
+
+var errEdDSACtxGo = errors.New("jwt: Ed25519ctx requires Go 1.20 or later")
+
+// Ed25519ctxSign is not available before Go 1.20.
+func ed25519ctxSign(key ed25519.PrivateKey, message []byte, context string) ([]byte, error) {
+	return nil, errEdDSACtxGo
+}
+
+// Ed25519ctxVerify is not available before Go 1.20.
+func ed25519ctxVerify(key ed25519.PublicKey, message, sig []byte, context string) error {
+	return errEdDSACtxGo
+}
@@ -0,0 +1,25 @@
+//go:build go1.20
+
+package jwt
+
+import "crypto/ed25519"
+
+// Ed25519ctxSign returns the Ed25519ctx signature of message.
+func ed25519ctxSign(key ed25519.PrivateKey, message []byte, context string) ([]byte, error) {
+	if err := checkEdDSAContext(context); err != nil {
+		return nil, err
+	}
+	return key.Sign(nil, message, &ed25519.Options{Context: context})
+}
+
+// Ed25519ctxVerify returns ErrSigMiss when sig is not the Ed25519ctx signature
+// of message.
+func ed25519ctxVerify(key ed25519.PublicKey, message, sig []byte, context string) error {
+	if err := checkEdDSAContext(context); err != nil {
+		return err
+	}
+	if ed25519.VerifyWithOptions(key, message, sig, &ed25519.Options{Context: context}) != nil {
+		return ErrSigMiss
+	}
+	return nil
+}
@@ -0,0 +1,34 @@
+//go:build !jwtverifyonly
+
+package jwt
+
+import (
+	"crypto/ed25519"
+	"encoding/json"
+)
+
+// EdDSACtxSign is like EdDSASign, yet it signs as Ed25519ctx with the context
+// string. See EdDSACtxCheck for the reverse.
+func (c *Claims) EdDSACtxSign(key ed25519.PrivateKey, context string, extraHeaders ...json.RawMessage) (token []byte, err error) {
+	if err := fipsAlg(EdDSA, 0); err != nil {
+		return nil, err
+	}
+	if err := checkEdDSAContext(context); err != nil {
+		return nil, err
+	}
+	encSigLen := encoding.EncodedLen(ed25519.SignatureSize)
+	buf, err := c.newToken(EdDSA, encSigLen, extraHeaders)
+	if err != nil {
+		return nil, err
+	}
+	end := len(buf) + 1 + encSigLen
+
+	sig, err := ed25519ctxSign(key, buf, context)
+	if err != nil {
+		return nil, err
+	}
+
+	buf = append(buf, '.')
+	encoding.Encode(buf[len(buf):end], sig)
+	return buf[:end], nil
+}
@@ -0,0 +1,61 @@
+//go:build go1.20
+
+package jwt
+
+import (
+	"crypto/ed25519"
+	"strings"
+	"testing"
+)
+
+func TestEdDSACtx(t *testing.T) {
+	var c Claims
+	c.Subject = "alice"
+	token, err := c.EdDSACtxSign(testKeyEd25519Private, "billing")
+	if err != nil {
+		t.Fatal("sign error:", err)
+	}
+
+	got, err := EdDSACtxCheck(token, testKeyEd25519Public, "billing")
+	if err != nil {
+		t.Fatal("check error:", err)
+	}
+	if got.Subject != "alice" {
+		t.Errorf("got subject %q, want alice", got.Subject)
+	}
+
+	// domain separation
+	if _, err := EdDSACtxCheck(token, testKeyEd25519Public, "mail"); err != ErrSigMiss {
+		t.Errorf("other context got error %v, want %v", err, ErrSigMiss)
+	}
+	if _, err := EdDSACheck(token, testKeyEd25519Public); err != ErrSigMiss {
+		t.Errorf("plain Ed25519 got error %v, want %v", err, ErrSigMiss)
+	}
+	plain, err := c.EdDSASign(testKeyEd25519Private)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := EdDSACtxCheck(plain, testKeyEd25519Public, "billing"); err != ErrSigMiss {
+		t.Errorf("plain token got error %v, want %v", err, ErrSigMiss)
+	}
+
+	// register
+	keys := &KeyRegister{EdDSAs: []ed25519.PublicKey{testKeyEd25519Public}, EdDSAContext: "billing"}
+	if _, err := keys.Check(token); err != nil {
+		t.Error("register check error:", err)
+	}
+	if _, err := keys.Check(plain); err != ErrSigMiss {
+		t.Errorf("register check of plain token got error %v, want %v", err, ErrSigMiss)
+	}
+}
+
+func TestEdDSACtxSize(t *testing.T) {
+	for _, context := range []string{"", strings.Repeat("x", 256)} {
+		if _, err := new(Claims).EdDSACtxSign(testKeyEd25519Private, context); err != errEdDSACtxSize {
+			t.Errorf("context of %d bytes got sign error %v, want %v", len(context), err, errEdDSACtxSize)
+		}
+	}
+	if _, err := new(Claims).EdDSACtxSign(testKeyEd25519Private, strings.Repeat("x", 255)); err != nil {
+		t.Error("context of 255 bytes got error:", err)
+	}
+}
@@ -0,0 +1,92 @@
+package jwt
+
+import (
+	"context"
+	"sync"
+	"time"
+)
+
+// EnrichCache memoizes the claims added by an enrichment source per subject,
+// i.e., the combination of Issuer and Subject. Claims without a subject are
+// not cached. The zero value is not usable; Source must be set.
+//
+//	handler.Enrich = (&jwt.EnrichCache{
+//		Source: lookupGroups,
+//		Claims: []string{"groups"},
+//		TTL:    5 * time.Minute,
+//	}).Enrich
+//
+// Multiple goroutines may invoke methods on an EnrichCache simultaneously.
+type EnrichCache struct {
+	// Source adds claims to Set, e.g., group membership from a
+	// directory service. Errors are not cached.
+	Source func(ctx context.Context, c *Claims) error
+
+	// Claims are the names set by Source. Only these are cached. The
+	// values are shared among requests, and thus read-only.
+	Claims []string
+
+	// TTL is the maximum age of cache entries.
+	TTL time.Duration
+
+	mutex     sync.Mutex
+	entries   map[string]enrichEntry
+	nextSweep time.Time
+}
+
+type enrichEntry struct {
+	expires time.Time
+	values  map[string]interface{}
+}
+
+// Enrich applies either the cached claims or Source. The signature matches
+// Handler.Enrich.
+func (cache *EnrichCache) Enrich(ctx context.Context, c *Claims) error {
+	if c.Subject == "" {
+		return cache.Source(ctx, c)
+	}
+	key := c.Issuer + "\x00" + c.Subject
+	now := time.Now()
+
+	cache.mutex.Lock()
+	entry, ok := cache.entries[key]
+	cache.mutex.Unlock()
+	if ok && now.Before(entry.expires) {
+		if c.Set == nil {
+			c.Set = make(map[string]interface{}, len(entry.values))
+		}
+		for name, v := range entry.values {
+			c.Set[name] = v
+		}
+		return nil
+	}
+
+	if err := cache.Source(ctx, c); err != nil {
+		return err
+	}
+	entry = enrichEntry{
+		expires: now.Add(cache.TTL),
+		values:  make(map[string]interface{}, len(cache.Claims)),
+	}
+	for _, name := range cache.Claims {
+		if v, ok := c.Set[name]; ok {
+			entry.values[name] = v
+		}
+	}
+
+	cache.mutex.Lock()
+	defer cache.mutex.Unlock()
+	if cache.entries == nil {
+		cache.entries = make(map[string]enrichEntry)
+	}
+	if now.After(cache.nextSweep) {
+		for k, e := range cache.entries {
+			if !now.Before(e.expires) {
+				delete(cache.entries, k)
+			}
+		}
+		cache.nextSweep = now.Add(cache.TTL)
+	}
+	cache.entries[key] = entry
+	return nil
+}
@@ -0,0 +1,79 @@
+package jwt
+
+import (
+	"context"
+	"crypto/ed25519"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+)
+
+func TestEnrichCache(t *testing.T) {
+	var calls int
+	cache := &EnrichCache{
+		Source: func(ctx context.Context, c *Claims) error {
+			calls++
+			if c.Subject == "mallory" {
+				return errors.New("directory unavailable")
+			}
+			c.Set["groups"] = []interface{}{"staff"}
+			c.Set["uncached"] = true
+			return nil
+		},
+		Claims: []string{"groups"},
+		TTL:    time.Minute,
+	}
+
+	for i := 0; i < 3; i++ {
+		c := &Claims{Registered: Registered{Subject: "alice"}, Set: map[string]interface{}{}}
+		if err := cache.Enrich(context.Background(), c); err != nil {
+			t.Fatal("enrich error:", err)
+		}
+		if groups, ok := ParseScope(c.Set["groups"]); !ok || !groups.Has("staff") {
+			t.Errorf("call %d got groups %v", i, c.Set["groups"])
+		}
+	}
+	if calls != 1 {
+		t.Errorf("got %d source calls, want 1", calls)
+	}
+
+	for i := 0; i < 2; i++ {
+		c := &Claims{Registered: Registered{Subject: "mallory"}, Set: map[string]interface{}{}}
+		if err := cache.Enrich(context.Background(), c); err == nil {
+			t.Error("source error not returned")
+		}
+	}
+	if calls != 3 {
+		t.Errorf("got %d source calls, want 3 as errors are not cached", calls)
+	}
+}
+
+func TestHandleEnrich(t *testing.T) {
+	handler := &Handler{
+		Target: http.HandlerFunc(func(http.ResponseWriter, *http.Request) {}),
+		Keys:   &KeyRegister{EdDSAs: []ed25519.PublicKey{testKeyEd25519Public}},
+		Enrich: func(ctx context.Context, c *Claims) error {
+			if c.Subject == "" {
+				return errors.New("no subject")
+			}
+			c.Set["scope"] = "read"
+			return nil
+		},
+		Expect: &Expect{Scopes: []string{"read"}},
+	}
+
+	for subject, want := range map[string]int{"alice": http.StatusOK, "": http.StatusServiceUnavailable} {
+		req := httptest.NewRequest("GET", "/", nil)
+		c := &Claims{Registered: Registered{Subject: subject}, Set: map[string]interface{}{}}
+		if err := c.EdDSASignHeader(req, testKeyEd25519Private); err != nil {
+			t.Fatal(err)
+		}
+		resp := httptest.NewRecorder()
+		handler.ServeHTTP(resp, req)
+		if resp.Code != want {
+			t.Errorf("subject %q got HTTP %d, want %d", subject, resp.Code, want)
+		}
+	}
+}
@@ -0,0 +1,119 @@
+package jwt
+
+import (
+	"errors"
+	"fmt"
+	"time"
+)
+
+var (
+	errAudience  = errors.New(`jwt: audience ["aud"] not accepted`)
+	errIssuer    = errors.New(`jwt: issuer ["iss"] not accepted`)
+	errNoExpires = errors.New(`jwt: expiration time ["exp"] absent`)
+	errTenant    = errors.New("jwt: tenant not accepted")
+)
+
+// ScopeError signals the absence of a required scope.
+type ScopeError string
+
+// Error honors the error interface.
+func (e ScopeError) Error() string {
+	return fmt.Sprintf("jwt: scope %q not granted", string(e))
+}
+
+// Expect defines claim requirements. The zero value accepts any claims.
+type Expect struct {
+	// Audience is the identifier of the resource, if any. See
+	// Registered.AcceptAudienceMatch for details.
+	Audience string
+	// AudienceMatch defines the comparison with Audience. The
+	// zero value is the strict string equality of RFC 7519.
+	AudienceMatch AudienceMatch
+
+	// Issuers has the issuers accepted. Any issuer, including none, is
+	// accepted when empty.
+	Issuers []string
+
+	// RequireExpires rejects tokens without an expiration time, when
+	// set. Such tokens remain valid forever otherwise.
+	RequireExpires bool
+
+	// Scopes must all be granted. See Claims.Scopes for details.
+	Scopes []string
+
+	// AnyRole has the roles accepted. Any roles, including none, are
+	// accepted when empty. See Claims.AnyRole for details.
+	AnyRole []string
+
+	// Tenants has the tenant identifiers accepted. Any tenant, including
+	// none, is accepted when empty. See Claims.Tenant for details.
+	Tenants []string
+
+	// ACRValues has the authentication context classes accepted, in
+	// order of preference. Any class, including none, is accepted when
+	// empty. See Claims.ACR for details.
+	ACRValues []string
+	// AMR has the authentication methods which must all be present.
+	// See Claims.AMR for details.
+	AMR []string
+	// MaxAuthAge limits the time since the end-user authentication when
+	// non-zero. See Claims.AuthTime for details.
+	MaxAuthAge time.Duration
+
+	// Policy has additional rules, if any. See Policy for details.
+	Policy *Policy
+}
+
+// Accept verifies c against the requirements. The return is a ScopeError when
+// any of the Scopes is not granted, a RoleError when none of AnyRole is granted,
+// a *StepUpError when the authentication does not meet ACRValues, AMR or
+// MaxAuthAge, and a PolicyError when a statement of Policy does not hold.
+func (e *Expect) Accept(c *Claims) error {
+	if e.Audience != "" && !c.AcceptAudienceMatch(e.Audience, e.AudienceMatch) {
+		return errAudience
+	}
+	if len(e.Issuers) != 0 && !contains(e.Issuers, c.Issuer) {
+		return errIssuer
+	}
+	if e.RequireExpires && c.Expires == nil {
+		return errNoExpires
+	}
+
+	if len(e.Tenants) != 0 {
+		tenant, ok := c.Tenant()
+		if !ok || !contains(e.Tenants, tenant) {
+			return errTenant
+		}
+	}
+
+	if len(e.Scopes) != 0 {
+		granted := c.Scopes()
+		for _, s := range e.Scopes {
+			if !granted.Has(s) {
+				return ScopeError(s)
+			}
+		}
+	}
+
+	if len(e.AnyRole) != 0 && !c.AnyRole(e.AnyRole...) {
+		return RoleError(e.AnyRole)
+	}
+
+	if err := e.acceptAuthentication(c); err != nil {
+		return err
+	}
+
+	if e.Policy != nil {
+		return e.Policy.Accept(c)
+	}
+	return nil
+}
+
+func contains(a []string, s string) bool {
+	for _, e := range a {
+		if e == s {
+			return true
+		}
+	}
+	return false
+}
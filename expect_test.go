@@ -0,0 +1,34 @@
+package jwt
+
+import "testing"
+
+func TestExpectAccept(t *testing.T) {
+	c := Claims{Registered: Registered{Audiences: []string{"https://api.example.com"}}, Set: map[string]interface{}{
+		"tid":   "acme",
+		"scope": "read write:*",
+	}}
+
+	golden := []struct {
+		e    Expect
+		want error
+	}{
+		{Expect{}, nil},
+		{Expect{Tenants: []string{"globex", "acme"}}, nil},
+		{Expect{Tenants: []string{"globex"}}, errTenant},
+		{Expect{Scopes: []string{"read", "write:all"}}, nil},
+		{Expect{Scopes: []string{"read", "admin"}}, ScopeError("admin")},
+		{Expect{Tenants: []string{"globex"}, Scopes: []string{"admin"}}, errTenant},
+		{Expect{Audience: "https://api.example.com"}, nil},
+		{Expect{Audience: "https://api.example.com/v2"}, errAudience},
+		{Expect{Audience: "https://api.example.com/v2", AudienceMatch: AudiencePrefix}, nil},
+	}
+	for _, gold := range golden {
+		if err := gold.e.Accept(&c); err != gold.want {
+			t.Errorf("%+v got error %v, want %v", gold.e, err, gold.want)
+		}
+	}
+
+	if err := (&Expect{Tenants: []string{"acme"}}).Accept(new(Claims)); err != errTenant {
+		t.Errorf("tenant absence got error %v, want %v", err, errTenant)
+	}
+}
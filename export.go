@@ -0,0 +1,201 @@
+package jwt
+
+import (
+	"crypto/sha256"
+	"encoding/json"
+	"sort"
+	"strconv"
+	"sync"
+	"sync/atomic"
+	"time"
+)
+
+// ExportField is a claim value of an ExportRecord. Objects and arrays are
+// flattened into a field per element, with the member names and the array
+// indices in dot notation, e.g., "realm_access.roles.0".
+type ExportField struct {
+	Key   string // claim path
+	Type  string // "string", "number", "bool" or "null"
+	Value string // JSON for numbers, and text for the others
+}
+
+// ExportRecord is a snapshot of a verified token.
+type ExportRecord struct {
+	Time        time.Time     // moment of export
+	TokenSHA256 string        // base64url, as in Transcript
+	KeyID       string        // key ID from the JOSE header, if any
+	Fields      []ExportField // in order of Key
+}
+
+// Exporter delivers records of verified tokens in batches, e.g., to SIEM
+// ingestion, without the need to parse tokens out of logs. Delivery takes
+// place from a dedicated goroutine, such that the verification path does not
+// block. Records are dropped when the queue is full.
+//
+// Multiple goroutines may invoke methods on an Exporter simultaneously.
+type Exporter struct {
+	// Deliver receives each batch. Calls do not overlap.
+	Deliver func(batch []ExportRecord)
+
+	// BatchSize is the maximum number of records per Deliver. The zero
+	// value defaults to 100.
+	BatchSize int
+
+	// FlushInterval is the maximum delay before delivery of a record.
+	// The zero value defaults to one second.
+	FlushInterval time.Duration
+
+	// QueueSize is the number of records pending before any drops. The
+	// zero value defaults to 1024.
+	QueueSize int
+
+	start   sync.Once
+	mutex   sync.RWMutex // protects closed and queue closure
+	closed  bool
+	queue   chan ExportRecord
+	done    chan struct{}
+	dropped uint64 // atomic counter
+}
+
+// Export queues a record of token with its verified claims.
+func (e *Exporter) Export(token []byte, c *Claims) {
+	e.start.Do(e.launch)
+
+	sum := sha256.Sum256(token)
+	record := ExportRecord{
+		Time:        Clock(),
+		TokenSHA256: encoding.EncodeToString(sum[:]),
+		KeyID:       c.KeyID,
+		Fields:      exportFields(c),
+	}
+
+	e.mutex.RLock()
+	defer e.mutex.RUnlock()
+	if e.closed {
+		atomic.AddUint64(&e.dropped, 1)
+		return
+	}
+	select {
+	case e.queue <- record:
+		break
+	default:
+		atomic.AddUint64(&e.dropped, 1)
+	}
+}
+
+// Dropped returns the number of records discarded, due to a full queue or due
+// to Close.
+func (e *Exporter) Dropped() uint64 {
+	return atomic.LoadUint64(&e.dropped)
+}
+
+// Close delivers any pending records, and it stops the delivery routine.
+// Records from Export after Close are dropped.
+func (e *Exporter) Close() {
+	e.start.Do(e.launch)
+
+	e.mutex.Lock()
+	if !e.closed {
+		e.closed = true
+		close(e.queue)
+	}
+	e.mutex.Unlock()
+	<-e.done
+}
+
+func (e *Exporter) launch() {
+	size := e.QueueSize
+	if size <= 0 {
+		size = 1024
+	}
+	e.queue = make(chan ExportRecord, size)
+	e.done = make(chan struct{})
+	go e.deliverLoop()
+}
+
+func (e *Exporter) deliverLoop() {
+	defer close(e.done)
+
+	batchSize := e.BatchSize
+	if batchSize <= 0 {
+		batchSize = 100
+	}
+	interval := e.FlushInterval
+	if interval <= 0 {
+		interval = time.Second
+	}
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	var batch []ExportRecord
+	flush := func() {
+		if len(batch) != 0 {
+			e.Deliver(batch)
+			batch = nil
+		}
+	}
+	for {
+		select {
+		case record, ok := <-e.queue:
+			if !ok {
+				flush()
+				return
+			}
+			batch = append(batch, record)
+			if len(batch) >= batchSize {
+				flush()
+			}
+		case <-ticker.C:
+			flush()
+		}
+	}
+}
+
+// ExportFields flattens the claims, including Registered.
+func exportFields(c *Claims) []ExportField {
+	var fields []ExportField
+	for name, value := range c.All() {
+		fields = appendExportFields(fields, name, value)
+	}
+	sort.Slice(fields, func(i, j int) bool { return fields[i].Key < fields[j].Key })
+	return fields
+}
+
+func appendExportFields(fields []ExportField, key string, value interface{}) []ExportField {
+	switch v := value.(type) {
+	case nil:
+		return append(fields, ExportField{Key: key, Type: "null"})
+	case string:
+		return append(fields, ExportField{Key: key, Type: "string", Value: v})
+	case bool:
+		return append(fields, ExportField{Key: key, Type: "bool", Value: strconv.FormatBool(v)})
+	case float64:
+		b, err := json.Marshal(v)
+		if err != nil {
+			// NaN and infinity have no JSON representation
+			return append(fields, ExportField{Key: key, Type: "number", Value: strconv.FormatFloat(v, 'g', -1, 64)})
+		}
+		return append(fields, ExportField{Key: key, Type: "number", Value: string(b)})
+	case []interface{}:
+		for i, e := range v {
+			fields = appendExportFields(fields, key+"."+strconv.Itoa(i), e)
+		}
+		return fields
+	case map[string]interface{}:
+		for name, e := range v {
+			fields = appendExportFields(fields, key+"."+name, e)
+		}
+		return fields
+	default:
+		// other types, as in Set from code, get their JSON
+		b, err := json.Marshal(v)
+		if err != nil {
+			return fields
+		}
+		var generic interface{}
+		if err := json.Unmarshal(b, &generic); err != nil {
+			return fields
+		}
+		return appendExportFields(fields, key, generic)
+	}
+}
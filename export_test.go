@@ -0,0 +1,121 @@
+package jwt
+
+import (
+	"crypto/ed25519"
+	"crypto/sha256"
+	"net/http"
+	"net/http/httptest"
+	"reflect"
+	"sync"
+	"testing"
+	"time"
+)
+
+func TestExportFields(t *testing.T) {
+	var c Claims
+	c.Issuer = "idp"
+	c.Audiences = []string{"a", "b"}
+	c.Expires = NewNumericTime(time.Unix(1700000000, 0))
+	c.Set = map[string]interface{}{
+		"iss":          "ignored",
+		"admin":        true,
+		"nothing":      nil,
+		"realm_access": map[string]interface{}{"roles": []interface{}{"ops", 2.5}},
+		"count":        7,
+	}
+
+	want := []ExportField{
+		{"admin", "bool", "true"},
+		{"aud.0", "string", "a"},
+		{"aud.1", "string", "b"},
+		{"count", "number", "7"},
+		{"exp", "number", "1700000000"},
+		{"iss", "string", "idp"},
+		{"nothing", "null", ""},
+		{"realm_access.roles.0", "string", "ops"},
+		{"realm_access.roles.1", "number", "2.5"},
+	}
+	if got := exportFields(&c); !reflect.DeepEqual(got, want) {
+		t.Errorf("got fields %q,\nwant %q", got, want)
+	}
+}
+
+func TestExporter(t *testing.T) {
+	var mutex sync.Mutex
+	var batches [][]ExportRecord
+	e := &Exporter{
+		Deliver: func(batch []ExportRecord) {
+			mutex.Lock()
+			defer mutex.Unlock()
+			batches = append(batches, batch)
+		},
+		BatchSize:     2,
+		FlushInterval: time.Hour,
+	}
+
+	h := &Handler{
+		Keys:     &KeyRegister{EdDSAs: []ed25519.PublicKey{testKeyEd25519Public}},
+		Target:   http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {}),
+		Exporter: e,
+		Expect:   &Expect{Audience: "api"},
+		Func: func(w http.ResponseWriter, r *http.Request, c *Claims) (pass bool) {
+			return r.URL.Path != "/drop"
+		},
+	}
+	var tokens []string
+	for _, aud := range []string{"api", "other", "api", "drop", "api"} {
+		c := &Claims{KeyID: "k1"}
+		c.Audiences = []string{aud}
+		path := "/"
+		if aud == "drop" {
+			// denied after all claim verification
+			c.Audiences[0], path = "api", "/drop"
+		}
+		req := httptest.NewRequest("GET", path, nil)
+		if err := c.EdDSASignHeader(req, testKeyEd25519Private); err != nil {
+			t.Fatal(err)
+		}
+		h.ServeHTTP(httptest.NewRecorder(), req)
+		if aud == "api" {
+			token, _ := BearerToken(req.Header)
+			tokens = append(tokens, token)
+		}
+	}
+	e.Close()
+
+	if len(batches) != 2 || len(batches[0]) != 2 || len(batches[1]) != 1 {
+		t.Fatalf("got batches %v, want sizes 2 and 1", batches)
+	}
+	for i, r := range append(batches[0], batches[1]...) {
+		sum := sha256.Sum256([]byte(tokens[i]))
+		if want := encoding.EncodeToString(sum[:]); r.TokenSHA256 != want {
+			t.Errorf("record %d got token hash %q, want %q", i, r.TokenSHA256, want)
+		}
+		if r.KeyID != "k1" || len(r.Fields) != 1 || r.Fields[0].Key != "aud.0" {
+			t.Errorf("record %d got key ID %q and fields %q", i, r.KeyID, r.Fields)
+		}
+	}
+
+	e.Export([]byte("late"), new(Claims))
+	if n := e.Dropped(); n != 1 {
+		t.Errorf("got %d records dropped after close, want 1", n)
+	}
+}
+
+func TestExporterFlushInterval(t *testing.T) {
+	delivered := make(chan []ExportRecord)
+	e := &Exporter{
+		Deliver:       func(batch []ExportRecord) { delivered <- batch },
+		FlushInterval: 10 * time.Millisecond,
+	}
+	defer e.Close()
+	e.Export([]byte("a.b.c"), new(Claims))
+	select {
+	case batch := <-delivered:
+		if len(batch) != 1 {
+			t.Errorf("got %d records, want 1", len(batch))
+		}
+	case <-time.After(time.Second):
+		t.Error("no delivery after flush interval")
+	}
+}
@@ -0,0 +1,129 @@
+package jwt
+
+import (
+	"crypto/hmac"
+	"crypto/sha256"
+	"errors"
+	"net"
+	"net/http"
+)
+
+// FingerprintClaim is the default claim name for Fingerprint.
+const FingerprintClaim = "cfp"
+
+var (
+	errNoFingerprint       = errors.New("jwt: client fingerprint claim absent")
+	errFingerprintMismatch = errors.New("jwt: client fingerprint mismatch")
+)
+
+// Fingerprint binds tokens to client attributes, as a defense in depth against
+// token theft. Each attribute is embedded as a (truncated) hash, such that the
+// claims do not disclose the client details. The zero value includes no
+// attributes.
+type Fingerprint struct {
+	// Claim is the name of the JSON object with the hashes. The empty
+	// string defaults to FingerprintClaim.
+	Claim string
+
+	// IPv4Prefix and IPv6Prefix set the number of leading bits from
+	// the remote address to include. Prefixes tolerate address changes
+	// within a network, e.g., 24 for IPv4 and 64 for IPv6. Zero
+	// excludes the address of the respective version. Note that the
+	// address comes from http.Request RemoteAddr, which may be a proxy.
+	IPv4Prefix, IPv6Prefix int
+
+	// UserAgent includes the User-Agent header.
+	UserAgent bool
+
+	// DeviceHeader includes the value of a request header with a device
+	// identifier, e.g., "X-Device-ID", when not empty.
+	DeviceHeader string
+
+	// Secret keys the hashes with HMAC when not empty, which prevents
+	// the reversal of low-entropy attributes like address prefixes.
+	Secret []byte
+
+	// Tolerate sets the number of mismatching attributes accepted. The
+	// zero value requires all attributes to match.
+	Tolerate int
+}
+
+// Attributes returns the hash per attribute name.
+func (f *Fingerprint) attributes(r *http.Request) map[string]string {
+	m := make(map[string]string, 3)
+
+	if f.IPv4Prefix > 0 || f.IPv6Prefix > 0 {
+		host, _, err := net.SplitHostPort(r.RemoteAddr)
+		if err != nil {
+			host = r.RemoteAddr
+		}
+		var prefix net.IP // nil when excluded or unknown
+		if ip := net.ParseIP(host); ip != nil {
+			if ip4 := ip.To4(); ip4 != nil {
+				if f.IPv4Prefix > 0 {
+					prefix = ip4.Mask(net.CIDRMask(f.IPv4Prefix, 32))
+				}
+			} else if f.IPv6Prefix > 0 {
+				prefix = ip.Mask(net.CIDRMask(f.IPv6Prefix, 128))
+			}
+		}
+		m["ip"] = f.hash(prefix.String())
+	}
+	if f.UserAgent {
+		m["ua"] = f.hash(r.Header.Get("User-Agent"))
+	}
+	if f.DeviceHeader != "" {
+		m["dev"] = f.hash(r.Header.Get(f.DeviceHeader))
+	}
+	return m
+}
+
+func (f *Fingerprint) hash(s string) string {
+	var sum []byte
+	if len(f.Secret) != 0 {
+		m := hmac.New(sha256.New, f.Secret)
+		m.Write([]byte(s))
+		sum = m.Sum(nil)
+	} else {
+		a := sha256.Sum256([]byte(s))
+		sum = a[:]
+	}
+	return encoding.EncodeToString(sum[:16])
+}
+
+func (f *Fingerprint) claim() string {
+	if f.Claim == "" {
+		return FingerprintClaim
+	}
+	return f.Claim
+}
+
+// Embed sets the fingerprint claim of c with the attributes of r.
+func (f *Fingerprint) Embed(c *Claims, r *http.Request) {
+	object := make(map[string]interface{})
+	for name, hash := range f.attributes(r) {
+		object[name] = hash
+	}
+	if c.Set == nil {
+		c.Set = make(map[string]interface{})
+	}
+	c.Set[f.claim()] = object
+}
+
+// Accept verifies the fingerprint claim of c against the attributes of r.
+func (f *Fingerprint) Accept(c *Claims, r *http.Request) error {
+	object, ok := c.Set[f.claim()].(map[string]interface{})
+	if !ok {
+		return errNoFingerprint
+	}
+	var mismatches int
+	for name, hash := range f.attributes(r) {
+		if s, ok := object[name].(string); !ok || !hmac.Equal([]byte(s), []byte(hash)) {
+			mismatches++
+		}
+	}
+	if mismatches > f.Tolerate {
+		return errFingerprintMismatch
+	}
+	return nil
+}
@@ -0,0 +1,76 @@
+package jwt
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+func TestFingerprint(t *testing.T) {
+	f := Fingerprint{IPv4Prefix: 24, IPv6Prefix: 64, UserAgent: true, DeviceHeader: "X-Device-ID", Secret: []byte("guest")}
+
+	origin := httptest.NewRequest(http.MethodPost, "/login", nil)
+	origin.RemoteAddr = "192.0.2.7:1234"
+	origin.Header.Set("User-Agent", "test/1.0")
+	origin.Header.Set("X-Device-ID", "d1")
+	var c Claims
+	f.Embed(&c, origin)
+
+	golden := []struct {
+		remoteAddr, userAgent, device string
+		tolerate                      int
+		want                          error
+	}{
+		{"192.0.2.7:1234", "test/1.0", "d1", 0, nil},
+		{"192.0.2.99:4321", "test/1.0", "d1", 0, nil},
+		{"192.0.3.7:1234", "test/1.0", "d1", 0, errFingerprintMismatch},
+		{"192.0.3.7:1234", "test/1.0", "d1", 1, nil},
+		{"192.0.3.7:1234", "test/2.0", "d1", 1, errFingerprintMismatch},
+		{"[2001:db8::1]:1234", "test/1.0", "d1", 0, errFingerprintMismatch},
+		{"192.0.2.7:1234", "test/1.0", "", 0, errFingerprintMismatch},
+	}
+	for _, gold := range golden {
+		r := httptest.NewRequest(http.MethodGet, "/", nil)
+		r.RemoteAddr = gold.remoteAddr
+		r.Header.Set("User-Agent", gold.userAgent)
+		r.Header.Set("X-Device-ID", gold.device)
+
+		f.Tolerate = gold.tolerate
+		if err := f.Accept(&c, r); err != gold.want {
+			t.Errorf("%s %q %q tolerate %d: got error %v, want %v", gold.remoteAddr, gold.userAgent, gold.device, gold.tolerate, err, gold.want)
+		}
+	}
+
+	if err := f.Accept(new(Claims), origin); err != errNoFingerprint {
+		t.Errorf("without claim got error %v, want %v", err, errNoFingerprint)
+	}
+}
+
+func TestHandlerFingerprint(t *testing.T) {
+	f := &Fingerprint{UserAgent: true}
+	h := &Handler{
+		Target:      http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {}),
+		Keys:        &KeyRegister{Secrets: [][]byte{[]byte("guest")}},
+		Fingerprint: f,
+	}
+
+	origin := httptest.NewRequest(http.MethodGet, "/", nil)
+	origin.Header.Set("User-Agent", "test/1.0")
+	var c Claims
+	f.Embed(&c, origin)
+	token, err := c.HMACSign(HS256, []byte("guest"))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	for userAgent, want := range map[string]int{"test/1.0": http.StatusOK, "curl/8.0": http.StatusUnauthorized} {
+		r := httptest.NewRequest(http.MethodGet, "/", nil)
+		r.Header.Set("Authorization", "Bearer "+string(token))
+		r.Header.Set("User-Agent", userAgent)
+		w := httptest.NewRecorder()
+		h.ServeHTTP(w, r)
+		if w.Code != want {
+			t.Errorf("User-Agent %q: got status %d, want %d", userAgent, w.Code, want)
+		}
+	}
+}
@@ -0,0 +1,121 @@
+package jwt
+
+import (
+	"crypto"
+	"crypto/ed25519"
+	"crypto/rsa"
+	"errors"
+)
+
+// ErrFIPSKey signals a key which does not meet the FIPS policy.
+var ErrFIPSKey = errors.New("jwt: key rejected by FIPS policy")
+
+// FIPSPolicy restricts cryptography to FIPS-approved use, as in FIPS 186-5 and
+// NIST SP 800-131A. ECDSA is approved for all curves supported.
+type FIPSPolicy struct {
+	// EdDSA permits Ed25519, which is not approved in all settings.
+	EdDSA bool
+
+	// MinRSABits is the minimum modulus size in bits, with 2048 for zero.
+	MinRSABits int
+
+	// MinSecretSize is the minimum HMAC secret size in bytes, with 14
+	// (112 bits of security strength) for zero.
+	MinSecretSize int
+}
+
+// FIPS enforces a policy when set. Algorithms outside of the policy are treated
+// as not in use, i.e., the Check and Sign functions return an AlgError. Keys
+// which do not meet the policy fail with ErrFIPSKey, including registration
+// with LoadJWK and LoadPEM. Any modifications should be made before first use,
+// i.e., from either main or init.
+var FIPS *FIPSPolicy
+
+// FIPSApproved returns whether alg is permitted, with respect to its hash
+// registration (if any). All algorithms in use are permitted without a FIPS
+// policy.
+func FIPSApproved(alg string) bool {
+	if alg == EdDSA {
+		return fipsAlg(alg, 0) == nil
+	}
+	for _, algs := range []map[string]crypto.Hash{ECDSAAlgs, HMACAlgs, RSAAlgs} {
+		if hash, ok := algs[alg]; ok {
+			return fipsAlg(alg, hash) == nil
+		}
+	}
+	return false
+}
+
+// FIPSAlg returns an AlgError when the FIPS policy does not permit alg with
+// hash.
+func fipsAlg(alg string, hash crypto.Hash) error {
+	if FIPS == nil {
+		return nil
+	}
+	if alg == EdDSA {
+		if !FIPS.EdDSA {
+			return AlgError(alg)
+		}
+		return nil
+	}
+	switch hash {
+	case crypto.SHA224, crypto.SHA256, crypto.SHA384, crypto.SHA512,
+		crypto.SHA512_224, crypto.SHA512_256,
+		crypto.SHA3_224, crypto.SHA3_256, crypto.SHA3_384, crypto.SHA3_512:
+		return nil
+	}
+	return AlgError(alg)
+}
+
+// CheckSecret verifies the size of an HMAC secret.
+func checkSecret(secret []byte) error {
+	if len(secret) == 0 {
+		return errNoSecret
+	}
+	if FIPS == nil {
+		return nil
+	}
+	min := FIPS.MinSecretSize
+	if min == 0 {
+		min = 14
+	}
+	if len(secret) < min {
+		return ErrFIPSKey
+	}
+	return nil
+}
+
+// FIPSKey verifies key for registration.
+func fipsKey(key interface{}) error {
+	if FIPS == nil {
+		return nil
+	}
+	switch t := key.(type) {
+	case ed25519.PublicKey, ed25519.PrivateKey:
+		if !FIPS.EdDSA {
+			return ErrFIPSKey
+		}
+	case *rsa.PublicKey:
+		return fipsRSA(t)
+	case *rsa.PrivateKey:
+		return fipsRSA(&t.PublicKey)
+	case []byte:
+		return checkSecret(t)
+	}
+	return nil
+}
+
+// FIPSRSA verifies the modulus size of an RSA key.
+func fipsRSA(key *rsa.PublicKey) error {
+	if FIPS == nil {
+		return nil
+	}
+	min := FIPS.MinRSABits
+	if min == 0 {
+		min = 2048
+	}
+	if key.N.BitLen() < min {
+		return ErrFIPSKey
+	}
+	return nil
+}
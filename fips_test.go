@@ -0,0 +1,69 @@
+package jwt
+
+import (
+	"crypto"
+	"crypto/ed25519"
+	"crypto/rsa"
+	"testing"
+)
+
+func TestFIPS(t *testing.T) {
+	defer func() { FIPS = nil }()
+
+	c := new(Claims)
+	c.Subject = "alice"
+	edToken, err := c.EdDSASign(testKeyEd25519Private)
+	if err != nil {
+		t.Fatal(err)
+	}
+	rsaToken, err := c.RSASign(PS256, testKeyRSA1024)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	FIPS = new(FIPSPolicy)
+
+	if _, err := c.EdDSASign(testKeyEd25519Private); err != AlgError(EdDSA) {
+		t.Errorf("EdDSA sign got error %v, want %v", err, AlgError(EdDSA))
+	}
+	keys := KeyRegister{EdDSAs: []ed25519.PublicKey{testKeyEd25519Public}}
+	if _, err := keys.Check(edToken); err != AlgError(EdDSA) {
+		t.Errorf("EdDSA check got error %v, want %v", err, AlgError(EdDSA))
+	}
+	if _, err := c.RSASign(PS256, testKeyRSA1024); err != ErrFIPSKey {
+		t.Errorf("RSA 1024 sign got error %v, want %v", err, ErrFIPSKey)
+	}
+	keys = KeyRegister{RSAs: []*rsa.PublicKey{&testKeyRSA1024.PublicKey}}
+	if _, err := keys.Check(rsaToken); err != ErrFIPSKey {
+		t.Errorf("RSA 1024 check got error %v, want %v", err, ErrFIPSKey)
+	}
+	if _, err := c.RSASign(PS256, testKeyRSA2048); err != nil {
+		t.Error("RSA 2048 sign error:", err)
+	}
+	if _, err := c.HMACSign(HS256, []byte("guest")); err != ErrFIPSKey {
+		t.Errorf("short secret got error %v, want %v", err, ErrFIPSKey)
+	}
+	if _, err := c.HMACSign(HS256, []byte("correct horse battery staple")); err != nil {
+		t.Error("HMAC sign error:", err)
+	}
+	if _, err := keys.LoadJWK([]byte(`{"kty":"oct","k":"Z3Vlc3Q"}`)); err != ErrFIPSKey {
+		t.Errorf("short JWK secret got error %v, want %v", err, ErrFIPSKey)
+	}
+
+	HMACAlgs["HS1"] = crypto.SHA1
+	defer delete(HMACAlgs, "HS1")
+	for alg, want := range map[string]bool{EdDSA: false, ES256: true, HS256: true, PS512: true, RS256: true, "HS1": false} {
+		if got := FIPSApproved(alg); got != want {
+			t.Errorf("FIPSApproved(%q) got %t, want %t", alg, got, want)
+		}
+	}
+
+	FIPS.EdDSA = true
+	if !FIPSApproved(EdDSA) {
+		t.Error("EdDSA not approved with policy permission")
+	}
+	keys = KeyRegister{EdDSAs: []ed25519.PublicKey{testKeyEd25519Public}}
+	if _, err := keys.Check(edToken); err != nil {
+		t.Error("EdDSA check with policy permission:", err)
+	}
+}
@@ -0,0 +1,114 @@
+// Package gcp provides token issuance with keys from Google Cloud Key
+// Management Service (KMS), without any dependency on the Google Cloud SDK.
+// Private keys never leave KMS. Implement the KMS interface with a thin wrapper
+// around the client of choice, e.g., “cloud.google.com/go/kms/apiv1”.
+package gcp
+
+import (
+	"context"
+	"crypto"
+	"crypto/ecdsa"
+	"crypto/rsa"
+	"crypto/x509"
+	"encoding/pem"
+	"fmt"
+	"strings"
+
+	"github.com/pascaldekloe/jwt"
+)
+
+// KMS is the subset of the Cloud KMS API in use. Keys are identified by the
+// resource name of a crypto key version, e.g.,
+// "projects/p/locations/global/keyRings/r/cryptoKeys/k/cryptoKeyVersions/1".
+type KMS interface {
+	// AsymmetricSign returns the signature of a digest, as in an
+	// AsymmetricSignRequest with the Digest field set for hash.
+	AsymmetricSign(ctx context.Context, name string, digest []byte, hash crypto.Hash) (signature []byte, err error)
+
+	// GetPublicKey returns the PEM-encoded public key, together with
+	// the name of the CryptoKeyVersionAlgorithm, e.g.,
+	// "EC_SIGN_P256_SHA256", as in a PublicKey response.
+	GetPublicKey(ctx context.Context, name string) (pemText, algorithm string, err error)
+}
+
+// Algorithm returns the JWT algorithm of a CryptoKeyVersionAlgorithm name.
+// The elliptic curve secp256k1 is not supported.
+func Algorithm(kmsAlgorithm string) (alg string, ok bool) {
+	var hash string
+	switch {
+	case kmsAlgorithm == "EC_SIGN_P256_SHA256":
+		return jwt.ES256, true
+	case kmsAlgorithm == "EC_SIGN_P384_SHA384":
+		return jwt.ES384, true
+	case strings.HasPrefix(kmsAlgorithm, "RSA_SIGN_PKCS1_"):
+		alg, hash = "RS", kmsAlgorithm[len("RSA_SIGN_PKCS1_"):]
+	case strings.HasPrefix(kmsAlgorithm, "RSA_SIGN_PSS_"):
+		alg, hash = "PS", kmsAlgorithm[len("RSA_SIGN_PSS_"):]
+	default:
+		return "", false
+	}
+
+	// key size followed by the hash, e.g., "2048_SHA256"
+	i := strings.IndexByte(hash, '_')
+	if i < 0 {
+		return "", false
+	}
+	switch hash[i+1:] {
+	case "SHA256":
+		return alg + "256", true
+	case "SHA384":
+		return alg + "384", true
+	case "SHA512":
+		return alg + "512", true
+	}
+	return "", false
+}
+
+// LoadKeys adds the public keys of each crypto key version to the register,
+// with their respective resource name as the key ID. Keys which are present
+// already, with the same key ID, are skipped, as with jwt.KeyRegister.Merge.
+func LoadKeys(ctx context.Context, client KMS, keys *jwt.KeyRegister, names ...string) (keysAdded int, err error) {
+	var staged jwt.KeyRegister
+	for _, name := range names {
+		pub, _, err := publicKey(ctx, client, name)
+		if err != nil {
+			return 0, err
+		}
+		switch t := pub.(type) {
+		case *ecdsa.PublicKey:
+			staged.ECDSAs = append(staged.ECDSAs, t)
+			staged.ECDSAIDs = append(staged.ECDSAIDs, name)
+		case *rsa.PublicKey:
+			staged.RSAs = append(staged.RSAs, t)
+			staged.RSAIDs = append(staged.RSAIDs, name)
+		}
+	}
+	return keys.Merge(&staged)
+}
+
+// PublicKey retrieves either an *ecdsa.PublicKey or an *rsa.PublicKey, with
+// the respective JWT algorithm.
+func publicKey(ctx context.Context, client KMS, name string) (crypto.PublicKey, string, error) {
+	text, kmsAlg, err := client.GetPublicKey(ctx, name)
+	if err != nil {
+		return nil, "", fmt.Errorf("gcp: KMS public key of %q: %w", name, err)
+	}
+	alg, ok := Algorithm(kmsAlg)
+	if !ok {
+		return nil, "", fmt.Errorf("gcp: KMS key %q with unsupported algorithm %q", name, kmsAlg)
+	}
+	block, _ := pem.Decode([]byte(text))
+	if block == nil {
+		return nil, "", fmt.Errorf("gcp: KMS public key of %q not in PEM", name)
+	}
+	pub, err := x509.ParsePKIXPublicKey(block.Bytes)
+	if err != nil {
+		return nil, "", fmt.Errorf("gcp: KMS public key of %q: %w", name, err)
+	}
+	switch pub.(type) {
+	case *ecdsa.PublicKey, *rsa.PublicKey:
+		return pub, alg, nil
+	default:
+		return nil, "", fmt.Errorf("gcp: KMS key %q of unsupported type %T", name, pub)
+	}
+}
@@ -0,0 +1,132 @@
+package gcp
+
+import (
+	"context"
+	"crypto"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/x509"
+	"encoding/pem"
+	"errors"
+	"testing"
+
+	"github.com/pascaldekloe/jwt"
+)
+
+// FakeKMS serves private keys by resource name.
+type fakeKMS map[string]struct {
+	key       crypto.Signer
+	algorithm string
+}
+
+func (f fakeKMS) GetPublicKey(ctx context.Context, name string) (string, string, error) {
+	v, ok := f[name]
+	if !ok {
+		return "", "", errors.New("NotFound")
+	}
+	der, err := x509.MarshalPKIXPublicKey(v.key.Public())
+	if err != nil {
+		return "", "", err
+	}
+	return string(pem.EncodeToMemory(&pem.Block{Type: "PUBLIC KEY", Bytes: der})), v.algorithm, nil
+}
+
+func (f fakeKMS) AsymmetricSign(ctx context.Context, name string, digest []byte, hash crypto.Hash) ([]byte, error) {
+	v, ok := f[name]
+	if !ok {
+		return nil, errors.New("NotFound")
+	}
+	if len(digest) != hash.Size() {
+		return nil, errors.New("InvalidArgument")
+	}
+	switch v.algorithm {
+	case "RSA_SIGN_PSS_2048_SHA256":
+		return rsa.SignPSS(rand.Reader, v.key.(*rsa.PrivateKey), hash, digest, &rsa.PSSOptions{SaltLength: rsa.PSSSaltLengthEqualsHash})
+	default:
+		return v.key.Sign(rand.Reader, digest, hash)
+	}
+}
+
+func TestAlgorithm(t *testing.T) {
+	golden := map[string]string{
+		"EC_SIGN_P256_SHA256":         jwt.ES256,
+		"EC_SIGN_P384_SHA384":         jwt.ES384,
+		"RSA_SIGN_PKCS1_2048_SHA256":  jwt.RS256,
+		"RSA_SIGN_PKCS1_4096_SHA512":  jwt.RS512,
+		"RSA_SIGN_PSS_3072_SHA256":    jwt.PS256,
+		"RSA_SIGN_PSS_4096_SHA512":    jwt.PS512,
+		"RSA_SIGN_RAW_PKCS1_2048":     "",
+		"EC_SIGN_SECP256K1_SHA256":    "",
+		"GOOGLE_SYMMETRIC_ENCRYPTION": "",
+	}
+	for kmsAlg, want := range golden {
+		got, ok := Algorithm(kmsAlg)
+		if got != want || ok != (want != "") {
+			t.Errorf("%s: got %q (%t), want %q", kmsAlg, got, ok, want)
+		}
+	}
+}
+
+func TestSignAndLoadKeys(t *testing.T) {
+	ec256, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	if err != nil {
+		t.Fatal(err)
+	}
+	ec384, err := ecdsa.GenerateKey(elliptic.P384(), rand.Reader)
+	if err != nil {
+		t.Fatal(err)
+	}
+	rsaKey, err := rsa.GenerateKey(rand.Reader, 2048)
+	if err != nil {
+		t.Fatal(err)
+	}
+	const ring = "projects/p/locations/global/keyRings/r/cryptoKeys/"
+	client := fakeKMS{
+		ring + "ec256/cryptoKeyVersions/1": {ec256, "EC_SIGN_P256_SHA256"},
+		ring + "ec384/cryptoKeyVersions/1": {ec384, "EC_SIGN_P384_SHA384"},
+		ring + "pkcs1/cryptoKeyVersions/1": {rsaKey, "RSA_SIGN_PKCS1_2048_SHA256"},
+		ring + "pss/cryptoKeyVersions/2":   {rsaKey, "RSA_SIGN_PSS_2048_SHA256"},
+	}
+
+	var keys jwt.KeyRegister
+	var names []string
+	for name := range client {
+		names = append(names, name)
+	}
+	n, err := LoadKeys(context.Background(), client, &keys, names...)
+	if err != nil {
+		t.Fatal("load error:", err)
+	}
+	if n != 4 {
+		t.Errorf("got %d keys added, want 4", n)
+	}
+
+	for name, v := range client {
+		signer, err := NewSigner(context.Background(), client, name)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if want, _ := Algorithm(v.algorithm); signer.Alg() != want {
+			t.Errorf("%s: got algorithm %q, want %q", name, signer.Alg(), want)
+		}
+		var c jwt.Claims
+		c.Subject = "test"
+		token, err := signer.Sign(context.Background(), &c)
+		if err != nil {
+			t.Fatalf("%s: sign error: %s", name, err)
+		}
+		got, err := keys.Check(token)
+		if err != nil {
+			t.Fatalf("%s: check error: %s", name, err)
+		}
+		if got.KeyID != name || got.Subject != "test" {
+			t.Errorf("%s: got key ID %q and subject %q", name, got.KeyID, got.Subject)
+		}
+	}
+
+	if _, err := NewSigner(context.Background(), client, ring+"absent/cryptoKeyVersions/1"); err == nil {
+		t.Error("no error for absent key")
+	}
+}
@@ -0,0 +1,70 @@
+//go:build !jwtverifyonly
+
+package gcp
+
+import (
+	"context"
+	"crypto"
+	"encoding/json"
+	"fmt"
+	"io"
+
+	"github.com/pascaldekloe/jwt"
+)
+
+// Signer issues tokens with a crypto key version from KMS. Multiple goroutines
+// may invoke methods on a Signer simultaneously.
+type Signer struct {
+	client KMS
+	name   string
+	alg    string
+	public crypto.PublicKey
+}
+
+// NewSigner returns a Signer for the crypto key version with the resource name.
+// The public key and its algorithm are retrieved once.
+func NewSigner(ctx context.Context, client KMS, name string) (*Signer, error) {
+	pub, alg, err := publicKey(ctx, client, name)
+	if err != nil {
+		return nil, err
+	}
+	return &Signer{client: client, name: name, alg: alg, public: pub}, nil
+}
+
+// Name returns the resource name of the crypto key version.
+func (s *Signer) Name() string { return s.name }
+
+// Alg returns the JWT algorithm, as determined by the key version.
+func (s *Signer) Alg() string { return s.alg }
+
+// Public returns the public key, which is either an *ecdsa.PublicKey or an
+// *rsa.PublicKey.
+func (s *Signer) Public() crypto.PublicKey { return s.public }
+
+// Sign updates the Raw fields and returns a new JWT, signed by KMS. Claims
+// without a KeyID get the resource name, which matches the key ID from
+// LoadKeys. See jwt.Claims.SignWith for details.
+func (s *Signer) Sign(ctx context.Context, c *jwt.Claims, extraHeaders ...json.RawMessage) (token []byte, err error) {
+	if c.KeyID == "" {
+		c.KeyID = s.name
+	}
+	return c.SignWith(s.alg, &kmsSigner{ctx: ctx, signer: s}, extraHeaders...)
+}
+
+// KMSSigner binds a context to crypto.Signer.
+type kmsSigner struct {
+	ctx    context.Context
+	signer *Signer
+}
+
+// Public implements crypto.Signer.
+func (k *kmsSigner) Public() crypto.PublicKey { return k.signer.public }
+
+// Sign implements crypto.Signer.
+func (k *kmsSigner) Sign(_ io.Reader, digest []byte, opts crypto.SignerOpts) ([]byte, error) {
+	sig, err := k.signer.client.AsymmetricSign(k.ctx, k.signer.name, digest, opts.HashFunc())
+	if err != nil {
+		return nil, fmt.Errorf("gcp: KMS sign with %q: %w", k.signer.name, err)
+	}
+	return sig, nil
+}
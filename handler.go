@@ -0,0 +1,65 @@
+package jwt
+
+import (
+	"errors"
+	"net/http"
+	"time"
+)
+
+var errNoExpectation = errors.New("jwt: handler needs an audience or issuer expectation; see WithAudience and WithIssuer")
+
+// HandlerOption configures a Handler from NewHandler.
+type HandlerOption func(*Handler)
+
+// WithAudience accepts tokens for the resource identifier only. See
+// Expect.Audience for details.
+func WithAudience(audience string) HandlerOption {
+	return func(h *Handler) { h.Expect.Audience = audience }
+}
+
+// WithIssuer accepts tokens from the issuers only. See Expect.Issuers for
+// details.
+func WithIssuer(issuers ...string) HandlerOption {
+	return func(h *Handler) {
+		h.Expect.Issuers = append(h.Expect.Issuers, issuers...)
+	}
+}
+
+// WithoutExpiry accepts tokens without an expiration time, which remain valid
+// forever.
+func WithoutExpiry() HandlerOption {
+	return func(h *Handler) { h.Expect.RequireExpires = false }
+}
+
+// WithLeeway sets the tolerance with time constraints. See
+// Handler.TemporalLeeway for details.
+func WithLeeway(leeway time.Duration) HandlerOption {
+	return func(h *Handler) { h.TemporalLeeway = leeway }
+}
+
+// WithScopes rejects tokens without all of the scopes granted. See
+// Expect.Scopes for details.
+func WithScopes(scopes ...string) HandlerOption {
+	return func(h *Handler) {
+		h.Expect.Scopes = append(h.Expect.Scopes, scopes...)
+	}
+}
+
+// NewHandler returns a Handler with secure defaults. Options must include
+// an audience or an issuer expectation, i.e., WithAudience or WithIssuer.
+// Tokens without an expiration time are rejected, unless WithoutExpiry. The
+// fields of Handler and of its Expect remain available for further settings.
+func NewHandler(target http.Handler, keys *KeyRegister, opts ...HandlerOption) (*Handler, error) {
+	h := &Handler{
+		Target: target,
+		Keys:   keys,
+		Expect: &Expect{RequireExpires: true},
+	}
+	for _, o := range opts {
+		o(h)
+	}
+	if h.Expect.Audience == "" && len(h.Expect.Issuers) == 0 {
+		return nil, errNoExpectation
+	}
+	return h, nil
+}
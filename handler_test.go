@@ -0,0 +1,72 @@
+package jwt
+
+import (
+	"crypto/ed25519"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+)
+
+func TestNewHandler(t *testing.T) {
+	keys := &KeyRegister{EdDSAs: []ed25519.PublicKey{testKeyEd25519Public}}
+	target := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {})
+
+	if _, err := NewHandler(target, keys); err != errNoExpectation {
+		t.Errorf("got error %v, want %v", err, errNoExpectation)
+	}
+	if _, err := NewHandler(target, keys, WithLeeway(time.Second), WithoutExpiry()); err != errNoExpectation {
+		t.Errorf("got error %v, want %v", err, errNoExpectation)
+	}
+
+	h, err := NewHandler(target, keys, WithIssuer("https://idp.example"), WithScopes("read"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	exp := NewNumericTime(time.Now().Add(time.Hour))
+
+	golden := []struct {
+		iss    string
+		exp    *NumericTime
+		scope  string
+		status int
+	}{
+		{"https://idp.example", exp, "read", http.StatusOK},
+		{"https://idp.example", nil, "read", http.StatusUnauthorized},
+		{"https://other.example", exp, "read", http.StatusUnauthorized},
+		{"", exp, "read", http.StatusUnauthorized},
+		{"https://idp.example", exp, "write", http.StatusForbidden},
+	}
+	for _, gold := range golden {
+		var c Claims
+		c.Issuer = gold.iss
+		c.Expires = gold.exp
+		c.Set = map[string]interface{}{"scope": gold.scope}
+		req := httptest.NewRequest("GET", "/", nil)
+		if err := c.EdDSASignHeader(req, testKeyEd25519Private); err != nil {
+			t.Fatal(err)
+		}
+		resp := httptest.NewRecorder()
+		h.ServeHTTP(resp, req)
+		if resp.Code != gold.status {
+			t.Errorf("iss %q, exp %v, scope %q: got status %d, want %d", gold.iss, gold.exp, gold.scope, resp.Code, gold.status)
+		}
+	}
+
+	// explicit opt-out
+	h, err = NewHandler(target, keys, WithAudience("api"), WithoutExpiry())
+	if err != nil {
+		t.Fatal(err)
+	}
+	var c Claims
+	c.Audiences = []string{"api"}
+	req := httptest.NewRequest("GET", "/", nil)
+	if err := c.EdDSASignHeader(req, testKeyEd25519Private); err != nil {
+		t.Fatal(err)
+	}
+	resp := httptest.NewRecorder()
+	h.ServeHTTP(resp, req)
+	if resp.Code != http.StatusOK {
+		t.Errorf("without expiry got status %d, want 200", resp.Code)
+	}
+}
@@ -0,0 +1,25 @@
+package jwt
+
+import (
+	"crypto"
+	"encoding/json"
+)
+
+// Hash returns a digest of the claims in a canonical form, i.e., a JSON object
+// with its members sorted by name at each level, and with numbers in their
+// shortest representation. The JOSE header is not included. Semantically
+// identical claims thus have the same hash, regardless of their signature,
+// their key, or their formatting, which allows for deduplication of tokens
+// after re-signing. Registered take precedence over Set, as with signing. The
+// return is nil when the claims have values without a JSON representation,
+// such as NaN. Hash panics when h is not available, as in crypto.Hash.New.
+func (c *Claims) Hash(h crypto.Hash) []byte {
+	// map members are sorted by encoding/json
+	canonical, err := json.Marshal(c.All())
+	if err != nil {
+		return nil
+	}
+	digest := h.New()
+	digest.Write(canonical)
+	return digest.Sum(nil)
+}
@@ -0,0 +1,55 @@
+package jwt
+
+import (
+	"bytes"
+	"crypto"
+	_ "crypto/sha256"
+	"math"
+	"testing"
+)
+
+func TestClaimsHash(t *testing.T) {
+	// same claims with other formatting, another signature and another key
+	tokenA := []byte(`eyJhbGciOiJIUzI1NiJ9.eyJzdWIiOiJhbGljZSIsImF1ZCI6ImFwaSIsImV4cCI6MTcwMDAwMDAwMC4wLCJuIjp7ImIiOjIsImEiOjF9fQ.`)
+	tokenB := []byte(`eyJhbGciOiJFZERTQSIsImtpZCI6IngifQ.eyAibiI6IHsiYSI6IDEuMCwgImIiOiAyfSwgImV4cCI6IDE3MDAwMDAwMDAsICJhdWQiOiBbImFwaSJdLCAic3ViIjogImFsaWNlIiB9.`)
+	a, err := ParseWithoutCheck(tokenA)
+	if err != nil {
+		t.Fatal(err)
+	}
+	b, err := ParseWithoutCheck(tokenB)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	hashA, hashB := a.Hash(crypto.SHA256), b.Hash(crypto.SHA256)
+	if len(hashA) != 32 {
+		t.Fatalf("got %d-byte hash, want 32", len(hashA))
+	}
+	if !bytes.Equal(hashA, hashB) {
+		t.Errorf("payloads %s and %s got different hashes", a.Raw, b.Raw)
+	}
+
+	// registered fields count, in Go as much as in JSON
+	var c Claims
+	c.Subject = "alice"
+	c.Audiences = []string{"api"}
+	c.Expires = (*NumericTime)(new(float64))
+	*c.Expires = 1700000000
+	c.Set = map[string]interface{}{"n": map[string]interface{}{"a": 1, "b": 2.0}}
+	if got := c.Hash(crypto.SHA256); !bytes.Equal(got, hashA) {
+		t.Error("claims in Go got a different hash")
+	}
+	if _, ok := c.Set[subject]; ok {
+		t.Error("hash modified the claims set")
+	}
+
+	c.Subject = "bob"
+	if got := c.Hash(crypto.SHA256); bytes.Equal(got, hashA) {
+		t.Error("other subject got the same hash")
+	}
+
+	c.Set["nan"] = math.NaN()
+	if got := c.Hash(crypto.SHA256); got != nil {
+		t.Errorf("NaN got hash %x, want nil", got)
+	}
+}
@@ -0,0 +1,25 @@
+package jwt
+
+import "encoding/json"
+
+// HeaderExtra returns the JOSE header parameter with name as is, i.e., in JSON
+// encoding. Parameters processed by the Check functions, namely "alg", "kid"
+// and "crit", are not included. Use KeyID for "kid". The header is read from
+// RawHeader, as set by the Check and Sign functions. Note that a signature
+// check does not imply that the content of any extra parameter is understood,
+// unless it is listed as critical; see EvalCrit.
+func (c *Claims) HeaderExtra(name string) (value json.RawMessage, ok bool) {
+	switch name {
+	case "alg", "kid", "crit":
+		return nil, false
+	}
+	if len(c.RawHeader) == 0 {
+		return nil, false
+	}
+	var header map[string]json.RawMessage
+	if err := json.Unmarshal([]byte(c.RawHeader), &header); err != nil {
+		return nil, false
+	}
+	value, ok = header[name]
+	return value, ok
+}
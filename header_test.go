@@ -0,0 +1,43 @@
+package jwt
+
+import (
+	"encoding/json"
+	"testing"
+)
+
+func TestHeaderExtra(t *testing.T) {
+	c := &Claims{KeyID: "k1"}
+	token, err := c.HMACSign(HS256, []byte("guest"), json.RawMessage(`{"typ":"JWT","trace":{"id":"x7"},"tenant":"acme"}`))
+	if err != nil {
+		t.Fatal(err)
+	}
+	got, err := HMACCheck(token, []byte("guest"))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	golden := []struct {
+		name string
+		want string // empty for absent
+	}{
+		{"typ", `"JWT"`},
+		{"trace", `{"id":"x7"}`},
+		{"tenant", `"acme"`},
+		{"alg", ""},
+		{"kid", ""},
+		{"cty", ""},
+	}
+	for _, gold := range golden {
+		raw, ok := got.HeaderExtra(gold.name)
+		switch {
+		case gold.want == "" && ok:
+			t.Errorf("%q got %s, want absent", gold.name, raw)
+		case gold.want != "" && string(raw) != gold.want:
+			t.Errorf("%q got %s (present %t), want %s", gold.name, raw, ok, gold.want)
+		}
+	}
+
+	if _, ok := new(Claims).HeaderExtra("typ"); ok {
+		t.Error("header parameter from claims without RawHeader")
+	}
+}
@@ -0,0 +1,380 @@
+// Package httpsig signs HTTP requests conform “HTTP Message Signatures” RFC
+// 9421, with claims from a verified JWT as signature parameters. The intended
+// use is a gateway which forwards requests to upstream services, after the
+// JWT checks out.
+package httpsig
+
+import (
+	"crypto/ecdsa"
+	"crypto/ed25519"
+	"crypto/hmac"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/base64"
+	"errors"
+	"fmt"
+	"math/big"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/pascaldekloe/jwt"
+)
+
+// DefaultComponents are the covered components when none are specified.
+var DefaultComponents = []string{"@method", "@authority", "@path", "@query"}
+
+// MaxAge limits the age of signatures on Verify, by their "created"
+// parameter, such that captured requests can not be replayed indefinitely.
+// Zero disables the limit.
+var MaxAge = 5 * time.Minute
+
+// Leeway is the tolerance for clock differences on Verify, with both the
+// "created" and any "expires" parameter.
+var Leeway = time.Minute
+
+var (
+	errKeyType = errors.New("httpsig: unsupported key type")
+	errCreated = errors.New("httpsig: signature parameter \"created\" absent or malformed")
+	errExpired = errors.New("httpsig: signature expired")
+	errFuture  = errors.New("httpsig: signature created in the future")
+)
+
+// Signer produces message signatures.
+type Signer struct {
+	// Key is either an ed25519.PrivateKey ("ed25519"), an
+	// *ecdsa.PrivateKey on P-256 ("ecdsa-p256-sha256"), or a []byte
+	// secret ("hmac-sha256").
+	Key interface{}
+
+	// KeyID is the optional "keyid" parameter.
+	KeyID string
+
+	// Label names the signature. The empty string defaults to "sig1".
+	Label string
+
+	// Components are the covered components, in order, e.g., "@method"
+	// or "content-type". Nil defaults to DefaultComponents.
+	Components []string
+
+	// Claims are the names of JWT claims to include as signature
+	// parameters, when present as a string. Names must be valid keys of
+	// structured fields, i.e., lowercase.
+	Claims []string
+}
+
+// Sign adds the Signature-Input and the Signature header to r. Claims may be
+// nil when no claim parameters apply.
+func (s *Signer) Sign(r *http.Request, c *jwt.Claims) error {
+	return s.sign(r, c, time.Now())
+}
+
+func (s *Signer) sign(r *http.Request, c *jwt.Claims, now time.Time) error {
+	components := s.Components
+	if components == nil {
+		components = DefaultComponents
+	}
+	label := s.Label
+	if label == "" {
+		label = "sig1"
+	}
+
+	var params strings.Builder
+	params.WriteByte('(')
+	for i, name := range components {
+		if i != 0 {
+			params.WriteByte(' ')
+		}
+		params.WriteString(strconv.Quote(name))
+	}
+	params.WriteString(");created=")
+	params.WriteString(strconv.FormatInt(now.Unix(), 10))
+	if s.KeyID != "" {
+		if err := writeParam(&params, "keyid", s.KeyID); err != nil {
+			return err
+		}
+	}
+	if c != nil {
+		for _, name := range s.Claims {
+			if v, ok := c.String(name); ok {
+				if err := writeParam(&params, name, v); err != nil {
+					return err
+				}
+			}
+		}
+	}
+
+	base, err := Base(r, components, params.String())
+	if err != nil {
+		return err
+	}
+	sig, err := s.signature([]byte(base))
+	if err != nil {
+		return err
+	}
+
+	r.Header.Set("Signature-Input", label+"="+params.String())
+	r.Header.Set("Signature", label+"=:"+base64.StdEncoding.EncodeToString(sig)+":")
+	return nil
+}
+
+func (s *Signer) signature(base []byte) ([]byte, error) {
+	switch key := s.Key.(type) {
+	case ed25519.PrivateKey:
+		return ed25519.Sign(key, base), nil
+	case *ecdsa.PrivateKey:
+		if key.Curve.Params().BitSize != 256 {
+			return nil, errKeyType
+		}
+		digest := sha256.Sum256(base)
+		r, s, err := ecdsa.Sign(rand.Reader, key, digest[:])
+		if err != nil {
+			return nil, err
+		}
+		sig := make([]byte, 64)
+		r.FillBytes(sig[:32])
+		s.FillBytes(sig[32:])
+		return sig, nil
+	case []byte:
+		m := hmac.New(sha256.New, key)
+		m.Write(base)
+		return m.Sum(nil), nil
+	default:
+		return nil, errKeyType
+	}
+}
+
+// Verify checks the signature with label on r against the public counterpart
+// of the Signer key: ed25519.PublicKey, *ecdsa.PublicKey, or a []byte secret.
+// Signatures older than MaxAge are rejected, and so are signatures past their
+// "expires" parameter, if any. The return has the signature parameters, as
+// serialized.
+func Verify(r *http.Request, label string, key interface{}) (params string, err error) {
+	return verify(r, label, key, time.Now())
+}
+
+func verify(r *http.Request, label string, key interface{}, now time.Time) (params string, err error) {
+	params, ok := member(strings.Join(r.Header.Values("Signature-Input"), ", "), label)
+	if !ok {
+		return "", fmt.Errorf("httpsig: no Signature-Input %q", label)
+	}
+	encoded, ok := member(strings.Join(r.Header.Values("Signature"), ", "), label)
+	if !ok || len(encoded) < 2 || encoded[0] != ':' || encoded[len(encoded)-1] != ':' {
+		return "", fmt.Errorf("httpsig: no Signature %q", label)
+	}
+	sig, err := base64.StdEncoding.DecodeString(encoded[1 : len(encoded)-1])
+	if err != nil {
+		return "", fmt.Errorf("httpsig: malformed Signature: %w", err)
+	}
+
+	end := strings.IndexByte(params, ')')
+	if !strings.HasPrefix(params, "(") || end < 0 {
+		return "", errors.New("httpsig: malformed Signature-Input")
+	}
+	var components []string
+	for _, f := range strings.Fields(params[1:end]) {
+		name, err := strconv.Unquote(f)
+		if err != nil {
+			return "", errors.New("httpsig: malformed Signature-Input")
+		}
+		components = append(components, name)
+	}
+	base, err := Base(r, components, params)
+	if err != nil {
+		return "", err
+	}
+
+	var valid bool
+	switch key := key.(type) {
+	case ed25519.PublicKey:
+		valid = ed25519.Verify(key, []byte(base), sig)
+	case *ecdsa.PublicKey:
+		if len(sig) != 64 {
+			break
+		}
+		digest := sha256.Sum256([]byte(base))
+		valid = ecdsa.Verify(key, digest[:], new(big.Int).SetBytes(sig[:32]), new(big.Int).SetBytes(sig[32:]))
+	case []byte:
+		m := hmac.New(sha256.New, key)
+		m.Write([]byte(base))
+		valid = hmac.Equal(sig, m.Sum(nil))
+	default:
+		return "", errKeyType
+	}
+	if !valid {
+		return "", jwt.ErrSigMiss
+	}
+
+	if err := checkTime(params[end+1:], now); err != nil {
+		return "", err
+	}
+	return params, nil
+}
+
+// CheckTime applies MaxAge, Leeway and any expiry on the parameters.
+func checkTime(params string, now time.Time) error {
+	v, ok := param(params, "created")
+	if !ok {
+		return errCreated
+	}
+	created, err := strconv.ParseInt(v, 10, 64)
+	if err != nil {
+		return errCreated
+	}
+	if time.Unix(created, 0).After(now.Add(Leeway)) {
+		return errFuture
+	}
+	if MaxAge != 0 && now.Sub(time.Unix(created, 0)) > MaxAge+Leeway {
+		return errExpired
+	}
+	if v, ok := param(params, "expires"); ok {
+		expires, err := strconv.ParseInt(v, 10, 64)
+		if err != nil || !now.Before(time.Unix(expires, 0).Add(Leeway)) {
+			return errExpired
+		}
+	}
+	return nil
+}
+
+// Member returns the value of a dictionary member, as serialized, conform RFC
+// 8941, subsection 4.2.2. Commas within strings do not separate members.
+func member(dict, label string) (string, bool) {
+	for _, m := range splitOutsideStrings(dict, ',') {
+		m = strings.Trim(m, " \t")
+		if strings.HasPrefix(m, label+"=") {
+			return m[len(label)+1:], true
+		}
+	}
+	return "", false
+}
+
+// Param returns the value of a parameter, as serialized, from a sequence of
+// parameters, conform RFC 8941, subsection 4.2.3.2.
+func param(params, key string) (string, bool) {
+	for _, p := range splitOutsideStrings(params, ';') {
+		p = strings.TrimLeft(p, " ")
+		if strings.HasPrefix(p, key+"=") {
+			return p[len(key)+1:], true
+		}
+	}
+	return "", false
+}
+
+// SplitOutsideStrings slices s around each occurrence of sep, except for the
+// ones within a string, i.e., between double quotes with backslash escapes.
+func splitOutsideStrings(s string, sep byte) []string {
+	var parts []string
+	var quoted bool
+	offset := 0
+	for i := 0; i < len(s); i++ {
+		switch c := s[i]; {
+		case quoted && c == '\\':
+			i++ // escaped
+		case c == '"':
+			quoted = !quoted
+		case !quoted && c == sep:
+			parts = append(parts, s[offset:i])
+			offset = i + 1
+		}
+	}
+	return append(parts, s[offset:])
+}
+
+// Base returns the signature base of RFC 9421, subsection 2.5.
+func Base(r *http.Request, components []string, params string) (string, error) {
+	var buf strings.Builder
+	for _, name := range components {
+		var value string
+		switch name {
+		case "@method":
+			value = r.Method
+		case "@authority":
+			value = r.Host
+			if value == "" {
+				value = r.URL.Host
+			}
+			value = strings.ToLower(value)
+		case "@scheme":
+			value = strings.ToLower(r.URL.Scheme)
+			if value == "" {
+				value = "http"
+				if r.TLS != nil {
+					value = "https"
+				}
+			}
+		case "@path":
+			value = r.URL.EscapedPath()
+			if value == "" {
+				value = "/"
+			}
+		case "@query":
+			value = "?" + r.URL.RawQuery
+		case "@request-target":
+			value = r.URL.RequestURI()
+		default:
+			if strings.HasPrefix(name, "@") || name != strings.ToLower(name) {
+				return "", fmt.Errorf("httpsig: unsupported component %q", name)
+			}
+			var values []string
+			if name == "host" {
+				values = []string{r.Host}
+			} else {
+				values = r.Header.Values(name)
+			}
+			if len(values) == 0 {
+				return "", fmt.Errorf("httpsig: component %q absent", name)
+			}
+			for i, v := range values {
+				values[i] = strings.TrimSpace(v)
+			}
+			value = strings.Join(values, ", ")
+		}
+		buf.WriteString(strconv.Quote(name))
+		buf.WriteString(": ")
+		buf.WriteString(value)
+		buf.WriteByte('\n')
+	}
+	buf.WriteString(`"@signature-params": `)
+	buf.WriteString(params)
+	return buf.String(), nil
+}
+
+// WriteParam appends a string parameter conform RFC 8941.
+func writeParam(buf *strings.Builder, key, value string) error {
+	for i := 0; i < len(key); i++ {
+		c := key[i]
+		if !(c >= 'a' && c <= 'z' || c == '*' || i != 0 && (c >= '0' && c <= '9' || c == '_' || c == '-' || c == '.')) {
+			return fmt.Errorf("httpsig: parameter name %q not a structured field key", key)
+		}
+	}
+	buf.WriteByte(';')
+	buf.WriteString(key)
+	buf.WriteString(`="`)
+	for i := 0; i < len(value); i++ {
+		c := value[i]
+		if c < 0x20 || c > 0x7e {
+			return fmt.Errorf("httpsig: parameter %q value not in printable ASCII", key)
+		}
+		if c == '"' || c == '\\' {
+			buf.WriteByte('\\')
+		}
+		buf.WriteByte(c)
+	}
+	buf.WriteByte('"')
+	return nil
+}
+
+// Wrap returns a handler which signs each request before passing it on to
+// next. The claims are read from the request context with claimsKey, as placed
+// by jwt.Handler with ContextKey. Signing failures get status code 500.
+func (s *Signer) Wrap(next http.Handler, claimsKey interface{}) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		c, _ := r.Context().Value(claimsKey).(*jwt.Claims)
+		if err := s.Sign(r, c); err != nil {
+			http.Error(w, err.Error(), http.StatusInternalServerError)
+			return
+		}
+		next.ServeHTTP(w, r)
+	})
+}
@@ -0,0 +1,156 @@
+package jwt
+
+import (
+	"crypto/x509"
+	"encoding/base64"
+	"encoding/json"
+	"encoding/pem"
+	"errors"
+	"fmt"
+	"strings"
+)
+
+var errImportFormat = errors.New("jwt: neither a JWKS nor a certificate map")
+
+// ImportJWKS is like LoadJWK, yet it accepts the deviations from RFC 7517 and
+// RFC 7518 which are found in documents from cloud providers and identity
+// platforms. The register remains unmodified on error.
+//
+//   - Maps of key IDs to PEM-encoded certificates, as with the legacy Google
+//     and Firebase endpoints, are read as such.
+//   - Keys with an X.509 certificate chain ("x5c") only, i.e., without the
+//     key parameters, use the public key of the first certificate.
+//   - Key parameters may have base64 padding, and the standard alphabet.
+//   - Elliptic curve coordinates may have their leading zeros omitted.
+//   - Encryption keys ("use" "enc") are skipped, as they are not for JWS.
+//
+// The "alg" field is ignored, as with LoadJWK. Key usage is determined by the
+// algorithm of each token.
+func (keys *KeyRegister) ImportJWKS(data []byte) (keysAdded int, err error) {
+	var doc map[string]json.RawMessage
+	if err := json.Unmarshal(data, &doc); err != nil {
+		return 0, err
+	}
+
+	var staged KeyRegister
+	switch {
+	case doc["keys"] != nil:
+		var set struct {
+			Keys []*jwk
+		}
+		if err := json.Unmarshal(data, &set); err != nil {
+			return 0, err
+		}
+		for i, j := range set.Keys {
+			if j == nil || j.Use == "enc" {
+				continue
+			}
+			source := fmt.Sprintf("JWKS key %d", i)
+			key, err := j.importKey()
+			if err != nil {
+				return 0, fmt.Errorf("jwt: %s: %w", source, err)
+			}
+			added, err := keys.loadKey(&staged, key, j.Kid, source)
+			if err != nil {
+				return 0, err
+			}
+			if added {
+				keysAdded++
+			}
+		}
+
+	case doc["kty"] != nil || doc["x5c"] != nil:
+		j := new(jwk)
+		if err := json.Unmarshal(data, j); err != nil {
+			return 0, err
+		}
+		key, err := j.importKey()
+		if err != nil {
+			return 0, err
+		}
+		added, err := keys.loadKey(&staged, key, j.Kid, "JWK")
+		if err != nil {
+			return 0, err
+		}
+		if added {
+			keysAdded++
+		}
+
+	default:
+		var certs map[string]string
+		if err := json.Unmarshal(data, &certs); err != nil {
+			return 0, errImportFormat
+		}
+		for kid, text := range certs {
+			block, _ := pem.Decode([]byte(text))
+			if block == nil || block.Type != "CERTIFICATE" {
+				return 0, fmt.Errorf("jwt: key ID %q: %w", kid, errImportFormat)
+			}
+			cert, err := x509.ParseCertificate(block.Bytes)
+			if err != nil {
+				return 0, fmt.Errorf("jwt: key ID %q: %w", kid, err)
+			}
+			added, err := keys.loadKey(&staged, cert.PublicKey, kid, fmt.Sprintf("certificate of key ID %q", kid))
+			if err != nil {
+				return 0, fmt.Errorf("jwt: key ID %q: %w", kid, err)
+			}
+			if added {
+				keysAdded++
+			}
+		}
+	}
+
+	keys.addAll(&staged)
+	return keysAdded, nil
+}
+
+// ImportKey returns the public key or secret of j after normalization.
+func (j *jwk) importKey() (interface{}, error) {
+	if j.K == nil && j.X == nil && j.N == nil && len(j.X5c) != 0 {
+		// “Each string in the array is a base64-encoded (Section 4 of
+		// [RFC4648] -- not base64url-encoded) DER [ITU.X690.2008]
+		// PKIX certificate value.”
+		// — “JSON Web Key (JWK)” RFC 7517, subsection 4.7
+		der, err := base64.StdEncoding.DecodeString(j.X5c[0])
+		if err != nil {
+			return nil, fmt.Errorf("jwt: JWK with malformed certificate: %w", err)
+		}
+		cert, err := x509.ParseCertificate(der)
+		if err != nil {
+			return nil, err
+		}
+		return cert.PublicKey, nil
+	}
+
+	for _, p := range []*string{j.K, j.X, j.Y, j.N, j.E} {
+		if p != nil {
+			*p = strings.TrimRight(*p, "=")
+			*p = strings.NewReplacer("+", "-", "/", "_").Replace(*p)
+		}
+	}
+
+	if j.Kty != nil && *j.Kty == "EC" {
+		var size int
+		switch j.Crv {
+		case "P-256":
+			size = 32
+		case "P-384":
+			size = 48
+		case "P-521":
+			size = 66
+		}
+		for _, p := range []*string{j.X, j.Y} {
+			if p == nil {
+				continue
+			}
+			bytes, err := encoding.DecodeString(*p)
+			if err == nil && len(bytes) < size {
+				padded := make([]byte, size)
+				copy(padded[size-len(bytes):], bytes)
+				*p = encoding.EncodeToString(padded)
+			}
+		}
+	}
+
+	return j.key()
+}
@@ -0,0 +1,77 @@
+package jwt
+
+import (
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"testing"
+)
+
+// The corpus in testdata/jwks has the formats as published by the respective
+// providers, with generated keys.
+func TestImportJWKS(t *testing.T) {
+	golden := []struct {
+		file   string
+		kids   []string
+		strict bool // whether LoadJWK accepts the document as is
+	}{
+		{"cognito.json", []string{"1Z0wcT6Yqh3RRdcBw9Yd+2Q0pKkxE0wa5m4Xrt3Fc0A=", "nWv8HzYbIuVrqgqgSObb1xTeZAyP8dhb7eGr0SM5Mnk="}, true},
+		{"entra-id.json", []string{"JYhAcTPMZ_LX6DBlOWQ7Hn0NeXE", "L1KfKFI_jnXbwWc22xZxw1sUHH0"}, true},
+		// padding, and an encryption key
+		{"keycloak.json", []string{"xQ2GbEuX3bGWMd7bOk3aOI8zKo7xxP8GZq6jpE0KfFc"}, false},
+		// legacy Google and Firebase certificate map
+		{"google-x509.json", []string{"a3b762f871cdb3bae0044c649622fc1396eda3e3", "ac3e3e558111c7c7a75c5b65134d22f63ee006d0"}, false},
+		{"x5c-only.json", []string{"ec-cert", "rsa-cert"}, false},
+		// coordinate without leading zero, and with padding
+		{"ec-short.json", []string{"iap-1"}, false},
+	}
+
+	for _, gold := range golden {
+		data, err := os.ReadFile(filepath.Join("testdata", "jwks", gold.file))
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		_, err = new(KeyRegister).LoadJWK(data)
+		if gold.strict != (err == nil) {
+			t.Errorf("%s: got LoadJWK error %v, want strict %t", gold.file, err, gold.strict)
+		}
+
+		var keys KeyRegister
+		n, err := keys.ImportJWKS(data)
+		if err != nil {
+			t.Errorf("%s: import error: %v", gold.file, err)
+			continue
+		}
+		if n != len(gold.kids) {
+			t.Errorf("%s: imported %d keys, want %d", gold.file, n, len(gold.kids))
+		}
+		var kids []string
+		for _, ids := range [][]string{keys.ECDSAIDs, keys.EdDSAIDs, keys.RSAIDs, keys.SecretIDs} {
+			kids = append(kids, ids...)
+		}
+		sort.Strings(kids)
+		if got, want := strings.Join(kids, " "), strings.Join(gold.kids, " "); got != want {
+			t.Errorf("%s: got key IDs %q, want %q", gold.file, got, want)
+		}
+	}
+}
+
+func TestImportJWKSAtomic(t *testing.T) {
+	var keys KeyRegister
+	_, err := keys.ImportJWKS([]byte(`{"keys": [
+		{"kty": "oct", "k": "c2VjcmV0", "kid": "a"},
+		{"kty": "bad"}
+	]}`))
+	if err == nil {
+		t.Error("no error for unsupported key type")
+	}
+	if len(keys.Secrets) != 0 {
+		t.Error("register modified on error")
+	}
+
+	if _, err := keys.ImportJWKS([]byte(`{"k1": "not PEM"}`)); err == nil {
+		t.Error("no error for certificate map without PEM")
+	}
+}
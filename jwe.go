@@ -0,0 +1,273 @@
+package jwt
+
+import (
+	"bytes"
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/hmac"
+	"crypto/rsa"
+	"crypto/sha1"
+	"crypto/sha256"
+	"crypto/sha512"
+	"encoding/binary"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"hash"
+	"strings"
+)
+
+// Key management algorithms for JWE, from “JSON Web Algorithms (JWA)” RFC 7518,
+// section 4.
+const (
+	Dir        = "dir"          // direct use of a shared symmetric key
+	RSAOAEP    = "RSA-OAEP"     // RSAES OAEP with SHA-1
+	RSAOAEP256 = "RSA-OAEP-256" // RSAES OAEP with SHA-256
+)
+
+// Content encryption algorithms for JWE, from “JSON Web Algorithms (JWA)” RFC
+// 7518, section 5.
+const (
+	A128CBCHS256 = "A128CBC-HS256" // AES-CBC with HMAC-SHA256
+	A192CBCHS384 = "A192CBC-HS384" // AES-CBC with HMAC-SHA384
+	A256CBCHS512 = "A256CBC-HS512" // AES-CBC with HMAC-SHA512
+	A128GCM      = "A128GCM"       // AES-GCM with a 128-bit key
+	A192GCM      = "A192GCM"       // AES-GCM with a 192-bit key
+	A256GCM      = "A256GCM"       // AES-GCM with a 256-bit key
+)
+
+// ErrDecrypt means that none of the keys could decrypt the JWE. The cause is
+// not disclosed, which includes key mismatches and authentication failures.
+var ErrDecrypt = errors.New("jwt: JWE decryption failed")
+
+var (
+	errJWEParts  = errors.New("jwt: JWE compact serialization without 5 parts")
+	errJWEZip    = errors.New(`jwt: JWE compression ["zip"] not supported`)
+	errNotNested = errors.New(`jwt: JWE content type ["cty"] is not "JWT"`)
+	errNestedJWS = errors.New("jwt: nested JWT is not a JWS in compact serialization")
+)
+
+// DecryptKeys has the credentials for JWE decryption, with the same key ID
+// conventions as KeyRegister.
+type DecryptKeys struct {
+	RSAs    []*rsa.PrivateKey // RSA-OAEP and RSA-OAEP-256 credentials
+	Secrets [][]byte          // direct encryption credentials
+
+	RSAIDs    []string // RSAs key ID mapping
+	SecretIDs []string // Secrets key ID mapping
+}
+
+// JWEHeader has the JOSE header fields of a JWE.
+type jweHeader struct {
+	Alg  string   `json:"alg"`
+	Enc  string   `json:"enc"`
+	Kid  string   `json:"kid,omitempty"`
+	Cty  string   `json:"cty,omitempty"`
+	Zip  string   `json:"zip,omitempty"`
+	Crit []string `json:"crit,omitempty"`
+}
+
+// Decrypt returns the plaintext of a JWE in compact serialization, together
+// with the (decoded) JOSE header. The return is an AlgError for unsupported
+// algorithms, and ErrDecrypt for any decryption failure.
+func (keys *DecryptKeys) Decrypt(jwe []byte) (plaintext []byte, header json.RawMessage, err error) {
+	parts := bytes.Split(jwe, []byte{'.'})
+	if len(parts) != 5 {
+		return nil, nil, errJWEParts
+	}
+	header, err = encoding.DecodeString(string(parts[0]))
+	if err != nil {
+		return nil, nil, fmt.Errorf("jwt: malformed JWE header: %w", err)
+	}
+	var h jweHeader
+	if err := json.Unmarshal(header, &h); err != nil {
+		return nil, nil, fmt.Errorf("jwt: malformed JWE header: %w", err)
+	}
+	if h.Zip != "" {
+		return nil, nil, errJWEZip
+	}
+	if h.Crit != nil {
+		if len(h.Crit) == 0 {
+			return nil, nil, errCritEmpty
+		}
+		if err := EvalCrit(jwe, h.Crit, header); err != nil {
+			return nil, nil, err
+		}
+	}
+	var decoded [4][]byte
+	for i, p := range parts[1:] {
+		decoded[i], err = encoding.DecodeString(string(p))
+		if err != nil {
+			return nil, nil, fmt.Errorf("jwt: malformed JWE part %d: %w", i+2, err)
+		}
+	}
+	encKey, iv, ciphertext, tag := decoded[0], decoded[1], decoded[2], decoded[3]
+	// “Let the Additional Authenticated Data encryption parameter be
+	// ASCII(Encoded Protected Header).”
+	// — “JSON Web Encryption (JWE)” RFC 7516, subsection 5.2, step 14
+	aad := parts[0]
+
+	keySize, ok := contentKeySize(h.Enc)
+	if !ok {
+		return nil, nil, AlgError(h.Enc)
+	}
+
+	var oaepHash hash.Hash
+	switch h.Alg {
+	case Dir:
+		if len(encKey) != 0 {
+			return nil, nil, ErrDecrypt
+		}
+		options, _ := byKID(keys.Secrets, keys.SecretIDs, h.Kid)
+		for _, secret := range options {
+			if len(secret) != keySize {
+				continue
+			}
+			plaintext, err := decryptContent(h.Enc, secret, iv, ciphertext, tag, aad)
+			if err == nil {
+				return plaintext, header, nil
+			}
+		}
+		return nil, nil, ErrDecrypt
+
+	case RSAOAEP:
+		oaepHash = sha1.New()
+	case RSAOAEP256:
+		oaepHash = sha256.New()
+	default:
+		return nil, nil, AlgError(h.Alg)
+	}
+
+	options, _ := byKID(keys.RSAs, keys.RSAIDs, h.Kid)
+	for _, key := range options {
+		cek, err := rsa.DecryptOAEP(oaepHash, nil, key, encKey, nil)
+		if err != nil || len(cek) != keySize {
+			continue
+		}
+		plaintext, err := decryptContent(h.Enc, cek, iv, ciphertext, tag, aad)
+		wipe(cek)
+		if err == nil {
+			return plaintext, header, nil
+		}
+	}
+	return nil, nil, ErrDecrypt
+}
+
+// CheckNested parses a nested JWT, i.e., a JWS wrapped in a JWE with content
+// type "JWT", if, and only if, the JWE decrypts with dec and the signature of
+// the JWS checks out. Tokens without encryption are checked as is, as with
+// Check. The Claims have the JOSE header of the JWS. Use Claims.Valid to
+// complete the verification.
+func (keys *KeyRegister) CheckNested(token []byte, dec *DecryptKeys) (*Claims, error) {
+	if bytes.Count(token, []byte{'.'}) != 4 {
+		return keys.Check(token)
+	}
+
+	plaintext, header, err := dec.Decrypt(token)
+	if err != nil {
+		return nil, err
+	}
+	var h jweHeader
+	if err := json.Unmarshal(header, &h); err != nil {
+		return nil, err
+	}
+	// “In the case that nested signing or encryption is employed, this
+	// Header Parameter MUST be present; in this case, the value MUST be
+	// "JWT", to indicate that a Nested JWT is carried in this JWT.”
+	// — “JSON Web Token (JWT)” RFC 7519, subsection 5.2
+	if !strings.EqualFold(h.Cty, "JWT") {
+		return nil, errNotNested
+	}
+	if bytes.Count(plaintext, []byte{'.'}) != 2 {
+		return nil, errNestedJWS
+	}
+	return keys.Check(plaintext)
+}
+
+// ContentKeySize returns the number of bytes for the key of enc.
+func contentKeySize(enc string) (int, bool) {
+	switch enc {
+	case A128GCM:
+		return 16, true
+	case A192GCM:
+		return 24, true
+	case A256GCM, A128CBCHS256:
+		return 32, true
+	case A192CBCHS384:
+		return 48, true
+	case A256CBCHS512:
+		return 64, true
+	}
+	return 0, false
+}
+
+// CBCHash returns the HMAC hash for the AES-CBC algorithms.
+func cbcHash(enc string) func() hash.Hash {
+	switch enc {
+	case A128CBCHS256:
+		return sha256.New
+	case A192CBCHS384:
+		return sha512.New384
+	default:
+		return sha512.New
+	}
+}
+
+// DecryptContent authenticates and decrypts with the content encryption key.
+func decryptContent(enc string, cek, iv, ciphertext, tag, aad []byte) ([]byte, error) {
+	if strings.HasSuffix(enc, "GCM") {
+		block, err := aes.NewCipher(cek)
+		if err != nil {
+			return nil, err
+		}
+		gcm, err := cipher.NewGCM(block)
+		if err != nil {
+			return nil, err
+		}
+		if len(iv) != gcm.NonceSize() || len(tag) != gcm.Overhead() {
+			return nil, ErrDecrypt
+		}
+		sealed := make([]byte, 0, len(ciphertext)+len(tag))
+		sealed = append(append(sealed, ciphertext...), tag...)
+		return gcm.Open(nil, iv, sealed, aad)
+	}
+
+	// “JSON Web Algorithms (JWA)” RFC 7518, subsection 5.2.2.2
+	macKey, encKey := cek[:len(cek)/2], cek[len(cek)/2:]
+	if len(iv) != aes.BlockSize || len(ciphertext) == 0 || len(ciphertext)%aes.BlockSize != 0 {
+		return nil, ErrDecrypt
+	}
+	if !hmac.Equal(tag, cbcTag(enc, macKey, aad, iv, ciphertext)) {
+		return nil, ErrDecrypt
+	}
+	block, err := aes.NewCipher(encKey)
+	if err != nil {
+		return nil, err
+	}
+	plaintext := make([]byte, len(ciphertext))
+	cipher.NewCBCDecrypter(block, iv).CryptBlocks(plaintext, ciphertext)
+
+	// PKCS #7 padding
+	n := int(plaintext[len(plaintext)-1])
+	if n == 0 || n > aes.BlockSize {
+		return nil, ErrDecrypt
+	}
+	for _, b := range plaintext[len(plaintext)-n:] {
+		if int(b) != n {
+			return nil, ErrDecrypt
+		}
+	}
+	return plaintext[:len(plaintext)-n], nil
+}
+
+// CBCTag returns the authentication tag of AES-CBC with HMAC-SHA2.
+func cbcTag(enc string, macKey, aad, iv, ciphertext []byte) []byte {
+	mac := hmac.New(cbcHash(enc), macKey)
+	mac.Write(aad)
+	mac.Write(iv)
+	mac.Write(ciphertext)
+	var al [8]byte
+	binary.BigEndian.PutUint64(al[:], uint64(len(aad))*8)
+	mac.Write(al[:])
+	return mac.Sum(nil)[:len(macKey)]
+}
@@ -0,0 +1,115 @@
+//go:build !jwtverifyonly
+
+package jwt
+
+import (
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/sha1"
+	"crypto/sha256"
+	"encoding/json"
+	"fmt"
+	"hash"
+	"io"
+	"strings"
+)
+
+// EncryptNested wraps a JWS in a JWE with content type "JWT", i.e., it makes
+// a nested JWT. Alg is one of Dir, with a []byte key of the size of enc, or
+// RSAOAEP or RSAOAEP256, with an *rsa.PublicKey. The key ID is optional. See
+// KeyRegister.CheckNested for the reverse.
+func EncryptNested(jws []byte, alg, enc string, key interface{}, kid string) ([]byte, error) {
+	keySize, ok := contentKeySize(enc)
+	if !ok {
+		return nil, AlgError(enc)
+	}
+
+	var cek, encKey []byte
+	switch alg {
+	case Dir:
+		secret, ok := key.([]byte)
+		if !ok {
+			return nil, fmt.Errorf("jwt: key type %T for JWE algorithm %q", key, alg)
+		}
+		if len(secret) != keySize {
+			return nil, fmt.Errorf("jwt: %d-byte key for JWE encryption %q, want %d", len(secret), enc, keySize)
+		}
+		cek = secret
+
+	case RSAOAEP, RSAOAEP256:
+		pub, ok := key.(*rsa.PublicKey)
+		if !ok {
+			return nil, fmt.Errorf("jwt: key type %T for JWE algorithm %q", key, alg)
+		}
+		var oaepHash hash.Hash = sha1.New()
+		if alg == RSAOAEP256 {
+			oaepHash = sha256.New()
+		}
+		cek = make([]byte, keySize)
+		defer wipe(cek)
+		if _, err := io.ReadFull(rand.Reader, cek); err != nil {
+			return nil, err
+		}
+		var err error
+		encKey, err = rsa.EncryptOAEP(oaepHash, rand.Reader, pub, cek, nil)
+		if err != nil {
+			return nil, err
+		}
+
+	default:
+		return nil, AlgError(alg)
+	}
+
+	header, err := json.Marshal(&jweHeader{Alg: alg, Enc: enc, Kid: kid, Cty: "JWT"})
+	if err != nil {
+		return nil, err
+	}
+	aad := []byte(encoding.EncodeToString(header))
+
+	var iv, ciphertext, tag []byte
+	if strings.HasSuffix(enc, "GCM") {
+		block, err := aes.NewCipher(cek)
+		if err != nil {
+			return nil, err
+		}
+		gcm, err := cipher.NewGCM(block)
+		if err != nil {
+			return nil, err
+		}
+		iv = make([]byte, gcm.NonceSize())
+		if _, err := io.ReadFull(rand.Reader, iv); err != nil {
+			return nil, err
+		}
+		sealed := gcm.Seal(nil, iv, jws, aad)
+		ciphertext, tag = sealed[:len(jws)], sealed[len(jws):]
+	} else {
+		macKey, aesKey := cek[:len(cek)/2], cek[len(cek)/2:]
+		block, err := aes.NewCipher(aesKey)
+		if err != nil {
+			return nil, err
+		}
+		iv = make([]byte, aes.BlockSize)
+		if _, err := io.ReadFull(rand.Reader, iv); err != nil {
+			return nil, err
+		}
+		// PKCS #7 padding
+		n := aes.BlockSize - len(jws)%aes.BlockSize
+		ciphertext = make([]byte, len(jws)+n)
+		copy(ciphertext, jws)
+		for i := len(jws); i < len(ciphertext); i++ {
+			ciphertext[i] = byte(n)
+		}
+		cipher.NewCBCEncrypter(block, iv).CryptBlocks(ciphertext, ciphertext)
+		tag = cbcTag(enc, macKey, aad, iv, ciphertext)
+	}
+
+	var buf strings.Builder
+	buf.Write(aad)
+	for _, part := range [][]byte{encKey, iv, ciphertext, tag} {
+		buf.WriteByte('.')
+		buf.WriteString(encoding.EncodeToString(part))
+	}
+	return []byte(buf.String()), nil
+}
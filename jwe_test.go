@@ -0,0 +1,161 @@
+package jwt
+
+import (
+	"bytes"
+	"crypto/ed25519"
+	"crypto/rsa"
+	"encoding/hex"
+	"errors"
+	"strings"
+	"testing"
+)
+
+// “JSON Web Algorithms (JWA)” RFC 7518, appendix B.1
+func TestDecryptContentA128CBCHS256(t *testing.T) {
+	key, _ := hex.DecodeString("000102030405060708090a0b0c0d0e0f101112131415161718191a1b1c1d1e1f")
+	iv, _ := hex.DecodeString("1af38c2dc2b96ffdd86694092341bc04")
+	ciphertext, _ := hex.DecodeString("c80edfa32ddf39d5ef00c0b468834279a2e46a1b8049f792f76bfe54b903a9c9a94ac9b47ad2655c5f10f9aef71427e2fc6f9b3f399a221489f16362c703233609d45ac69864e3321cf82935ac4096c86e133314c54019e8ca7980dfa4b9cf1b384c486f3a54c51078158ee5d79de59fbd34d848b3d69550a67646344427ade54b8851ffb598f7f80074b9473c82e2db")
+	tag, _ := hex.DecodeString("652c3fa36b0a7c5b3219fab3a30bc1c4")
+	aad := []byte("The second principle of Auguste Kerckhoffs")
+	want := "A cipher system must not be required to be secret, and it must be able to fall into the hands of the enemy without inconvenience"
+
+	got, err := decryptContent(A128CBCHS256, key, iv, ciphertext, tag, aad)
+	if err != nil {
+		t.Fatal("decrypt error:", err)
+	}
+	if string(got) != want {
+		t.Errorf("got plaintext %q, want %q", got, want)
+	}
+
+	tag[0] ^= 1
+	if _, err := decryptContent(A128CBCHS256, key, iv, ciphertext, tag, aad); err != ErrDecrypt {
+		t.Errorf("tampered tag got error %v, want %v", err, ErrDecrypt)
+	}
+}
+
+func TestCheckNested(t *testing.T) {
+	keys := &KeyRegister{EdDSAs: []ed25519.PublicKey{testKeyEd25519Public}}
+	var c Claims
+	c.Subject = "alice"
+	jws, err := c.EdDSASign(testKeyEd25519Private)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	encs := []string{A128GCM, A192GCM, A256GCM, A128CBCHS256, A192CBCHS384, A256CBCHS512}
+	for _, enc := range encs {
+		size, _ := contentKeySize(enc)
+		secret := bytes.Repeat([]byte{byte(size)}, size)
+		dec := &DecryptKeys{
+			RSAs:      []*rsa.PrivateKey{testKeyRSA1024, testKeyRSA2048},
+			RSAIDs:    []string{"", "enc1"},
+			Secrets:   [][]byte{bytes.Repeat([]byte{1}, 32), secret},
+			SecretIDs: []string{"other", "shared"},
+		}
+
+		for _, alg := range []string{Dir, RSAOAEP, RSAOAEP256} {
+			var key interface{} = &testKeyRSA2048.PublicKey
+			kid := "enc1"
+			if alg == Dir {
+				key, kid = secret, "shared"
+			}
+			token, err := EncryptNested(jws, alg, enc, key, kid)
+			if err != nil {
+				t.Errorf("%s %s: encrypt error: %s", alg, enc, err)
+				continue
+			}
+			if n := strings.Count(string(token), "."); n != 4 {
+				t.Errorf("%s %s: got %d dots, want 4", alg, enc, n)
+			}
+			got, err := keys.CheckNested(token, dec)
+			if err != nil {
+				t.Errorf("%s %s: check error: %s", alg, enc, err)
+				continue
+			}
+			if got.Subject != "alice" {
+				t.Errorf("%s %s: got subject %q, want alice", alg, enc, got.Subject)
+			}
+
+			// wrong decryption keys
+			if _, err := keys.CheckNested(token, &DecryptKeys{RSAs: []*rsa.PrivateKey{testKeyRSA4096}, Secrets: [][]byte{make([]byte, size)}}); err != ErrDecrypt {
+				t.Errorf("%s %s: wrong keys got error %v, want %v", alg, enc, err, ErrDecrypt)
+			}
+		}
+	}
+
+	// plain JWS passes as is
+	if got, err := keys.CheckNested(jws, new(DecryptKeys)); err != nil || got.Subject != "alice" {
+		t.Errorf("plain JWS got (%v, %v)", got, err)
+	}
+}
+
+func TestCheckNestedErrors(t *testing.T) {
+	keys := &KeyRegister{EdDSAs: []ed25519.PublicKey{testKeyEd25519Public}}
+	secret := make([]byte, 16)
+	dec := &DecryptKeys{Secrets: [][]byte{secret}}
+
+	// not a JWT inside
+	token, err := EncryptNested([]byte("hello"), Dir, A128GCM, secret, "")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := keys.CheckNested(token, dec); err != errNestedJWS {
+		t.Errorf("JWE with non-JWS content got error %v, want %v", err, errNestedJWS)
+	}
+
+	// unsupported algorithms
+	for _, header := range []string{
+		`{"alg":"A128KW","enc":"A128GCM","cty":"JWT"}`,
+		`{"alg":"dir","enc":"A128CTR","cty":"JWT"}`,
+	} {
+		token := encoding.EncodeToString([]byte(header)) + "...."
+		var algErr AlgError
+		if _, err := keys.CheckNested([]byte(token), dec); !errors.As(err, &algErr) {
+			t.Errorf("header %s got error %v, want an AlgError", header, err)
+		}
+	}
+
+	// compression
+	token = []byte(encoding.EncodeToString([]byte(`{"alg":"dir","enc":"A128GCM","zip":"DEF"}`)) + "....")
+	if _, err := keys.CheckNested(token, dec); err != errJWEZip {
+		t.Errorf("compression got error %v, want %v", err, errJWEZip)
+	}
+
+	// signature mismatch inside
+	jws, err := new(Claims).EdDSASign(testKeyEd25519Private)
+	if err != nil {
+		t.Fatal(err)
+	}
+	jws[len(jws)-2] ^= 1
+	token, err = EncryptNested(jws, Dir, A128GCM, secret, "")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := keys.CheckNested(token, dec); err != ErrSigMiss {
+		t.Errorf("tampered JWS got error %v, want %v", err, ErrSigMiss)
+	}
+}
+
+func TestEncryptNestedHeader(t *testing.T) {
+	jws, err := new(Claims).EdDSASign(testKeyEd25519Private)
+	if err != nil {
+		t.Fatal(err)
+	}
+	golden := []struct{ kid, want string }{
+		{"", `{"alg":"dir","enc":"A128GCM","cty":"JWT"}`},
+		{"shared", `{"alg":"dir","enc":"A128GCM","kid":"shared","cty":"JWT"}`},
+	}
+	for _, gold := range golden {
+		token, err := EncryptNested(jws, Dir, A128GCM, make([]byte, 16), gold.kid)
+		if err != nil {
+			t.Fatal(err)
+		}
+		header, err := encoding.DecodeString(string(token[:bytes.IndexByte(token, '.')]))
+		if err != nil {
+			t.Fatal(err)
+		}
+		if string(header) != gold.want {
+			t.Errorf("key ID %q: got header %s, want %s", gold.kid, header, gold.want)
+		}
+	}
+}
@@ -0,0 +1,148 @@
+package jwt
+
+import (
+	"crypto/elliptic"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"math/big"
+	"strings"
+	"time"
+)
+
+// JWKSetType is the media type of signed JWKS from “OpenID Federation 1.0”,
+// section 5.2.1, in the "typ" header notation.
+const JWKSetType = "jwk-set+jwt"
+
+// JWKSetHeader is the extra JOSE header for signed JWKS. See JWKSetClaims.
+var JWKSetHeader = json.RawMessage(`{"typ":"` + JWKSetType + `"}`)
+
+var (
+	errJWKSetType = errors.New(`jwt: signed JWKS without "typ" ` + JWKSetType)
+	errJWKSetKeys = errors.New(`jwt: signed JWKS without "keys" array`)
+)
+
+type jwkOut struct {
+	Kty string `json:"kty"`
+	Kid string `json:"kid,omitempty"`
+	Crv string `json:"crv,omitempty"`
+	X   string `json:"x,omitempty"`
+	Y   string `json:"y,omitempty"`
+	N   string `json:"n,omitempty"`
+	E   string `json:"e,omitempty"`
+}
+
+// JWKS exports the (public) keys as a JSON Web Key Set, including any key IDs.
+// Elements from the Secret and the HMAC fields, if any, are not included.
+func (keys *KeyRegister) JWKS() ([]byte, error) {
+	var set struct {
+		Keys []jwkOut `json:"keys"`
+	}
+	set.Keys = []jwkOut{}
+
+	kid := func(ids []string, i int) string {
+		if i < len(ids) {
+			return ids[i]
+		}
+		return ""
+	}
+	for i, key := range keys.ECDSAs {
+		var crv string
+		switch key.Curve {
+		case elliptic.P256():
+			crv = "P-256"
+		case elliptic.P384():
+			crv = "P-384"
+		case elliptic.P521():
+			crv = "P-521"
+		default:
+			return nil, fmt.Errorf("jwt: JWK export of elliptic curve %q not supported", key.Curve.Params().Name)
+		}
+		size := (key.Curve.Params().BitSize + 7) / 8
+		set.Keys = append(set.Keys, jwkOut{
+			Kty: "EC",
+			Kid: kid(keys.ECDSAIDs, i),
+			Crv: crv,
+			X:   encoding.EncodeToString(key.X.FillBytes(make([]byte, size))),
+			Y:   encoding.EncodeToString(key.Y.FillBytes(make([]byte, size))),
+		})
+	}
+	for i, key := range keys.EdDSAs {
+		set.Keys = append(set.Keys, jwkOut{
+			Kty: "OKP",
+			Kid: kid(keys.EdDSAIDs, i),
+			Crv: "Ed25519",
+			X:   encoding.EncodeToString(key),
+		})
+	}
+	for i, key := range keys.RSAs {
+		set.Keys = append(set.Keys, jwkOut{
+			Kty: "RSA",
+			Kid: kid(keys.RSAIDs, i),
+			N:   encoding.EncodeToString(key.N.Bytes()),
+			E:   encoding.EncodeToString(big.NewInt(int64(key.E)).Bytes()),
+		})
+	}
+	return json.Marshal(&set)
+}
+
+// JWKSetClaims sets the (public) keys as the "keys" claim of c, conform the
+// JWKS encoding. Sign c with JWKSetHeader as an extra header to publish the
+// JWKS as a signed (JWT) document. Issuer, Subject, Issued and Expires should
+// be set too.
+func (keys *KeyRegister) JWKSetClaims(c *Claims) error {
+	jwks, err := keys.JWKS()
+	if err != nil {
+		return err
+	}
+	var set struct {
+		Keys json.RawMessage `json:"keys"`
+	}
+	if err := json.Unmarshal(jwks, &set); err != nil {
+		return err
+	}
+	if c.Set == nil {
+		c.Set = make(map[string]interface{})
+	}
+	c.Set["keys"] = set.Keys
+	return nil
+}
+
+// LoadSignedJWKS adds keys from a signed JWKS, as produced with JWKSetClaims,
+// to the register. The token must check out with trust, i.e., the pinned (or
+// federation) keys, and it must be valid at the current time with leeway. The
+// register remains unmodified on error.
+func (keys *KeyRegister) LoadSignedJWKS(token []byte, trust *KeyRegister, leeway time.Duration) (keysAdded int, err error) {
+	claims, err := trust.Check(token)
+	if err != nil {
+		return 0, err
+	}
+
+	var header struct {
+		Typ string `json:"typ"`
+	}
+	if err := json.Unmarshal(claims.RawHeader, &header); err != nil {
+		return 0, err
+	}
+	typ := header.Typ
+	if len(typ) > len("application/") && strings.EqualFold(typ[:len("application/")], "application/") {
+		typ = typ[len("application/"):]
+	}
+	if !strings.EqualFold(typ, JWKSetType) {
+		return 0, errJWKSetType
+	}
+
+	if err := claims.AcceptTemporal(time.Now(), leeway); err != nil {
+		return 0, err
+	}
+
+	array, ok := claims.Set["keys"].([]interface{})
+	if !ok {
+		return 0, errJWKSetKeys
+	}
+	jwks, err := json.Marshal(map[string]interface{}{"keys": array})
+	if err != nil {
+		return 0, err
+	}
+	return keys.LoadJWK(jwks)
+}
@@ -0,0 +1,79 @@
+package jwt
+
+import (
+	"bytes"
+	"crypto/ecdsa"
+	"crypto/ed25519"
+	"crypto/rsa"
+	"testing"
+	"time"
+)
+
+func TestJWKSRoundTrip(t *testing.T) {
+	keys := &KeyRegister{
+		ECDSAs:   []*ecdsa.PublicKey{&testKeyEC256.PublicKey, &testKeyEC384.PublicKey, &testKeyEC521.PublicKey},
+		EdDSAs:   []ed25519.PublicKey{testKeyEd25519Public},
+		RSAs:     []*rsa.PublicKey{&testKeyRSA1024.PublicKey},
+		ECDSAIDs: []string{"", "ec384"},
+		RSAIDs:   []string{"rsa"},
+		Secrets:  [][]byte{[]byte("excluded")},
+	}
+	jwks, err := keys.JWKS()
+	if err != nil {
+		t.Fatal("JWKS error:", err)
+	}
+
+	var got KeyRegister
+	n, err := got.LoadJWK(jwks)
+	if n != 5 || err != nil {
+		t.Fatalf("LoadJWK got (%d, %v), want (5, nil)", n, err)
+	}
+	want, _ := keys.PEM()
+	if pem, _ := got.PEM(); !bytes.Equal(pem, want) {
+		t.Errorf("got PEM %s\nwant %s", pem, want)
+	}
+	if len(got.ECDSAIDs) < 2 || got.ECDSAIDs[1] != "ec384" || len(got.RSAIDs) != 1 || got.RSAIDs[0] != "rsa" {
+		t.Errorf("got key IDs %q and %q", got.ECDSAIDs, got.RSAIDs)
+	}
+	if len(got.Secrets) != 0 {
+		t.Error("secrets exported")
+	}
+}
+
+func TestSignedJWKS(t *testing.T) {
+	published := &KeyRegister{EdDSAs: []ed25519.PublicKey{testKeyEd25519Public}, EdDSAIDs: []string{"leaf"}}
+	trust := &KeyRegister{ECDSAs: []*ecdsa.PublicKey{&testKeyEC256.PublicKey}}
+
+	var c Claims
+	c.Issuer = "https://op.example"
+	c.Expires = NewNumericTime(time.Now().Add(time.Hour).Truncate(time.Second))
+	if err := published.JWKSetClaims(&c); err != nil {
+		t.Fatal("JWKSetClaims error:", err)
+	}
+	token, err := c.ECDSASign(ES256, testKeyEC256, JWKSetHeader)
+	if err != nil {
+		t.Fatal("sign error:", err)
+	}
+
+	var keys KeyRegister
+	n, err := keys.LoadSignedJWKS(token, trust, 0)
+	if n != 1 || err != nil {
+		t.Fatalf("LoadSignedJWKS got (%d, %v), want (1, nil)", n, err)
+	}
+	if len(keys.EdDSAIDs) != 1 || keys.EdDSAIDs[0] != "leaf" {
+		t.Errorf("got key IDs %q, want [leaf]", keys.EdDSAIDs)
+	}
+
+	// untrusted signer
+	if _, err := new(KeyRegister).LoadSignedJWKS(token, published, 0); err == nil {
+		t.Error("untrusted signature got no error")
+	}
+	// typ absent
+	token, err = c.ECDSASign(ES256, testKeyEC256)
+	if err != nil {
+		t.Fatal("sign error:", err)
+	}
+	if _, err := new(KeyRegister).LoadSignedJWKS(token, trust, 0); err != errJWKSetType {
+		t.Errorf("no typ got error %v, want %v", err, errJWKSetType)
+	}
+}
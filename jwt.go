@@ -1,10 +1,14 @@
 // Package jwt implements “JSON Web Token (JWT)” RFC 7519.
 // Signatures only; no unsecured nor encrypted tokens.
+//
+// The jwtverifyonly build tag excludes token issuance, including the sign
+// methods and SessionManager, and it rejects private keys from PEM. Embedded
+// verifiers get a smaller binary with less attack surface. Tests require the
+// full build.
 package jwt
 
 import (
 	"crypto"
-	"crypto/hmac"
 	"crypto/rsa"
 	_ "crypto/sha256" // link into binary
 	_ "crypto/sha512" // link into binary
@@ -13,7 +17,9 @@ import (
 	"errors"
 	"fmt"
 	"math"
+	"strings"
 	"sync"
+	"sync/atomic"
 	"time"
 )
 
@@ -47,7 +53,7 @@ const (
 // Algorithm support is configured with hash registrations.
 // Any modifications should be made before first use to prevent
 // data races in the Check and Sign functions, i.e., customise
-// from either main or init.
+// from either main or init. See Algorithm for other schemes.
 var (
 	ECDSAAlgs = map[string]crypto.Hash{
 		ES256: crypto.SHA256,
@@ -80,6 +86,9 @@ func hashLookup(alg string, algs map[string]crypto.Hash) (crypto.Hash, error) {
 	if !hash.Available() {
 		return 0, errHashLink
 	}
+	if err := fipsAlg(alg, hash); err != nil {
+		return 0, err
+	}
 	return hash, nil
 }
 
@@ -103,21 +112,43 @@ var errNoSecret = errors.New("jwt: empty secret rejected")
 // Multiple goroutines may invoke methods on an HMAC simultaneously.
 type HMAC struct {
 	alg     string
-	digests sync.Pool
+	size    int         // signature size
+	digests sync.Pool   // *hmacDigest, unkeyed
+	header  headerCache // for tokens with a key ID
+
+	// The inner and outer pad are the only copies of the secret.
+	ipad, opad []byte
+	wiped      uint32 // atomic
+}
+
+// HeaderCache holds the last JOSE header with a key ID, in encoded form. The
+// zero value is ready for use.
+type headerCache struct {
+	v atomic.Value // *cachedHeader
+}
+
+type cachedHeader struct {
+	kid    string
+	raw    json.RawMessage
+	prefix string // base64 with trailing dot
 }
 
 // NewHMAC returns a new reusable instance.
 func NewHMAC(alg string, secret []byte) (*HMAC, error) {
-	if len(secret) == 0 {
-		return nil, errNoSecret
+	if err := checkSecret(secret); err != nil {
+		return nil, err
 	}
 	hash, err := hashLookup(alg, HMACAlgs)
 	if err != nil {
 		return nil, err
 	}
-	return &HMAC{alg, sync.Pool{New: func() interface{} {
-		return hmac.New(hash.New, secret)
-	}}}, nil
+	h := &HMAC{alg: alg, size: hash.Size()}
+	h.digests.New = func() interface{} {
+		poolEvent(PoolHMAC, PoolNew)
+		return &hmacDigest{inner: hash.New(), outer: hash.New()}
+	}
+	h.ipad, h.opad = hmacPads(hash.New(), secret)
+	return h, nil
 }
 
 var encoding = base64.RawURLEncoding
@@ -184,6 +215,7 @@ var (
 	errFromFuture = errors.New(`jwt: issued ["iat"] in the future`)
 	errForFuture  = errors.New(`jwt: scheduled ["nbf"] for the future`)
 	errExpired    = errors.New(`jwt: expiration time ["exp"] passed`)
+	errTooOld     = errors.New(`jwt: issued ["iat"] exceeds the maximum age`)
 )
 
 // AcceptTemporal verifies Issued, NotBefore and Expires each against t when the
@@ -203,6 +235,35 @@ func (r *Registered) AcceptTemporal(t time.Time, leeway time.Duration) error {
 	return nil // OK
 }
 
+// AcceptAge is the equivalent of AcceptTemporal for tokens without expiry.
+// Issued must be present, and it may not be more than maxAge before t. The
+// NotBefore and Expires claims are verified as with AcceptTemporal.
+func (r *Registered) AcceptAge(t time.Time, maxAge, leeway time.Duration) error {
+	if r.Issued == nil {
+		return errNoIssued
+	}
+	if err := r.AcceptTemporal(t, leeway); err != nil {
+		return err
+	}
+	if r.Issued.Time().Before(t.Add(-maxAge - leeway)) {
+		return errTooOld
+	}
+	return nil
+}
+
+// TTL returns the remaining validity at now, i.e., the duration until Expires.
+// Any violation of AcceptTemporal, without leeway, is returned instead. Tokens
+// without an expiration time get the maximum duration.
+func (r *Registered) TTL(now time.Time) (time.Duration, error) {
+	if err := r.AcceptTemporal(now, 0); err != nil {
+		return 0, err
+	}
+	if r.Expires == nil {
+		return 1<<63 - 1, nil
+	}
+	return r.Expires.Time().Sub(now), nil
+}
+
 // AcceptAudience verifies the applicability of an audience identified as
 // stringOrURI. Any stringOrURI is accepted on absence of the aud(ience) claim.
 func (r *Registered) AcceptAudience(stringOrURI string) bool {
@@ -214,6 +275,78 @@ func (r *Registered) AcceptAudience(stringOrURI string) bool {
 	return len(r.Audiences) == 0
 }
 
+// AudienceMatch defines the comparison of audiences.
+type AudienceMatch int
+
+// Audience Comparison Modes
+const (
+	// AudienceExact is the strict string equality of RFC 7519.
+	AudienceExact AudienceMatch = iota
+
+	// AudiencePrefix accepts any resource within the URL of the audience,
+	// e.g., "https://api.example.com" accepts "https://api.example.com/v2"
+	// but not "https://api.example.com.evil.net". Audiences without a URL
+	// scheme are compared as with AudienceExact.
+	AudiencePrefix
+
+	// AudienceWildcard accepts asterisks in the audience to match any
+	// sequence of characters, excluding the slash '/', e.g.,
+	// "https://*.example.com" accepts "https://api.example.com".
+	AudienceWildcard
+)
+
+// AcceptAudienceMatch is like AcceptAudience, yet it compares according to m.
+func (r *Registered) AcceptAudienceMatch(stringOrURI string, m AudienceMatch) bool {
+	for _, s := range r.Audiences {
+		if stringOrURI == s {
+			return true
+		}
+
+		switch m {
+		case AudiencePrefix:
+			if !strings.Contains(s, "://") || !strings.HasPrefix(stringOrURI, s) {
+				break
+			}
+			if strings.HasSuffix(s, "/") {
+				return true
+			}
+			switch stringOrURI[len(s)] {
+			case '/', '?', '#':
+				return true
+			}
+
+		case AudienceWildcard:
+			if wildcardMatch(s, stringOrURI) {
+				return true
+			}
+		}
+	}
+	return len(r.Audiences) == 0
+}
+
+// WildcardMatch returns whether s matches pattern with asterisks for any
+// sequence of characters other than the slash '/'.
+func wildcardMatch(pattern, s string) bool {
+	i := strings.IndexByte(pattern, '*')
+	if i < 0 {
+		return pattern == s
+	}
+	if !strings.HasPrefix(s, pattern[:i]) {
+		return false
+	}
+	s, pattern = s[i:], pattern[i+1:]
+
+	// try each possible span, shortest first
+	for n := 0; ; n++ {
+		if wildcardMatch(pattern, s[n:]) {
+			return true
+		}
+		if n >= len(s) || s[n] == '/' {
+			return false
+		}
+	}
+}
+
 // Claims are the (signed) statements of a JWT.
 type Claims struct {
 	// Registered field values take precedence over Set.
@@ -235,6 +235,30 @@ func TestAcceptTemporal(t *testing.T) {
 	}
 }
 
+func TestTTL(t *testing.T) {
+	now := time.Unix(1537622794, 0)
+
+	var r Registered
+	if d, err := r.TTL(now); err != nil || d != 1<<63-1 {
+		t.Errorf("no expiry got (%s, %v), want maximum", d, err)
+	}
+
+	r.Expires = NewNumericTime(now.Add(90 * time.Second))
+	if d, err := r.TTL(now); err != nil || d != 90*time.Second {
+		t.Errorf("got (%s, %v), want 1m30s", d, err)
+	}
+
+	r.NotBefore = NewNumericTime(now.Add(time.Second))
+	if d, err := r.TTL(now); err != errForFuture || d != 0 {
+		t.Errorf("not before got (%s, %v), want error %v", d, err, errForFuture)
+	}
+
+	r.NotBefore = nil
+	if d, err := r.TTL(now.Add(90 * time.Second)); err != errExpired || d != 0 {
+		t.Errorf("expired got (%s, %v), want error %v", d, err, errExpired)
+	}
+}
+
 func TestNumericTimeMapping(t *testing.T) {
 	if got := NewNumericTime(time.Time{}); got != nil {
 		t.Errorf("NewNumericTime from zero value got %f, want nil", *got)
@@ -412,3 +436,23 @@ func mustNewUnsupportedCurveKey() *ecdsa.PrivateKey {
 	}
 	return key
 }
+
+func TestAcceptAge(t *testing.T) {
+	now := time.Unix(1537622794, 0)
+	golden := []struct {
+		issued *NumericTime
+		err    error
+	}{
+		{nil, errNoIssued},
+		{NewNumericTime(now), nil},
+		{NewNumericTime(now.Add(-time.Hour)), nil},
+		{NewNumericTime(now.Add(-time.Hour - 2*time.Second)), errTooOld},
+		{NewNumericTime(now.Add(2 * time.Second)), errFromFuture},
+	}
+	for _, gold := range golden {
+		r := Registered{Issued: gold.issued}
+		if err := r.AcceptAge(now, time.Hour, time.Second); err != gold.err {
+			t.Errorf("issued %v got error %v, want %v", gold.issued, err, gold.err)
+		}
+	}
+}
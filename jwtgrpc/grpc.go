@@ -0,0 +1,78 @@
+// Package jwtgrpc maps JWT verification onto gRPC conventions, without any
+// dependency on the gRPC module. Metadata is represented as a plain map, which
+// is compatible with “google.golang.org/grpc/metadata” MD.
+package jwtgrpc
+
+import (
+	"errors"
+	"time"
+
+	"github.com/pascaldekloe/jwt"
+)
+
+// Code is a gRPC status code, compatible with “google.golang.org/grpc/codes”
+// Code.
+type Code uint32
+
+// Status Codes
+const (
+	OK               Code = 0
+	PermissionDenied Code = 7
+	Internal         Code = 13
+	Unauthenticated  Code = 16
+)
+
+// ChallengeKey is the trailing metadata key for the Bearer challenge, as in
+// the WWW-Authenticate header of HTTP.
+const ChallengeKey = "www-authenticate"
+
+// AuthorizationKey is the (request) metadata key for the credentials, as in
+// the Authorization header of HTTP.
+const AuthorizationKey = "authorization"
+
+// Status maps an error from the jwt package to a gRPC status, with the same
+// semantics as jwt.Handler has for HTTP. A jwt.ScopeError or a jwt.RoleError
+// results in PermissionDenied. ErrBindingPrefix results in Internal, without
+// trailer. Any other error results in Unauthenticated. The trailer has the
+// Bearer challenge. A nil error gets the OK code with no message and no
+// trailer.
+func Status(err error) (code Code, msg string, trailer map[string][]string) {
+	if err == nil {
+		return OK, "", nil
+	}
+	if errors.Is(err, ErrBindingPrefix) {
+		return Internal, err.Error(), nil
+	}
+	code = Unauthenticated
+	var scope jwt.ScopeError
+	var role jwt.RoleError
+	if errors.As(err, &scope) || errors.As(err, &role) {
+		code = PermissionDenied
+	}
+	trailer = map[string][]string{ChallengeKey: {jwt.BearerChallenge(err)}}
+	return code, err.Error(), trailer
+}
+
+// BearerToken extracts the token from gRPC metadata. The absence of any
+// authorization results in jwt.ErrNoHeader.
+func BearerToken(md map[string][]string) (token string, err error) {
+	return jwt.BearerToken(map[string][]string{"Authorization": md[AuthorizationKey]})
+}
+
+// Check applies jwt.KeyRegister.Check on the Bearer token from gRPC metadata,
+// including the time constraints with leeway. Use Status to map any error.
+func Check(md map[string][]string, keys *jwt.KeyRegister, leeway time.Duration) (*jwt.Claims, error) {
+	token, err := BearerToken(md)
+	if err != nil {
+		return nil, err
+	}
+	claims, err := keys.Check([]byte(token))
+	if err != nil {
+		return nil, err
+	}
+	err = claims.AcceptTemporal(time.Now(), leeway)
+	if err != nil {
+		return nil, err
+	}
+	return claims, nil
+}
@@ -0,0 +1,122 @@
+package jwtgrpc
+
+import (
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/pascaldekloe/jwt"
+)
+
+func TestStatus(t *testing.T) {
+	golden := []struct {
+		err       error
+		code      Code
+		challenge string
+	}{
+		{jwt.ErrNoHeader, Unauthenticated, "Bearer"},
+		{jwt.ScopeError("admin"), PermissionDenied, `Bearer error="insufficient_scope", scope="admin"`},
+		{jwt.ErrSigMiss, Unauthenticated, `Bearer error="invalid_token", error_description="jwt: signature mismatch"`},
+	}
+	for _, gold := range golden {
+		code, msg, trailer := Status(gold.err)
+		if code != gold.code {
+			t.Errorf("%v: got code %d, want %d", gold.err, code, gold.code)
+		}
+		if msg != gold.err.Error() {
+			t.Errorf("%v: got message %q", gold.err, msg)
+		}
+		if got := trailer[ChallengeKey]; len(got) != 1 || got[0] != gold.challenge {
+			t.Errorf("%v: got challenge %q, want %q", gold.err, got, gold.challenge)
+		}
+	}
+
+	if code, msg, trailer := Status(nil); code != OK || msg != "" || trailer != nil {
+		t.Errorf("nil error got code %d, message %q, trailer %q", code, msg, trailer)
+	}
+}
+
+func TestCheck(t *testing.T) {
+	secret := []byte("guest")
+	keys := jwt.KeyRegister{Secrets: [][]byte{secret}}
+
+	var c jwt.Claims
+	c.Subject = "test"
+	c.Expires = jwt.NewNumericTime(time.Now().Add(time.Minute))
+	token, err := c.HMACSign(jwt.HS256, secret)
+	if err != nil {
+		t.Fatal("sign error:", err)
+	}
+
+	md := map[string][]string{AuthorizationKey: {"Bearer " + string(token)}}
+	got, err := Check(md, &keys, 0)
+	if err != nil {
+		t.Fatal("check error:", err)
+	}
+	if got.Subject != "test" {
+		t.Errorf("got subject %q, want test", got.Subject)
+	}
+
+	_, err = Check(map[string][]string{}, &keys, 0)
+	if !errors.Is(err, jwt.ErrNoHeader) {
+		t.Errorf("got error %v, want jwt.ErrNoHeader", err)
+	}
+}
+
+func TestMetadataBinding(t *testing.T) {
+	var c jwt.Claims
+	c.Subject = "alice"
+	c.Audiences = []string{"a", "b"}
+	c.Set = map[string]interface{}{"name": "Zoë", "nums": []interface{}{1.0}}
+
+	b := MetadataBinding{
+		Keys:   map[string]string{"sub": "X-Verified-Sub", "aud": "x-verified-aud", "name": "x-verified-name"},
+		Join:   ", ",
+		Escape: true,
+		Prefix: "X-Verified-",
+	}
+	md := map[string][]string{
+		"x-verified-sub":   {"mallory"},
+		"X-Verified-Admin": {"true"},
+		"trace-id":         {"abc"},
+	}
+	got, err := b.Bind(md, &c)
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := map[string][]string{
+		"x-verified-sub":  {"alice"},
+		"x-verified-aud":  {"a, b"},
+		"x-verified-name": {"Zo%C3%AB"},
+		"trace-id":        {"abc"},
+	}
+	if len(got) != len(want) {
+		t.Errorf("got %q, want %q", got, want)
+	}
+	for k, v := range want {
+		if len(got[k]) != 1 || got[k][0] != v[0] {
+			t.Errorf("got %q for %q, want %q", got[k], k, v)
+		}
+	}
+	if len(md) != 3 || md["x-verified-sub"][0] != "mallory" {
+		t.Errorf("input metadata modified: %q", md)
+	}
+
+	b.Keys = map[string]string{"nums": "x-verified-nums"}
+	_, err = b.Bind(nil, &c)
+	if err == nil || err.Error() != "jwtgrpc: want string for claim nums" {
+		t.Errorf("got error %v for numbers", err)
+	}
+	if code, _, _ := Status(err); code != Unauthenticated {
+		t.Errorf("got code %d for numbers, want %d", code, Unauthenticated)
+	}
+
+	b.Keys = map[string]string{"sub": "x-user"}
+	_, err = b.Bind(nil, &c)
+	if err != ErrBindingPrefix {
+		t.Errorf("got error %v, want %v", err, ErrBindingPrefix)
+	}
+	if code, _, trailer := Status(err); code != Internal || trailer != nil {
+		t.Errorf("got code %d and trailer %q for prefix mismatch, want %d without trailer", code, trailer, Internal)
+	}
+}
@@ -0,0 +1,115 @@
+package jwtgrpc
+
+import (
+	"errors"
+	"fmt"
+	"net/url"
+	"strings"
+
+	"github.com/pascaldekloe/jwt"
+)
+
+// ErrBindingPrefix signals a MetadataBinding key without the Prefix, which is
+// a configuration error. Status maps the error to Internal.
+var ErrBindingPrefix = errors.New("jwtgrpc: prefix mismatch in metadata binding")
+
+// ErrBindingClaim signals a MetadataBinding claim which is absent or not a
+// string. The claim name is appended to the message.
+var errBindingClaim = errors.New("jwtgrpc: want string for claim")
+
+// MetadataBinding propagates claims as gRPC metadata, like the HeaderBinding
+// of jwt.Handler does with HTTP headers. Pass the result of Bind as outgoing
+// metadata for downstream calls, or as header or trailer metadata of a
+// response.
+type MetadataBinding struct {
+	// Keys maps JWT claim names to metadata keys. Keys are lower case
+	// in gRPC. Bind rejects claims which are absent or not a string.
+	Keys map[string]string
+
+	// Join enables the binding of JSON arrays with strings only,
+	// including the "aud" claim, when not empty. The elements are
+	// joined with Join as a separator, e.g., ", ".
+	Join string
+
+	// Escape applies percent-encoding on the values, including any
+	// separator characters within the elements of an array. Escaping
+	// keeps the metadata content in printable ASCII, as required for
+	// keys without the "-bin" suffix.
+	Escape bool
+
+	// Prefix is an optional constraint for the metadata keys. Any
+	// client metadata that match the prefix are removed, such that
+	// clients can not spoof bound claims.
+	Prefix string
+}
+
+// Bind returns a copy of md without any of the keys with Prefix, and with the
+// claim values set on their respective key. Md may be nil. Note that incoming
+// metadata includes the authorization, which should not be forwarded as is.
+func (b *MetadataBinding) Bind(md map[string][]string, claims *jwt.Claims) (map[string][]string, error) {
+	prefix := strings.ToLower(b.Prefix)
+
+	bound := make(map[string][]string, len(md)+len(b.Keys))
+	for key, values := range md {
+		key = strings.ToLower(key)
+		if prefix == "" || !strings.HasPrefix(key, prefix) {
+			bound[key] = append(bound[key], values...)
+		}
+	}
+
+	for claimName, key := range b.Keys {
+		key = strings.ToLower(key)
+		if !strings.HasPrefix(key, prefix) {
+			return nil, ErrBindingPrefix
+		}
+		s, ok := b.value(claims, claimName)
+		if !ok {
+			return nil, fmt.Errorf("%w %s", errBindingClaim, claimName)
+		}
+		bound[key] = []string{s}
+	}
+	return bound, nil
+}
+
+// Value returns the metadata representation of a claim.
+func (b *MetadataBinding) value(claims *jwt.Claims, name string) (string, bool) {
+	s, ok := claims.String(name)
+	if ok {
+		if b.Escape {
+			s = url.PathEscape(s)
+		}
+		return s, true
+	}
+	if b.Join == "" {
+		return "", false
+	}
+
+	var elements []string
+	if name == "aud" && len(claims.Audiences) != 0 {
+		elements = claims.Audiences
+	} else {
+		array, ok := claims.Set[name].([]interface{})
+		if !ok {
+			return "", false
+		}
+		for _, o := range array {
+			s, ok := o.(string)
+			if !ok {
+				return "", false
+			}
+			elements = append(elements, s)
+		}
+	}
+
+	var buf strings.Builder
+	for i, s := range elements {
+		if i != 0 {
+			buf.WriteString(b.Join)
+		}
+		if b.Escape {
+			s = url.PathEscape(s)
+		}
+		buf.WriteString(s)
+	}
+	return buf.String(), true
+}
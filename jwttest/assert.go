@@ -0,0 +1,88 @@
+package jwttest
+
+import (
+	"bytes"
+	"crypto/ed25519"
+	"encoding/json"
+	"sort"
+	"testing"
+
+	"github.com/pascaldekloe/jwt"
+)
+
+// Test credentials are fixed for reproducible tokens. Never use them outside of
+// tests.
+var (
+	EdDSAKey   = ed25519.NewKeyFromSeed([]byte("jwttest fixed seed, do not use!!"))
+	HMACSecret = []byte("jwttest fixed secret, do not use")
+)
+
+// Keys verifies the tokens of AssertClaims. It has EdDSAKey and HMACSecret by
+// default. Tests may add keys of their own.
+var Keys = &jwt.KeyRegister{
+	EdDSAs:  []ed25519.PublicKey{EdDSAKey.Public().(ed25519.PublicKey)},
+	Secrets: [][]byte{HMACSecret},
+}
+
+// AssertClaims verifies the signature of token with Keys, and it reports each
+// claim which differs from want as an error on t. The names in ignore are not
+// compared, e.g., "iat" and "jti" for values which differ per issue. The key ID
+// is compared only when set in want.
+//
+//	jwttest.AssertClaims(t, token, want, "iat", "exp", "jti")
+func AssertClaims(t testing.TB, token []byte, want jwt.Claims, ignore ...string) {
+	t.Helper()
+	got, err := Keys.Check(token)
+	if err != nil {
+		t.Errorf("token %q: %s", token, err)
+		return
+	}
+	if want.KeyID != "" && got.KeyID != want.KeyID {
+		t.Errorf("token %q: got key ID %q, want %q", token, got.KeyID, want.KeyID)
+	}
+
+	gotJSON, err := claimsJSON(got)
+	if err != nil {
+		t.Fatal(err)
+	}
+	wantJSON, err := claimsJSON(&want)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	skip := make(map[string]bool, len(ignore))
+	for _, name := range ignore {
+		skip[name] = true
+	}
+	names := make([]string, 0, len(gotJSON)+len(wantJSON))
+	for name := range gotJSON {
+		names = append(names, name)
+	}
+	for name := range wantJSON {
+		if _, ok := gotJSON[name]; !ok {
+			names = append(names, name)
+		}
+	}
+	sort.Strings(names)
+	for _, name := range names {
+		if skip[name] {
+			continue
+		}
+		if a, b := gotJSON[name], wantJSON[name]; !bytes.Equal(a, b) {
+			t.Errorf("token %q: claim %s", token, jwt.Delta{Name: name, A: a, B: b})
+		}
+	}
+}
+
+// ClaimsJSON returns the JSON per claim, in a normalized form.
+func claimsJSON(c *jwt.Claims) (map[string]json.RawMessage, error) {
+	m := make(map[string]json.RawMessage)
+	for name, v := range c.All() {
+		bytes, err := json.Marshal(v)
+		if err != nil {
+			return nil, err
+		}
+		m[name] = bytes
+	}
+	return m, nil
+}
@@ -0,0 +1,66 @@
+package jwttest
+
+import (
+	"fmt"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/pascaldekloe/jwt"
+)
+
+// RecordingT captures errors.
+type recordingT struct {
+	testing.TB
+	errs []string
+}
+
+func (t *recordingT) Helper() {}
+
+func (t *recordingT) Errorf(format string, args ...interface{}) {
+	t.errs = append(t.errs, fmt.Sprintf(format, args...))
+}
+
+func TestAssertClaims(t *testing.T) {
+	var c jwt.Claims
+	c.Subject = "alice"
+	c.Audiences = []string{"api"}
+	c.Set = map[string]interface{}{"scope": "read", "n": 1}
+	if err := c.Stamp(time.Minute); err != nil {
+		t.Fatal(err)
+	}
+	token, err := c.EdDSASign(EdDSAKey)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	var want jwt.Claims
+	want.Subject = "alice"
+	want.Audiences = []string{"api"}
+	want.Set = map[string]interface{}{"scope": "read", "n": 1.0}
+	AssertClaims(t, token, want, "iat", "exp", "jti")
+
+	rec := &recordingT{TB: t}
+	want.Subject = "bob"
+	want.Set["extra"] = true
+	AssertClaims(rec, token, want, "iat", "exp", "jti")
+	got := strings.Join(rec.errs, "\n")
+	for _, s := range []string{`"extra": <absent> → true`, `"sub": "alice" → "bob"`} {
+		if !strings.Contains(got, s) {
+			t.Errorf("errors %q miss %q", got, s)
+		}
+	}
+	if len(rec.errs) != 2 {
+		t.Errorf("got %d errors, want 2: %q", len(rec.errs), rec.errs)
+	}
+
+	rec = &recordingT{TB: t}
+	token, err = c.EdDSASign(testKey)
+	if err != nil {
+		t.Fatal(err)
+	}
+	AssertClaims(rec, token, want)
+	if len(rec.errs) != 1 || !strings.Contains(rec.errs[0], "signature") {
+		t.Errorf("unknown key got errors %q, want one signature error", rec.errs)
+	}
+}
@@ -0,0 +1,73 @@
+package jwttest
+
+import (
+	"bytes"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/pascaldekloe/jwt"
+)
+
+// AssertBodyPreserved serves req with h, and it reports an error on t when the
+// body of req is read before h passes the request to its Target, or when the
+// body does not reach Target unmodified. Rejected requests must have their body
+// unread. The recording of the response is returned for further inspection. H
+// itself remains unmodified.
+func AssertBodyPreserved(t testing.TB, h *jwt.Handler, req *http.Request) *httptest.ResponseRecorder {
+	t.Helper()
+	var want []byte
+	if req.Body != nil {
+		var err error
+		want, err = io.ReadAll(req.Body)
+		if err != nil {
+			t.Fatal("request body unavailable:", err)
+		}
+		req.Body.Close()
+	}
+	body := &guardedBody{Reader: bytes.NewReader(want)}
+	req.Body = body
+
+	guarded := *h
+	target := h.Target
+	guarded.Target = http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if body.readCount != 0 {
+			t.Errorf("%d reads on request body before Target", body.readCount)
+		}
+		body.passed = true
+		got, err := io.ReadAll(r.Body)
+		if err != nil {
+			t.Error("request body at Target:", err)
+		} else if !bytes.Equal(got, want) {
+			t.Errorf("request body at Target got %q, want %q", got, want)
+		}
+		r.Body = io.NopCloser(bytes.NewReader(got))
+		if target != nil {
+			target.ServeHTTP(w, r)
+		}
+	})
+
+	w := httptest.NewRecorder()
+	guarded.ServeHTTP(w, req)
+	if !body.passed && body.readCount != 0 {
+		t.Errorf("%d reads on request body of rejected request", body.readCount)
+	}
+	return w
+}
+
+// GuardedBody counts reads.
+type guardedBody struct {
+	*bytes.Reader
+	readCount int
+	passed    bool // reached Target
+}
+
+func (b *guardedBody) Read(p []byte) (n int, err error) {
+	if !b.passed {
+		b.readCount++
+	}
+	return b.Reader.Read(p)
+}
+
+func (b *guardedBody) Close() error { return nil }
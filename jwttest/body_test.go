@@ -0,0 +1,73 @@
+package jwttest
+
+import (
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/pascaldekloe/jwt"
+)
+
+func TestAssertBodyPreserved(t *testing.T) {
+	var targetBody string
+	h := &jwt.Handler{
+		Keys: Keys,
+		Target: http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			b, _ := io.ReadAll(r.Body)
+			targetBody = string(b)
+		}),
+	}
+
+	req := httptest.NewRequest("POST", "/", strings.NewReader("payload"))
+	if err := new(jwt.Claims).EdDSASignHeader(req, EdDSAKey); err != nil {
+		t.Fatal(err)
+	}
+	if w := AssertBodyPreserved(t, h, req); w.Code != http.StatusOK {
+		t.Errorf("got HTTP %d, want 200", w.Code)
+	}
+	if targetBody != "payload" {
+		t.Errorf("target got body %q, want payload", targetBody)
+	}
+
+	// rejected
+	req = httptest.NewRequest("POST", "/", strings.NewReader("payload"))
+	if w := AssertBodyPreserved(t, h, req); w.Code != http.StatusUnauthorized {
+		t.Errorf("got HTTP %d, want 401", w.Code)
+	}
+}
+
+func TestAssertBodyPreservedViolation(t *testing.T) {
+	h := &jwt.Handler{
+		Keys:   Keys,
+		Target: http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {}),
+	}
+	consume := &jwt.Handler{
+		Keys: Keys,
+		Func: func(w http.ResponseWriter, r *http.Request, c *jwt.Claims) bool {
+			io.ReadAll(r.Body)
+			return false
+		},
+	}
+
+	req := httptest.NewRequest("POST", "/", strings.NewReader("payload"))
+	if err := new(jwt.Claims).EdDSASignHeader(req, EdDSAKey); err != nil {
+		t.Fatal(err)
+	}
+	rec := new(recordingT)
+	AssertBodyPreserved(rec, h, req)
+	if len(rec.errs) != 0 {
+		t.Errorf("got errors %q for compliant handler", rec.errs)
+	}
+
+	req = httptest.NewRequest("POST", "/", strings.NewReader("payload"))
+	if err := new(jwt.Claims).EdDSASignHeader(req, EdDSAKey); err != nil {
+		t.Fatal(err)
+	}
+	rec = new(recordingT)
+	AssertBodyPreserved(rec, consume, req)
+	if len(rec.errs) != 1 {
+		t.Errorf("got errors %q, want one for the body read", rec.errs)
+	}
+}
@@ -0,0 +1,121 @@
+// Package jwttest provides utilities for testing code which uses JWTs.
+package jwttest
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"math"
+	"testing"
+
+	"github.com/pascaldekloe/jwt"
+)
+
+// InteropAlgs has the algorithms implemented by both “github.com/golang-jwt/jwt”
+// (v5) and “github.com/go-jose/go-jose” (v3).
+var InteropAlgs = map[string]bool{
+	jwt.EdDSA: true,
+	jwt.ES256: true,
+	jwt.ES384: true,
+	jwt.ES512: true,
+	jwt.HS256: true,
+	jwt.HS384: true,
+	jwt.HS512: true,
+	jwt.PS256: true,
+	jwt.PS384: true,
+	jwt.PS512: true,
+	jwt.RS256: true,
+	jwt.RS384: true,
+	jwt.RS512: true,
+}
+
+// InteropIssues returns any constructs from token which are known to break, or
+// to behave differently, with other popular Go implementations. The semantics
+// of golang-jwt and go-jose are encoded here, rather than linked, to keep the
+// module free of dependencies. The signature is not verified.
+func InteropIssues(token []byte) []string {
+	claims, err := jwt.ParseWithoutCheck(token)
+	if err != nil {
+		return []string{err.Error()}
+	}
+	if n := bytes.Count(token, []byte{'.'}); n != 2 {
+		return []string{fmt.Sprintf("compact serialization with %d dots, want 2", n)}
+	}
+
+	var issues []string
+	report := func(format string, args ...interface{}) {
+		issues = append(issues, fmt.Sprintf(format, args...))
+	}
+
+	var header map[string]interface{}
+	if err := json.Unmarshal(claims.RawHeader, &header); err != nil {
+		return []string{err.Error()}
+	}
+	alg, ok := header["alg"].(string)
+	if !ok {
+		report(`JOSE header "alg" is not a string`)
+	} else if !InteropAlgs[alg] {
+		report("algorithm %q not supported by both golang-jwt and go-jose", alg)
+	}
+	if _, ok := header["crit"]; ok {
+		report(`JOSE header "crit" rejected by go-jose and ignored by golang-jwt`)
+	}
+
+	var payload map[string]interface{}
+	if err := json.Unmarshal(claims.Raw, &payload); err != nil {
+		return append(issues, err.Error())
+	}
+	for _, name := range []string{"iss", "sub", "jti"} {
+		if v, ok := payload[name]; ok {
+			if _, ok := v.(string); !ok {
+				report("claim %q is not a string, which fails the registered claims mapping", name)
+			}
+		}
+	}
+	switch aud := payload["aud"].(type) {
+	case nil, string:
+		break
+	case []interface{}:
+		for _, e := range aud {
+			if _, ok := e.(string); !ok {
+				report(`claim "aud" has a non-string element`)
+				break
+			}
+		}
+	default:
+		report(`claim "aud" is neither a string nor an array`)
+	}
+	for _, name := range []string{"exp", "nbf", "iat"} {
+		v, ok := payload[name]
+		if !ok {
+			continue
+		}
+		f, ok := v.(float64)
+		switch {
+		case !ok:
+			report("claim %q is not a number", name)
+		case f != math.Trunc(f):
+			report("claim %q has a fraction, which is truncated or rejected by other implementations", name)
+		case f < 0 || f >= 1<<53:
+			report("claim %q is out of range for an integer NumericDate", name)
+		}
+	}
+
+	for _, f := range jwt.Lint(token) {
+		switch f.Rule {
+		case jwt.RuleDuplicateHeader, jwt.RuleDuplicateClaim:
+			// encoding/json picks the last; others may pick the first
+			report("%s, which is resolved differently across implementations", f.Text)
+		}
+	}
+
+	return issues
+}
+
+// CheckInterop reports each of the InteropIssues as an error on t.
+func CheckInterop(t testing.TB, token []byte) {
+	t.Helper()
+	for _, issue := range InteropIssues(token) {
+		t.Errorf("token %q: interoperability: %s", token, issue)
+	}
+}
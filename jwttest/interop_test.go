@@ -0,0 +1,60 @@
+package jwttest
+
+import (
+	"crypto/ed25519"
+	"encoding/base64"
+	"encoding/json"
+	"reflect"
+	"testing"
+	"time"
+
+	"github.com/pascaldekloe/jwt"
+)
+
+var testKey = ed25519.NewKeyFromSeed(make([]byte, ed25519.SeedSize))
+
+func TestCheckInterop(t *testing.T) {
+	var c jwt.Claims
+	c.Subject = "alice"
+	c.Audiences = []string{"a", "b"}
+	c.Issued = jwt.NewNumericTime(time.Unix(1537622794, 0))
+	c.KeyID = "1"
+	token, err := c.EdDSASign(testKey)
+	if err != nil {
+		t.Fatal(err)
+	}
+	CheckInterop(t, token)
+}
+
+func TestInteropIssues(t *testing.T) {
+	defer func(f func([]byte, []string, json.RawMessage) error) {
+		jwt.EvalCrit = f
+	}(jwt.EvalCrit)
+	jwt.EvalCrit = func([]byte, []string, json.RawMessage) error { return nil }
+
+	golden := []struct {
+		header, payload string
+		want            []string
+	}{
+		{`{"alg":"HM5"}`, `{}`, []string{`algorithm "HM5" not supported by both golang-jwt and go-jose`}},
+		{`{"alg":"ES256","crit":["x"]}`, `{}`, []string{`JOSE header "crit" rejected by go-jose and ignored by golang-jwt`}},
+		{`{"alg":"ES256"}`, `{"iss":1}`, []string{`claim "iss" is not a string, which fails the registered claims mapping`}},
+		{`{"alg":"ES256"}`, `{"aud":["a",null]}`, []string{`claim "aud" has a non-string element`}},
+		{`{"alg":"ES256"}`, `{"aud":{}}`, []string{`claim "aud" is neither a string nor an array`}},
+		{`{"alg":"ES256"}`, `{"exp":1.5}`, []string{`claim "exp" has a fraction, which is truncated or rejected by other implementations`}},
+		{`{"alg":"ES256"}`, `{"nbf":-1}`, []string{`claim "nbf" is out of range for an integer NumericDate`}},
+		{`{"alg":"ES256"}`, `{"iat":"now"}`, []string{`claim "iat" is not a number`}},
+		{`{"alg":"ES256"}`, `{"x":1,"x":2}`, []string{`payload has "x" more than once, which is resolved differently across implementations`}},
+	}
+	for _, gold := range golden {
+		token := encode(gold.header) + "." + encode(gold.payload) + "."
+		got := InteropIssues([]byte(token))
+		if !reflect.DeepEqual(got, gold.want) {
+			t.Errorf("%s.%s got issues %q, want %q", gold.header, gold.payload, got, gold.want)
+		}
+	}
+}
+
+func encode(s string) string {
+	return base64.RawURLEncoding.EncodeToString([]byte(s))
+}
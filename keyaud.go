@@ -0,0 +1,56 @@
+package jwt
+
+import (
+	"context"
+	"errors"
+)
+
+// ErrKeyAudience denies a token verified with a key which is restricted to
+// other audiences. See KeyRegister.KeyAudiences for details.
+var ErrKeyAudience = errors.New("jwt: key not bound to token audience")
+
+// BindAudiences restricts the keys with key ID kid to tokens with any of the
+// audiences in their "aud" claim. Tokens without "aud" claim are denied, as
+// opposed to Claims.AcceptAudience. Repeated calls for the same key ID extend the
+// audiences. The binding holds regardless of any Expect or Claims.AcceptAudience
+// downstream, such that a key shared among services can not verify a token for
+// one service at another. Keys without a key ID can not be bound.
+//
+// The register resolves the key ID from the key which verified the token. The
+// Algorithms and the SecretSource have no such identity in the register, so
+// their binding applies to the key ID of the token instead. Set StrictKID to
+// prevent fallback from a bogus key ID onto a restricted Algorithm.
+func (keys *KeyRegister) BindAudiences(kid string, audiences ...string) {
+	if keys.KeyAudiences == nil {
+		keys.KeyAudiences = make(map[string][]string)
+	}
+	keys.KeyAudiences[kid] = append(keys.KeyAudiences[kid], audiences...)
+}
+
+// Check verifies token with the register, including any KeyAudiences. The key
+// is the identity (as in KeyUsage) of the matching key, if any.
+func (keys *KeyRegister) check(ctx context.Context, token []byte) (*Claims, interface{}, error) {
+	c, key, err := keys.verify(ctx, token)
+	if err != nil || len(keys.KeyAudiences) == 0 {
+		return c, key, err
+	}
+
+	kid := c.KeyID
+	if key != nil {
+		stat, ok := keys.describe(key)
+		if !ok {
+			return nil, nil, ErrKeyAudience // unreachable
+		}
+		kid = stat.KeyID
+	}
+	bound, ok := keys.KeyAudiences[kid]
+	if !ok || kid == "" {
+		return c, key, nil
+	}
+	for _, aud := range bound {
+		if contains(c.Audiences, aud) {
+			return c, key, nil
+		}
+	}
+	return nil, nil, ErrKeyAudience
+}
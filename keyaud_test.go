@@ -0,0 +1,75 @@
+package jwt
+
+import (
+	"crypto/ed25519"
+	"testing"
+)
+
+func TestBindAudiences(t *testing.T) {
+	keys := KeyRegister{
+		EdDSAs:    []ed25519.PublicKey{testKeyEd25519Public},
+		EdDSAIDs:  []string{"ed"},
+		Secrets:   [][]byte{[]byte("shared")},
+		SecretIDs: []string{"hs"},
+	}
+	keys.BindAudiences("hs", "a")
+	keys.BindAudiences("hs", "c")
+
+	tests := []struct {
+		audiences []string
+		keyID     string // header
+		secret    bool   // HMAC or EdDSA
+		want      error
+	}{
+		{[]string{"a"}, "", true, nil},
+		{[]string{"b", "c"}, "hs", true, nil},
+		{[]string{"b"}, "", true, ErrKeyAudience},
+		{[]string{"b"}, "hs", true, ErrKeyAudience},
+		{nil, "", true, ErrKeyAudience},
+		{[]string{"b"}, "ed", false, nil},
+	}
+	for _, test := range tests {
+		var c Claims
+		c.Audiences = test.audiences
+		c.KeyID = test.keyID
+		var token []byte
+		var err error
+		if test.secret {
+			token, err = c.HMACSign(HS256, []byte("shared"))
+		} else {
+			token, err = c.EdDSASign(testKeyEd25519Private)
+		}
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		_, err = keys.Check(token)
+		if err != test.want {
+			t.Errorf("audiences %q with key ID %q: got error %v, want %v", test.audiences, test.keyID, err, test.want)
+		}
+		_, err = keys.Subset("hs", "ed").Check(token)
+		if err != test.want {
+			t.Errorf("audiences %q with key ID %q: subset got error %v, want %v", test.audiences, test.keyID, err, test.want)
+		}
+	}
+}
+
+func TestBindAudiencesSource(t *testing.T) {
+	keys := KeyRegister{
+		SecretSource: func(kid string) ([]byte, error) {
+			return []byte("sourced secret"), nil
+		},
+	}
+	keys.BindAudiences("tenant", "a")
+
+	var c Claims
+	c.KeyID = "tenant"
+	c.Audiences = []string{"b"}
+	token, err := c.HMACSign(HS256, []byte("sourced secret"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := keys.Check(token); err != ErrKeyAudience {
+		t.Errorf("got error %v, want ErrKeyAudience", err)
+	}
+}
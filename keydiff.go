@@ -0,0 +1,127 @@
+package jwt
+
+import (
+	"crypto/ecdsa"
+	"crypto/ed25519"
+	"crypto/hmac"
+	"crypto/rsa"
+	"sort"
+)
+
+// KeySetDiff is the change from one key set to another. The KeyStat entries
+// identify keys without any statistics.
+type KeySetDiff struct {
+	Added   []KeyStat // keys in the new set only, by new Index
+	Removed []KeyStat // keys in the old set only, by old Index
+	Rotated []string  // key IDs with other key material, in order
+}
+
+// Changed returns whether the key sets differ.
+func (d *KeySetDiff) Changed() bool {
+	return len(d.Added) != 0 || len(d.Removed) != 0 || len(d.Rotated) != 0
+}
+
+// DiffKeys compares key set from with key set to. Keys are equal when both
+// their key material and their key ID match. A key ID present in both sets,
+// without any equal key, counts as rotated. Such keys are not listed in Added
+// nor in Removed. The Algorithms are not compared, as they need not be
+// comparable.
+func DiffKeys(from, to *KeyRegister) KeySetDiff {
+	fromEntries, toEntries := keyEntries(from), keyEntries(to)
+	gone := unmatchedEntries(fromEntries, toEntries)
+	fresh := unmatchedEntries(toEntries, fromEntries)
+
+	// key IDs on both sides
+	goneKIDs := make(map[string]bool)
+	for _, e := range gone {
+		if e.stat.KeyID != "" {
+			goneKIDs[e.stat.KeyID] = true
+		}
+	}
+	rotated := make(map[string]bool)
+	for _, e := range fresh {
+		if goneKIDs[e.stat.KeyID] {
+			rotated[e.stat.KeyID] = true
+		}
+	}
+
+	var diff KeySetDiff
+	for _, e := range fresh {
+		if !rotated[e.stat.KeyID] {
+			diff.Added = append(diff.Added, e.stat)
+		}
+	}
+	for _, e := range gone {
+		if !rotated[e.stat.KeyID] {
+			diff.Removed = append(diff.Removed, e.stat)
+		}
+	}
+	for kid := range rotated {
+		diff.Rotated = append(diff.Rotated, kid)
+	}
+	sort.Strings(diff.Rotated)
+	return diff
+}
+
+// KeyEntry is a key with its KeyStat identification.
+type keyEntry struct {
+	stat KeyStat
+	key  interface{}
+}
+
+// KeyEntries lists the comparable keys of a register.
+func keyEntries(keys *KeyRegister) []keyEntry {
+	if keys == nil {
+		return nil
+	}
+	var entries []keyEntry
+	for i, key := range keys.ECDSAs {
+		entries = append(entries, keyEntry{KeyStat{Type: "ECDSA", Index: i, KeyID: kidAt(keys.ECDSAIDs, i)}, key})
+	}
+	for i, key := range keys.EdDSAs {
+		entries = append(entries, keyEntry{KeyStat{Type: "EdDSA", Index: i, KeyID: kidAt(keys.EdDSAIDs, i)}, key})
+	}
+	for i, key := range keys.RSAs {
+		entries = append(entries, keyEntry{KeyStat{Type: "RSA", Index: i, KeyID: kidAt(keys.RSAIDs, i)}, key})
+	}
+	for i, h := range keys.HMACs {
+		entries = append(entries, keyEntry{KeyStat{Type: "HMAC", Index: i, KeyID: kidAt(keys.HMACIDs, i)}, h})
+	}
+	for i, secret := range keys.Secrets {
+		entries = append(entries, keyEntry{KeyStat{Type: "secret", Index: i, KeyID: kidAt(keys.SecretIDs, i)}, secret})
+	}
+	return entries
+}
+
+// UnmatchedEntries returns the entries of a without an equal in b.
+func unmatchedEntries(a, b []keyEntry) []keyEntry {
+	var unmatched []keyEntry
+NextEntry:
+	for _, e := range a {
+		for _, o := range b {
+			if e.stat.Type == o.stat.Type && e.stat.KeyID == o.stat.KeyID && equalKeys(e.key, o.key) {
+				continue NextEntry
+			}
+		}
+		unmatched = append(unmatched, e)
+	}
+	return unmatched
+}
+
+// EqualKeys compares key material of the same type.
+func equalKeys(a, b interface{}) bool {
+	switch t := a.(type) {
+	case *ecdsa.PublicKey:
+		return t.Equal(b)
+	case ed25519.PublicKey:
+		return t.Equal(b)
+	case *rsa.PublicKey:
+		return t.Equal(b)
+	case *HMAC:
+		return t == b
+	case []byte:
+		o, ok := b.([]byte)
+		return ok && hmac.Equal(t, o)
+	}
+	return false
+}
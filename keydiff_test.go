@@ -0,0 +1,50 @@
+package jwt
+
+import (
+	"crypto/ecdsa"
+	"crypto/ed25519"
+	"crypto/rsa"
+	"reflect"
+	"testing"
+)
+
+func TestDiffKeys(t *testing.T) {
+	from := &KeyRegister{
+		ECDSAs:    []*ecdsa.PublicKey{&testKeyEC256.PublicKey, &testKeyEC384.PublicKey},
+		ECDSAIDs:  []string{"stay", "rotate"},
+		RSAs:      []*rsa.PublicKey{&testKeyRSA2048.PublicKey},
+		RSAIDs:    []string{"gone"},
+		Secrets:   [][]byte{[]byte("same")},
+		SecretIDs: []string{""},
+	}
+	to := &KeyRegister{
+		ECDSAs:    []*ecdsa.PublicKey{&testKeyEC256.PublicKey},
+		ECDSAIDs:  []string{"stay"},
+		EdDSAs:    []ed25519.PublicKey{testKeyEd25519Public},
+		EdDSAIDs:  []string{"new"},
+		RSAs:      []*rsa.PublicKey{&testKeyRSA4096.PublicKey},
+		RSAIDs:    []string{"rotate"},
+		Secrets:   [][]byte{[]byte("same")},
+		SecretIDs: []string{""},
+	}
+
+	got := DiffKeys(from, to)
+	want := KeySetDiff{
+		Added:   []KeyStat{{Type: "EdDSA", Index: 0, KeyID: "new"}},
+		Removed: []KeyStat{{Type: "RSA", Index: 0, KeyID: "gone"}},
+		Rotated: []string{"rotate"},
+	}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("got diff %+v, want %+v", got, want)
+	}
+	if !got.Changed() {
+		t.Error("diff not changed")
+	}
+
+	if diff := DiffKeys(to, to); diff.Changed() {
+		t.Errorf("same key set got diff %+v", diff)
+	}
+	if diff := DiffKeys(nil, to); len(diff.Added) != 4 || len(diff.Removed) != 0 || len(diff.Rotated) != 0 {
+		t.Errorf("from nil got diff %+v, want 4 keys added", diff)
+	}
+}
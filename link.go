@@ -0,0 +1,175 @@
+package jwt
+
+import (
+	"errors"
+	"fmt"
+	"net/url"
+	"sync"
+	"time"
+)
+
+var (
+	errLinkNoToken   = errors.New("jwt: no token in link")
+	errLinkPlacement = errors.New("jwt: token in wrong part of link")
+	errLinkNoExpiry  = errors.New(`jwt: link token without expiration time ["exp"]`)
+	errLinkLifetime  = errors.New(`jwt: link token lifetime exceeds profile`)
+	errNoID          = errors.New(`jwt: JWT ID ["jti"] absent`)
+	errReplay        = errors.New(`jwt: JWT ID ["jti"] used before`)
+)
+
+// Placement is the location of a token in a URL.
+type Placement int
+
+const (
+	// InFragment keeps tokens out of server logs, proxies and Referer
+	// headers, as user agents do not transmit the fragment. Client-side
+	// code must pass the token on to the server.
+	InFragment Placement = iota
+
+	// InQuery puts tokens in the query string, for plain links handled
+	// server-side. Such URLs end up in access logs, and they may leak
+	// through the Referer header. Serve the landing page with a
+	// restrictive Referrer-Policy, and keep the lifetime short.
+	InQuery
+)
+
+// LinkProfile defines the embedding of tokens in URLs, as used for e-mail
+// verification and magic links. Each token gets an expiry and a unique JWT ID,
+// such that it can be accepted once only. The zero value uses the fragment
+// with parameter name "token", a 15 minute lifetime and a 2000 byte limit.
+type LinkProfile struct {
+	// Placement of the token. The token is rejected elsewhere.
+	Placement Placement
+
+	// Param is the parameter name, with "token" for the empty string.
+	Param string
+
+	// Lifetime is the validity of tokens, with 15 minutes for zero.
+	Lifetime time.Duration
+
+	// MaxURLLen limits the size of URLs, with 2000 for zero. Some mail
+	// clients and browsers truncate links beyond such length.
+	MaxURLLen int
+}
+
+func (p *LinkProfile) param() string {
+	if p.Param == "" {
+		return "token"
+	}
+	return p.Param
+}
+
+func (p *LinkProfile) lifetime() time.Duration {
+	if p.Lifetime == 0 {
+		return 15 * time.Minute
+	}
+	return p.Lifetime
+}
+
+func (p *LinkProfile) maxURLLen() int {
+	if p.MaxURLLen == 0 {
+		return 2000
+	}
+	return p.MaxURLLen
+}
+
+// Token returns the token from u conform the Placement. Tokens found in the
+// other part of u are rejected, as they may have leaked already.
+func (p *LinkProfile) Token(u *url.URL) ([]byte, error) {
+	query := u.Query()
+	fragment, err := url.ParseQuery(u.Fragment)
+	if err != nil && p.Placement == InFragment {
+		return nil, fmt.Errorf("jwt: link fragment: %w", err)
+	}
+
+	want, other := fragment, query
+	if p.Placement == InQuery {
+		want, other = query, fragment
+	}
+	if other.Get(p.param()) != "" {
+		return nil, errLinkPlacement
+	}
+	token := want.Get(p.param())
+	if token == "" {
+		return nil, errLinkNoToken
+	}
+	return []byte(token), nil
+}
+
+// Accept verifies the claims of a link token with Clock. The expiry must not
+// exceed Lifetime, and the JWT ID must be new to store, i.e., links are single
+// use. Use AcceptAudience for any audience requirements.
+func (p *LinkProfile) Accept(c *Claims, store IDStore) error {
+	if c.Expires == nil {
+		return errLinkNoExpiry
+	}
+	if err := c.AcceptAge(Clock(), p.lifetime(), 0); err != nil {
+		return err
+	}
+	if c.Expires.Time().Sub(c.Issued.Time()) > p.lifetime() {
+		return errLinkLifetime
+	}
+	return c.AcceptOnce(store)
+}
+
+// IDStore tracks the JWT IDs in use. Each ID is accepted once until expiry.
+type IDStore interface {
+	// Use registers id until expires. The return is false when id is
+	// registered already.
+	Use(id string, expires time.Time) (ok bool, err error)
+}
+
+// MemoryIDStore is an in-memory IDStore. The zero value is ready for use.
+// Expired entries are removed periodically.
+//
+// Multiple goroutines may invoke methods on a MemoryIDStore simultaneously.
+type MemoryIDStore struct {
+	mutex     sync.Mutex
+	expires   map[string]time.Time
+	nextSweep time.Time
+}
+
+// Use honors the IDStore interface.
+func (store *MemoryIDStore) Use(id string, expires time.Time) (ok bool, err error) {
+	now := Clock()
+
+	store.mutex.Lock()
+	defer store.mutex.Unlock()
+	if store.expires == nil {
+		store.expires = make(map[string]time.Time)
+	}
+	if now.After(store.nextSweep) {
+		for k, t := range store.expires {
+			if now.After(t) {
+				delete(store.expires, k)
+			}
+		}
+		store.nextSweep = now.Add(time.Minute)
+	}
+
+	if _, ok := store.expires[id]; ok {
+		return false, nil
+	}
+	store.expires[id] = expires
+	return true, nil
+}
+
+// AcceptOnce verifies that the JWT ID was not accepted before. The ID is held
+// by store until Expires. Tokens without an ID or without an expiration time
+// are rejected, as they can not be tracked.
+func (c *Claims) AcceptOnce(store IDStore) error {
+	if c.ID == "" {
+		return errNoID
+	}
+	if c.Expires == nil {
+		return errLinkNoExpiry
+	}
+	ok, err := store.Use(c.ID, c.Expires.Time())
+	if err != nil {
+		return err
+	}
+	if !ok {
+		return errReplay
+	}
+	return nil
+}
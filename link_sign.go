@@ -0,0 +1,40 @@
+//go:build !jwtverifyonly
+
+package jwt
+
+import (
+	"fmt"
+	"net/url"
+)
+
+// Link returns base with a token of c in it. The claims get stamped with the
+// Lifetime of the profile, and with a new JWT ID when absent. See Claims.Stamp.
+// URLs which exceed MaxURLLen are rejected.
+func (p *LinkProfile) Link(base *url.URL, c *Claims, sign SignFunc) (*url.URL, error) {
+	if err := c.Stamp(p.lifetime()); err != nil {
+		return nil, err
+	}
+	token, err := sign(c)
+	if err != nil {
+		return nil, err
+	}
+
+	u := *base
+	switch p.Placement {
+	case InQuery:
+		query := u.Query()
+		query.Set(p.param(), string(token))
+		u.RawQuery = query.Encode()
+	default:
+		param := url.Values{p.param(): {string(token)}}.Encode()
+		if u.Fragment != "" {
+			param = u.Fragment + "&" + param
+		}
+		u.Fragment, u.RawFragment = param, ""
+	}
+
+	if n := len(u.String()); n > p.maxURLLen() {
+		return nil, fmt.Errorf("jwt: link of %d bytes exceeds limit of %d", n, p.maxURLLen())
+	}
+	return &u, nil
+}
@@ -0,0 +1,89 @@
+package jwt
+
+import (
+	"encoding/json"
+	"net/url"
+	"testing"
+	"time"
+)
+
+func TestLinkProfile(t *testing.T) {
+	defer func() { Clock = time.Now }()
+	now := time.Date(2026, 10, 17, 12, 0, 0, 0, time.UTC)
+	Clock = func() time.Time { return now }
+
+	base, err := url.Parse("https://example.com/verify?lang=en#/welcome")
+	if err != nil {
+		t.Fatal(err)
+	}
+	golden := []LinkProfile{
+		{},
+		{Placement: InQuery, Param: "t", Lifetime: time.Hour},
+	}
+	for _, p := range golden {
+		c := new(Claims)
+		c.Subject = "alice@example.com"
+		u, err := p.Link(base, c, hmacSignFunc)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if c.ID == "" {
+			t.Error("no JWT ID set")
+		}
+		if got, want := c.Expires.Time().Sub(c.Issued.Time()), p.lifetime(); got != want {
+			t.Errorf("got lifetime %s, want %s", got, want)
+		}
+
+		// parse as received
+		u, err = url.Parse(u.String())
+		if err != nil {
+			t.Fatal(err)
+		}
+		if got := u.Query().Get("lang"); got != "en" {
+			t.Errorf("%s: base query lost", u)
+		}
+		token, err := p.Token(u)
+		if err != nil {
+			t.Fatalf("%s: %v", u, err)
+		}
+		got, err := HMACCheck(token, []byte("guest"))
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		store := new(MemoryIDStore)
+		if err := p.Accept(got, store); err != nil {
+			t.Errorf("%s: accept error: %v", u, err)
+		}
+		if err := p.Accept(got, store); err != errReplay {
+			t.Errorf("%s: second accept got error %v, want %v", u, err, errReplay)
+		}
+
+		other := p
+		other.Placement = 1 - p.Placement
+		if _, err := other.Token(u); err != errLinkPlacement {
+			t.Errorf("%s: other placement got error %v, want %v", u, err, errLinkPlacement)
+		}
+	}
+}
+
+func TestLinkProfileLimits(t *testing.T) {
+	base := &url.URL{Scheme: "https", Host: "example.com"}
+	p := LinkProfile{MaxURLLen: 100}
+	if _, err := p.Link(base, new(Claims), hmacSignFunc); err == nil {
+		t.Error("no error for link beyond MaxURLLen")
+	}
+
+	// lifetime beyond profile
+	c := new(Claims)
+	c.ID = "x"
+	c.Issued = NewNumericTime(time.Now().Add(-time.Minute))
+	c.Expires = NewNumericTime(time.Now().Add(time.Hour))
+	if err := new(LinkProfile).Accept(c, new(MemoryIDStore)); err != errLinkLifetime {
+		t.Errorf("got error %v, want %v", err, errLinkLifetime)
+	}
+}
+
+func hmacSignFunc(c *Claims, extraHeaders ...json.RawMessage) ([]byte, error) {
+	return c.HMACSign(HS256, []byte("guest"), extraHeaders...)
+}
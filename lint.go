@@ -0,0 +1,142 @@
+package jwt
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"time"
+)
+
+// Lint Rules
+const (
+	RuleMalformed       = "malformed"            // token structure broken
+	RuleBase64          = "non-canonical-base64" // ambiguous encoding
+	RuleDuplicateHeader = "duplicate-header"     // JOSE header repeats a name
+	RuleDuplicateClaim  = "duplicate-claim"      // payload repeats a name
+	RuleWeakAlg         = "weak-alg"             // unsecured
+	RuleUnknownAlg      = "unknown-alg"          // not registered
+	RuleNoExpiry        = "no-exp"               // lives forever
+	RuleLifetime        = "lifetime"             // exceeds LintMaxLifetime
+	RuleSize            = "size"                 // exceeds CookieLimit
+)
+
+// LintMaxLifetime is the longest validity accepted by Lint.
+var LintMaxLifetime = 7 * 24 * time.Hour
+
+// Finding is a Lint result.
+type Finding struct {
+	Rule string // one of the Rule constants
+	Text string // description
+}
+
+// String returns the rule with the description.
+func (f Finding) String() string {
+	return f.Rule + ": " + f.Text
+}
+
+// Lint inspects token for common mistakes. The signature is not verified. The
+// return is empty when no issues are found.
+func Lint(token []byte) []Finding {
+	var findings []Finding
+	report := func(rule, format string, args ...interface{}) {
+		findings = append(findings, Finding{rule, fmt.Sprintf(format, args...)})
+	}
+
+	if len(token) > CookieLimit {
+		report(RuleSize, "token of %d bytes exceeds the cookie limit of %d", len(token), CookieLimit)
+	}
+
+	parts := bytes.Split(token, []byte{'.'})
+	if len(parts) != 3 {
+		report(RuleMalformed, "got %d parts, want 3", len(parts))
+		return findings
+	}
+	for i, name := range []string{"JOSE header", "payload", "signature"} {
+		raw, err := encoding.DecodeString(string(parts[i]))
+		if err != nil {
+			report(RuleMalformed, "%s: %s", name, err)
+			return findings
+		}
+		if encoding.EncodeToString(raw) != string(parts[i]) {
+			report(RuleBase64, "%s has non-zero padding bits", name)
+		}
+	}
+
+	claims, err := ParseWithoutCheck(token)
+	if err != nil {
+		report(RuleMalformed, "%s", err)
+		return findings
+	}
+	for _, name := range duplicateNames(claims.RawHeader) {
+		report(RuleDuplicateHeader, "JOSE header has %q more than once", name)
+	}
+	for _, name := range duplicateNames(claims.Raw) {
+		report(RuleDuplicateClaim, "payload has %q more than once", name)
+	}
+
+	var header struct {
+		Alg string `json:"alg"`
+	}
+	json.Unmarshal(claims.RawHeader, &header) // checked by ParseWithoutCheck
+	switch header.Alg {
+	case "", "none":
+		report(RuleWeakAlg, "unsecured token with algorithm %q", header.Alg)
+	default:
+		_, hmac := HMACAlgs[header.Alg]
+		_, rsa := RSAAlgs[header.Alg]
+		_, ecdsa := ECDSAAlgs[header.Alg]
+		if !hmac && !rsa && !ecdsa && header.Alg != EdDSA {
+			report(RuleUnknownAlg, "algorithm %q not in use", header.Alg)
+		}
+	}
+
+	if claims.Expires == nil {
+		report(RuleNoExpiry, `no expiration time ["exp"]`)
+	} else {
+		start := claims.NotBefore
+		if start == nil {
+			start = claims.Issued
+		}
+		if start != nil {
+			lifetime := claims.Expires.Time().Sub(start.Time())
+			switch {
+			case lifetime <= 0:
+				report(RuleLifetime, "expires %s before it starts", -lifetime)
+			case lifetime > LintMaxLifetime:
+				report(RuleLifetime, "lifetime of %s exceeds %s", lifetime, LintMaxLifetime)
+			}
+		}
+	}
+
+	return findings
+}
+
+// DuplicateNames returns any names which occur more than once in the JSON
+// object, in order of appearance. Malformed JSON results in nil.
+func duplicateNames(object []byte) []string {
+	dec := json.NewDecoder(bytes.NewReader(object))
+	if t, err := dec.Token(); err != nil || t != json.Delim('{') {
+		return nil
+	}
+
+	var dups []string
+	seen := make(map[string]bool)
+	for dec.More() {
+		t, err := dec.Token()
+		if err != nil {
+			return nil
+		}
+		name, _ := t.(string)
+		if seen[name] {
+			dups = append(dups, name)
+		}
+		seen[name] = true
+
+		// skip value
+		var v json.RawMessage
+		if err := dec.Decode(&v); err != nil {
+			return nil
+		}
+	}
+	return dups
+}
@@ -0,0 +1,62 @@
+package jwt
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestLint(t *testing.T) {
+	b64 := encoding.EncodeToString
+
+	golden := []struct {
+		token string
+		want  []string
+	}{
+		{"", []string{RuleMalformed}},
+		{"a.b", []string{RuleMalformed}},
+		{"a.b.c.d", []string{RuleMalformed}},
+		{"e30.e30.!", []string{RuleMalformed}},
+		// golden HMAC 1 has a lifetime of 2 seconds
+		{goldenHMACs[1].token, nil},
+		{b64([]byte(`{"alg":"HS256"}`)) + "." + b64([]byte(`{"exp":1}`)) + ".AB", []string{RuleBase64}},
+		{b64([]byte(`{"alg":"HS256","alg":"HS512"}`)) + "." + b64([]byte(`{"exp":1,"sub":"a","sub":"b"}`)) + ".AA",
+			[]string{RuleDuplicateHeader, RuleDuplicateClaim}},
+		{b64([]byte(`{"alg":"none"}`)) + "." + b64([]byte(`{"exp":1}`)) + ".", []string{RuleWeakAlg}},
+		{b64([]byte(`{"alg":"RS256"}`)) + "." + b64([]byte(`{"exp":1}`)) + ".", nil},
+		{b64([]byte(`{"alg":"ES512"}`)) + "." + b64([]byte(`{"exp":1}`)) + ".", nil},
+		{b64([]byte(`{"alg":"XX1"}`)) + "." + b64([]byte(`{"exp":1}`)) + ".", []string{RuleUnknownAlg}},
+		{b64([]byte(`{"alg":"EdDSA"}`)) + "." + b64([]byte(`{}`)) + ".", []string{RuleNoExpiry}},
+		{b64([]byte(`{"alg":"EdDSA"}`)) + "." + b64([]byte(`{"iat":0,"exp":1e9}`)) + ".", []string{RuleLifetime}},
+		{b64([]byte(`{"alg":"EdDSA"}`)) + "." + b64([]byte(`{"nbf":2,"exp":1}`)) + ".", []string{RuleLifetime}},
+		{b64([]byte(`{"alg":"EdDSA"}`)) + "." + b64([]byte(`{"exp":1,"x":"`+strings.Repeat("x", 4096)+`"}`)) + ".", []string{RuleSize}},
+	}
+	for _, gold := range golden {
+		var got []string
+		for _, f := range Lint([]byte(gold.token)) {
+			got = append(got, f.Rule)
+		}
+		if !reflect.DeepEqual(got, gold.want) {
+			t.Errorf("%.60q got rules %q, want %q", gold.token, got, gold.want)
+		}
+	}
+}
+
+func TestLintText(t *testing.T) {
+	var c Claims
+	c.Issued = NewNumericTime(time.Unix(0, 0))
+	c.Expires = NewNumericTime(time.Unix(0, 0).Add(30 * 24 * time.Hour))
+	token, err := c.EdDSASign(testKeyEd25519Private)
+	if err != nil {
+		t.Fatal(err)
+	}
+	findings := Lint(token)
+	if len(findings) != 1 {
+		t.Fatalf("got findings %q, want 1", findings)
+	}
+	const want = "lifetime: lifetime of 720h0m0s exceeds 168h0m0s"
+	if got := findings[0].String(); got != want {
+		t.Errorf("got %q, want %q", got, want)
+	}
+}
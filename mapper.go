@@ -0,0 +1,85 @@
+package jwt
+
+// ClaimMapper transforms verified claims, e.g., to normalize the identity
+// representation of various issuers. See Handler.Mapper for usage.
+type ClaimMapper interface {
+	MapClaims(*Claims) error
+}
+
+// ClaimMapperFunc is a function adapter for ClaimMapper.
+type ClaimMapperFunc func(*Claims) error
+
+// MapClaims honors the ClaimMapper interface.
+func (f ClaimMapperFunc) MapClaims(c *Claims) error {
+	return f(c)
+}
+
+// ClaimMappers applies each entry in order. The first error is returned as is.
+type ClaimMappers []ClaimMapper
+
+// MapClaims honors the ClaimMapper interface.
+func (mappers ClaimMappers) MapClaims(c *Claims) error {
+	for _, m := range mappers {
+		if err := m.MapClaims(c); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// RenameClaim moves the claim from Set to another name, replacing any
+// existing value. Registered claims are not affected.
+func RenameClaim(from, to string) ClaimMapper {
+	return ClaimMapperFunc(func(c *Claims) error {
+		v, ok := c.Set[from]
+		if ok {
+			delete(c.Set, from)
+			c.Set[to] = v
+		}
+		return nil
+	})
+}
+
+// DropClaims removes the claims from Set. Registered claims are not affected.
+func DropClaims(names ...string) ClaimMapper {
+	return ClaimMapperFunc(func(c *Claims) error {
+		for _, name := range names {
+			delete(c.Set, name)
+		}
+		return nil
+	})
+}
+
+// LookupClaim translates the values of a claim in Set, either a space-delimited
+// string or an array of strings, with table. The result goes in Set as an
+// array of strings named to, without duplicates, in order of appearance. Values
+// absent from table are dropped. Claims of another type are ignored.
+//
+//	// map group IDs to roles
+//	jwt.LookupClaim("groups", "roles", map[string][]string{
+//		"7a3e9c": {"admin"},
+//		"51f0d2": {"reader", "writer"},
+//	})
+func LookupClaim(from, to string, table map[string][]string) ClaimMapper {
+	return ClaimMapperFunc(func(c *Claims) error {
+		values, ok := ParseScope(c.Set[from])
+		if !ok {
+			return nil
+		}
+		seen := make(map[string]bool)
+		mapped := make([]interface{}, 0, len(values))
+		for _, v := range values {
+			for _, s := range table[v] {
+				if !seen[s] {
+					seen[s] = true
+					mapped = append(mapped, s)
+				}
+			}
+		}
+		if c.Set == nil {
+			c.Set = make(map[string]interface{})
+		}
+		c.Set[to] = mapped
+		return nil
+	})
+}
@@ -0,0 +1,55 @@
+package jwt
+
+import (
+	"crypto/ed25519"
+	"net/http"
+	"net/http/httptest"
+	"reflect"
+	"testing"
+)
+
+func TestClaimMappers(t *testing.T) {
+	c := &Claims{Set: map[string]interface{}{
+		"grp":    []interface{}{"g1", "g2", "g3"},
+		"upn":    "alice@example.com",
+		"secret": "drop me",
+	}}
+	mapper := ClaimMappers{
+		RenameClaim("upn", "email"),
+		DropClaims("secret"),
+		LookupClaim("grp", "roles", map[string][]string{
+			"g1": {"admin", "reader"},
+			"g2": {"reader"},
+		}),
+	}
+	if err := mapper.MapClaims(c); err != nil {
+		t.Fatal("map error:", err)
+	}
+	want := map[string]interface{}{
+		"grp":   []interface{}{"g1", "g2", "g3"},
+		"email": "alice@example.com",
+		"roles": []interface{}{"admin", "reader"},
+	}
+	if !reflect.DeepEqual(c.Set, want) {
+		t.Errorf("got %#v, want %#v", c.Set, want)
+	}
+}
+
+func TestHandleMapper(t *testing.T) {
+	handler := &Handler{
+		Target: http.HandlerFunc(func(http.ResponseWriter, *http.Request) {}),
+		Keys:   &KeyRegister{EdDSAs: []ed25519.PublicKey{testKeyEd25519Public}},
+		Mapper: RenameClaim("permissions", "scope"),
+		Expect: &Expect{Scopes: []string{"read"}},
+	}
+	req := httptest.NewRequest("GET", "/", nil)
+	c := &Claims{Set: map[string]interface{}{"permissions": "write read"}}
+	if err := c.EdDSASignHeader(req, testKeyEd25519Private); err != nil {
+		t.Fatal(err)
+	}
+	resp := httptest.NewRecorder()
+	handler.ServeHTTP(resp, req)
+	if resp.Code != http.StatusOK {
+		t.Errorf("got HTTP %d, want 200", resp.Code)
+	}
+}
@@ -0,0 +1,140 @@
+package jwt
+
+import (
+	"crypto/ecdsa"
+	"crypto/ed25519"
+	"crypto/hmac"
+	"crypto/rsa"
+)
+
+// Merge adds the keys from other, including their key IDs, and it returns the
+// number of keys added. Keys which are present already are not added again,
+// unless their key IDs differ. A key without key ID takes the one from other,
+// if any. Algorithms are always added, as they need not be comparable. Other
+// remains unmodified.
+func (keys *KeyRegister) Merge(other *KeyRegister) (keysAdded int, err error) {
+	n, err := mergeKeys(keys, &keys.ECDSAs, &keys.ECDSAIDs, other.ECDSAs, other.ECDSAIDs, func(a, b *ecdsa.PublicKey) bool {
+		return a.Equal(b)
+	})
+	keysAdded += n
+	if err != nil {
+		return keysAdded, err
+	}
+	n, err = mergeKeys(keys, &keys.EdDSAs, &keys.EdDSAIDs, other.EdDSAs, other.EdDSAIDs, func(a, b ed25519.PublicKey) bool {
+		return a.Equal(b)
+	})
+	keysAdded += n
+	if err != nil {
+		return keysAdded, err
+	}
+	n, err = mergeKeys(keys, &keys.RSAs, &keys.RSAIDs, other.RSAs, other.RSAIDs, func(a, b *rsa.PublicKey) bool {
+		return a.Equal(b)
+	})
+	keysAdded += n
+	if err != nil {
+		return keysAdded, err
+	}
+	n, err = mergeKeys(keys, &keys.HMACs, &keys.HMACIDs, other.HMACs, other.HMACIDs, func(a, b *HMAC) bool {
+		return a == b
+	})
+	keysAdded += n
+	if err != nil {
+		return keysAdded, err
+	}
+	n, err = mergeKeys(keys, &keys.Secrets, &keys.SecretIDs, other.Secrets, other.SecretIDs, func(a, b []byte) bool {
+		return hmac.Equal(a, b)
+	})
+	keysAdded += n
+	if err != nil {
+		return keysAdded, err
+	}
+	n, err = mergeKeys(keys, &keys.Algorithms, &keys.AlgorithmIDs, other.Algorithms, other.AlgorithmIDs, func(a, b Algorithm) bool {
+		return false
+	})
+	keysAdded += n
+	return keysAdded, err
+}
+
+// MergeKeys adds each entry from src to the register, unless present in dst,
+// which is the respective register slice with its key IDs in dstIDs.
+func mergeKeys[T any](keys *KeyRegister, dst *[]T, dstIDs *[]string, src []T, srcIDs []string, equal func(a, b T) bool) (keysAdded int, err error) {
+	for i, key := range src {
+		kid := kidAt(srcIDs, i)
+		dup := false
+		for j, present := range *dst {
+			if !equal(present, key) {
+				continue
+			}
+			switch presentID := kidAt(*dstIDs, j); {
+			case presentID == kid || kid == "":
+				dup = true
+			case presentID == "":
+				for len(*dstIDs) <= j {
+					*dstIDs = append(*dstIDs, "")
+				}
+				(*dstIDs)[j] = kid
+				dup = true
+			}
+			if dup {
+				break
+			}
+		}
+		if dup {
+			continue
+		}
+		if err := keys.add(key, kid); err != nil {
+			return keysAdded, err
+		}
+		keysAdded++
+	}
+	return keysAdded, nil
+}
+
+// Subset returns a register with only the keys of which the key ID is in kids.
+// EdDSAContext, Usage, ResolveKID, StrictKID, RejectDuplicates and
+// KeyAudiences are copied as is. SecretSource is not, as it could resolve any
+// key ID. The slices of keys are not shared with the subset.
+func (keys *KeyRegister) Subset(kids ...string) *KeyRegister {
+	set := make(map[string]bool, len(kids))
+	for _, kid := range kids {
+		if kid != "" {
+			set[kid] = true
+		}
+	}
+
+	sub := &KeyRegister{
+		EdDSAContext: keys.EdDSAContext,
+		Usage:        keys.Usage,
+		ResolveKID:   keys.ResolveKID,
+		StrictKID:    keys.StrictKID,
+
+		RejectDuplicates: keys.RejectDuplicates,
+		KeyAudiences:     keys.KeyAudiences,
+	}
+	sub.ECDSAs, sub.ECDSAIDs = subsetKeys(keys.ECDSAs, keys.ECDSAIDs, set)
+	sub.EdDSAs, sub.EdDSAIDs = subsetKeys(keys.EdDSAs, keys.EdDSAIDs, set)
+	sub.RSAs, sub.RSAIDs = subsetKeys(keys.RSAs, keys.RSAIDs, set)
+	sub.HMACs, sub.HMACIDs = subsetKeys(keys.HMACs, keys.HMACIDs, set)
+	sub.Secrets, sub.SecretIDs = subsetKeys(keys.Secrets, keys.SecretIDs, set)
+	sub.Algorithms, sub.AlgorithmIDs = subsetKeys(keys.Algorithms, keys.AlgorithmIDs, set)
+	return sub
+}
+
+// SubsetKeys returns the entries with a key ID in set.
+func subsetKeys[T any](keys []T, ids []string, set map[string]bool) (subKeys []T, subIDs []string) {
+	for i, key := range keys {
+		if kid := kidAt(ids, i); set[kid] {
+			subKeys = append(subKeys, key)
+			subIDs = append(subIDs, kid)
+		}
+	}
+	return
+}
+
+// KidAt returns the key ID at index i, if any.
+func kidAt(ids []string, i int) string {
+	if i < len(ids) {
+		return ids[i]
+	}
+	return ""
+}
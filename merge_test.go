@@ -0,0 +1,76 @@
+package jwt
+
+import (
+	"crypto/ed25519"
+	"testing"
+)
+
+func TestMerge(t *testing.T) {
+	var base KeyRegister
+	base.add(&testKeyEC256.PublicKey, "")
+	base.add(testKeyEd25519Public, "ed1")
+	base.add([]byte("guest"), "s1")
+
+	var tenant KeyRegister
+	tenant.add(&testKeyEC256.PublicKey, "ec1")              // adopts key ID
+	tenant.add(testKeyEd25519Public, "ed1")                 // duplicate
+	tenant.add(testKeyEd25519Public, "ed2")                 // other key ID
+	tenant.add(&testKeyRSA2048.PublicKey, "rsa1")           // new
+	tenant.add([]byte("guest"), "")                         // duplicate
+	tenant.add(ed25519.PublicKey(testKeyEd25519Public), "") // duplicate
+
+	n, err := base.Merge(&tenant)
+	if err != nil {
+		t.Fatal("merge error:", err)
+	}
+	if n != 2 {
+		t.Errorf("got %d keys added, want 2", n)
+	}
+	if len(base.ECDSAs) != 1 || kidAt(base.ECDSAIDs, 0) != "ec1" {
+		t.Errorf("got ECDSA keys %d with IDs %q, want 1 with ec1", len(base.ECDSAs), base.ECDSAIDs)
+	}
+	if len(base.EdDSAs) != 2 || kidAt(base.EdDSAIDs, 1) != "ed2" {
+		t.Errorf("got EdDSA keys %d with IDs %q, want 2 with ed1 and ed2", len(base.EdDSAs), base.EdDSAIDs)
+	}
+	if len(base.RSAs) != 1 || len(base.Secrets) != 1 {
+		t.Errorf("got %d RSA keys and %d secrets, want 1 and 1", len(base.RSAs), len(base.Secrets))
+	}
+
+	// idempotent
+	n, err = base.Merge(&tenant)
+	if n != 0 || err != nil {
+		t.Errorf("second merge got (%d, %v), want (0, nil)", n, err)
+	}
+}
+
+func TestSubset(t *testing.T) {
+	var keys KeyRegister
+	keys.add(&testKeyEC256.PublicKey, "ec1")
+	keys.add(&testKeyEC384.PublicKey, "")
+	keys.add(testKeyEd25519Public, "ed1")
+	keys.add([]byte("guest"), "s1")
+	keys.add([]byte("other"), "s2")
+	keys.SecretSource = func(kid string) ([]byte, error) { return []byte("guest"), nil }
+
+	sub := keys.Subset("ec1", "s2", "", "unknown")
+	if len(sub.ECDSAs) != 1 || sub.ECDSAs[0] != &testKeyEC256.PublicKey {
+		t.Errorf("got ECDSA keys %v, want ec1 only", sub.ECDSAs)
+	}
+	if len(sub.EdDSAs) != 0 {
+		t.Errorf("got %d EdDSA keys, want none", len(sub.EdDSAs))
+	}
+	if len(sub.Secrets) != 1 || string(sub.Secrets[0]) != "other" || sub.SecretIDs[0] != "s2" {
+		t.Errorf("got secrets %q with IDs %q, want s2 only", sub.Secrets, sub.SecretIDs)
+	}
+	if sub.SecretSource != nil {
+		t.Error("secret source copied")
+	}
+
+	token, err := new(Claims).HMACSign(HS256, []byte("guest"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := sub.Check(token); err != ErrSigMiss {
+		t.Errorf("subset check got error %v, want %v", err, ErrSigMiss)
+	}
+}
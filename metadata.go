@@ -0,0 +1,69 @@
+package jwt
+
+import (
+	"encoding/json"
+	"net/http"
+	"sort"
+)
+
+// ResourceMetadataPath is the well-known location of ResourceMetadata, as per
+// “OAuth 2.0 Protected Resource Metadata” RFC 9728, subsection 3.
+const ResourceMetadataPath = "/.well-known/oauth-protected-resource"
+
+// ResourceMetadata is the protected resource description from RFC 9728,
+// subsection 2. ServeHTTP publishes the content as JSON.
+type ResourceMetadata struct {
+	Resource               string   `json:"resource"`
+	AuthorizationServers   []string `json:"authorization_servers,omitempty"`
+	JWKSURI                string   `json:"jwks_uri,omitempty"`
+	ScopesSupported        []string `json:"scopes_supported,omitempty"`
+	BearerMethodsSupported []string `json:"bearer_methods_supported,omitempty"`
+	ResourceName           string   `json:"resource_name,omitempty"`
+	ResourceDocumentation  string   `json:"resource_documentation,omitempty"`
+}
+
+// ResourceMetadata returns the description of resource, i.e., the URL of the
+// protected resource, conform the configuration of h. The scopes are the ones
+// required by Expect and RouteClaims. Authorization servers are the issuers,
+// identified by their URL.
+func (h *Handler) ResourceMetadata(resource string, authorizationServers ...string) *ResourceMetadata {
+	scopes := make(map[string]bool)
+	if h.Expect != nil {
+		for _, s := range h.Expect.Scopes {
+			scopes[s] = true
+		}
+	}
+	for _, e := range h.RouteClaims {
+		for _, s := range e.Scopes {
+			scopes[s] = true
+		}
+	}
+	var supported []string
+	for s := range scopes {
+		supported = append(supported, s)
+	}
+	sort.Strings(supported)
+
+	return &ResourceMetadata{
+		Resource:               resource,
+		AuthorizationServers:   authorizationServers,
+		ScopesSupported:        supported,
+		BearerMethodsSupported: []string{"header"},
+	}
+}
+
+// ServeHTTP honors the http.Handler interface.
+func (m *ResourceMetadata) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet && r.Method != http.MethodHead {
+		w.Header().Set("Allow", "GET, HEAD")
+		http.Error(w, "method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+	body, err := json.Marshal(m)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+	w.Header().Set("Content-Type", "application/json")
+	w.Write(body)
+}
@@ -0,0 +1,36 @@
+package jwt
+
+import (
+	"net/http/httptest"
+	"testing"
+)
+
+func TestResourceMetadata(t *testing.T) {
+	h := &Handler{
+		Expect: &Expect{Scopes: []string{"read"}},
+		RouteClaims: map[string]Expect{
+			"/admin/": {Scopes: []string{"admin", "read"}},
+		},
+	}
+	m := h.ResourceMetadata("https://api.example.com", "https://auth.example.com")
+	m.JWKSURI = "https://api.example.com/jwks.json"
+
+	resp := httptest.NewRecorder()
+	m.ServeHTTP(resp, httptest.NewRequest("GET", ResourceMetadataPath, nil))
+	if resp.Code != 200 {
+		t.Fatalf("got HTTP %d, want 200", resp.Code)
+	}
+	if got := resp.Header().Get("Content-Type"); got != "application/json" {
+		t.Errorf("got content type %q, want application/json", got)
+	}
+	const want = `{"resource":"https://api.example.com","authorization_servers":["https://auth.example.com"],"jwks_uri":"https://api.example.com/jwks.json","scopes_supported":["admin","read"],"bearer_methods_supported":["header"]}`
+	if got := resp.Body.String(); got != want {
+		t.Errorf("got %s\nwant %s", got, want)
+	}
+
+	resp = httptest.NewRecorder()
+	m.ServeHTTP(resp, httptest.NewRequest("POST", ResourceMetadataPath, nil))
+	if resp.Code != 405 {
+		t.Errorf("POST got HTTP %d, want 405", resp.Code)
+	}
+}
@@ -0,0 +1,72 @@
+package jwt
+
+import (
+	"errors"
+	"sync"
+)
+
+var (
+	errNoIssued = errors.New(`jwt: issued ["iat"] absent`)
+	errOutdated = errors.New(`jwt: issued ["iat"] before the latest accepted for issuer and subject`)
+)
+
+// IssuedStore tracks the latest issue time per issuer and subject. Once a
+// token of a subject is accepted, tokens of the same subject from the same
+// issuer which were issued before are rejected. This defends against replay of
+// long-lived tokens after credential rotation. Subjects are scoped by issuer,
+// as RFC 7519, subsection 4.1.2 only requires them to be unique per issuer.
+type IssuedStore interface {
+	// Advance updates the latest issue time of subject from issuer to
+	// issued, unless the latest is after issued already. The return is
+	// false in such case. Equal issue times are accepted.
+	Advance(issuer, subject string, issued NumericTime) (ok bool, err error)
+}
+
+// MemoryIssuedStore is an in-memory IssuedStore. The zero value is ready for
+// use. Note that there is no eviction; memory grows with each subject seen.
+//
+// Multiple goroutines may invoke methods on a MemoryIssuedStore simultaneously.
+type MemoryIssuedStore struct {
+	mutex  sync.Mutex
+	latest map[issuedKey]NumericTime
+}
+
+// IssuedKey is the MemoryIssuedStore index.
+type issuedKey struct{ issuer, subject string }
+
+// Advance honors the IssuedStore interface.
+func (store *MemoryIssuedStore) Advance(issuer, subject string, issued NumericTime) (ok bool, err error) {
+	key := issuedKey{issuer, subject}
+
+	store.mutex.Lock()
+	defer store.mutex.Unlock()
+
+	if latest, ok := store.latest[key]; ok && latest > issued {
+		return false, nil
+	}
+	if store.latest == nil {
+		store.latest = make(map[issuedKey]NumericTime)
+	}
+	store.latest[key] = issued
+	return true, nil
+}
+
+// AcceptIssued verifies that the token is not older than the latest accepted
+// for the issuer and subject. Tokens without a subject are not tracked. Tokens
+// without an issue time are rejected, as they can not be tracked.
+func (c *Claims) AcceptIssued(store IssuedStore) error {
+	if c.Subject == "" {
+		return nil
+	}
+	if c.Issued == nil {
+		return errNoIssued
+	}
+	ok, err := store.Advance(c.Issuer, c.Subject, *c.Issued)
+	if err != nil {
+		return err
+	}
+	if !ok {
+		return errOutdated
+	}
+	return nil
+}
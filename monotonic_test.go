@@ -0,0 +1,53 @@
+package jwt
+
+import (
+	"errors"
+	"testing"
+)
+
+func TestAcceptIssued(t *testing.T) {
+	var store MemoryIssuedStore
+	at := func(subject string, issued NumericTime) *Claims {
+		return &Claims{Registered: Registered{Issuer: "a", Subject: subject, Issued: &issued}}
+	}
+	from := func(issuer string, c *Claims) *Claims {
+		c.Issuer = issuer
+		return c
+	}
+
+	golden := []struct {
+		c    *Claims
+		want error
+	}{
+		{at("alice", 1000), nil},
+		{at("alice", 1000), nil},
+		{at("alice", 2000), nil},
+		{at("alice", 1999), errOutdated},
+		{from("b", at("alice", 1)), nil},
+		{from("b", at("alice", 0)), errOutdated},
+		{from("", at("alice", 1)), nil},
+		{at("bob", 1), nil},
+		{&Claims{Registered: Registered{Subject: "bob"}}, errNoIssued},
+		{new(Claims), nil},
+	}
+	for i, gold := range golden {
+		if err := gold.c.AcceptIssued(&store); err != gold.want {
+			t.Errorf("%d: got error %v, want %v", i, err, gold.want)
+		}
+	}
+}
+
+type failingIssuedStore struct{ err error }
+
+func (store failingIssuedStore) Advance(string, string, NumericTime) (bool, error) {
+	return false, store.err
+}
+
+func TestAcceptIssuedStoreError(t *testing.T) {
+	want := errors.New("store unavailable")
+	issued := NumericTime(1)
+	c := Claims{Registered: Registered{Subject: "alice", Issued: &issued}}
+	if err := c.AcceptIssued(failingIssuedStore{want}); err != want {
+		t.Errorf("got error %v, want %v", err, want)
+	}
+}
@@ -0,0 +1,103 @@
+package jwt
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/json"
+	"errors"
+	"fmt"
+)
+
+// MultiSigJWS is the general JWS JSON serialization of RFC 7515, subsection
+// 7.2.1, with protected headers only.
+type multiSigJWS struct {
+	Payload    string         `json:"payload"`
+	Signatures []multiSigPart `json:"signatures"`
+}
+
+type multiSigPart struct {
+	Protected string `json:"protected"`
+	Signature string `json:"signature"`
+}
+
+var (
+	errQuorum   = errors.New("jwt: signature quorum not met")
+	errNoQuorum = errors.New("jwt: quorum must be positive")
+)
+
+// CheckQuorum parses a JWS in general JSON serialization if, and only if, the
+// signatures of at least quorum distinct keys from the register check out.
+// Each signature is verified as with Check. Signatures which fail to verify
+// do not count, and neither do repeated signatures from the same key, which
+// includes the same key material registered under multiple key IDs. The
+// return has the JOSE header of the first valid signature. See
+// Claims.MultiSign for issuance.
+// Use Claims.Valid to complete the verification.
+func (keys *KeyRegister) CheckQuorum(jws []byte, quorum int) (*Claims, error) {
+	if quorum < 1 {
+		return nil, errNoQuorum
+	}
+	var m multiSigJWS
+	if err := json.Unmarshal(jws, &m); err != nil {
+		return nil, fmt.Errorf("jwt: malformed JWS JSON serialization: %w", err)
+	}
+
+	var claims *Claims
+	var lastErr error = ErrSigMiss
+	matched := make(map[interface{}]bool, quorum)
+	for _, s := range m.Signatures {
+		token := s.Protected + "." + m.Payload + "." + s.Signature
+		c, key, err := keys.check(context.Background(), []byte(token))
+		if err != nil {
+			lastErr = err
+			continue
+		}
+		fingerprint := keys.fingerprint(key)
+		if matched[fingerprint] {
+			continue
+		}
+		matched[fingerprint] = true
+		keys.used(key)
+		if claims == nil {
+			claims = c
+		}
+		if len(matched) >= quorum {
+			return claims, nil
+		}
+	}
+	return nil, fmt.Errorf("%w: %d of %d valid; last error: %s", errQuorum, len(matched), quorum, lastErr)
+}
+
+// Fingerprint returns a digest of the key material with the identity (as in
+// KeyUsage), such that the same key gives the same return, regardless of its
+// position in the register. Keys without identity in the register, i.e.,
+// Algorithms and the SecretSource, all share the nil return.
+func (keys *KeyRegister) fingerprint(id interface{}) interface{} {
+	stat, ok := keys.describe(id)
+	if !ok {
+		return id
+	}
+	var material []byte
+	switch stat.Type {
+	case "ECDSA":
+		key := keys.ECDSAs[stat.Index]
+		material = []byte(fmt.Sprintf("%s:%x:%x", key.Curve.Params().Name, key.X, key.Y))
+	case "EdDSA":
+		material = keys.EdDSAs[stat.Index]
+	case "RSA":
+		key := keys.RSAs[stat.Index]
+		material = []byte(fmt.Sprintf("%x:%x", key.N, key.E))
+	case "HMAC":
+		// the inner pad derives from the secret only
+		material = keys.HMACs[stat.Index].ipad
+	case "secret":
+		material = keys.Secrets[stat.Index]
+	}
+	digest := sha256.New()
+	digest.Write([]byte(stat.Type))
+	digest.Write([]byte{0})
+	digest.Write(material)
+	var sum [sha256.Size]byte
+	digest.Sum(sum[:0])
+	return sum
+}
@@ -0,0 +1,42 @@
+//go:build !jwtverifyonly
+
+package jwt
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+)
+
+// MultiSign updates the Raw fields and returns a JWS in the general JSON
+// serialization of RFC 7515, subsection 7.2.1, with a signature from each of
+// the signers. RawHeader is left with the header of the last signer. See
+// KeyRegister.CheckQuorum for verification.
+func (c *Claims) MultiSign(signers ...SignFunc) ([]byte, error) {
+	if len(signers) == 0 {
+		return nil, errors.New("jwt: no signers for multi-signature")
+	}
+
+	var m multiSigJWS
+	m.Signatures = make([]multiSigPart, len(signers))
+	for i, sign := range signers {
+		token, err := sign(c)
+		if err != nil {
+			return nil, err
+		}
+		firstDot := bytes.IndexByte(token, '.')
+		lastDot := bytes.LastIndexByte(token, '.')
+		if firstDot < 0 || lastDot == firstDot {
+			return nil, errors.New("jwt: signer output not in compact serialization")
+		}
+		payload := string(token[firstDot+1 : lastDot])
+		if i == 0 {
+			m.Payload = payload
+		} else if payload != m.Payload {
+			return nil, errors.New("jwt: payload of signers differ")
+		}
+		m.Signatures[i].Protected = string(token[:firstDot])
+		m.Signatures[i].Signature = string(token[lastDot+1:])
+	}
+	return json.Marshal(&m)
+}
@@ -0,0 +1,85 @@
+package jwt
+
+import (
+	"crypto/ecdsa"
+	"crypto/ed25519"
+	"encoding/json"
+	"errors"
+	"testing"
+)
+
+func TestMultiSign(t *testing.T) {
+	ed := func(c *Claims, extraHeaders ...json.RawMessage) ([]byte, error) {
+		return c.EdDSASign(testKeyEd25519Private, extraHeaders...)
+	}
+	ec := func(c *Claims, extraHeaders ...json.RawMessage) ([]byte, error) {
+		return c.ECDSASign(ES256, testKeyEC256, extraHeaders...)
+	}
+
+	var c Claims
+	c.Subject = "deploy"
+	jws, err := c.MultiSign(ed, ec, ed)
+	if err != nil {
+		t.Fatal("sign error:", err)
+	}
+
+	keys := KeyRegister{
+		EdDSAs: []ed25519.PublicKey{testKeyEd25519Public},
+		ECDSAs: []*ecdsa.PublicKey{&testKeyEC256.PublicKey},
+	}
+	got, err := keys.CheckQuorum(jws, 2)
+	if err != nil {
+		t.Fatal("check error:", err)
+	}
+	if got.Subject != "deploy" {
+		t.Errorf("got subject %q, want deploy", got.Subject)
+	}
+
+	// repeated key counts once
+	if _, err := keys.CheckQuorum(jws, 3); !errors.Is(err, errQuorum) {
+		t.Errorf("quorum 3 got error %v, want %v", err, errQuorum)
+	}
+	oneKey := KeyRegister{EdDSAs: []ed25519.PublicKey{testKeyEd25519Public}}
+	if _, err := oneKey.CheckQuorum(jws, 2); !errors.Is(err, errQuorum) {
+		t.Errorf("one key got error %v, want %v", err, errQuorum)
+	}
+	if _, err := oneKey.CheckQuorum(jws, 1); err != nil {
+		t.Errorf("one key with quorum 1 got error %v", err)
+	}
+
+	// payload swap breaks signatures
+	var m multiSigJWS
+	if err := json.Unmarshal(jws, &m); err != nil {
+		t.Fatal(err)
+	}
+	m.Payload = encoding.EncodeToString([]byte(`{"sub":"evil"}`))
+	tampered, _ := json.Marshal(&m)
+	if _, err := keys.CheckQuorum(tampered, 1); !errors.Is(err, errQuorum) {
+		t.Errorf("tampered payload got error %v, want %v", err, errQuorum)
+	}
+}
+
+func TestCheckQuorumSameKeyTwice(t *testing.T) {
+	sign := func(kid string) func(*Claims, ...json.RawMessage) ([]byte, error) {
+		return func(c *Claims, extraHeaders ...json.RawMessage) ([]byte, error) {
+			c.KeyID = kid
+			return c.EdDSASign(testKeyEd25519Private, extraHeaders...)
+		}
+	}
+	jws, err := new(Claims).MultiSign(sign("a"), sign("b"))
+	if err != nil {
+		t.Fatal("sign error:", err)
+	}
+
+	// one key under two key IDs
+	keys := KeyRegister{
+		EdDSAs:   []ed25519.PublicKey{testKeyEd25519Public, append(ed25519.PublicKey(nil), testKeyEd25519Public...)},
+		EdDSAIDs: []string{"a", "b"},
+	}
+	if _, err := keys.CheckQuorum(jws, 2); !errors.Is(err, errQuorum) {
+		t.Errorf("same key under two key IDs got error %v, want %v", err, errQuorum)
+	}
+	if _, err := keys.CheckQuorum(jws, 1); err != nil {
+		t.Errorf("quorum 1 got error %v", err)
+	}
+}
@@ -0,0 +1,60 @@
+package jwt
+
+import "testing"
+
+func TestNamespaceApply(t *testing.T) {
+	c := new(Claims)
+	c.Subject = "42"
+	ns := Namespace("urn:example:billing")
+	if err := ns.Apply(c); err != nil {
+		t.Fatal(err)
+	}
+	if c.Subject != "urn:example:billing:42" {
+		t.Errorf("got subject %q", c.Subject)
+	}
+	if !ns.Owns(c.ID) || len(c.ID) != len(ns)+1+32 {
+		t.Errorf("got JWT ID %q", c.ID)
+	}
+
+	// idempotent
+	sub, id := c.Subject, c.ID
+	if err := ns.Apply(c); err != nil {
+		t.Fatal(err)
+	}
+	if c.Subject != sub || c.ID != id {
+		t.Errorf("second apply got %q and %q", c.Subject, c.ID)
+	}
+
+	for _, bad := range []Namespace{"", "urn:", "example", "urn:example:"} {
+		if err := bad.Apply(new(Claims)); err != errNamespace {
+			t.Errorf("namespace %q got error %v, want %v", bad, err, errNamespace)
+		}
+	}
+}
+
+func TestAcceptNamespace(t *testing.T) {
+	owners := []Namespace{"urn:example:billing", "urn:example:billing:eu", "urn:example:mail"}
+
+	golden := []struct {
+		iss, sub, jti string
+		owner         Namespace
+		err           error
+	}{
+		{"", "urn:example:billing:42", "urn:example:billing:x1", "urn:example:billing", nil},
+		{"", "", "urn:example:mail:x2", "urn:example:mail", nil},
+		{"", "urn:example:billing:eu:7", "urn:example:billing:eu:x3", "urn:example:billing:eu", nil},
+		{"", "urn:example:billing:eu:7", "urn:example:billing:x4", "", errNamespaceSubject},
+		{"", "urn:example:mail:42", "urn:example:billing:x5", "", errNamespaceSubject},
+		{"", "urn:example:billing:42", "urn:example:billingx:x6", "", errNamespaceID},
+		{"", "42", "x7", "", errNamespaceID},
+		{"https://issuer.example.com", "", "urn:example:mail:x8", "", errNamespaceInternal},
+	}
+	for _, gold := range golden {
+		c := new(Claims)
+		c.Issuer, c.Subject, c.ID = gold.iss, gold.sub, gold.jti
+		owner, err := c.AcceptNamespace(owners...)
+		if owner != gold.owner || err != gold.err {
+			t.Errorf("%q %q %q: got (%q, %v), want (%q, %v)", gold.iss, gold.sub, gold.jti, owner, err, gold.owner, gold.err)
+		}
+	}
+}
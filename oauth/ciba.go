@@ -0,0 +1,165 @@
+package oauth
+
+import (
+	"errors"
+	"time"
+
+	"github.com/pascaldekloe/jwt"
+)
+
+var (
+	errNoScope    = errors.New("oauth: authentication request without scope")
+	errHintCount  = errors.New("oauth: authentication request needs exactly one of login_hint_token, id_token_hint or login_hint")
+	errNoID       = errors.New(`oauth: authentication request without JWT ID ["jti"]`)
+	errTemporal   = errors.New(`oauth: authentication request without "exp", "iat" or "nbf"`)
+	errHintIssuer = errors.New(`oauth: ID token hint issuer ["iss"] mismatch`)
+	errHintClient = errors.New(`oauth: ID token hint audience ["aud"] mismatch`)
+)
+
+// BackchannelRequest is a signed authentication request, as described by
+// “OpenID Connect Client-Initiated Backchannel Authentication Flow” (CIBA),
+// subsection 7.1.1. The same request objects can be pushed with “OAuth 2.0
+// Pushed Authorization Requests” RFC 9126 using RequestObject.Query.
+type BackchannelRequest struct {
+	// ClientID is the issuer.
+	ClientID string
+	// Audience is the issuer identifier of the OpenID Provider.
+	Audience string
+	// ID is the JWT ID. A random value is used when empty.
+	ID string
+
+	Scope                   string // scope
+	ClientNotificationToken string // client_notification_token, optional
+	ACRValues               string // acr_values, optional
+	BindingMessage          string // binding_message, optional
+	UserCode                string // user_code, optional
+	RequestedExpiry         int    // requested_expiry in seconds, optional
+
+	// Exactly one of the hints must be set.
+	LoginHintToken string // login_hint_token
+	IDTokenHint    string // id_token_hint
+	LoginHint      string // login_hint
+}
+
+func (r *BackchannelRequest) hintCount() int {
+	var n int
+	for _, s := range []string{r.LoginHintToken, r.IDTokenHint, r.LoginHint} {
+		if s != "" {
+			n++
+		}
+	}
+	return n
+}
+
+// Claims returns the claims of r, valid from now for lifetime. The result is
+// ready for any of the sign methods.
+func (r *BackchannelRequest) Claims(now time.Time, lifetime time.Duration) (*jwt.Claims, error) {
+	switch {
+	case r.ClientID == "":
+		return nil, errNoClientID
+	case r.Audience == "":
+		return nil, errNoAudience
+	case r.Scope == "":
+		return nil, errNoScope
+	case r.hintCount() != 1:
+		return nil, errHintCount
+	}
+
+	c := &jwt.Claims{Set: map[string]interface{}{"scope": r.Scope}}
+	add := func(name, value string) {
+		if value != "" {
+			c.Set[name] = value
+		}
+	}
+	add("client_notification_token", r.ClientNotificationToken)
+	add("acr_values", r.ACRValues)
+	add("binding_message", r.BindingMessage)
+	add("user_code", r.UserCode)
+	add("login_hint_token", r.LoginHintToken)
+	add("id_token_hint", r.IDTokenHint)
+	add("login_hint", r.LoginHint)
+	if r.RequestedExpiry != 0 {
+		c.Set["requested_expiry"] = r.RequestedExpiry
+	}
+
+	// “aud, iss, exp, iat, nbf, jti ... REQUIRED”
+	c.Issuer = r.ClientID
+	c.Audiences = []string{r.Audience}
+	c.Issued = jwt.NewNumericTime(now.Truncate(time.Second))
+	c.NotBefore = c.Issued
+	c.Expires = jwt.NewNumericTime(now.Add(lifetime).Truncate(time.Second))
+	c.ID = r.ID
+	if c.ID == "" {
+		var err error
+		c.ID, err = jwt.NewID()
+		if err != nil {
+			return nil, err
+		}
+	}
+	return c, nil
+}
+
+// CheckBackchannelRequest verifies a signed authentication request for the
+// OpenID Provider identified by issuer. The client ID is in the issuer claim.
+func CheckBackchannelRequest(token []byte, keys *jwt.KeyRegister, issuer string, leeway time.Duration) (*BackchannelRequest, error) {
+	claims, err := keys.Check(token)
+	if err != nil {
+		return nil, err
+	}
+	if claims.Expires == nil || claims.Issued == nil || claims.NotBefore == nil {
+		return nil, errTemporal
+	}
+	if err := claims.AcceptTemporal(time.Now(), leeway); err != nil {
+		return nil, err
+	}
+	if len(claims.Audiences) == 0 || !claims.AcceptAudience(issuer) {
+		return nil, errAudience
+	}
+	if claims.Issuer == "" {
+		return nil, errNoClientID
+	}
+	if claims.ID == "" {
+		return nil, errNoID
+	}
+
+	r := &BackchannelRequest{
+		ClientID: claims.Issuer,
+		Audience: issuer,
+		ID:       claims.ID,
+	}
+	r.Scope, _ = claims.String("scope")
+	r.ClientNotificationToken, _ = claims.String("client_notification_token")
+	r.ACRValues, _ = claims.String("acr_values")
+	r.BindingMessage, _ = claims.String("binding_message")
+	r.UserCode, _ = claims.String("user_code")
+	r.LoginHintToken, _ = claims.String("login_hint_token")
+	r.IDTokenHint, _ = claims.String("id_token_hint")
+	r.LoginHint, _ = claims.String("login_hint")
+	if f, ok := claims.Number("requested_expiry"); ok {
+		r.RequestedExpiry = int(f)
+	}
+	if r.Scope == "" {
+		return nil, errNoScope
+	}
+	if r.hintCount() != 1 {
+		return nil, errHintCount
+	}
+	return r, nil
+}
+
+// CheckIDTokenHint verifies an "id_token_hint" previously issued by the OpenID
+// Provider (identified by issuer) to the client. Expiry is not enforced, as
+// hints commonly refer to past sessions. The subject identifies the end-user.
+func CheckIDTokenHint(token []byte, keys *jwt.KeyRegister, issuer, clientID string) (*jwt.Claims, error) {
+	claims, err := keys.Check(token)
+	if err != nil {
+		return nil, err
+	}
+	if claims.Issuer != issuer {
+		return nil, errHintIssuer
+	}
+	if len(claims.Audiences) == 0 || !claims.AcceptAudience(clientID) {
+		return nil, errHintClient
+	}
+	return claims, nil
+}
@@ -0,0 +1,108 @@
+package oauth
+
+import (
+	"encoding/json"
+	"errors"
+	"net/url"
+	"time"
+
+	"github.com/pascaldekloe/jwt"
+)
+
+// RequestParam is the query parameter with a request object.
+const RequestParam = "request"
+
+// RequestObjectType is the "typ" header value for request objects.
+const RequestObjectType = "oauth-authz-req+jwt"
+
+// RequestObjectHeader is the JOSE header addition for request object
+// production. Pass it as one of the extraHeaders to any of the jwt.Claims sign
+// methods.
+//
+// “... it is RECOMMENDED that the JWT header parameter "typ" be used with the
+// value "oauth-authz-req+jwt" ...”
+// — “JWT-Secured Authorization Request (JAR)” RFC 9101, section 4
+var RequestObjectHeader = json.RawMessage(`{"typ":"oauth-authz-req+jwt"}`)
+
+var (
+	errNoClientID     = errors.New("oauth: request object without client ID")
+	errNoAudience     = errors.New("oauth: request object without audience")
+	errNoResponseType = errors.New("oauth: request object without response type")
+	errParamOverride  = errors.New("oauth: request object parameter overrides a dedicated field")
+)
+
+// RequestObject is an authorization request, as described by “JWT-Secured
+// Authorization Request (JAR)” RFC 9101. Encryption is not supported; request
+// objects are signed only.
+type RequestObject struct {
+	// ClientID is both the "client_id" and the issuer.
+	ClientID string
+	// Audience is the issuer identifier of the authorization server.
+	Audience string
+
+	ResponseType string // response_type
+	RedirectURI  string // redirect_uri, optional
+	Scope        string // scope, optional
+	State        string // state, optional
+	Nonce        string // nonce, optional
+
+	// Params has any additional authorization request parameters.
+	// Entries are treated conform the encoding/json package.
+	Params map[string]interface{}
+}
+
+// Claims returns the claims of r, with an issue time of now and an expiry time
+// of now plus lifetime. The result is ready for any of the sign methods.
+func (r *RequestObject) Claims(now time.Time, lifetime time.Duration) (*jwt.Claims, error) {
+	switch {
+	case r.ClientID == "":
+		return nil, errNoClientID
+	case r.Audience == "":
+		return nil, errNoAudience
+	case r.ResponseType == "":
+		return nil, errNoResponseType
+	}
+
+	set := make(map[string]interface{}, len(r.Params)+6)
+	for name, value := range r.Params {
+		switch name {
+		case "client_id", "response_type", "redirect_uri", "scope", "state", "nonce", "request", "request_uri":
+			return nil, errParamOverride
+		}
+		set[name] = value
+	}
+	set["client_id"] = r.ClientID
+	set["response_type"] = r.ResponseType
+	if r.RedirectURI != "" {
+		set["redirect_uri"] = r.RedirectURI
+	}
+	if r.Scope != "" {
+		set["scope"] = r.Scope
+	}
+	if r.State != "" {
+		set["state"] = r.State
+	}
+	if r.Nonce != "" {
+		set["nonce"] = r.Nonce
+	}
+
+	// “The Request Object MAY be signed ... it SHOULD contain the Claims
+	// "iss" (issuer) and "aud" (audience) as members”
+	// — RFC 9101, section 4
+	c := &jwt.Claims{Set: set}
+	c.Issuer = r.ClientID
+	c.Audiences = []string{r.Audience}
+	c.Issued = jwt.NewNumericTime(now.Truncate(time.Second))
+	c.Expires = jwt.NewNumericTime(now.Add(lifetime).Truncate(time.Second))
+	return c, nil
+}
+
+// Query returns the authorization request parameters for a signed request
+// object token. The client ID is duplicated outside of the request object,
+// as required by RFC 9101, section 5.
+func (r *RequestObject) Query(token []byte) url.Values {
+	return url.Values{
+		"client_id":  []string{r.ClientID},
+		RequestParam: []string{string(token)},
+	}
+}
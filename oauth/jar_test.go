@@ -0,0 +1,64 @@
+package oauth
+
+import (
+	"testing"
+	"time"
+)
+
+func TestRequestObject(t *testing.T) {
+	r := RequestObject{
+		ClientID:     "s6BhdRkqt3",
+		Audience:     "https://server.example.com",
+		ResponseType: "code id_token",
+		RedirectURI:  "https://client.example.org/cb",
+		Scope:        "openid",
+		State:        "af0ifjsldkj",
+		Nonce:        "n-0S6_WzA2Mj",
+		Params:       map[string]interface{}{"max_age": 86400},
+	}
+	now := time.Unix(1537622794, 0)
+	c, err := r.Claims(now, time.Minute)
+	if err != nil {
+		t.Fatal("claims error:", err)
+	}
+	token, err := c.EdDSASign(testKey, RequestObjectHeader)
+	if err != nil {
+		t.Fatal("sign error:", err)
+	}
+
+	const wantHeader = `{"alg":"EdDSA","typ":"oauth-authz-req+jwt"}`
+	if got := string(c.RawHeader); got != wantHeader {
+		t.Errorf("got header %s, want %s", got, wantHeader)
+	}
+	const wantPayload = `{"aud":["https://server.example.com"],"client_id":"s6BhdRkqt3","exp":1537622854,"iat":1537622794,"iss":"s6BhdRkqt3","max_age":86400,"nonce":"n-0S6_WzA2Mj","redirect_uri":"https://client.example.org/cb","response_type":"code id_token","scope":"openid","state":"af0ifjsldkj"}`
+	if got := string(c.Raw); got != wantPayload {
+		t.Errorf("got payload %s, want %s", got, wantPayload)
+	}
+
+	query := r.Query(token)
+	if got := query.Get("client_id"); got != "s6BhdRkqt3" {
+		t.Errorf("got client_id %q, want s6BhdRkqt3", got)
+	}
+	if _, err := testKeys.Check([]byte(query.Get(RequestParam))); err != nil {
+		t.Error("request parameter check error:", err)
+	}
+}
+
+func TestRequestObjectErrors(t *testing.T) {
+	golden := []struct {
+		r    RequestObject
+		want error
+	}{
+		{RequestObject{Audience: "a", ResponseType: "code"}, errNoClientID},
+		{RequestObject{ClientID: "c", ResponseType: "code"}, errNoAudience},
+		{RequestObject{ClientID: "c", Audience: "a"}, errNoResponseType},
+		{RequestObject{ClientID: "c", Audience: "a", ResponseType: "code",
+			Params: map[string]interface{}{"client_id": "other"}}, errParamOverride},
+	}
+	for _, gold := range golden {
+		_, err := gold.r.Claims(time.Now(), time.Minute)
+		if err != gold.want {
+			t.Errorf("%+v got error %v, want %v", gold.r, err, gold.want)
+		}
+	}
+}
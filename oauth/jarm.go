@@ -0,0 +1,97 @@
+// Package oauth provides JWT utilities for OAuth 2.0 extensions.
+package oauth
+
+import (
+	"errors"
+	"fmt"
+	"net/url"
+	"time"
+
+	"github.com/pascaldekloe/jwt"
+)
+
+// ResponseParam is the query parameter with a JARM.
+const ResponseParam = "response"
+
+// JARMLeeway controls the tolerance with time constraints.
+var JARMLeeway = time.Minute
+
+var (
+	errNoResponse = errors.New("oauth: no response parameter in authorization response")
+	errNoExpires  = errors.New(`oauth: authorization response without expiration time ["exp"]`)
+	errIssuer     = errors.New(`oauth: authorization response issuer ["iss"] mismatch`)
+	errAudience   = errors.New(`oauth: authorization response audience ["aud"] mismatch`)
+)
+
+// AuthorizationResponse is a validated “JWT Secured Authorization Response Mode
+// for OAuth 2.0 (JARM)”.
+type AuthorizationResponse struct {
+	// Claims are the (signed) statements of the JWT.
+	*jwt.Claims
+
+	// Code is the authorization code.
+	Code string
+
+	// State is the value from the authorization request, if any.
+	State string
+}
+
+// ErrorResponse is a validated JARM with an error, as described in RFC 6749,
+// subsection 4.1.2.1.
+type ErrorResponse struct {
+	Code        string // error
+	Description string // error_description
+	URI         string // error_uri
+	State       string // state
+}
+
+// Error honors the error interface.
+func (e *ErrorResponse) Error() string {
+	if e.Description == "" {
+		return fmt.Sprintf("oauth: authorization response error %q", e.Code)
+	}
+	return fmt.Sprintf("oauth: authorization response error %q: %s", e.Code, e.Description)
+}
+
+// CheckResponse verifies the JARM from a redirect URL query. The issuer must
+// match the authorization server, and the client ID must be in the audience.
+// Authorization errors from the server are returned as an *ErrorResponse, but
+// only when the JWT checks out.
+func CheckResponse(query url.Values, keys *jwt.KeyRegister, issuer, clientID string) (*AuthorizationResponse, error) {
+	token := query.Get(ResponseParam)
+	if token == "" {
+		return nil, errNoResponse
+	}
+	claims, err := keys.Check([]byte(token))
+	if err != nil {
+		return nil, err
+	}
+
+	// “exp REQUIRED”
+	// — JARM, section 2.1
+	if claims.Expires == nil {
+		return nil, errNoExpires
+	}
+	if err := claims.AcceptTemporal(time.Now(), JARMLeeway); err != nil {
+		return nil, err
+	}
+	if claims.Issuer != issuer {
+		return nil, errIssuer
+	}
+	if len(claims.Audiences) == 0 || !claims.AcceptAudience(clientID) {
+		return nil, errAudience
+	}
+
+	if code, ok := claims.String("error"); ok {
+		e := &ErrorResponse{Code: code}
+		e.Description, _ = claims.String("error_description")
+		e.URI, _ = claims.String("error_uri")
+		e.State, _ = claims.String("state")
+		return nil, e
+	}
+
+	resp := &AuthorizationResponse{Claims: claims}
+	resp.Code, _ = claims.String("code")
+	resp.State, _ = claims.String("state")
+	return resp, nil
+}
@@ -0,0 +1,110 @@
+package oauth
+
+import (
+	"crypto/ed25519"
+	"net/url"
+	"testing"
+	"time"
+
+	"github.com/pascaldekloe/jwt"
+)
+
+var testKey = ed25519.NewKeyFromSeed(make([]byte, ed25519.SeedSize))
+
+var testKeys = &jwt.KeyRegister{EdDSAs: []ed25519.PublicKey{testKey.Public().(ed25519.PublicKey)}}
+
+func testJARM(t *testing.T, c *jwt.Claims) url.Values {
+	t.Helper()
+	token, err := c.EdDSASign(testKey)
+	if err != nil {
+		t.Fatal("sign error:", err)
+	}
+	return url.Values{ResponseParam: []string{string(token)}}
+}
+
+func TestCheckResponse(t *testing.T) {
+	c := &jwt.Claims{
+		Registered: jwt.Registered{
+			Issuer:    "https://accounts.example.com",
+			Audiences: []string{"s6BhdRkqt3"},
+			Expires:   jwt.NewNumericTime(time.Now().Add(time.Minute)),
+		},
+		Set: map[string]interface{}{
+			"code":  "PyyFaux2o7Q0YfXBU32jhw.5FXSQpvr8akv9CeRDSd0QA",
+			"state": "S8NJ7uqk5fY4EjNvP_G_FtyJu6pUsvH9jsYni9dMAJw",
+		},
+	}
+	resp, err := CheckResponse(testJARM(t, c), testKeys, "https://accounts.example.com", "s6BhdRkqt3")
+	if err != nil {
+		t.Fatal("check error:", err)
+	}
+	if resp.Code != c.Set["code"] {
+		t.Errorf("got code %q, want %q", resp.Code, c.Set["code"])
+	}
+	if resp.State != c.Set["state"] {
+		t.Errorf("got state %q, want %q", resp.State, c.Set["state"])
+	}
+}
+
+func TestCheckResponseError(t *testing.T) {
+	c := &jwt.Claims{
+		Registered: jwt.Registered{
+			Issuer:    "https://accounts.example.com",
+			Audiences: []string{"s6BhdRkqt3"},
+			Expires:   jwt.NewNumericTime(time.Now().Add(time.Minute)),
+		},
+		Set: map[string]interface{}{
+			"error": "access_denied",
+			"state": "xyz",
+		},
+	}
+	_, err := CheckResponse(testJARM(t, c), testKeys, "https://accounts.example.com", "s6BhdRkqt3")
+	e, ok := err.(*ErrorResponse)
+	if !ok {
+		t.Fatalf("got error %v, want an *ErrorResponse", err)
+	}
+	if e.Code != "access_denied" || e.State != "xyz" {
+		t.Errorf("got %+v, want code access_denied with state xyz", e)
+	}
+}
+
+func TestCheckResponseReject(t *testing.T) {
+	valid := jwt.Registered{
+		Issuer:    "https://accounts.example.com",
+		Audiences: []string{"s6BhdRkqt3"},
+		Expires:   jwt.NewNumericTime(time.Now().Add(time.Minute)),
+	}
+
+	noExpires := valid
+	noExpires.Expires = nil
+	expired := valid
+	expired.Expires = jwt.NewNumericTime(time.Now().Add(-time.Hour))
+	otherIssuer := valid
+	otherIssuer.Issuer = "https://evil.example.com"
+	noAudience := valid
+	noAudience.Audiences = nil
+	otherAudience := valid
+	otherAudience.Audiences = []string{"other"}
+
+	golden := []struct {
+		registered jwt.Registered
+		want       string
+	}{
+		{noExpires, errNoExpires.Error()},
+		{expired, `jwt: expiration time ["exp"] passed`},
+		{otherIssuer, errIssuer.Error()},
+		{noAudience, errAudience.Error()},
+		{otherAudience, errAudience.Error()},
+	}
+	for _, gold := range golden {
+		query := testJARM(t, &jwt.Claims{Registered: gold.registered})
+		_, err := CheckResponse(query, testKeys, "https://accounts.example.com", "s6BhdRkqt3")
+		if err == nil || err.Error() != gold.want {
+			t.Errorf("%+v got error %v, want %s", gold.registered, err, gold.want)
+		}
+	}
+
+	if _, err := CheckResponse(url.Values{"code": []string{"x"}}, testKeys, "", ""); err != errNoResponse {
+		t.Errorf("plain query got error %v, want %v", err, errNoResponse)
+	}
+}
@@ -0,0 +1,28 @@
+package jwt
+
+import (
+	"crypto/hmac"
+	"crypto/sha256"
+)
+
+// OpaqueKID returns a key ID derived from id with an HMAC secret. Issuers set
+// the opaque value as Claims.KeyID, such that the key naming does not leak.
+// Verifiers resolve the original with OpaqueKIDResolver.
+func OpaqueKID(secret []byte, id string) string {
+	m := hmac.New(sha256.New, secret)
+	m.Write([]byte(id))
+	return encoding.EncodeToString(m.Sum(nil)[:16])
+}
+
+// OpaqueKIDResolver returns a KeyRegister.ResolveKID for the OpaqueKID of
+// each of the key IDs.
+func OpaqueKIDResolver(secret []byte, ids ...string) func(kid string) (id string, ok bool) {
+	table := make(map[string]string, len(ids))
+	for _, id := range ids {
+		table[OpaqueKID(secret, id)] = id
+	}
+	return func(kid string) (id string, ok bool) {
+		id, ok = table[kid]
+		return
+	}
+}
@@ -0,0 +1,43 @@
+package jwt
+
+import (
+	"crypto/ed25519"
+	"testing"
+)
+
+func TestOpaqueKID(t *testing.T) {
+	secret := []byte("guest")
+	keys := KeyRegister{
+		EdDSAs:     []ed25519.PublicKey{testKeyEd25519Public},
+		EdDSAIDs:   []string{"signer-2024"},
+		ResolveKID: OpaqueKIDResolver(secret, "signer-2024", "signer-2025"),
+		StrictKID:  true,
+	}
+
+	var c Claims
+	c.KeyID = OpaqueKID(secret, "signer-2024")
+	if c.KeyID == "signer-2024" || len(c.KeyID) != 22 {
+		t.Fatalf("got opaque key ID %q", c.KeyID)
+	}
+	token, err := c.EdDSASign(testKeyEd25519Private)
+	if err != nil {
+		t.Fatal(err)
+	}
+	got, err := keys.Check(token)
+	if err != nil {
+		t.Fatal("check error:", err)
+	}
+	if got.KeyID != "signer-2024" {
+		t.Errorf("got key ID %q, want resolved signer-2024", got.KeyID)
+	}
+
+	// plain key ID is not resolved
+	c.KeyID = "nonexistent"
+	token, err = c.EdDSASign(testKeyEd25519Private)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := keys.Check(token); err != errKIDMiss {
+		t.Errorf("got error %v, want %v", err, errKIDMiss)
+	}
+}
@@ -0,0 +1,21 @@
+//go:build !jwtverifyonly
+
+package jwt
+
+import (
+	"crypto/x509"
+	"encoding/pem"
+)
+
+// ParsePrivatePEM returns the private key from a PEM block of a private key
+// type.
+func parsePrivatePEM(block *pem.Block) (interface{}, error) {
+	switch block.Type {
+	case "EC PRIVATE KEY":
+		return x509.ParseECPrivateKey(block.Bytes)
+	case "RSA PRIVATE KEY":
+		return x509.ParsePKCS1PrivateKey(block.Bytes)
+	default:
+		return x509.ParsePKCS8PrivateKey(block.Bytes)
+	}
+}
@@ -0,0 +1,15 @@
+//go:build jwtverifyonly
+
+package jwt
+
+import (
+	"encoding/pem"
+	"errors"
+)
+
+var errPrivatePEM = errors.New("jwt: private key PEM excluded from verify-only build")
+
+// ParsePrivatePEM rejects private keys, as the jwtverifyonly build tag is set.
+func parsePrivatePEM(block *pem.Block) (interface{}, error) {
+	return nil, errPrivatePEM
+}
@@ -0,0 +1,42 @@
+package jwt
+
+import "sync"
+
+// PEMTypes has the custom PEM block types by name.
+var (
+	pemTypesMutex sync.RWMutex
+	pemTypes      = make(map[string]func([]byte) (interface{}, error))
+)
+
+// RegisterPEMType makes LoadPEM accept blocks of a type, e.g., "OPENSSH PRIVATE
+// KEY" or some vendor-specific wrapper. Parse receives the block content, after
+// any decryption, and it returns a key of any type supported by the register.
+// Private keys are reduced to their public counterpart. LoadPEM overwrites both
+// the content and any private key from parse with zeros once done, so the key
+// must not share memory with the content. Registration
+// panics when the name is empty or already in use, including the types built
+// in, or when parse is nil.
+func RegisterPEMType(name string, parse func([]byte) (interface{}, error)) {
+	if name == "" || parse == nil {
+		panic("jwt: RegisterPEMType with empty name or nil parse")
+	}
+	switch name {
+	case "CERTIFICATE", "PUBLIC KEY", "PRIVATE KEY", "EC PRIVATE KEY", "RSA PRIVATE KEY":
+		panic("jwt: RegisterPEMType of built-in type " + name)
+	}
+
+	pemTypesMutex.Lock()
+	defer pemTypesMutex.Unlock()
+	if _, ok := pemTypes[name]; ok {
+		panic("jwt: RegisterPEMType called twice for type " + name)
+	}
+	pemTypes[name] = parse
+}
+
+// PEMTypeParser returns the registered parser for a PEM block type, if any.
+func pemTypeParser(name string) (parse func([]byte) (interface{}, error), ok bool) {
+	pemTypesMutex.RLock()
+	defer pemTypesMutex.RUnlock()
+	parse, ok = pemTypes[name]
+	return
+}
@@ -0,0 +1,65 @@
+package jwt
+
+import (
+	"crypto/ed25519"
+	"encoding/pem"
+	"errors"
+	"sync"
+	"testing"
+)
+
+// Registration is global, and go test may run multiple times.
+var registerTestPEMTypes sync.Once
+
+func testPEMTypes() {
+	registerTestPEMTypes.Do(func() {
+		RegisterPEMType("TEST ED25519 SEED", func(content []byte) (interface{}, error) {
+			if len(content) != ed25519.SeedSize {
+				return nil, errors.New("seed size mismatch")
+			}
+			return ed25519.NewKeyFromSeed(content), nil
+		})
+		RegisterPEMType("TEST DUPLICATE", func([]byte) (interface{}, error) { return nil, nil })
+	})
+}
+
+func TestRegisterPEMType(t *testing.T) {
+	testPEMTypes()
+
+	seed := append([]byte(nil), testKeyEd25519Private.Seed()...)
+	text := pem.EncodeToMemory(&pem.Block{Type: "TEST ED25519 SEED", Bytes: seed})
+	var keys KeyRegister
+	n, err := keys.LoadPEM(text, nil)
+	if err != nil {
+		t.Fatal("load error:", err)
+	}
+	if n != 1 || len(keys.EdDSAs) != 1 || !keys.EdDSAs[0].Equal(testKeyEd25519Public) {
+		t.Errorf("got %d keys added with EdDSAs %x, want the test key", n, keys.EdDSAs)
+	}
+
+	text = pem.EncodeToMemory(&pem.Block{Type: "TEST ED25519 SEED", Bytes: []byte("short")})
+	if _, err := keys.LoadPEM(text, nil); err == nil || err.Error() != "seed size mismatch" {
+		t.Errorf("got error %v, want the parse error", err)
+	}
+
+	text = pem.EncodeToMemory(&pem.Block{Type: "OPENSSH PRIVATE KEY", Bytes: []byte("unregistered")})
+	if _, err := keys.LoadPEM(text, nil); err == nil || err.Error() != `jwt: unknown PEM type "OPENSSH PRIVATE KEY"` {
+		t.Errorf("got error %v, want unknown PEM type", err)
+	}
+}
+
+func TestRegisterPEMTypePanics(t *testing.T) {
+	testPEMTypes()
+	parse := func([]byte) (interface{}, error) { return nil, nil }
+
+	for _, name := range []string{"", "PUBLIC KEY", "TEST DUPLICATE"} {
+		func() {
+			defer func() {
+				if recover() == nil {
+					t.Errorf("no panic for type %q", name)
+				}
+			}()
+			RegisterPEMType(name, parse)
+		}()
+	}
+}
@@ -0,0 +1,118 @@
+// Package pkcs11 provides token issuance with keys from hardware security
+// modules through PKCS#11, without any dependency on a PKCS#11 binding (cgo).
+// Private keys never leave the module. Implement the Module interface with a
+// thin wrapper around the binding of choice, e.g., “github.com/miekg/pkcs11”.
+package pkcs11
+
+import (
+	"context"
+	"crypto"
+	"crypto/ecdsa"
+	"crypto/ed25519"
+	"crypto/rsa"
+	"fmt"
+
+	"github.com/pascaldekloe/jwt"
+)
+
+// PKCS#11 Mechanism Types
+const (
+	CKM_RSA_PKCS     = 0x0001 // PKCS #1 v1.5 on a DigestInfo
+	CKM_RSA_PKCS_PSS = 0x000D // PSS on a digest
+	CKM_ECDSA        = 0x1041 // ECDSA on a digest
+	CKM_EDDSA        = 0x1057 // EdDSA on the message
+)
+
+// PKCS#11 Hash Mechanism Types and Mask-Generation Functions, for the PSS
+// parameters.
+const (
+	CKM_SHA256 = 0x0250
+	CKM_SHA384 = 0x0260
+	CKM_SHA512 = 0x0270
+
+	CKG_MGF1_SHA256 = 0x0002
+	CKG_MGF1_SHA384 = 0x0003
+	CKG_MGF1_SHA512 = 0x0004
+)
+
+// Mechanism is a PKCS#11 signing mechanism with its parameters.
+type Mechanism struct {
+	Type uint // CKM_ constant
+
+	// CK_RSA_PKCS_PSS_PARAMS for CKM_RSA_PKCS_PSS only
+	HashAlg    uint // CKM_ hash constant
+	MGF        uint // CKG_ constant
+	SaltLength uint // in bytes
+}
+
+// Module is the subset of a PKCS#11 module in use. Keys are private key objects
+// (CKO_PRIVATE_KEY) identified by their CKA_LABEL within a slot. Session
+// management, including any login, is up to the implementation.
+type Module interface {
+	// Sign returns the signature of data, as in C_SignInit followed by
+	// C_Sign. ECDSA signatures are in the raw PKCS#11 format, i.e., the
+	// concatenation of r and s.
+	Sign(ctx context.Context, slot uint, label string, m Mechanism, data []byte) (signature []byte, err error)
+
+	// PublicKey returns the public counterpart of the private key, as
+	// either an *ecdsa.PublicKey, an *rsa.PublicKey or an
+	// ed25519.PublicKey.
+	PublicKey(ctx context.Context, slot uint, label string) (crypto.PublicKey, error)
+}
+
+// Key identifies a private key on a module.
+type Key struct {
+	Slot  uint   // slot ID
+	Label string // CKA_LABEL
+
+	// KeyID is the JWT "kid". The zero value defaults to Label.
+	KeyID string
+}
+
+// Kid returns the JWT key ID.
+func (k Key) kid() string {
+	if k.KeyID != "" {
+		return k.KeyID
+	}
+	return k.Label
+}
+
+// LoadKeys adds the public key of each Key to the register, with their
+// respective key ID. Keys which are present already, with the same key ID, are
+// skipped, as with jwt.KeyRegister.Merge.
+func LoadKeys(ctx context.Context, module Module, keys *jwt.KeyRegister, objects ...Key) (keysAdded int, err error) {
+	var staged jwt.KeyRegister
+	for _, o := range objects {
+		pub, err := publicKey(ctx, module, o)
+		if err != nil {
+			return 0, err
+		}
+		switch t := pub.(type) {
+		case *ecdsa.PublicKey:
+			staged.ECDSAs = append(staged.ECDSAs, t)
+			staged.ECDSAIDs = append(staged.ECDSAIDs, o.kid())
+		case *rsa.PublicKey:
+			staged.RSAs = append(staged.RSAs, t)
+			staged.RSAIDs = append(staged.RSAIDs, o.kid())
+		case ed25519.PublicKey:
+			staged.EdDSAs = append(staged.EdDSAs, t)
+			staged.EdDSAIDs = append(staged.EdDSAIDs, o.kid())
+		}
+	}
+	return keys.Merge(&staged)
+}
+
+// PublicKey retrieves either an *ecdsa.PublicKey, an *rsa.PublicKey or an
+// ed25519.PublicKey.
+func publicKey(ctx context.Context, module Module, k Key) (crypto.PublicKey, error) {
+	pub, err := module.PublicKey(ctx, k.Slot, k.Label)
+	if err != nil {
+		return nil, fmt.Errorf("pkcs11: public key of %q in slot %d: %w", k.Label, k.Slot, err)
+	}
+	switch pub.(type) {
+	case *ecdsa.PublicKey, *rsa.PublicKey, ed25519.PublicKey:
+		return pub, nil
+	default:
+		return nil, fmt.Errorf("pkcs11: key %q in slot %d of unsupported type %T", k.Label, k.Slot, pub)
+	}
+}
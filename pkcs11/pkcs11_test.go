@@ -0,0 +1,144 @@
+package pkcs11
+
+import (
+	"context"
+	"crypto"
+	"crypto/ecdsa"
+	"crypto/ed25519"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/rsa"
+	"errors"
+	"testing"
+
+	"github.com/pascaldekloe/jwt"
+)
+
+// FakeModule serves private keys by label, in slot 1 only.
+type fakeModule map[string]crypto.Signer
+
+func (f fakeModule) key(slot uint, label string) (crypto.Signer, error) {
+	key, ok := f[label]
+	if !ok || slot != 1 {
+		return nil, errors.New("CKR_KEY_HANDLE_INVALID")
+	}
+	return key, nil
+}
+
+func (f fakeModule) PublicKey(ctx context.Context, slot uint, label string) (crypto.PublicKey, error) {
+	key, err := f.key(slot, label)
+	if err != nil {
+		return nil, err
+	}
+	return key.Public(), nil
+}
+
+func (f fakeModule) Sign(ctx context.Context, slot uint, label string, m Mechanism, data []byte) ([]byte, error) {
+	key, err := f.key(slot, label)
+	if err != nil {
+		return nil, err
+	}
+	switch m.Type {
+	case CKM_ECDSA:
+		k := key.(*ecdsa.PrivateKey)
+		r, s, err := ecdsa.Sign(rand.Reader, k, data)
+		if err != nil {
+			return nil, err
+		}
+		size := (k.Curve.Params().BitSize + 7) / 8
+		sig := make([]byte, 2*size)
+		r.FillBytes(sig[:size])
+		s.FillBytes(sig[size:])
+		return sig, nil
+	case CKM_EDDSA:
+		return ed25519.Sign(key.(ed25519.PrivateKey), data), nil
+	case CKM_RSA_PKCS:
+		// raw PKCS #1 v1.5 on the DigestInfo
+		return rsa.SignPKCS1v15(rand.Reader, key.(*rsa.PrivateKey), 0, data)
+	case CKM_RSA_PKCS_PSS:
+		hashes := map[uint]crypto.Hash{CKM_SHA256: crypto.SHA256, CKM_SHA384: crypto.SHA384, CKM_SHA512: crypto.SHA512}
+		hash := hashes[m.HashAlg]
+		if hash.Size() != int(m.SaltLength) {
+			return nil, errors.New("CKR_MECHANISM_PARAM_INVALID")
+		}
+		return rsa.SignPSS(rand.Reader, key.(*rsa.PrivateKey), hash, data, &rsa.PSSOptions{SaltLength: int(m.SaltLength)})
+	}
+	return nil, errors.New("CKR_MECHANISM_INVALID")
+}
+
+func TestSignAndLoadKeys(t *testing.T) {
+	ec384, err := ecdsa.GenerateKey(elliptic.P384(), rand.Reader)
+	if err != nil {
+		t.Fatal(err)
+	}
+	rsaKey, err := rsa.GenerateKey(rand.Reader, 2048)
+	if err != nil {
+		t.Fatal(err)
+	}
+	_, edKey, err := ed25519.GenerateKey(rand.Reader)
+	if err != nil {
+		t.Fatal(err)
+	}
+	module := fakeModule{"ec": ec384, "rsa": rsaKey, "ed": edKey}
+	ecKey := Key{Slot: 1, Label: "ec", KeyID: "hsm-ec-1"}
+	rsaRef := Key{Slot: 1, Label: "rsa"}
+	edRef := Key{Slot: 1, Label: "ed"}
+
+	var keys jwt.KeyRegister
+	n, err := LoadKeys(context.Background(), module, &keys, ecKey, rsaRef, edRef)
+	if err != nil {
+		t.Fatal("load error:", err)
+	}
+	if n != 3 {
+		t.Errorf("got %d keys added, want 3", n)
+	}
+
+	golden := []struct {
+		key   Key
+		alg   string
+		keyID string
+	}{
+		{ecKey, jwt.ES384, "hsm-ec-1"},
+		{rsaRef, jwt.RS256, "rsa"},
+		{rsaRef, jwt.RS512, "rsa"},
+		{rsaRef, jwt.PS384, "rsa"},
+		{edRef, jwt.EdDSA, "ed"},
+	}
+	for _, gold := range golden {
+		signer, err := NewSigner(context.Background(), module, gold.key)
+		if err != nil {
+			t.Fatal(err)
+		}
+		var c jwt.Claims
+		c.Subject = "test"
+		token, err := signer.Sign(context.Background(), &c, gold.alg)
+		if err != nil {
+			t.Fatalf("%s %s: sign error: %s", gold.key.Label, gold.alg, err)
+		}
+		got, err := keys.Check(token)
+		if err != nil {
+			t.Fatalf("%s %s: check error: %s", gold.key.Label, gold.alg, err)
+		}
+		if got.KeyID != gold.keyID || got.Subject != "test" {
+			t.Errorf("%s %s: got key ID %q and subject %q", gold.key.Label, gold.alg, got.KeyID, got.Subject)
+		}
+	}
+}
+
+func TestSignerErrors(t *testing.T) {
+	if _, err := NewSigner(context.Background(), fakeModule{}, Key{Slot: 1, Label: "absent"}); err == nil {
+		t.Error("no error for absent key")
+	}
+
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	if err != nil {
+		t.Fatal(err)
+	}
+	signer, err := NewSigner(context.Background(), fakeModule{"k": key}, Key{Slot: 1, Label: "k"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := signer.Sign(context.Background(), new(jwt.Claims), jwt.RS256); err != jwt.AlgError(jwt.RS256) {
+		t.Errorf("got error %v, want %v", err, jwt.AlgError(jwt.RS256))
+	}
+}
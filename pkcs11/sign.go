@@ -0,0 +1,124 @@
+//go:build !jwtverifyonly
+
+package pkcs11
+
+import (
+	"context"
+	"crypto"
+	"crypto/ecdsa"
+	"crypto/ed25519"
+	"crypto/rsa"
+	"encoding/asn1"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"math/big"
+
+	"github.com/pascaldekloe/jwt"
+)
+
+var errSigSize = errors.New("pkcs11: ECDSA signature of malformed size")
+
+// DigestInfo prefixes of PKCS #1 v1.5, as in RFC 8017, subsection 9.2.
+var digestInfoPrefixes = map[crypto.Hash][]byte{
+	crypto.SHA256: {0x30, 0x31, 0x30, 0x0d, 0x06, 0x09, 0x60, 0x86, 0x48, 0x01, 0x65, 0x03, 0x04, 0x02, 0x01, 0x05, 0x00, 0x04, 0x20},
+	crypto.SHA384: {0x30, 0x41, 0x30, 0x0d, 0x06, 0x09, 0x60, 0x86, 0x48, 0x01, 0x65, 0x03, 0x04, 0x02, 0x02, 0x05, 0x00, 0x04, 0x30},
+	crypto.SHA512: {0x30, 0x51, 0x30, 0x0d, 0x06, 0x09, 0x60, 0x86, 0x48, 0x01, 0x65, 0x03, 0x04, 0x02, 0x03, 0x05, 0x00, 0x04, 0x40},
+}
+
+// PSS parameters per hash function.
+var pssMechanisms = map[crypto.Hash]Mechanism{
+	crypto.SHA256: {Type: CKM_RSA_PKCS_PSS, HashAlg: CKM_SHA256, MGF: CKG_MGF1_SHA256, SaltLength: 32},
+	crypto.SHA384: {Type: CKM_RSA_PKCS_PSS, HashAlg: CKM_SHA384, MGF: CKG_MGF1_SHA384, SaltLength: 48},
+	crypto.SHA512: {Type: CKM_RSA_PKCS_PSS, HashAlg: CKM_SHA512, MGF: CKG_MGF1_SHA512, SaltLength: 64},
+}
+
+// Signer issues tokens with a PKCS#11 key. Multiple goroutines may invoke
+// methods on a Signer simultaneously, given a Module which supports so.
+type Signer struct {
+	module Module
+	key    Key
+	public crypto.PublicKey
+}
+
+// NewSigner returns a Signer for the key. The public key is retrieved once.
+func NewSigner(ctx context.Context, module Module, key Key) (*Signer, error) {
+	pub, err := publicKey(ctx, module, key)
+	if err != nil {
+		return nil, err
+	}
+	return &Signer{module: module, key: key, public: pub}, nil
+}
+
+// KeyID returns the JWT key ID, which matches the one from LoadKeys.
+func (s *Signer) KeyID() string { return s.key.kid() }
+
+// Public returns the public key, which is either an *ecdsa.PublicKey, an
+// *rsa.PublicKey or an ed25519.PublicKey.
+func (s *Signer) Public() crypto.PublicKey { return s.public }
+
+// Sign updates the Raw fields and returns a new JWT, signed by the module.
+// Claims without a KeyID get the one from Signer. See jwt.Claims.SignWith for
+// details.
+func (s *Signer) Sign(ctx context.Context, c *jwt.Claims, alg string, extraHeaders ...json.RawMessage) (token []byte, err error) {
+	if c.KeyID == "" {
+		c.KeyID = s.key.kid()
+	}
+	return c.SignWith(alg, &moduleSigner{ctx: ctx, signer: s}, extraHeaders...)
+}
+
+// ModuleSigner binds a context to crypto.Signer.
+type moduleSigner struct {
+	ctx    context.Context
+	signer *Signer
+}
+
+// Public implements crypto.Signer.
+func (m *moduleSigner) Public() crypto.PublicKey { return m.signer.public }
+
+// Sign implements crypto.Signer.
+func (m *moduleSigner) Sign(_ io.Reader, digest []byte, opts crypto.SignerOpts) ([]byte, error) {
+	var mech Mechanism
+	data := digest
+	switch m.signer.public.(type) {
+	case *ecdsa.PublicKey:
+		mech.Type = CKM_ECDSA
+	case ed25519.PublicKey:
+		mech.Type = CKM_EDDSA // digest is the message
+	case *rsa.PublicKey:
+		hash := opts.HashFunc()
+		if _, ok := opts.(*rsa.PSSOptions); ok {
+			var ok bool
+			mech, ok = pssMechanisms[hash]
+			if !ok {
+				return nil, fmt.Errorf("pkcs11: PSS with unsupported hash %s", hash)
+			}
+		} else {
+			prefix, ok := digestInfoPrefixes[hash]
+			if !ok {
+				return nil, fmt.Errorf("pkcs11: PKCS #1 v1.5 with unsupported hash %s", hash)
+			}
+			mech.Type = CKM_RSA_PKCS
+			data = append(append(make([]byte, 0, len(prefix)+len(digest)), prefix...), digest...)
+		}
+	}
+
+	k := m.signer.key
+	sig, err := m.signer.module.Sign(m.ctx, k.Slot, k.Label, mech, data)
+	if err != nil {
+		return nil, fmt.Errorf("pkcs11: sign with %q in slot %d: %w", k.Label, k.Slot, err)
+	}
+
+	if mech.Type == CKM_ECDSA {
+		// crypto.Signer convention is ASN.1
+		if len(sig) == 0 || len(sig)%2 != 0 {
+			return nil, errSigSize
+		}
+		return asn1.Marshal(struct{ R, S *big.Int }{
+			R: new(big.Int).SetBytes(sig[:len(sig)/2]),
+			S: new(big.Int).SetBytes(sig[len(sig)/2:]),
+		})
+	}
+	return sig, nil
+}
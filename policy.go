@@ -0,0 +1,561 @@
+package jwt
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+// PolicyError signals a policy statement which does not hold.
+type PolicyError string
+
+// Error honors the error interface.
+func (e PolicyError) Error() string {
+	return fmt.Sprintf("jwt: policy %q not met", string(e))
+}
+
+// Policy is a set of acceptance rules, compiled from expressions such as
+//
+//	iss == "idp" && "admin" in roles && exp - iat <= 3600
+//
+// Identifiers resolve to claims by name, with the registered claims in their
+// JSON representation: "iss", "sub" and "jti" are strings, "aud" is an array of
+// strings, and "exp", "nbf" and "iat" are numbers. Dots select members of JSON
+// objects, e.g., "realm_access.roles", unless a claim has the name as is. The
+// identifier "now" is the current time from Clock, in seconds since the epoch.
+// Literals are numbers, strings in JSON notation, arrays of literals in square
+// brackets, true and false.
+//
+// Operators in order of precedence are "!" (negation), "+" and "-" (numbers
+// only), "==", "!=", "<", "<=", ">", ">=" (numbers only) and "in", then "&&",
+// and finally "||", with parenthesis for grouping. The "in" operator tests
+// membership of an array, or membership of a space-separated string, as with
+// the "scope" claim. Claims which are absent, and values of another type, are
+// equal to nothing. Each statement must evaluate to true.
+//
+// The zero value accepts any claims. Multiple goroutines may invoke methods on
+// a Policy simultaneously.
+type Policy struct {
+	rules []policyRule
+}
+
+type policyRule struct {
+	statement string
+	eval      policyFunc
+}
+
+// PolicyFunc evaluates to nil, bool, float64, string or []interface{}, plus
+// any other type from JSON for claims.
+type policyFunc func(c *Claims, now float64) interface{}
+
+// CompilePolicy returns a Policy of all statements.
+func CompilePolicy(statements ...string) (*Policy, error) {
+	p := new(Policy)
+	for _, s := range statements {
+		eval, err := compilePolicyStatement(s)
+		if err != nil {
+			return nil, err
+		}
+		p.rules = append(p.rules, policyRule{statement: s, eval: eval})
+	}
+	return p, nil
+}
+
+// Accept verifies c against each statement. The return is a PolicyError for
+// the first statement which does not hold.
+func (p *Policy) Accept(c *Claims) error {
+	if len(p.rules) == 0 {
+		return nil
+	}
+	now := float64(Clock().UnixNano()) / 1e9
+	for _, r := range p.rules {
+		if r.eval(c, now) != true {
+			return PolicyError(r.statement)
+		}
+	}
+	return nil
+}
+
+// String returns the statements, one per line.
+func (p *Policy) String() string {
+	var buf strings.Builder
+	for i, r := range p.rules {
+		if i != 0 {
+			buf.WriteByte('\n')
+		}
+		buf.WriteString(r.statement)
+	}
+	return buf.String()
+}
+
+// MarshalText implements encoding.TextMarshaler.
+func (p *Policy) MarshalText() ([]byte, error) {
+	return []byte(p.String()), nil
+}
+
+// UnmarshalText implements encoding.TextUnmarshaler with one statement per
+// line. Empty lines and lines which start with a '#' are ignored.
+func (p *Policy) UnmarshalText(text []byte) error {
+	var statements []string
+	for _, line := range strings.Split(string(text), "\n") {
+		line = strings.TrimSpace(line)
+		if line != "" && line[0] != '#' {
+			statements = append(statements, line)
+		}
+	}
+	compiled, err := CompilePolicy(statements...)
+	if err != nil {
+		return err
+	}
+	*p = *compiled
+	return nil
+}
+
+var errPolicyEnd = errors.New("unexpected end of statement")
+
+// PolicyParser is a recursive descent over the tokens of a statement.
+type policyParser struct {
+	src string
+	pos int    // read index in src
+	tok string // current token, or empty at the end
+	at  int    // offset of tok in src
+}
+
+func compilePolicyStatement(s string) (policyFunc, error) {
+	p := policyParser{src: s}
+	f, err := p.parse()
+	if err != nil {
+		return nil, fmt.Errorf("jwt: policy %q: %w", s, err)
+	}
+	return f, nil
+}
+
+func (p *policyParser) parse() (policyFunc, error) {
+	if err := p.next(); err != nil {
+		return nil, err
+	}
+	if p.tok == "" {
+		return nil, errPolicyEnd
+	}
+	f, err := p.or()
+	if err != nil {
+		return nil, err
+	}
+	if p.tok != "" {
+		return nil, p.unexpected()
+	}
+	return f, nil
+}
+
+func (p *policyParser) unexpected() error {
+	if p.tok == "" {
+		return errPolicyEnd
+	}
+	return fmt.Errorf("unexpected %q at offset %d", p.tok, p.at)
+}
+
+// Next reads the following token into tok.
+func (p *policyParser) next() error {
+	for p.pos < len(p.src) && (p.src[p.pos] == ' ' || p.src[p.pos] == '\t') {
+		p.pos++
+	}
+	p.at = p.pos
+	if p.pos >= len(p.src) {
+		p.tok = ""
+		return nil
+	}
+
+	s := p.src[p.pos:]
+	switch c := s[0]; {
+	case c == '"':
+		i := 1
+		for ; i < len(s) && s[i] != '"'; i++ {
+			if s[i] == '\\' {
+				i++
+			}
+		}
+		if i >= len(s) {
+			return fmt.Errorf("unterminated string at offset %d", p.at)
+		}
+		p.tok = s[:i+1]
+
+	case c >= '0' && c <= '9' || c == '.':
+		i := 1
+		for i < len(s) && (s[i] >= '0' && s[i] <= '9' || s[i] == '.' || s[i] == 'e' || s[i] == 'E') {
+			i++
+		}
+		p.tok = s[:i]
+
+	case c == '_' || c >= 'a' && c <= 'z' || c >= 'A' && c <= 'Z':
+		i := 1
+		for i < len(s) && (s[i] == '_' || s[i] == '.' || s[i] >= '0' && s[i] <= '9' || s[i] >= 'a' && s[i] <= 'z' || s[i] >= 'A' && s[i] <= 'Z') {
+			i++
+		}
+		p.tok = s[:i]
+
+	default:
+		for _, op := range [...]string{"==", "!=", "<=", ">=", "&&", "||"} {
+			if strings.HasPrefix(s, op) {
+				p.tok = op
+				p.pos += len(op)
+				return nil
+			}
+		}
+		if !strings.ContainsRune("!<>+-()[],", rune(c)) {
+			return fmt.Errorf("unexpected character %q at offset %d", c, p.at)
+		}
+		p.tok = s[:1]
+	}
+	p.pos += len(p.tok)
+	return nil
+}
+
+func (p *policyParser) or() (policyFunc, error) {
+	f, err := p.and()
+	if err != nil {
+		return nil, err
+	}
+	for p.tok == "||" {
+		if err := p.next(); err != nil {
+			return nil, err
+		}
+		g, err := p.and()
+		if err != nil {
+			return nil, err
+		}
+		f = policyOr(f, g)
+	}
+	return f, nil
+}
+
+func policyOr(f, g policyFunc) policyFunc {
+	return func(c *Claims, now float64) interface{} {
+		return f(c, now) == true || g(c, now) == true
+	}
+}
+
+func (p *policyParser) and() (policyFunc, error) {
+	f, err := p.comparison()
+	if err != nil {
+		return nil, err
+	}
+	for p.tok == "&&" {
+		if err := p.next(); err != nil {
+			return nil, err
+		}
+		g, err := p.comparison()
+		if err != nil {
+			return nil, err
+		}
+		f = policyAnd(f, g)
+	}
+	return f, nil
+}
+
+func policyAnd(f, g policyFunc) policyFunc {
+	return func(c *Claims, now float64) interface{} {
+		return f(c, now) == true && g(c, now) == true
+	}
+}
+
+func (p *policyParser) comparison() (policyFunc, error) {
+	f, err := p.sum()
+	if err != nil {
+		return nil, err
+	}
+	op := p.tok
+	switch op {
+	case "==", "!=", "<", "<=", ">", ">=", "in":
+		break
+	default:
+		return f, nil
+	}
+	if err := p.next(); err != nil {
+		return nil, err
+	}
+	g, err := p.sum()
+	if err != nil {
+		return nil, err
+	}
+
+	switch op {
+	case "==":
+		return func(c *Claims, now float64) interface{} {
+			return policyEqual(f(c, now), g(c, now))
+		}, nil
+	case "!=":
+		return func(c *Claims, now float64) interface{} {
+			return !policyEqual(f(c, now), g(c, now))
+		}, nil
+	case "in":
+		return func(c *Claims, now float64) interface{} {
+			return policyIn(f(c, now), g(c, now))
+		}, nil
+	}
+	return func(c *Claims, now float64) interface{} {
+		x, ok := f(c, now).(float64)
+		if !ok {
+			return false
+		}
+		y, ok := g(c, now).(float64)
+		if !ok {
+			return false
+		}
+		switch op {
+		case "<":
+			return x < y
+		case "<=":
+			return x <= y
+		case ">":
+			return x > y
+		default:
+			return x >= y
+		}
+	}, nil
+}
+
+// PolicyEqual compares strings, numbers and booleans.
+func policyEqual(x, y interface{}) bool {
+	switch x.(type) {
+	case string, float64, bool:
+		return x == y
+	}
+	return false
+}
+
+// PolicyIn tests membership of an array or a space-separated string.
+func policyIn(x, set interface{}) bool {
+	switch set := set.(type) {
+	case []interface{}:
+		for _, e := range set {
+			if policyEqual(x, e) {
+				return true
+			}
+		}
+	case string:
+		s, ok := x.(string)
+		if !ok || s == "" {
+			return false
+		}
+		for _, e := range strings.Fields(set) {
+			if e == s {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+func (p *policyParser) sum() (policyFunc, error) {
+	f, err := p.unary()
+	if err != nil {
+		return nil, err
+	}
+	for p.tok == "+" || p.tok == "-" {
+		minus := p.tok == "-"
+		if err := p.next(); err != nil {
+			return nil, err
+		}
+		g, err := p.unary()
+		if err != nil {
+			return nil, err
+		}
+		f = policySum(f, g, minus)
+	}
+	return f, nil
+}
+
+func policySum(f, g policyFunc, minus bool) policyFunc {
+	return func(c *Claims, now float64) interface{} {
+		x, ok := f(c, now).(float64)
+		if !ok {
+			return nil
+		}
+		y, ok := g(c, now).(float64)
+		if !ok {
+			return nil
+		}
+		if minus {
+			return x - y
+		}
+		return x + y
+	}
+}
+
+func (p *policyParser) unary() (policyFunc, error) {
+	if p.tok != "!" {
+		return p.operand()
+	}
+	if err := p.next(); err != nil {
+		return nil, err
+	}
+	f, err := p.unary()
+	if err != nil {
+		return nil, err
+	}
+	return func(c *Claims, now float64) interface{} {
+		return f(c, now) != true
+	}, nil
+}
+
+func (p *policyParser) operand() (policyFunc, error) {
+	switch tok := p.tok; {
+	case tok == "(":
+		if err := p.next(); err != nil {
+			return nil, err
+		}
+		f, err := p.or()
+		if err != nil {
+			return nil, err
+		}
+		if p.tok != ")" {
+			return nil, p.unexpected()
+		}
+		return f, p.next()
+
+	case tok == "[", tok == "-", tok == "true", tok == "false",
+		tok != "" && (tok[0] == '"' || tok[0] == '.' || tok[0] >= '0' && tok[0] <= '9'):
+		v, err := p.literal()
+		if err != nil {
+			return nil, err
+		}
+		return func(*Claims, float64) interface{} { return v }, nil
+
+	case tok == "now":
+		return func(_ *Claims, now float64) interface{} { return now }, p.next()
+
+	case tok == "in", tok == "":
+		return nil, p.unexpected()
+
+	case tok[0] == '_' || tok[0] >= 'a' && tok[0] <= 'z' || tok[0] >= 'A' && tok[0] <= 'Z':
+		return policyClaim(tok), p.next()
+	}
+	return nil, p.unexpected()
+}
+
+// Literal reads a constant.
+func (p *policyParser) literal() (interface{}, error) {
+	switch tok := p.tok; {
+	case tok == "true", tok == "false":
+		return tok == "true", p.next()
+
+	case tok == "-":
+		if err := p.next(); err != nil {
+			return nil, err
+		}
+		at := p.at
+		v, err := p.literal()
+		if err != nil {
+			return nil, err
+		}
+		f, ok := v.(float64)
+		if !ok {
+			return nil, fmt.Errorf("negation of non-number at offset %d", at)
+		}
+		return -f, nil
+
+	case tok == "[":
+		a := []interface{}{}
+		if err := p.next(); err != nil {
+			return nil, err
+		}
+		for p.tok != "]" {
+			if len(a) != 0 {
+				if p.tok != "," {
+					return nil, p.unexpected()
+				}
+				if err := p.next(); err != nil {
+					return nil, err
+				}
+			}
+			v, err := p.literal()
+			if err != nil {
+				return nil, err
+			}
+			a = append(a, v)
+		}
+		return a, p.next()
+
+	case tok != "" && tok[0] == '"':
+		var s string
+		if err := json.Unmarshal([]byte(tok), &s); err != nil {
+			return nil, fmt.Errorf("malformed string at offset %d: %w", p.at, err)
+		}
+		return s, p.next()
+
+	case tok != "" && (tok[0] == '.' || tok[0] >= '0' && tok[0] <= '9'):
+		f, err := strconv.ParseFloat(tok, 64)
+		if err != nil {
+			return nil, fmt.Errorf("malformed number at offset %d", p.at)
+		}
+		return f, p.next()
+	}
+	return nil, p.unexpected()
+}
+
+// PolicyClaim resolves a claim by name.
+func policyClaim(name string) policyFunc {
+	switch name {
+	case issuer, subject, id:
+		return func(c *Claims, _ float64) interface{} {
+			var s string
+			switch name {
+			case issuer:
+				s = c.Issuer
+			case subject:
+				s = c.Subject
+			default:
+				s = c.ID
+			}
+			if s == "" {
+				return c.Set[name]
+			}
+			return s
+		}
+	case audience:
+		return func(c *Claims, _ float64) interface{} {
+			if len(c.Audiences) == 0 {
+				return c.Set[name]
+			}
+			a := make([]interface{}, len(c.Audiences))
+			for i, s := range c.Audiences {
+				a[i] = s
+			}
+			return a
+		}
+	case expires, notBefore, issued:
+		return func(c *Claims, _ float64) interface{} {
+			var t *NumericTime
+			switch name {
+			case expires:
+				t = c.Expires
+			case notBefore:
+				t = c.NotBefore
+			default:
+				t = c.Issued
+			}
+			if t == nil {
+				return c.Set[name]
+			}
+			return float64(*t)
+		}
+	}
+	path := strings.Split(name, ".")
+	return func(c *Claims, _ float64) interface{} {
+		v, ok := c.Set[name]
+		if ok || len(path) == 1 {
+			return v
+		}
+		v = c.Set[path[0]]
+		for _, key := range path[1:] {
+			m, ok := v.(map[string]interface{})
+			if !ok {
+				return nil
+			}
+			v = m[key]
+		}
+		return v
+	}
+}
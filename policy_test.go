@@ -0,0 +1,127 @@
+package jwt
+
+import (
+	"encoding/json"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestPolicy(t *testing.T) {
+	defer func() { Clock = time.Now }()
+	Clock = func() time.Time { return time.Unix(1500, 0) }
+
+	var c Claims
+	c.Issuer = "idp"
+	c.Audiences = []string{"api", "web"}
+	c.Issued = NewNumericTime(time.Unix(1000, 0))
+	c.Expires = NewNumericTime(time.Unix(4000, 0))
+	c.Set = map[string]interface{}{
+		"roles":          []interface{}{"admin", "dev"},
+		"scope":          "read write",
+		"email_verified": true,
+		"level":          3.0,
+		"realm_access":   map[string]interface{}{"roles": []interface{}{"ops"}},
+	}
+
+	golden := []struct {
+		statement string
+		want      bool
+	}{
+		{`iss == "idp" && "admin" in roles && exp - iat <= 3600`, true},
+		{`iss == "idp" && "admin" in roles && exp - iat <= 2999`, false},
+		{`iss != "idp"`, false},
+		{`sub == ""`, false},
+		{`sub != "x"`, true},
+		{`"api" in aud`, true},
+		{`"mobile" in aud`, false},
+		{`"write" in scope`, true},
+		{`"wr" in scope`, false},
+		{`iss in ["other", "idp"]`, true},
+		{`level >= 3 && level < 3.5`, true},
+		{`level > 3 || !email_verified`, false},
+		{`email_verified`, true},
+		{`!(email_verified && level == 3)`, false},
+		{`nbf <= now`, false},
+		{`iat + 600 > now`, true},
+		{`exp > now`, true},
+		{`level - -1 == 4`, true},
+		{`"ops" in realm_access.roles`, true},
+		{`realm_access.absent.deep == 1`, false},
+		{`iss < 1`, false},
+		{`absent + 1 > 0`, false},
+		{`roles == roles`, false},
+		{`true`, true},
+	}
+	for _, gold := range golden {
+		p, err := CompilePolicy(gold.statement)
+		if err != nil {
+			t.Errorf("%s: compile error: %s", gold.statement, err)
+			continue
+		}
+		err = p.Accept(&c)
+		switch {
+		case gold.want && err != nil:
+			t.Errorf("%s: got error %v", gold.statement, err)
+		case !gold.want && err != PolicyError(gold.statement):
+			t.Errorf("%s: got error %v, want %v", gold.statement, err, PolicyError(gold.statement))
+		}
+	}
+}
+
+func TestPolicyCompileErrors(t *testing.T) {
+	golden := []struct {
+		statement string
+		want      string
+	}{
+		{``, "unexpected end of statement"},
+		{`iss ==`, "unexpected end of statement"},
+		{`iss = "idp"`, `unexpected character '=' at offset 4`},
+		{`iss == "idp`, "unterminated string at offset 7"},
+		{`(iss == "idp"`, "unexpected end of statement"},
+		{`iss == "idp")`, `unexpected ")" at offset 12`},
+		{`iss in ["a" "b"]`, `unexpected "\"b\"" at offset 12`},
+		{`level == 1.2.3`, "malformed number at offset 9"},
+		{`in roles`, `unexpected "in" at offset 0`},
+		{`exp - -iat > 0`, `unexpected "iat" at offset 7`},
+		{`level == -"x"`, "negation of non-number at offset 10"},
+	}
+	for _, gold := range golden {
+		_, err := CompilePolicy(gold.statement)
+		if err == nil {
+			t.Errorf("%s: no compile error", gold.statement)
+			continue
+		}
+		if !strings.HasSuffix(err.Error(), gold.want) {
+			t.Errorf("%s: got error %q, want suffix %q", gold.statement, err, gold.want)
+		}
+	}
+}
+
+func TestPolicyConfig(t *testing.T) {
+	var config struct {
+		Expect struct {
+			Policy *Policy `json:"policy"`
+		} `json:"expect"`
+	}
+	const text = `{"expect": {"policy": "# issuer\niss == \"idp\"\n\n\"admin\" in roles\n"}}`
+	if err := json.Unmarshal([]byte(text), &config); err != nil {
+		t.Fatal(err)
+	}
+	p := config.Expect.Policy
+	if got, want := p.String(), "iss == \"idp\"\n\"admin\" in roles"; got != want {
+		t.Errorf("got statements %q, want %q", got, want)
+	}
+
+	var c Claims
+	c.Issuer = "idp"
+	c.Set = map[string]interface{}{"roles": []interface{}{"dev"}}
+	e := Expect{Policy: p}
+	if err := e.Accept(&c); err != PolicyError(`"admin" in roles`) {
+		t.Errorf("got error %v, want %v", err, PolicyError(`"admin" in roles`))
+	}
+	c.Set["roles"] = []interface{}{"admin"}
+	if err := e.Accept(&c); err != nil {
+		t.Errorf("got error %v", err)
+	}
+}
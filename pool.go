@@ -0,0 +1,91 @@
+package jwt
+
+import (
+	"encoding/json"
+	"sync"
+)
+
+// PoolOp is an object pool operation.
+type PoolOp int
+
+// Pool operations.
+const (
+	PoolGet PoolOp = iota // acquisition
+	PoolNew               // allocation, due to an empty pool on acquisition
+	PoolPut               // return
+)
+
+// PoolHMAC is the pool name of the digests from HMAC instances.
+const PoolHMAC = "HMAC"
+
+// PoolHook is invoked for each operation on the internal object pools. Note
+// that the hook is on the hot path. Nil disables. See PoolStats for a collector.
+var PoolHook func(pool string, op PoolOp)
+
+func poolEvent(pool string, op PoolOp) {
+	if f := PoolHook; f != nil {
+		f(pool, op)
+	}
+}
+
+// PoolStat has the operation counts of an object pool. A high ratio of News to
+// Gets indicates pool misses, e.g., due to garbage collection between bursts.
+type PoolStat struct {
+	Gets uint64
+	News uint64
+	Puts uint64
+}
+
+// PoolStats collects operation counts per pool. The zero value is ready for
+// use. Install with jwt.PoolHook = stats.Observe. PoolStats honors the expvar.Var
+// interface, i.e., expvar.Publish("jwt_pools", stats) exposes the counts.
+//
+// Multiple goroutines may invoke methods on a PoolStats simultaneously.
+type PoolStats struct {
+	mutex   sync.Mutex
+	perPool map[string]*PoolStat
+}
+
+// Observe records an operation on pool. The signature matches PoolHook.
+func (stats *PoolStats) Observe(pool string, op PoolOp) {
+	stats.mutex.Lock()
+	defer stats.mutex.Unlock()
+
+	s, ok := stats.perPool[pool]
+	if !ok {
+		if stats.perPool == nil {
+			stats.perPool = make(map[string]*PoolStat)
+		}
+		s = new(PoolStat)
+		stats.perPool[pool] = s
+	}
+	switch op {
+	case PoolGet:
+		s.Gets++
+	case PoolNew:
+		s.News++
+	case PoolPut:
+		s.Puts++
+	}
+}
+
+// Snapshot returns a copy of the statistics per pool.
+func (stats *PoolStats) Snapshot() map[string]PoolStat {
+	stats.mutex.Lock()
+	defer stats.mutex.Unlock()
+
+	m := make(map[string]PoolStat, len(stats.perPool))
+	for pool, s := range stats.perPool {
+		m[pool] = *s
+	}
+	return m
+}
+
+// String returns the Snapshot in JSON, conform the expvar.Var interface.
+func (stats *PoolStats) String() string {
+	bytes, err := json.Marshal(stats.Snapshot())
+	if err != nil {
+		return "{}" // unreachable
+	}
+	return string(bytes)
+}
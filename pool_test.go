@@ -0,0 +1,38 @@
+package jwt
+
+import (
+	"encoding/json"
+	"testing"
+)
+
+func TestPoolStats(t *testing.T) {
+	defer func() { PoolHook = nil }()
+	stats := new(PoolStats)
+	PoolHook = stats.Observe
+
+	h, err := NewHMAC(HS256, []byte("guest"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	for i := 0; i < 3; i++ {
+		if _, err := h.Sign(new(Claims)); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	got := stats.Snapshot()[PoolHMAC]
+	if got.Gets != 3 || got.Puts != 3 {
+		t.Errorf("got %d gets and %d puts, want 3 each", got.Gets, got.Puts)
+	}
+	if got.News < 1 || got.News > 3 {
+		t.Errorf("got %d news, want 1–3", got.News)
+	}
+
+	var m map[string]PoolStat
+	if err := json.Unmarshal([]byte(stats.String()), &m); err != nil {
+		t.Fatal("expvar string:", err)
+	}
+	if m[PoolHMAC] != got {
+		t.Errorf("expvar string got %+v, want %+v", m[PoolHMAC], got)
+	}
+}
@@ -0,0 +1,69 @@
+package jwt
+
+import (
+	"encoding/json"
+	"reflect"
+	"sort"
+)
+
+// IsSigned returns whether the claim with name has the value from the signed
+// payload in Raw. Claims added or changed after the signature check, such as
+// with Handler Mapper or Enrich, are derived rather than signed. Authorization
+// decisions may require signed claims for their integrity. Raw is verified by
+// the Check functions only; see ParseWithoutCheck.
+func (c *Claims) IsSigned(name string) bool {
+	signed, err := c.signedClaims()
+	if err != nil {
+		return false
+	}
+	raw, ok := signed[name]
+	if !ok {
+		return false
+	}
+	v, ok := c.All()[name]
+	if !ok {
+		return false
+	}
+	return sameJSON(name, raw, v)
+}
+
+// Derived returns the names of claims which do not have the value from the
+// signed payload in Raw, in alphabetical order. See IsSigned for details.
+func (c *Claims) Derived() []string {
+	signed, _ := c.signedClaims()
+	var names []string
+	for name, v := range c.All() {
+		raw, ok := signed[name]
+		if !ok || !sameJSON(name, raw, v) {
+			names = append(names, name)
+		}
+	}
+	sort.Strings(names)
+	return names
+}
+
+func (c *Claims) signedClaims() (map[string]interface{}, error) {
+	var signed map[string]interface{}
+	if len(c.Raw) == 0 {
+		return nil, errNoPayload
+	}
+	err := json.Unmarshal([]byte(c.Raw), &signed)
+	return signed, err
+}
+
+// SameJSON returns whether v has the same JSON representation as raw, which
+// is the decoded signed value.
+func sameJSON(name string, raw, v interface{}) bool {
+	if s, ok := raw.(string); ok && name == audience {
+		raw = []interface{}{s} // normalized by All
+	}
+	bytes, err := json.Marshal(v)
+	if err != nil {
+		return false
+	}
+	var normalized interface{}
+	if err := json.Unmarshal(bytes, &normalized); err != nil {
+		return false
+	}
+	return reflect.DeepEqual(raw, normalized)
+}
@@ -0,0 +1,53 @@
+package jwt
+
+import (
+	"context"
+	"reflect"
+	"testing"
+)
+
+func TestIsSigned(t *testing.T) {
+	var c Claims
+	c.Subject = "alice"
+	c.Audiences = []string{"svc"}
+	c.Set = map[string]interface{}{"roles": []interface{}{"user"}, "permissions": "r"}
+	token, err := c.HMACSign(HS256, []byte("guest"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	keys := KeyRegister{Secrets: [][]byte{[]byte("guest")}}
+	got, err := keys.Check(token)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// derive
+	if err := RenameClaim("permissions", "scope").MapClaims(got); err != nil {
+		t.Fatal(err)
+	}
+	cache := EnrichCache{Source: func(ctx context.Context, c *Claims) error {
+		c.Set["tenant"] = "t1"
+		return nil
+	}, Claims: []string{"tenant"}}
+	if err := cache.Enrich(context.Background(), got); err != nil {
+		t.Fatal(err)
+	}
+	got.Set["roles"] = append(got.Set["roles"].([]interface{}), "admin")
+
+	for name, want := range map[string]bool{
+		"sub":         true,
+		"aud":         true,
+		"permissions": false,
+		"scope":       false,
+		"tenant":      false,
+		"roles":       false,
+		"absent":      false,
+	} {
+		if got := got.IsSigned(name); got != want {
+			t.Errorf("IsSigned(%q) got %t, want %t", name, got, want)
+		}
+	}
+	if names, want := got.Derived(), []string{"roles", "scope", "tenant"}; !reflect.DeepEqual(names, want) {
+		t.Errorf("got derived %q, want %q", names, want)
+	}
+}
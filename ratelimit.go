@@ -0,0 +1,63 @@
+package jwt
+
+import (
+	"crypto/hmac"
+	"crypto/sha256"
+	"errors"
+	"hash"
+)
+
+var errRateLimitClaim = errors.New("jwt: rate limit claim absent")
+
+// RateLimitKey derives a stable key from verified claims for rate limiting.
+// Keys are hashed, such that limiters, logs and metrics do not see subjects.
+// The issuer is part of the hash, as subjects are unique per issuer only.
+type RateLimitKey struct {
+	// Claim is the source of the key. The empty string defaults
+	// to "sub". The claim must be a string.
+	Claim string
+
+	// Secret keys the hash with HMAC-SHA256, when set, which prevents
+	// the derivation of keys for known subjects. Plain SHA-256 is used
+	// otherwise.
+	Secret []byte
+
+	// Header receives the key on each request passed to Target by a
+	// Handler, when set. The name must match any HeaderPrefix.
+	Header string
+
+	// ContextKey places the key, as a string, in the context of each
+	// request passed to Target by a Handler, when set.
+	ContextKey interface{}
+}
+
+func (k *RateLimitKey) claim() string {
+	if k.Claim == "" {
+		return subject
+	}
+	return k.Claim
+}
+
+// Key returns the rate limit key for c. The return is false when the claim is
+// absent, empty or not a string.
+func (k *RateLimitKey) Key(c *Claims) (key string, ok bool) {
+	name := k.claim()
+	value, ok := c.String(name)
+	if !ok || value == "" {
+		return "", false
+	}
+
+	var digest hash.Hash
+	if len(k.Secret) != 0 {
+		digest = hmac.New(sha256.New, k.Secret)
+	} else {
+		digest = sha256.New()
+	}
+	digest.Write([]byte(c.Issuer))
+	digest.Write([]byte{0})
+	digest.Write([]byte(name))
+	digest.Write([]byte{0})
+	digest.Write([]byte(value))
+	// 128 bits suffice for keys
+	return encoding.EncodeToString(digest.Sum(nil)[:16]), true
+}
@@ -0,0 +1,87 @@
+package jwt
+
+import (
+	"crypto/ed25519"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+func TestRateLimitKey(t *testing.T) {
+	var c Claims
+	c.Issuer = "https://issuer.example.com"
+	c.Subject = "alice"
+	c.Set = map[string]interface{}{"azp": "app1", "n": 42.0}
+
+	var k RateLimitKey
+	key, ok := k.Key(&c)
+	if !ok || len(key) != 22 {
+		t.Fatalf("got key %q (%t), want 22 characters", key, ok)
+	}
+	if again, _ := k.Key(&c); again != key {
+		t.Errorf("got key %q, then %q", key, again)
+	}
+
+	other := c
+	other.Issuer = "https://other.example.com"
+	if got, _ := k.Key(&other); got == key {
+		t.Error("same key for other issuer")
+	}
+	if got, _ := (&RateLimitKey{Secret: []byte("guest")}).Key(&c); got == key {
+		t.Error("same key with secret")
+	}
+	if got, ok := (&RateLimitKey{Claim: "azp"}).Key(&c); !ok || got == key {
+		t.Errorf("azp claim got key %q (%t)", got, ok)
+	}
+
+	for _, name := range []string{"n", "absent"} {
+		if _, ok := (&RateLimitKey{Claim: name}).Key(&c); ok {
+			t.Errorf("claim %q got a key", name)
+		}
+	}
+}
+
+func TestHandlerRateLimitKey(t *testing.T) {
+	type ctxKey struct{}
+	var c Claims
+	c.Subject = "alice"
+	want, _ := new(RateLimitKey).Key(&c)
+
+	var reached bool
+	handler := Handler{
+		Keys:         &KeyRegister{EdDSAs: []ed25519.PublicKey{testKeyEd25519Public}},
+		HeaderPrefix: "X-Verified-",
+		RateLimitKey: &RateLimitKey{Header: "X-Verified-Rate-Key", ContextKey: ctxKey{}},
+		Target: http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			reached = true
+			if got := r.Header.Get("X-Verified-Rate-Key"); got != want {
+				t.Errorf("got header %q, want %q", got, want)
+			}
+			if got := r.Context().Value(ctxKey{}); got != want {
+				t.Errorf("got context value %v, want %q", got, want)
+			}
+		}),
+	}
+
+	req := httptest.NewRequest("GET", "/", nil)
+	req.Header.Set("X-Verified-Rate-Key", "spoofed")
+	if err := c.EdDSASignHeader(req, testKeyEd25519Private); err != nil {
+		t.Fatal(err)
+	}
+	handler.ServeHTTP(httptest.NewRecorder(), req)
+	if !reached {
+		t.Error("target not reached")
+	}
+
+	// no subject
+	reached = false
+	req = httptest.NewRequest("GET", "/", nil)
+	if err := new(Claims).EdDSASignHeader(req, testKeyEd25519Private); err != nil {
+		t.Fatal(err)
+	}
+	w := httptest.NewRecorder()
+	handler.ServeHTTP(w, req)
+	if reached || w.Code != http.StatusUnauthorized {
+		t.Errorf("without subject got HTTP %d, reached %t", w.Code, reached)
+	}
+}
@@ -0,0 +1,29 @@
+package jwt
+
+import (
+	"bytes"
+	"errors"
+	"io"
+)
+
+// ErrReadLimit means that a token exceeds the size limit of CheckReader.
+var ErrReadLimit = errors.New("jwt: token size exceeds the read limit")
+
+// CheckReader parses a JWT from r if, and only if, the token does not exceed
+// limit in bytes, and the signature checks out with keys. Reads stop after one
+// byte past limit, which protects against streams of unbounded size. Leading
+// and trailing white space is ignored, such as the newline at the end of a
+// file. Use Claims.Valid to complete the verification.
+func CheckReader(r io.Reader, limit int64, keys *KeyRegister) (*Claims, error) {
+	if limit < 0 {
+		limit = 0
+	}
+	token, err := io.ReadAll(io.LimitReader(r, limit+1))
+	if err != nil {
+		return nil, err
+	}
+	if int64(len(token)) > limit {
+		return nil, ErrReadLimit
+	}
+	return keys.Check(bytes.TrimSpace(token))
+}
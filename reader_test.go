@@ -0,0 +1,56 @@
+package jwt
+
+import (
+	"crypto/ed25519"
+	"errors"
+	"io"
+	"strings"
+	"testing"
+	"testing/iotest"
+)
+
+func TestCheckReader(t *testing.T) {
+	keys := &KeyRegister{EdDSAs: []ed25519.PublicKey{testKeyEd25519Public}}
+	var c Claims
+	c.Subject = "stream"
+	token, err := c.EdDSASign(testKeyEd25519Private)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// chunked with a trailing newline
+	r := iotest.OneByteReader(strings.NewReader(string(token) + "\n"))
+	got, err := CheckReader(r, int64(len(token))+1, keys)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got.Subject != "stream" {
+		t.Errorf("got subject %q, want stream", got.Subject)
+	}
+
+	// one byte too many
+	r = strings.NewReader(string(token) + "\n")
+	if _, err := CheckReader(r, int64(len(token)), keys); err != ErrReadLimit {
+		t.Errorf("got error %v, want %v", err, ErrReadLimit)
+	}
+
+	// unbounded stream
+	infinite := io.MultiReader(strings.NewReader(string(token)), zeroReader{})
+	if _, err := CheckReader(infinite, 1<<16, keys); err != ErrReadLimit {
+		t.Errorf("got error %v, want %v", err, ErrReadLimit)
+	}
+
+	readErr := errors.New("connection reset")
+	if _, err := CheckReader(iotest.ErrReader(readErr), 1<<16, keys); err != readErr {
+		t.Errorf("got error %v, want %v", err, readErr)
+	}
+}
+
+type zeroReader struct{}
+
+func (zeroReader) Read(p []byte) (int, error) {
+	for i := range p {
+		p[i] = 0
+	}
+	return len(p), nil
+}
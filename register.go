@@ -2,6 +2,7 @@ package jwt
 
 import (
 	"bytes"
+	"context"
 	"crypto/ecdsa"
 	"crypto/ed25519"
 	"crypto/elliptic"
@@ -12,8 +13,8 @@ import (
 	"encoding/pem"
 	"errors"
 	"fmt"
-	"hash"
 	"math/big"
+	"sync/atomic"
 )
 
 // KeyRegister is a collection of recognized credentials.
@@ -24,6 +25,10 @@ type KeyRegister struct {
 	HMACs   []*HMAC             // HMAC credentials
 	Secrets [][]byte            // HMAC credentials
 
+	// Algorithms has credentials for custom signature schemes.
+	// See Algorithm for details.
+	Algorithms []Algorithm
+
 	// Optional key identification. See Claims.KeyID for details.
 	// Non-empty strings match the respective key or secret by index.
 	ECDSAIDs  []string // ECDSAs key ID mapping
@@ -31,96 +36,198 @@ type KeyRegister struct {
 	RSAIDs    []string // RSAs key ID mapping
 	HMACIDs   []string // HMACs key ID mapping
 	SecretIDs []string // Secrets key ID mapping
+
+	AlgorithmIDs []string // Algorithms key ID mapping
+
+	// EdDSAContext verifies the EdDSAs as Ed25519ctx with the context
+	// string, when not empty. See EdDSACtxCheck for details.
+	EdDSAContext string
+
+	// Usage collects verification statistics when set. See
+	// KeyRegister.UsageStats for details.
+	Usage *KeyUsage
+
+	// ResolveKID maps the key ID of tokens to the key ID of the register,
+	// when set. Opaque key IDs keep key naming private. Unresolved key
+	// IDs are applied as is. Claims.KeyID gets the resolved value. See
+	// OpaqueKID for an implementation.
+	ResolveKID func(kid string) (id string, ok bool)
+
+	// SecretSource resolves HMAC secrets by key ID at check time, when
+	// set. It is consulted for tokens with a key ID which matches none
+	// of the HMACs and Secrets. The return is nil for unknown key IDs.
+	// The register does not modify the return. See SecretCache for
+	// memoization.
+	SecretSource func(kid string) ([]byte, error)
+
+	// StrictKID rejects tokens with a key ID which matches none of the
+	// keys, before any signature attempts. See KIDFallback for the
+	// default behaviour.
+	StrictKID bool
+
+	// RejectDuplicates makes LoadPEM, LoadJWK, LoadJWKPartial and
+	// ImportJWKS fail with a DuplicateKeyError on keys which are present
+	// already, with the same key ID. Such keys are skipped otherwise.
+	RejectDuplicates bool
+
+	// KeyAudiences restricts keys, by key ID, to tokens for any of the
+	// audiences mapped. Tokens verified with a restricted key fail with
+	// ErrKeyAudience otherwise. See KeyRegister.BindAudiences for details.
+	KeyAudiences map[string][]string
 }
 
 // Check parses a JWT if, and only if, the signature checks out.
 // Use Claims.Valid to complete the verification.
 func (keys *KeyRegister) Check(token []byte) (*Claims, error) {
-	var c Claims
-	lastDot, sig, alg, err := c.scan(token)
+	return keys.CheckContext(context.Background(), token)
+}
+
+// CheckContext is like Check, yet it aborts with the error of ctx once done.
+// The context is evaluated before each signature attempt, which bounds the
+// verification time of large registers to about the duration of one attempt
+// past the deadline. Errors from ctx, such as context.DeadlineExceeded, are
+// returned as is, to distinguish them from verification failure.
+func (keys *KeyRegister) CheckContext(ctx context.Context, token []byte) (*Claims, error) {
+	c, key, err := keys.check(ctx, token)
+	if key != nil {
+		keys.used(key)
+	}
+	return c, err
+}
+
+// Verify checks the signature of token with the register. The key is the
+// identity (as in KeyUsage) of the matching key, if any.
+func (keys *KeyRegister) verify(ctx context.Context, token []byte) (*Claims, interface{}, error) {
+	if err := ctx.Err(); err != nil {
+		return nil, nil, err
+	}
+
+	var claims Claims
+	lastDot, sig, alg, err := claims.scan(token)
 	if err != nil {
-		return nil, err
+		return nil, nil, err
+	}
+	if keys.ResolveKID != nil && claims.KeyID != "" {
+		if id, ok := keys.ResolveKID(claims.KeyID); ok {
+			claims.KeyID = id
+		}
 	}
 	body := token[:lastDot]
 	buf := sig[len(sig):]
 
 	switch hashAlg, err := hashLookup(alg, HMACAlgs); err.(type) {
 	case nil:
-		hMACOptions := keys.HMACs
-		if c.KeyID != "" {
-			for i, kid := range keys.HMACIDs {
-				if kid == c.KeyID && i < len(hMACOptions) {
-					hMACOptions = hMACOptions[i : i+1]
-					break
-				}
-			}
+		if len(sig) != hashAlg.Size() {
+			return nil, nil, ErrSigSize
 		}
-		for _, h := range hMACOptions {
-			if h.alg == alg {
-				digest := h.digests.Get().(hash.Hash)
-				digest.Reset()
+		hMACOptions, hMACMatch := byKID(keys.HMACs, keys.HMACIDs, claims.KeyID)
+		keyOptions, secretMatch := byKID(keys.Secrets, keys.SecretIDs, claims.KeyID)
+		if !hMACMatch && !secretMatch {
+			secret, err := keys.sourceSecret(claims.KeyID)
+			switch {
+			case err != nil:
+				return nil, nil, err
+			case secret != nil:
+				if err := checkSecret(secret); err != nil {
+					wipe(secret)
+					return nil, nil, err
+				}
+				digest := hmac.New(hashAlg.New, secret)
+				wipe(secret)
 				digest.Write(body)
-				sum := digest.Sum(buf)
-				h.digests.Put(digest)
-				if hmac.Equal(sig, sum) {
-					return &c, c.applyPayload()
+				if !hmac.Equal(sig, digest.Sum(buf)) {
+					return nil, nil, ErrSigMiss
+				}
+				// resolved secrets have no identity in the register
+				return &claims, nil, claims.applyPayload()
+			default:
+				if err := keys.kidMiss(claims.KeyID); err != nil {
+					return nil, nil, err
 				}
 			}
 		}
 
-		keyOptions := keys.Secrets
-		if c.KeyID != "" {
-			for i, kid := range keys.SecretIDs {
-				if kid == c.KeyID && i < len(keyOptions) {
-					keyOptions = keyOptions[i : i+1]
-					break
-				}
+		for _, h := range hMACOptions {
+			if err := ctx.Err(); err != nil {
+				return nil, nil, err
+			}
+			if h.alg != alg {
+				continue
+			}
+			digest, err := h.digest()
+			if err != nil {
+				continue // wiped
+			}
+			digest.Write(body)
+			sum := digest.Sum(buf)
+			h.release(digest)
+			if hmac.Equal(sig, sum) {
+				return &claims, h, claims.applyPayload()
 			}
 		}
 
 		for _, secret := range keyOptions {
+			if err := ctx.Err(); err != nil {
+				return nil, nil, err
+			}
+			if err := checkSecret(secret); err != nil {
+				return nil, nil, err
+			}
 			digest := hmac.New(hashAlg.New, secret)
 			digest.Write(body)
 			if hmac.Equal(sig, digest.Sum(buf)) {
-				return &c, c.applyPayload()
+				return &claims, keyID(secret), claims.applyPayload()
 			}
 		}
-		return nil, ErrSigMiss
+		return nil, nil, ErrSigMiss
 
 	case AlgError:
 		break // next
 	default:
-		return nil, err
+		return nil, nil, err
 	}
 
 	if alg == EdDSA {
-		keyOptions := keys.EdDSAs
-		if c.KeyID != "" {
-			for i, kid := range keys.EdDSAIDs {
-				if kid == c.KeyID && i < len(keyOptions) {
-					keyOptions = keyOptions[i : i+1]
-					break
-				}
+		if err := fipsAlg(alg, 0); err != nil {
+			return nil, nil, err
+		}
+		if len(sig) != ed25519.SignatureSize {
+			return nil, nil, ErrSigSize
+		}
+		keyOptions, ok := byKID(keys.EdDSAs, keys.EdDSAIDs, claims.KeyID)
+		if !ok {
+			if err := keys.kidMiss(claims.KeyID); err != nil {
+				return nil, nil, err
 			}
 		}
 
 		for _, key := range keyOptions {
+			if err := ctx.Err(); err != nil {
+				return nil, nil, err
+			}
+			if keys.EdDSAContext != "" {
+				switch err := ed25519ctxVerify(key, body, sig, keys.EdDSAContext); err {
+				case nil:
+					return &claims, keyID(key), claims.applyPayload()
+				case ErrSigMiss:
+					continue
+				default:
+					return nil, nil, err
+				}
+			}
 			if ed25519.Verify(key, body, sig) {
-				return &c, c.applyPayload()
+				return &claims, keyID(key), claims.applyPayload()
 			}
 		}
-		return nil, ErrSigMiss
+		return nil, nil, ErrSigMiss
 	}
 
 	switch hash, err := hashLookup(alg, RSAAlgs); err.(type) {
 	case nil:
-		keyOptions := keys.RSAs
-		if c.KeyID != "" {
-			for i, kid := range keys.RSAIDs {
-				if kid == c.KeyID && i < len(keyOptions) {
-					keyOptions = keyOptions[i : i+1]
-					break
-				}
+		keyOptions, ok := byKID(keys.RSAs, keys.RSAIDs, claims.KeyID)
+		if !ok {
+			if err := keys.kidMiss(claims.KeyID); err != nil {
+				return nil, nil, err
 			}
 		}
 
@@ -128,34 +235,40 @@ func (keys *KeyRegister) Check(token []byte) (*Claims, error) {
 		digest.Write(body)
 		digestSum := digest.Sum(buf)
 		for _, key := range keyOptions {
+			if err := ctx.Err(); err != nil {
+				return nil, nil, err
+			}
+			if err := fipsRSA(key); err != nil {
+				return nil, nil, err
+			}
 			if alg != "" && alg[0] == 'P' {
 				err = rsa.VerifyPSS(key, hash, digestSum, sig, &pSSOptions)
 			} else {
 				err = rsa.VerifyPKCS1v15(key, hash, digestSum, sig)
 			}
 			if err == nil {
-				return &c, c.applyPayload()
+				return &claims, key, claims.applyPayload()
 			}
 		}
-		return nil, ErrSigMiss
+		return nil, nil, ErrSigMiss
 
 	case AlgError:
 		break // next
 	default:
-		return nil, err
+		return nil, nil, err
 	}
 
-	switch hash, err := hashLookup(alg, ECDSAAlgs); err {
+	switch hash, err := hashLookup(alg, ECDSAAlgs); err.(type) {
 	case nil:
-		keyOptions := keys.ECDSAs
-		if c.KeyID != "" {
-			for i, kid := range keys.ECDSAIDs {
-				if kid == c.KeyID && i < len(keyOptions) {
-					keyOptions = keyOptions[i : i+1]
-					break
-				}
+		keyOptions, ok := byKID(keys.ECDSAs, keys.ECDSAIDs, claims.KeyID)
+		if !ok {
+			if err := keys.kidMiss(claims.KeyID); err != nil {
+				return nil, nil, err
 			}
 		}
+		if !ecdsaSigSizeOK(len(sig), keyOptions...) {
+			return nil, nil, ErrSigSize
+		}
 
 		r := new(big.Int).SetBytes(sig[:len(sig)/2])
 		s := new(big.Int).SetBytes(sig[len(sig)/2:])
@@ -163,30 +276,82 @@ func (keys *KeyRegister) Check(token []byte) (*Claims, error) {
 		digest.Write(body)
 		digestSum := digest.Sum(buf)
 		for _, key := range keyOptions {
+			if len(sig) != ecdsaSigSize(key) {
+				continue // other curve
+			}
+			if err := ctx.Err(); err != nil {
+				return nil, nil, err
+			}
 			if ecdsa.Verify(key, digestSum, r, s) {
-				return &c, c.applyPayload()
+				return &claims, key, claims.applyPayload()
 			}
 		}
-		return nil, ErrSigMiss
+		return nil, nil, ErrSigMiss
 
+	case AlgError:
+		return keys.checkAlgorithms(ctx, &claims, alg, body, sig)
 	default:
-		return nil, err
+		return nil, nil, err
+	}
+}
+
+// ByKID returns the option with kid, if any. The return is all options when no
+// match is found, with false for a non-empty kid.
+func byKID[T any](options []T, ids []string, kid string) ([]T, bool) {
+	if kid == "" {
+		return options, true
+	}
+	for i, id := range ids {
+		if id == kid && i < len(options) {
+			return options[i : i+1], true
+		}
 	}
+	return options, false
+}
+
+var errKIDMiss = errors.New(`jwt: no key for key ID ["kid"]`)
+
+// KIDFallback is invoked by KeyRegister.Check for each token with a key ID
+// which matches none of the keys, unless StrictKID is set. Such tokens are
+// verified against all keys of the algorithm. A steady flow of fallbacks may
+// indicate that an issuer has new keys which are not loaded yet.
+var KIDFallback func(kid string)
+
+var kidFallbacks uint64
+
+// KIDFallbackCount returns the number of KIDFallback events thus far.
+func KIDFallbackCount() uint64 {
+	return atomic.LoadUint64(&kidFallbacks)
+}
+
+// KidMiss handles a key ID without any match.
+func (keys *KeyRegister) kidMiss(kid string) error {
+	if keys.StrictKID {
+		return errKIDMiss
+	}
+	atomic.AddUint64(&kidFallbacks, 1)
+	if f := KIDFallback; f != nil {
+		f(kid)
+	}
+	return nil
 }
 
 var errUnencryptedPEM = errors.New("jwt: unencrypted PEM rejected due password expectation")
 
 // LoadPEM scans text for PEM-encoded keys. Each occurrence found is then added
 // to the register. Extraction works with certificates, public keys and private
-// keys. PEM encryption is enforced with a non-empty password to ensure security
-// when ordered.
+// keys, plus any types from RegisterPEMType. PEM encryption is enforced with a
+// non-empty password to ensure security when ordered. Neither the password
+// nor any private key material is retained; decoded private keys are
+// overwritten with zeros before LoadPEM returns.
 func (keys *KeyRegister) LoadPEM(text, password []byte) (keysAdded int, err error) {
-	for {
+	for blockIndex := 0; ; blockIndex++ {
 		block, remainder := pem.Decode(text)
 		if block == nil {
 			return
 		}
 		text = remainder
+		source := fmt.Sprintf("PEM block %d", blockIndex)
 
 		if x509.IsEncryptedPEMBlock(block) {
 			block.Bytes, err = x509.DecryptPEMBlock(block, password)
@@ -208,40 +373,56 @@ func (keys *KeyRegister) LoadPEM(text, password []byte) (keysAdded int, err erro
 				return keysAdded, err
 			}
 			for _, c := range certs {
-				if err := keys.add(c.PublicKey, ""); err != nil {
+				added, err := keys.loadKey(nil, c.PublicKey, "", source)
+				if err != nil {
 					return keysAdded, err
 				}
-				keysAdded++
+				if added {
+					keysAdded++
+				}
 			}
 			continue
 
 		case "PUBLIC KEY":
 			key, err = x509.ParsePKIXPublicKey(block.Bytes)
 
-		case "PRIVATE KEY":
-			key, err = x509.ParsePKCS8PrivateKey(block.Bytes)
-
-		case "EC PRIVATE KEY":
-			key, err = x509.ParseECPrivateKey(block.Bytes)
-
-		case "RSA PRIVATE KEY":
-			key, err = x509.ParsePKCS1PrivateKey(block.Bytes)
+		case "PRIVATE KEY", "EC PRIVATE KEY", "RSA PRIVATE KEY":
+			key, err = parsePrivatePEM(block)
+			// only the public part is added
+			wipe(block.Bytes)
+			if err == nil {
+				defer wipePrivateKey(key)
+			}
 
 		default:
-			return keysAdded, fmt.Errorf("jwt: unknown PEM type %q", block.Type)
+			parse, ok := pemTypeParser(block.Type)
+			if !ok {
+				return keysAdded, fmt.Errorf("jwt: unknown PEM type %q", block.Type)
+			}
+			key, err = parse(block.Bytes)
+			wipe(block.Bytes)
+			if err == nil {
+				defer wipePrivateKey(key)
+			}
 		}
 		if err != nil {
 			return keysAdded, err
 		}
-		if err := keys.add(key, ""); err != nil {
+		added, err := keys.loadKey(nil, key, "", source)
+		if err != nil {
 			return keysAdded, err
 		}
-
-		keysAdded++
+		if added {
+			keysAdded++
+		}
 	}
 }
 
 func (keys *KeyRegister) add(key interface{}, kid string) error {
+	if err := fipsKey(key); err != nil {
+		return err
+	}
+
 	var i int
 	var ids *[]string
 
@@ -252,7 +433,8 @@ func (keys *KeyRegister) add(key interface{}, kid string) error {
 		ids = &keys.ECDSAIDs
 	case *ecdsa.PrivateKey:
 		i = len(keys.ECDSAs)
-		keys.ECDSAs = append(keys.ECDSAs, &t.PublicKey)
+		pub := t.PublicKey // don't retain the private key
+		keys.ECDSAs = append(keys.ECDSAs, &pub)
 		ids = &keys.ECDSAIDs
 	case ed25519.PublicKey:
 		i = len(keys.EdDSAs)
@@ -268,12 +450,21 @@ func (keys *KeyRegister) add(key interface{}, kid string) error {
 		ids = &keys.RSAIDs
 	case *rsa.PrivateKey:
 		i = len(keys.RSAs)
-		keys.RSAs = append(keys.RSAs, &t.PublicKey)
+		pub := t.PublicKey // don't retain the private key
+		keys.RSAs = append(keys.RSAs, &pub)
 		ids = &keys.RSAIDs
+	case *HMAC:
+		i = len(keys.HMACs)
+		keys.HMACs = append(keys.HMACs, t)
+		ids = &keys.HMACIDs
 	case []byte:
 		i = len(keys.Secrets)
 		keys.Secrets = append(keys.Secrets, t)
 		ids = &keys.SecretIDs
+	case Algorithm:
+		i = len(keys.Algorithms)
+		keys.Algorithms = append(keys.Algorithms, t)
+		ids = &keys.AlgorithmIDs
 	default:
 		return fmt.Errorf("jwt: unsupported key type %T", t)
 	}
@@ -331,11 +522,16 @@ type jwk struct {
 	Crv string
 
 	K, X, Y, N, E *string
+
+	Use string
+	X5c []string // for ImportJWKS only
 }
 
 // LoadJWK adds keys from the JSON data to the register, including the key ID,
 // a.k.a "kid", when present. If the object has a "keys" attribute, then data is
 // read as a JWKS (JSON Web Key Set). Otherwise, data is read as a single JWK.
+// The register remains unmodified on error, i.e., either all keys are added or
+// none. See LoadJWKPartial for a lenient alternative.
 func (keys *KeyRegister) LoadJWK(data []byte) (keysAdded int, err error) {
 	j := new(jwk)
 	if err := json.Unmarshal(data, j); err != nil {
@@ -343,37 +539,115 @@ func (keys *KeyRegister) LoadJWK(data []byte) (keysAdded int, err error) {
 	}
 
 	if j.Keys == nil {
-		if err := keys.addJWK(j); err != nil {
+		key, err := j.key()
+		if err != nil {
+			return 0, err
+		}
+		added, err := keys.loadKey(nil, key, j.Kid, "JWK")
+		if err != nil || !added {
 			return 0, err
 		}
 		return 1, nil
 	}
 
+	// stage for all-or-nothing
+	var staged KeyRegister
 	for i, k := range j.Keys {
-		if err := keys.addJWK(k); err != nil {
-			return i, err
+		key, err := k.key()
+		if err != nil {
+			return 0, err
+		}
+		added, err := keys.loadKey(&staged, key, k.Kid, fmt.Sprintf("JWKS key %d", i))
+		if err != nil {
+			return 0, err
+		}
+		if added {
+			keysAdded++
 		}
 	}
-	return len(j.Keys), nil
+	keys.addAll(&staged)
+	return keysAdded, nil
+}
+
+// LoadJWKPartial is like LoadJWK, yet each malformed or unsupported key is
+// skipped instead of rejecting the entire data. The errors have the position of
+// the respective key in the JWKS.
+func (keys *KeyRegister) LoadJWKPartial(data []byte) (keysAdded int, errs []error) {
+	j := new(jwk)
+	if err := json.Unmarshal(data, j); err != nil {
+		return 0, []error{err}
+	}
+
+	if j.Keys == nil {
+		key, err := j.key()
+		if err != nil {
+			return 0, []error{err}
+		}
+		added, err := keys.loadKey(nil, key, j.Kid, "JWK")
+		if err != nil {
+			return 0, []error{err}
+		}
+		if added {
+			keysAdded++
+		}
+		return keysAdded, nil
+	}
+
+	for i, k := range j.Keys {
+		source := fmt.Sprintf("JWKS key %d", i)
+		key, err := k.key()
+		if err != nil {
+			errs = append(errs, fmt.Errorf("jwt: %s: %w", source, err))
+			continue
+		}
+		added, err := keys.loadKey(nil, key, k.Kid, source)
+		if err != nil {
+			errs = append(errs, fmt.Errorf("jwt: %s: %w", source, err))
+			continue
+		}
+		if added {
+			keysAdded++
+		}
+	}
+	return keysAdded, errs
+}
+
+// AddAll adds all keys from o, including their key IDs, without any
+// deduplication, as opposed to Merge.
+func (keys *KeyRegister) addAll(o *KeyRegister) {
+	for i, key := range o.ECDSAs {
+		keys.add(key, kidAt(o.ECDSAIDs, i))
+	}
+	for i, key := range o.EdDSAs {
+		keys.add(key, kidAt(o.EdDSAIDs, i))
+	}
+	for i, key := range o.RSAs {
+		keys.add(key, kidAt(o.RSAIDs, i))
+	}
+	for i, secret := range o.Secrets {
+		keys.add(secret, kidAt(o.SecretIDs, i))
+	}
 }
 
 var (
 	errJWKNoKty = errors.New("jwt: JWK missing \"kty\" field")
 	errJWKParam = errors.New("jwt: JWK missing key–parameter field")
 
-	errJWKCurveSize = errors.New("jwt: JWK curve parameters don't match curve size")
-	errJWKCurveMiss = errors.New("jwt: JWK curve parameters are not on the curve")
+	errJWKCurveSize   = errors.New("jwt: JWK curve parameters don't match curve size")
+	errJWKCurveMiss   = errors.New("jwt: JWK curve parameters are not on the curve")
+	errJWKEd25519Size = errors.New("jwt: JWK Ed25519 public key of wrong size")
 )
 
-func (keys *KeyRegister) addJWK(j *jwk) error {
+// Key returns the public key or secret of j.
+func (j *jwk) key() (interface{}, error) {
 	// See RFC 7518, subsection 6.1
 
 	if j.Kty == nil {
-		return errJWKNoKty
+		return nil, errJWKNoKty
 	}
 	switch *j.Kty {
 	default:
-		return fmt.Errorf("jwt: JWK with unsupported key type %q", *j.Kty)
+		return nil, fmt.Errorf("jwt: JWK with unsupported key type %q", *j.Kty)
 
 	case "EC":
 		var curve elliptic.Curve
@@ -385,63 +659,68 @@ func (keys *KeyRegister) addJWK(j *jwk) error {
 		case "P-521":
 			curve = elliptic.P521()
 		default:
-			return fmt.Errorf("jwt: JWK with unsupported elliptic curve %q", j.Crv)
+			return nil, fmt.Errorf("jwt: JWK with unsupported elliptic curve %q", j.Crv)
 		}
 
-		x, err := intParam(j.X)
+		xBytes, err := dataParam(j.X)
 		if err != nil {
-			return err
+			return nil, err
 		}
-		y, err := intParam(j.Y)
+		yBytes, err := dataParam(j.Y)
 		if err != nil {
-			return err
+			return nil, err
 		}
 
+		// “The length of this octet string MUST be the full size of a
+		// coordinate for the curve specified in the "crv" parameter.”
+		// — “JSON Web Algorithms (JWA)” RFC 7518, subsection 6.2.1.2
 		size := (curve.Params().BitSize + 7) / 8
-		xSize, ySize := (x.BitLen()+7)/8, (y.BitLen()+7)/8
-		if xSize != size || ySize != size {
-			return errJWKCurveSize
+		if len(xBytes) != size || len(yBytes) != size {
+			return nil, errJWKCurveSize
 		}
+		x := new(big.Int).SetBytes(xBytes)
+		y := new(big.Int).SetBytes(yBytes)
 
 		if !curve.IsOnCurve(x, y) {
-			return errJWKCurveMiss
+			return nil, errJWKCurveMiss
 		}
 
-		keys.add(&ecdsa.PublicKey{Curve: curve, X: x, Y: y}, j.Kid)
+		return &ecdsa.PublicKey{Curve: curve, X: x, Y: y}, nil
 
 	case "RSA":
 		n, err := intParam(j.N)
 		if err != nil {
-			return err
+			return nil, err
 		}
 		e, err := intParam(j.E)
 		if err != nil {
-			return err
+			return nil, err
 		}
 
-		keys.add(&rsa.PublicKey{N: n, E: int(e.Int64())}, j.Kid)
+		return &rsa.PublicKey{N: n, E: int(e.Int64())}, nil
 
 	case "oct":
 		bytes, err := dataParam(j.K)
 		if err != nil {
-			return err
+			return nil, err
 		}
-		keys.add(bytes, j.Kid)
+		return bytes, nil
 
 	case "OKP":
 		switch j.Crv {
 		case "Ed25519":
 			bytes, err := dataParam(j.X)
 			if err != nil {
-				return err
+				return nil, err
 			}
-			keys.add(ed25519.PublicKey(bytes), j.Kid)
+			if len(bytes) != ed25519.PublicKeySize {
+				return nil, errJWKEd25519Size
+			}
+			return ed25519.PublicKey(bytes), nil
 		default:
-			return fmt.Errorf("jwt: JWK with unsupported elliptic curve %q", j.Crv)
+			return nil, fmt.Errorf("jwt: JWK with unsupported elliptic curve %q", j.Crv)
 		}
 	}
-
-	return nil
 }
 
 func dataParam(p *string) ([]byte, error) {
@@ -2,6 +2,7 @@ package jwt
 
 import (
 	"bytes"
+	"context"
 	"crypto"
 	"crypto/ecdsa"
 	"crypto/ed25519"
@@ -11,6 +12,7 @@ import (
 	"encoding/json"
 	"errors"
 	"testing"
+	"time"
 )
 
 // Tests the golden cases.
@@ -170,8 +172,9 @@ EUTC5n7n+Qeyo3rL3iLhC/jn3rouX1FA5J7baL17KzDSiF5eQVlLOIfy
 	if err != nil {
 		t.Fatal(err)
 	}
-	if n != 9 {
-		t.Errorf("extracted %d keys, want 7", n)
+	// the EdDSA public key duplicates the private one
+	if n != 8 {
+		t.Errorf("extracted %d keys, want 8", n)
 	}
 
 	// add the HMAC keys
@@ -722,6 +725,8 @@ var GoldenJWKErrors = []struct {
 		errors.New(`jwt: JWK with malformed key–parameter field: illegal base64 data at input byte 0`)},
 	{`{"kty": "oct"}`, errJWKParam},
 	{`{"kty": "OKP", "crv": "Ed25519"}`, errJWKParam},
+	{`{"kty": "OKP", "crv": "Ed25519", "x": "11qYAYKxCrfVS_7TyWQHOg"}`, errJWKEd25519Size},
+	{`{"kty": "OKP", "crv": "Ed25519", "x": ""}`, errJWKEd25519Size},
 	{`{"kty": "OKP", "crv": "bad"}`,
 		errors.New(`jwt: JWK with unsupported elliptic curve "bad"`)},
 	{`{"kty":"EC", "crv":"P-384",
@@ -749,3 +754,108 @@ func TestKeyRegisterLoadJWKErrors(t *testing.T) {
 		}
 	}
 }
+
+func TestKeyRegisterLoadJWKAtomic(t *testing.T) {
+	const jwks = `{"keys": [
+		{"kty": "oct", "k": "c2VjcmV0", "kid": "a"},
+		{"kty": "bad"},
+		{"kty": "oct", "k": "c2VjcmV0Mg", "kid": "b"}
+	]}`
+
+	var keys KeyRegister
+	n, err := keys.LoadJWK([]byte(jwks))
+	if n != 0 || err == nil {
+		t.Errorf("LoadJWK got (%d, %v), want (0, error)", n, err)
+	}
+	if len(keys.Secrets) != 0 || len(keys.SecretIDs) != 0 {
+		t.Errorf("LoadJWK left %d secrets with IDs %q in register", len(keys.Secrets), keys.SecretIDs)
+	}
+
+	n, errs := keys.LoadJWKPartial([]byte(jwks))
+	if n != 2 {
+		t.Errorf("LoadJWKPartial added %d keys, want 2", n)
+	}
+	if want := `jwt: JWKS key 1: jwt: JWK with unsupported key type "bad"`; len(errs) != 1 || errs[0].Error() != want {
+		t.Errorf("LoadJWKPartial got errors %q, want [%q]", errs, want)
+	}
+	if len(keys.Secrets) != 2 || string(keys.Secrets[1]) != "secret2" || keys.SecretIDs[1] != "b" {
+		t.Errorf("LoadJWKPartial got secrets %q with IDs %q", keys.Secrets, keys.SecretIDs)
+	}
+}
+
+func TestKeyRegisterStrictKID(t *testing.T) {
+	keys := KeyRegister{
+		Secrets:   [][]byte{[]byte("secret")},
+		SecretIDs: []string{"current"},
+	}
+	c := Claims{KeyID: "next"}
+	token, err := c.HMACSign(HS256, []byte("secret"))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	var fallbacks []string
+	KIDFallback = func(kid string) { fallbacks = append(fallbacks, kid) }
+	defer func() { KIDFallback = nil }()
+	before := KIDFallbackCount()
+
+	if _, err := keys.Check(token); err != nil {
+		t.Error("fallback check error:", err)
+	}
+	if len(fallbacks) != 1 || fallbacks[0] != "next" {
+		t.Errorf("got fallback events %q, want [\"next\"]", fallbacks)
+	}
+	if n := KIDFallbackCount() - before; n != 1 {
+		t.Errorf("fallback count increased with %d, want 1", n)
+	}
+
+	keys.StrictKID = true
+	if _, err := keys.Check(token); err != errKIDMiss {
+		t.Errorf("strict check got error %v, want %v", err, errKIDMiss)
+	}
+	if len(fallbacks) != 1 {
+		t.Errorf("strict check got fallback events %q", fallbacks)
+	}
+}
+
+func TestCheckContext(t *testing.T) {
+	var keys KeyRegister
+	for i := 0; i < 100; i++ {
+		keys.Secrets = append(keys.Secrets, []byte{byte(i)})
+	}
+	var c Claims
+	token, err := c.HMACSign(HS256, []byte{99})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if _, err := keys.CheckContext(context.Background(), token); err != nil {
+		t.Error("check error:", err)
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), -time.Second)
+	defer cancel()
+	if _, err := keys.CheckContext(ctx, token); err != context.DeadlineExceeded {
+		t.Errorf("expired deadline got error %v, want %v", err, context.DeadlineExceeded)
+	}
+
+	// abort between signature attempts
+	ctx = &countdownContext{Context: context.Background(), n: 5}
+	if _, err := keys.CheckContext(ctx, token); err != context.DeadlineExceeded {
+		t.Errorf("deadline during attempts got error %v, want %v", err, context.DeadlineExceeded)
+	}
+}
+
+// CountdownContext expires after n Err calls.
+type countdownContext struct {
+	context.Context
+	n int
+}
+
+func (ctx *countdownContext) Err() error {
+	if ctx.n <= 0 {
+		return context.DeadlineExceeded
+	}
+	ctx.n--
+	return nil
+}
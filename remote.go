@@ -0,0 +1,374 @@
+package jwt
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+)
+
+// RemoteKeysLimit is the maximum size of a JWKS document in bytes.
+var RemoteKeysLimit int64 = 1 << 20
+
+var errRemoteStale = errors.New("jwt: remote keys exceed the maximum staleness")
+
+// RemoteKeys is a KeyRegister which loads from a JWKS location, e.g., the
+// "jwks_uri" of an OpenID provider. Keys are refreshed on demand, i.e., during
+// checks. Failed refreshes keep the last good key set in use, within MaxStale.
+// Keys which are malformed or unsupported are skipped, as long as any usable key
+// remains.
+// Refreshes are conditional requests when the server provides an ETag or a
+// Last-Modified header. A freshness lifetime from the HTTP caching headers,
+// i.e., Cache-Control max-age or Expires, shortens RefreshInterval, down to
+// RetryInterval at minimum.
+//
+// Multiple goroutines may invoke methods on a RemoteKeys simultaneously.
+type RemoteKeys struct {
+	// URL locates the JWKS.
+	URL string
+
+	// Client is used for fetching. Nil defaults to http.DefaultClient.
+	Client *http.Client
+
+	// RefreshInterval is the maximum age of the key set before a
+	// refresh. Zero defaults to 10 minutes.
+	RefreshInterval time.Duration
+
+	// RetryInterval is the minimum time between fetches after a
+	// failure. Zero defaults to 30 seconds.
+	RetryInterval time.Duration
+
+	// MaxStale is the maximum age of the last good key set, in case of
+	// refresh failures. Zero means no limit.
+	MaxStale time.Duration
+
+	// OnChange receives the difference with each refresh which changes
+	// the key set, when set. The initial load is not reported. Calls do
+	// not overlap. Unexpected key churn at an identity provider may be
+	// an early sign of compromise. See DiffKeys for details.
+	OnChange func(url string, diff KeySetDiff)
+
+	refreshMutex sync.Mutex // serializes fetches
+
+	mutex       sync.Mutex // state lock
+	keys        *KeyRegister
+	etag        string    // validator of keys, if any
+	modified    string    // Last-Modified of keys, if any
+	fetched     time.Time // last success
+	lastAttempt time.Time
+	lastErr     error
+	skipped     []error       // keys left out of the key set in use
+	lifetime    time.Duration // from caching headers, if any
+	hasLifetime bool
+}
+
+// RemoteStatus is the health of RemoteKeys.
+type RemoteStatus struct {
+	KeyCount    int       // in use
+	Fetched     time.Time // last success, if any
+	LastAttempt time.Time // last fetch, if any
+	LastErr     error     // from the last fetch, if any
+	Skipped     []error   // keys left out of the key set in use, if any
+	Stale       bool      // whether keys exceed RefreshInterval
+}
+
+// Status returns the current state.
+func (r *RemoteKeys) Status() RemoteStatus {
+	r.mutex.Lock()
+	defer r.mutex.Unlock()
+	s := RemoteStatus{
+		Fetched:     r.fetched,
+		LastAttempt: r.lastAttempt,
+		LastErr:     r.lastErr,
+		Skipped:     r.skipped,
+		Stale:       r.keys != nil && time.Since(r.fetched) > r.freshness(),
+	}
+	if k := r.keys; k != nil {
+		s.KeyCount = len(k.ECDSAs) + len(k.EdDSAs) + len(k.RSAs) + len(k.HMACs) + len(k.Secrets)
+	}
+	return s
+}
+
+func (r *RemoteKeys) refreshInterval() time.Duration {
+	if r.RefreshInterval == 0 {
+		return 10 * time.Minute
+	}
+	return r.RefreshInterval
+}
+
+// Freshness returns the maximum age of the key set before a refresh. The
+// caller must hold the state lock.
+func (r *RemoteKeys) freshness() time.Duration {
+	d := r.refreshInterval()
+	if r.hasLifetime && r.lifetime < d {
+		d = r.lifetime
+		if floor := r.retryInterval(); d < floor {
+			d = floor
+		}
+	}
+	return d
+}
+
+func (r *RemoteKeys) retryInterval() time.Duration {
+	if r.RetryInterval == 0 {
+		return 30 * time.Second
+	}
+	return r.RetryInterval
+}
+
+// Check applies KeyRegister.Check with the current keys.
+func (r *RemoteKeys) Check(token []byte) (*Claims, error) {
+	keys, err := r.Keys(context.Background())
+	if err != nil {
+		return nil, err
+	}
+	return keys.Check(token)
+}
+
+// CheckContext applies KeyRegister.CheckContext with the current keys. Any
+// refresh is bound to ctx as well.
+func (r *RemoteKeys) CheckContext(ctx context.Context, token []byte) (*Claims, error) {
+	keys, err := r.Keys(ctx)
+	if err != nil {
+		return nil, err
+	}
+	return keys.CheckContext(ctx, token)
+}
+
+// CheckHeader applies KeyRegister.CheckHeader with the current keys.
+func (r *RemoteKeys) CheckHeader(req *http.Request) (*Claims, error) {
+	keys, err := r.Keys(req.Context())
+	if err != nil {
+		return nil, err
+	}
+	return keys.CheckHeader(req)
+}
+
+// Keys returns the current key set. A refresh is attempted when the keys exceed
+// RefreshInterval. The last good key set stays in use on refresh failures, for
+// as long as it doesn't exceed MaxStale. The return must not be modified.
+func (r *RemoteKeys) Keys(ctx context.Context) (*KeyRegister, error) {
+	r.mutex.Lock()
+	keys, fetched, freshness := r.keys, r.fetched, r.freshness()
+	err, lastAttempt := r.lastErr, r.lastAttempt
+	r.mutex.Unlock()
+	if keys != nil && time.Since(fetched) <= freshness {
+		return keys, nil
+	}
+
+	if err == nil || time.Since(lastAttempt) >= r.retryInterval() {
+		err = r.Refresh(ctx)
+		r.mutex.Lock()
+		keys, fetched = r.keys, r.fetched
+		r.mutex.Unlock()
+	}
+	if err == nil {
+		return keys, nil
+	}
+	if keys == nil {
+		return nil, err
+	}
+	if r.MaxStale != 0 && time.Since(fetched) > r.MaxStale {
+		return nil, fmt.Errorf("%w: %s", errRemoteStale, err)
+	}
+	return keys, nil // stale-while-revalidate
+}
+
+// Refresh fetches the key set, regardless of its age. Failure due to ctx is not
+// recorded, i.e., it does not delay the refreshes of other callers with the
+// RetryInterval.
+func (r *RemoteKeys) Refresh(ctx context.Context) error {
+	start := time.Now()
+	r.refreshMutex.Lock()
+	defer r.refreshMutex.Unlock()
+
+	r.mutex.Lock()
+	if r.lastAttempt.After(start) {
+		// fetched by another goroutine in the meantime
+		err := r.lastErr
+		r.mutex.Unlock()
+		return err
+	}
+	etag, modified := r.etag, r.modified
+	if r.keys == nil {
+		etag, modified = "", ""
+	}
+	r.mutex.Unlock()
+
+	resp, err := fetchJWKS(ctx, r.Client, r.URL, etag, modified)
+	if err != nil && ctx.Err() != nil {
+		// the fault of the caller does not affect the others
+		return err
+	}
+	var keys *KeyRegister
+	var skipped []error
+	if err == nil && resp.body != nil {
+		keys = new(KeyRegister)
+		n, errs := keys.LoadJWKPartial(resp.body)
+		switch {
+		case len(errs) == 0:
+			break
+		case n == 0:
+			err = fmt.Errorf("jwt: remote keys from %s: no usable keys: %w", r.URL, errs[0])
+		default:
+			skipped = errs
+		}
+	}
+
+	r.mutex.Lock()
+	r.lastAttempt = time.Now()
+	r.lastErr = err
+	if err != nil {
+		r.mutex.Unlock()
+		return err
+	}
+	previous := r.keys
+	if keys != nil {
+		r.keys, r.skipped = keys, skipped
+	}
+	r.etag, r.modified = resp.etag, resp.modified
+	r.lifetime, r.hasLifetime = resp.lifetime, resp.hasLifetime
+	r.fetched = r.lastAttempt
+	r.mutex.Unlock()
+
+	// serialized by the refresh lock
+	if r.OnChange != nil && previous != nil && keys != nil {
+		if diff := DiffKeys(previous, keys); diff.Changed() {
+			r.OnChange(r.URL, diff)
+		}
+	}
+	return nil
+}
+
+// JWKSResponse is the outcome of a JWKS fetch.
+type jwksResponse struct {
+	body     []byte // nil when not modified
+	etag     string // validator, if any
+	modified string // Last-Modified, if any
+
+	// freshness lifetime from the caching headers, if any
+	lifetime    time.Duration
+	hasLifetime bool
+}
+
+// FetchJWKS does a conditional GET with the validators.
+func fetchJWKS(ctx context.Context, client *http.Client, url, etag, modified string) (*jwksResponse, error) {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, url, nil)
+	if err != nil {
+		return nil, err
+	}
+	req.Header.Set("Accept", "application/jwk-set+json, application/json")
+	if etag != "" {
+		req.Header.Set("If-None-Match", etag)
+	}
+	if modified != "" {
+		req.Header.Set("If-Modified-Since", modified)
+	}
+
+	if client == nil {
+		client = http.DefaultClient
+	}
+	resp, err := client.Do(req)
+	if err != nil {
+		return nil, fmt.Errorf("jwt: remote keys unavailable: %w", err)
+	}
+	defer resp.Body.Close()
+	r := &jwksResponse{etag: etag, modified: modified}
+	r.lifetime, r.hasLifetime = cacheLifetime(resp.Header)
+	switch resp.StatusCode {
+	case http.StatusOK:
+		break
+	case http.StatusNotModified:
+		if etag == "" && modified == "" {
+			return nil, fmt.Errorf("jwt: remote keys GET %s got HTTP %q unconditionally", url, resp.Status)
+		}
+		return r, nil
+	default:
+		return nil, fmt.Errorf("jwt: remote keys GET %s got HTTP %q", url, resp.Status)
+	}
+	r.body, err = io.ReadAll(io.LimitReader(resp.Body, RemoteKeysLimit+1))
+	if err != nil {
+		return nil, fmt.Errorf("jwt: remote keys unavailable: %w", err)
+	}
+	if int64(len(r.body)) > RemoteKeysLimit {
+		return nil, fmt.Errorf("jwt: remote keys GET %s exceeds %d bytes", url, RemoteKeysLimit)
+	}
+	r.etag, r.modified = resp.Header.Get("ETag"), resp.Header.Get("Last-Modified")
+	return r, nil
+}
+
+// CacheLifetime returns the freshness lifetime from the HTTP caching headers,
+// if any, conform RFC 9111, section 4.2. A Cache-Control max-age takes
+// precedence over Expires. The Age is subtracted. No-cache and no-store give
+// a zero lifetime.
+func cacheLifetime(h http.Header) (lifetime time.Duration, ok bool) {
+	var maxAge int64 = -1
+	for _, line := range h.Values("Cache-Control") {
+		for _, directive := range strings.Split(line, ",") {
+			directive = strings.ToLower(strings.TrimSpace(directive))
+			switch {
+			case directive == "no-cache", directive == "no-store":
+				return 0, true
+			case strings.HasPrefix(directive, "max-age="):
+				n, err := strconv.ParseInt(strings.Trim(directive[len("max-age="):], `"`), 10, 32)
+				if err != nil || n < 0 {
+					// “a cache ought to consider the response to be stale”
+					n = 0
+				}
+				maxAge = n
+			}
+		}
+	}
+
+	switch {
+	case maxAge >= 0:
+		lifetime = time.Duration(maxAge) * time.Second
+	case h.Get("Expires") != "":
+		expires, err := http.ParseTime(h.Get("Expires"))
+		if err != nil {
+			// “invalid date formats, especially the value "0",
+			// as representing a time in the past”
+			return 0, true
+		}
+		date, err := http.ParseTime(h.Get("Date"))
+		if err != nil {
+			date = time.Now()
+		}
+		lifetime = expires.Sub(date)
+	default:
+		return 0, false
+	}
+
+	if age, err := strconv.ParseInt(h.Get("Age"), 10, 32); err == nil && age > 0 {
+		lifetime -= time.Duration(age) * time.Second
+	}
+	if lifetime < 0 {
+		lifetime = 0
+	}
+	return lifetime, true
+}
+
+// LoadJWKSFromURL adds the keys from a JWKS location, e.g., the "jwks_uri" of
+// an OpenID provider, with LoadJWK. The document is fetched with
+// http.DefaultClient. Expires is the end of the freshness lifetime according
+// to the HTTP caching headers, i.e., Cache-Control max-age or Expires, or the
+// zero value in absence thereof. See RemoteKeys for automated refreshes.
+func (keys *KeyRegister) LoadJWKSFromURL(ctx context.Context, url string) (keysAdded int, expires time.Time, err error) {
+	resp, err := fetchJWKS(ctx, nil, url, "", "")
+	if err != nil {
+		return 0, time.Time{}, err
+	}
+	keysAdded, err = keys.LoadJWK(resp.body)
+	if err != nil {
+		return keysAdded, time.Time{}, fmt.Errorf("jwt: remote keys from %s: %w", url, err)
+	}
+	if resp.hasLifetime {
+		expires = time.Now().Add(resp.lifetime)
+	}
+	return keysAdded, expires, nil
+}
@@ -0,0 +1,299 @@
+package jwt
+
+import (
+	"context"
+	"crypto/ecdsa"
+	"crypto/ed25519"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+func TestRemoteKeysStale(t *testing.T) {
+	jwks, err := (&KeyRegister{EdDSAs: []ed25519.PublicKey{testKeyEd25519Public}}).JWKS()
+	if err != nil {
+		t.Fatal(err)
+	}
+	var down int32
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if atomic.LoadInt32(&down) != 0 {
+			http.Error(w, "maintenance", http.StatusServiceUnavailable)
+			return
+		}
+		w.Write(jwks)
+	}))
+	defer srv.Close()
+
+	remote := &RemoteKeys{
+		URL:             srv.URL,
+		RefreshInterval: time.Nanosecond,
+		RetryInterval:   time.Nanosecond,
+		MaxStale:        time.Hour,
+	}
+	token, err := new(Claims).EdDSASign(testKeyEd25519Private)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := remote.Check(token); err != nil {
+		t.Fatal("check error:", err)
+	}
+	if s := remote.Status(); s.KeyCount != 1 || s.LastErr != nil || s.Fetched.IsZero() {
+		t.Errorf("got status %+v", s)
+	}
+
+	atomic.StoreInt32(&down, 1)
+	if _, err := remote.Check(token); err != nil {
+		t.Error("check with stale keys error:", err)
+	}
+	if s := remote.Status(); s.KeyCount != 1 || s.LastErr == nil || !s.Stale {
+		t.Errorf("got status %+v, want stale with error", s)
+	}
+
+	remote.MaxStale = time.Nanosecond
+	if _, err := remote.Check(token); !errors.Is(err, errRemoteStale) {
+		t.Errorf("check beyond maximum staleness got error %v, want %v", err, errRemoteStale)
+	}
+
+	atomic.StoreInt32(&down, 0)
+	if err := remote.Refresh(context.Background()); err != nil {
+		t.Fatal("refresh error:", err)
+	}
+	if s := remote.Status(); s.LastErr != nil {
+		t.Errorf("got status %+v after recovery", s)
+	}
+}
+
+func TestRemoteKeysETag(t *testing.T) {
+	jwks, err := (&KeyRegister{EdDSAs: []ed25519.PublicKey{testKeyEd25519Public}}).JWKS()
+	if err != nil {
+		t.Fatal(err)
+	}
+	var full, conditional int32
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Header.Get("If-None-Match") == `"v1"` {
+			atomic.AddInt32(&conditional, 1)
+			w.WriteHeader(http.StatusNotModified)
+			return
+		}
+		atomic.AddInt32(&full, 1)
+		w.Header().Set("ETag", `"v1"`)
+		w.Write(jwks)
+	}))
+	defer srv.Close()
+
+	remote := &RemoteKeys{URL: srv.URL}
+	for i := 0; i < 3; i++ {
+		if err := remote.Refresh(context.Background()); err != nil {
+			t.Fatal("refresh error:", err)
+		}
+	}
+	if full != 1 || conditional != 2 {
+		t.Errorf("got %d full and %d conditional fetches, want 1 and 2", full, conditional)
+	}
+
+	token, err := new(Claims).EdDSASign(testKeyEd25519Private)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := remote.Check(token); err != nil {
+		t.Error("check after not modified error:", err)
+	}
+}
+
+func TestCacheLifetime(t *testing.T) {
+	golden := []struct {
+		header   http.Header
+		lifetime time.Duration
+		ok       bool
+	}{
+		{http.Header{}, 0, false},
+		{http.Header{"Cache-Control": {"public, max-age=300"}}, 5 * time.Minute, true},
+		{http.Header{"Cache-Control": {"max-age=300"}, "Age": {"60"}}, 4 * time.Minute, true},
+		{http.Header{"Cache-Control": {"max-age=30"}, "Age": {"60"}}, 0, true},
+		{http.Header{"Cache-Control": {"max-age=bogus"}}, 0, true},
+		{http.Header{"Cache-Control": {"no-cache"}}, 0, true},
+		{http.Header{"Cache-Control": {"Max-Age=60", "No-Store"}}, 0, true},
+		{http.Header{"Expires": {"Mon, 02 Jan 2006 15:05:00 GMT"}, "Date": {"Mon, 02 Jan 2006 15:04:00 GMT"}}, time.Minute, true},
+		{http.Header{"Expires": {"0"}}, 0, true},
+		{http.Header{"Cache-Control": {"max-age=10"}, "Expires": {"Mon, 02 Jan 2006 15:05:00 GMT"}, "Date": {"Mon, 02 Jan 2006 15:04:00 GMT"}}, 10 * time.Second, true},
+	}
+	for _, gold := range golden {
+		lifetime, ok := cacheLifetime(gold.header)
+		if lifetime != gold.lifetime || ok != gold.ok {
+			t.Errorf("%v: got (%s, %t), want (%s, %t)", gold.header, lifetime, ok, gold.lifetime, gold.ok)
+		}
+	}
+}
+
+func TestLoadJWKSFromURL(t *testing.T) {
+	jwks, err := (&KeyRegister{EdDSAs: []ed25519.PublicKey{testKeyEd25519Public}}).JWKS()
+	if err != nil {
+		t.Fatal(err)
+	}
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Cache-Control", "public, max-age=3600")
+		w.Write(jwks)
+	}))
+	defer srv.Close()
+
+	var keys KeyRegister
+	before := time.Now()
+	n, expires, err := keys.LoadJWKSFromURL(context.Background(), srv.URL)
+	if err != nil {
+		t.Fatal("load error:", err)
+	}
+	if n != 1 || len(keys.EdDSAs) != 1 {
+		t.Errorf("got %d keys added, want 1", n)
+	}
+	if d := expires.Sub(before); d < time.Hour || d > time.Hour+time.Minute {
+		t.Errorf("got expiry %s after load, want an hour", d)
+	}
+
+	_, _, err = keys.LoadJWKSFromURL(context.Background(), srv.URL+"/\x7f")
+	if err == nil {
+		t.Error("malformed URL got no error")
+	}
+}
+
+func TestRemoteKeysMaxAge(t *testing.T) {
+	jwks, err := (&KeyRegister{EdDSAs: []ed25519.PublicKey{testKeyEd25519Public}}).JWKS()
+	if err != nil {
+		t.Fatal(err)
+	}
+	var fetches int32
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		atomic.AddInt32(&fetches, 1)
+		w.Header().Set("Cache-Control", "max-age=0")
+		w.Write(jwks)
+	}))
+	defer srv.Close()
+
+	remote := &RemoteKeys{URL: srv.URL, RetryInterval: time.Nanosecond}
+	for i := 0; i < 3; i++ {
+		if _, err := remote.Keys(context.Background()); err != nil {
+			t.Fatal("keys error:", err)
+		}
+	}
+	if fetches != 3 {
+		t.Errorf("got %d fetches with max-age 0, want 3", fetches)
+	}
+
+	remote.RetryInterval = time.Hour
+	for i := 0; i < 3; i++ {
+		if _, err := remote.Keys(context.Background()); err != nil {
+			t.Fatal("keys error:", err)
+		}
+	}
+	if fetches != 3 {
+		t.Errorf("got %d fetches with max-age 0 and retry interval of an hour, want 3", fetches)
+	}
+}
+
+func TestRemoteKeysOnChange(t *testing.T) {
+	var jwks atomic.Value
+	setKeys := func(keys *KeyRegister) {
+		b, err := keys.JWKS()
+		if err != nil {
+			t.Fatal(err)
+		}
+		jwks.Store(b)
+	}
+	setKeys(&KeyRegister{EdDSAs: []ed25519.PublicKey{testKeyEd25519Public}, EdDSAIDs: []string{"1"}})
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Write(jwks.Load().([]byte))
+	}))
+	defer srv.Close()
+
+	var diffs []KeySetDiff
+	remote := &RemoteKeys{
+		URL: srv.URL,
+		OnChange: func(url string, diff KeySetDiff) {
+			if url != srv.URL {
+				t.Errorf("got URL %q, want %q", url, srv.URL)
+			}
+			diffs = append(diffs, diff)
+		},
+	}
+	if err := remote.Refresh(context.Background()); err != nil {
+		t.Fatal("refresh error:", err)
+	}
+	if err := remote.Refresh(context.Background()); err != nil {
+		t.Fatal("refresh error:", err)
+	}
+	if len(diffs) != 0 {
+		t.Fatalf("got diffs %+v without change", diffs)
+	}
+
+	setKeys(&KeyRegister{ECDSAs: []*ecdsa.PublicKey{&testKeyEC256.PublicKey}, ECDSAIDs: []string{"2"}})
+	if err := remote.Refresh(context.Background()); err != nil {
+		t.Fatal("refresh error:", err)
+	}
+	if len(diffs) != 1 {
+		t.Fatalf("got %d diffs, want 1", len(diffs))
+	}
+	d := diffs[0]
+	if len(d.Added) != 1 || d.Added[0].KeyID != "2" || len(d.Removed) != 1 || d.Removed[0].KeyID != "1" || len(d.Rotated) != 0 {
+		t.Errorf("got diff %+v, want key ID 2 added and 1 removed", d)
+	}
+}
+
+func TestRemoteKeysCallerCancel(t *testing.T) {
+	jwks, err := (&KeyRegister{EdDSAs: []ed25519.PublicKey{testKeyEd25519Public}}).JWKS()
+	if err != nil {
+		t.Fatal(err)
+	}
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Write(jwks)
+	}))
+	defer srv.Close()
+
+	remote := &RemoteKeys{URL: srv.URL, RetryInterval: time.Hour}
+	canceled, cancel := context.WithCancel(context.Background())
+	cancel()
+	if _, err := remote.Keys(canceled); !errors.Is(err, context.Canceled) {
+		t.Fatalf("canceled context got error %v, want %v", err, context.Canceled)
+	}
+	if s := remote.Status(); s.LastErr != nil || !s.LastAttempt.IsZero() {
+		t.Errorf("cancelation recorded in status %+v", s)
+	}
+
+	// no retry delay for other callers
+	keys, err := remote.Keys(context.Background())
+	if err != nil {
+		t.Fatal("keys error:", err)
+	}
+	if len(keys.EdDSAs) != 1 {
+		t.Errorf("got %d EdDSA keys, want 1", len(keys.EdDSAs))
+	}
+}
+
+func TestRemoteKeysPartial(t *testing.T) {
+	var body atomic.Value
+	body.Store(`{"keys":[
+		{"kty":"OKP","crv":"Ed25519","kid":"ed","x":"11qYAYKxCrfVS_7TyWQHOg7hcvPapiMlrwIaaPcHURo"},
+		{"kty":"OKP","crv":"X448","kid":"future","x":"AA"}
+	]}`)
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Write([]byte(body.Load().(string)))
+	}))
+	defer srv.Close()
+
+	remote := &RemoteKeys{URL: srv.URL}
+	if err := remote.Refresh(context.Background()); err != nil {
+		t.Fatal("refresh error:", err)
+	}
+	if s := remote.Status(); s.KeyCount != 1 || len(s.Skipped) != 1 {
+		t.Errorf("got status %+v, want 1 key with 1 skipped", s)
+	}
+
+	body.Store(`{"keys":[{"kty":"OKP","crv":"X448","kid":"future","x":"AA"}]}`)
+	if err := remote.Refresh(context.Background()); err == nil {
+		t.Error("refresh without usable keys got no error")
+	}
+	if s := remote.Status(); s.KeyCount != 1 {
+		t.Errorf("got status %+v, want the last good key set", s)
+	}
+}
@@ -0,0 +1,88 @@
+package jwt
+
+import (
+	"crypto/ecdsa"
+	"crypto/ed25519"
+	"crypto/hmac"
+	"crypto/rsa"
+)
+
+// RemoveKeyID deletes each key with the key ID, and it returns the number of
+// keys removed. The respective key ID slices stay aligned. The empty string
+// matches nothing. Use SyncRegister for removal while in use.
+func (keys *KeyRegister) RemoveKeyID(kid string) (keysRemoved int) {
+	if kid == "" {
+		return 0
+	}
+	keysRemoved += removeIf(&keys.ECDSAs, &keys.ECDSAIDs, hasKID[*ecdsa.PublicKey](kid))
+	keysRemoved += removeIf(&keys.EdDSAs, &keys.EdDSAIDs, hasKID[ed25519.PublicKey](kid))
+	keysRemoved += removeIf(&keys.RSAs, &keys.RSAIDs, hasKID[*rsa.PublicKey](kid))
+	keysRemoved += removeIf(&keys.HMACs, &keys.HMACIDs, hasKID[*HMAC](kid))
+	keysRemoved += removeIf(&keys.Secrets, &keys.SecretIDs, hasKID[[]byte](kid))
+	keysRemoved += removeIf(&keys.Algorithms, &keys.AlgorithmIDs, hasKID[Algorithm](kid))
+	return keysRemoved
+}
+
+// RemoveKey deletes each occurrence of key, regardless of its key ID, and it
+// returns the number of keys removed. Private keys match their public
+// counterpart. Secrets match by value, and *HMAC matches by identity.
+// Algorithms need not be comparable, so they are removed by key ID only. The
+// respective key ID slices stay aligned. Use SyncRegister for removal while in
+// use.
+func (keys *KeyRegister) RemoveKey(key interface{}) (keysRemoved int) {
+	switch t := key.(type) {
+	case *ecdsa.PrivateKey:
+		return keys.RemoveKey(&t.PublicKey)
+	case ed25519.PrivateKey:
+		return keys.RemoveKey(t.Public())
+	case *rsa.PrivateKey:
+		return keys.RemoveKey(&t.PublicKey)
+
+	case *ecdsa.PublicKey:
+		return removeIf(&keys.ECDSAs, &keys.ECDSAIDs, func(present *ecdsa.PublicKey, _ string) bool {
+			return present.Equal(t)
+		})
+	case ed25519.PublicKey:
+		return removeIf(&keys.EdDSAs, &keys.EdDSAIDs, func(present ed25519.PublicKey, _ string) bool {
+			return present.Equal(t)
+		})
+	case *rsa.PublicKey:
+		return removeIf(&keys.RSAs, &keys.RSAIDs, func(present *rsa.PublicKey, _ string) bool {
+			return present.Equal(t)
+		})
+	case *HMAC:
+		return removeIf(&keys.HMACs, &keys.HMACIDs, func(present *HMAC, _ string) bool {
+			return present == t
+		})
+	case []byte:
+		return removeIf(&keys.Secrets, &keys.SecretIDs, func(present []byte, _ string) bool {
+			return hmac.Equal(present, t)
+		})
+	}
+	return 0
+}
+
+// HasKID returns a removeIf match on key ID.
+func hasKID[T any](kid string) func(key T, id string) bool {
+	return func(_ T, id string) bool { return id == kid }
+}
+
+// RemoveIf deletes the entries for which match is true from both keys and ids.
+// The slices are copied, as concurrent reads may hold on to the previous ones.
+func removeIf[T any](keys *[]T, ids *[]string, match func(key T, id string) bool) (n int) {
+	var newKeys []T
+	var newIDs []string
+	for i, key := range *keys {
+		id := kidAt(*ids, i)
+		if match(key, id) {
+			n++
+			continue
+		}
+		newKeys = append(newKeys, key)
+		newIDs = append(newIDs, id)
+	}
+	if n != 0 {
+		*keys, *ids = newKeys, newIDs
+	}
+	return n
+}
@@ -0,0 +1,77 @@
+package jwt
+
+import (
+	"crypto/ecdsa"
+	"crypto/ed25519"
+	"crypto/rsa"
+	"testing"
+)
+
+func TestRemoveKeyID(t *testing.T) {
+	keys := KeyRegister{
+		ECDSAs:    []*ecdsa.PublicKey{&testKeyEC256.PublicKey, &testKeyEC384.PublicKey, &testKeyEC521.PublicKey},
+		ECDSAIDs:  []string{"a", "b"},
+		Secrets:   [][]byte{[]byte("one"), []byte("two")},
+		SecretIDs: []string{"b", ""},
+	}
+
+	if n := keys.RemoveKeyID(""); n != 0 {
+		t.Errorf("empty key ID removed %d keys", n)
+	}
+	if n := keys.RemoveKeyID("b"); n != 2 {
+		t.Errorf("removed %d keys, want 2", n)
+	}
+	if len(keys.ECDSAs) != 2 || keys.ECDSAs[1] != &testKeyEC521.PublicKey {
+		t.Errorf("got ECDSAs %v", keys.ECDSAs)
+	}
+	if len(keys.ECDSAIDs) != 2 || keys.ECDSAIDs[0] != "a" || keys.ECDSAIDs[1] != "" {
+		t.Errorf("got ECDSA key IDs %q, want [a, \"\"]", keys.ECDSAIDs)
+	}
+	if len(keys.Secrets) != 1 || string(keys.Secrets[0]) != "two" || len(keys.SecretIDs) != 1 || keys.SecretIDs[0] != "" {
+		t.Errorf("got secrets %q with key IDs %q", keys.Secrets, keys.SecretIDs)
+	}
+
+	c := Claims{KeyID: "a"}
+	token, err := c.ECDSASign(ES256, testKeyEC256)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := keys.Check(token); err != nil {
+		t.Error("check error:", err)
+	}
+	if n := keys.RemoveKeyID("a"); n != 1 {
+		t.Errorf("removed %d keys, want 1", n)
+	}
+	if _, err := keys.Check(token); err != ErrSigMiss {
+		t.Errorf("removed key got error %v, want %v", err, ErrSigMiss)
+	}
+}
+
+func TestRemoveKey(t *testing.T) {
+	keys := KeyRegister{
+		EdDSAs:    []ed25519.PublicKey{testKeyEd25519Public},
+		RSAs:      []*rsa.PublicKey{&testKeyRSA1024.PublicKey, &testKeyRSA2048.PublicKey},
+		RSAIDs:    []string{"", "x"},
+		Secrets:   [][]byte{[]byte("one"), []byte("two"), []byte("one")},
+		SecretIDs: []string{"1", "2", "3"},
+	}
+
+	if n := keys.RemoveKey(testKeyRSA2048); n != 1 {
+		t.Errorf("RSA private key removed %d keys, want 1", n)
+	}
+	if len(keys.RSAs) != 1 || len(keys.RSAIDs) != 1 || keys.RSAIDs[0] != "" {
+		t.Errorf("got %d RSAs with key IDs %q", len(keys.RSAs), keys.RSAIDs)
+	}
+	if n := keys.RemoveKey([]byte("one")); n != 2 {
+		t.Errorf("secret removed %d keys, want 2", n)
+	}
+	if len(keys.SecretIDs) != 1 || keys.SecretIDs[0] != "2" {
+		t.Errorf("got secret key IDs %q, want [2]", keys.SecretIDs)
+	}
+	if n := keys.RemoveKey(testKeyEd25519Private); n != 1 {
+		t.Errorf("EdDSA private key removed %d keys, want 1", n)
+	}
+	if n := keys.RemoveKey("unsupported"); n != 0 {
+		t.Errorf("string removed %d keys", n)
+	}
+}
@@ -0,0 +1,111 @@
+package jwt
+
+import (
+	"fmt"
+	"sort"
+)
+
+// Authorization claims from “JSON Web Token (JWT) Profile for OAuth 2.0 Access
+// Tokens” RFC 9068, subsection 2.2.3.1, with their semantics from “System for
+// Cross-domain Identity Management: Core Schema” RFC 7643, subsection 4.1.2.
+const (
+	GroupsClaim       = "groups"
+	RolesClaim        = "roles"
+	EntitlementsClaim = "entitlements"
+)
+
+// RoleError signals the absence of any of the roles required.
+type RoleError []string
+
+// Error honors the error interface.
+func (e RoleError) Error() string {
+	if len(e) == 1 {
+		return fmt.Sprintf("jwt: role %q not granted", e[0])
+	}
+	return fmt.Sprintf("jwt: none of roles %q granted", []string(e))
+}
+
+// Groups returns the "groups" claim. The return is nil when absent or when the
+// representation is not a string nor an array of strings.
+func (c *Claims) Groups() []string { return stringsClaim(c.Set[GroupsClaim]) }
+
+// Roles returns the "roles" claim. The return is nil when absent or when the
+// representation is not a string nor an array of strings.
+func (c *Claims) Roles() []string { return stringsClaim(c.Set[RolesClaim]) }
+
+// Entitlements returns the "entitlements" claim. The return is nil when absent
+// or when the representation is not a string nor an array of strings.
+func (c *Claims) Entitlements() []string { return stringsClaim(c.Set[EntitlementsClaim]) }
+
+// StringsClaim reads a single string as one entry, or an array of strings. The
+// return is nil for any other data type, including arrays with any non-string.
+func stringsClaim(v interface{}) []string {
+	switch t := v.(type) {
+	case string:
+		return []string{t}
+	case []string:
+		return t
+	case []interface{}:
+		a := make([]string, 0, len(t))
+		for _, o := range t {
+			s, ok := o.(string)
+			if !ok {
+				return nil
+			}
+			a = append(a, s)
+		}
+		return a
+	}
+	return nil
+}
+
+// HasRole returns whether the "roles" claim has role. Roles are case-sensitive.
+func (c *Claims) HasRole(role string) bool {
+	return contains(c.Roles(), role)
+}
+
+// AnyRole returns whether the "roles" claim has one or more of roles.
+func (c *Claims) AnyRole(roles ...string) bool {
+	granted := c.Roles()
+	for _, role := range roles {
+		if contains(granted, role) {
+			return true
+		}
+	}
+	return false
+}
+
+// RBAC maps roles to their permissions, for role-based access control. The
+// permissions follow Scope semantics, i.e., entries with a trailing asterisk
+// are wildcards. Roles are read from the "roles" claim.
+type RBAC map[string]Scope
+
+// Permissions returns the permissions of all roles in c, sorted, without
+// duplicates. Roles which are not in the map grant nothing.
+func (m RBAC) Permissions(c *Claims) Scope {
+	set := make(map[string]struct{})
+	for _, role := range c.Roles() {
+		for _, p := range m[role] {
+			set[p] = struct{}{}
+		}
+	}
+	if len(set) == 0 {
+		return nil
+	}
+	perms := make(Scope, 0, len(set))
+	for p := range set {
+		perms = append(perms, p)
+	}
+	sort.Strings(perms)
+	return perms
+}
+
+// Allow returns whether any of the roles in c grants permission.
+func (m RBAC) Allow(c *Claims, permission string) bool {
+	for _, role := range c.Roles() {
+		if m[role].Has(permission) {
+			return true
+		}
+	}
+	return false
+}
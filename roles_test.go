@@ -0,0 +1,87 @@
+package jwt
+
+import (
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"reflect"
+	"testing"
+)
+
+func TestAuthorizationClaims(t *testing.T) {
+	c := Claims{Set: map[string]interface{}{
+		"groups":       "staff",
+		"roles":        []interface{}{"reader", "billing-admin"},
+		"entitlements": []interface{}{"export", 42.0},
+	}}
+	if got := c.Groups(); !reflect.DeepEqual(got, []string{"staff"}) {
+		t.Errorf("got groups %q, want staff", got)
+	}
+	if got := c.Roles(); !reflect.DeepEqual(got, []string{"reader", "billing-admin"}) {
+		t.Errorf("got roles %q, want reader and billing-admin", got)
+	}
+	if got := c.Entitlements(); got != nil {
+		t.Errorf("got entitlements %q for an array with a number, want nil", got)
+	}
+
+	if !c.HasRole("reader") || c.HasRole("Reader") || c.HasRole("staff") {
+		t.Error("HasRole mismatch")
+	}
+	if !c.AnyRole("writer", "billing-admin") || c.AnyRole("writer") || c.AnyRole() {
+		t.Error("AnyRole mismatch")
+	}
+}
+
+func TestRBAC(t *testing.T) {
+	rbac := RBAC{
+		"reader":        {"invoice:read", "report:read"},
+		"billing-admin": {"invoice:*"},
+		"auditor":       {"*"},
+	}
+	c := Claims{Set: map[string]interface{}{
+		"roles": []interface{}{"reader", "billing-admin", "unknown"},
+	}}
+
+	want := Scope{"invoice:*", "invoice:read", "report:read"}
+	if got := rbac.Permissions(&c); !reflect.DeepEqual(got, want) {
+		t.Errorf("got permissions %q, want %q", got, want)
+	}
+	for perm, want := range map[string]bool{
+		"invoice:write": true,
+		"report:read":   true,
+		"report:write":  false,
+		"":              false,
+	} {
+		if got := rbac.Allow(&c, perm); got != want {
+			t.Errorf("permission %q allowed %t, want %t", perm, got, want)
+		}
+	}
+	if rbac.Allow(new(Claims), "invoice:read") {
+		t.Error("claims without roles allowed")
+	}
+}
+
+func TestExpectAnyRole(t *testing.T) {
+	c := Claims{Set: map[string]interface{}{"roles": "reader"}}
+	if err := (&Expect{AnyRole: []string{"writer", "reader"}}).Accept(&c); err != nil {
+		t.Error("role match got error:", err)
+	}
+
+	err := (&Expect{AnyRole: []string{"writer", "admin"}}).Accept(&c)
+	var role RoleError
+	if !errors.As(err, &role) || len(role) != 2 {
+		t.Fatalf("role miss got error %v, want a RoleError", err)
+	}
+	if got, want := err.Error(), `jwt: none of roles ["writer" "admin"] granted`; got != want {
+		t.Errorf("got error %q, want %q", got, want)
+	}
+	if code, _ := OAuthError(err); code != InsufficientScope {
+		t.Errorf("got OAuth error code %q, want %q", code, InsufficientScope)
+	}
+
+	w := httptest.NewRecorder()
+	new(Handler).deny(w, err)
+	if w.Code != http.StatusForbidden {
+		t.Errorf("got HTTP status %d, want %d", w.Code, http.StatusForbidden)
+	}
+}
@@ -0,0 +1,81 @@
+package jwt
+
+import "strings"
+
+// Scope is a set of access permissions, as described by “The OAuth 2.0
+// Authorization Framework” RFC 6749, subsection 3.3. Entries with a trailing
+// asterisk are wildcards, e.g., "repo:*" matches both "repo:read" and
+// "repo:write", and a plain "*" matches any scope.
+type Scope []string
+
+// ParseScope reads either a space-delimited string or an array of strings,
+// conform the JSON mapping of Claims.Set. The return is false for any other
+// data type.
+func ParseScope(v interface{}) (Scope, bool) {
+	switch t := v.(type) {
+	case string:
+		return Scope(strings.Fields(t)), true
+	case []string:
+		return Scope(t), true
+	case []interface{}:
+		s := make(Scope, 0, len(t))
+		for _, o := range t {
+			e, ok := o.(string)
+			if !ok {
+				return nil, false
+			}
+			s = append(s, e)
+		}
+		return s, true
+	}
+	return nil, false
+}
+
+// Scopes returns the "scope" claim from RFC 8693, subsection 4.2, with the
+// "scp" claim as a fallback. The return is nil when neither is present or when
+// the representation is not a string nor an array of strings.
+func (c *Claims) Scopes() Scope {
+	if s, ok := ParseScope(c.Set["scope"]); ok {
+		return s
+	}
+	s, _ := ParseScope(c.Set["scp"])
+	return s
+}
+
+// Has returns whether any entry of s grants scope.
+func (s Scope) Has(scope string) bool {
+	for _, granted := range s {
+		if granted == scope {
+			return true
+		}
+		if l := len(granted) - 1; l >= 0 && granted[l] == '*' && strings.HasPrefix(scope, granted[:l]) {
+			return true
+		}
+	}
+	return false
+}
+
+// HasAll returns whether s grants each of the scopes.
+func (s Scope) HasAll(scopes ...string) bool {
+	for _, scope := range scopes {
+		if !s.Has(scope) {
+			return false
+		}
+	}
+	return true
+}
+
+// HasAny returns whether s grants one or more of the scopes.
+func (s Scope) HasAny(scopes ...string) bool {
+	for _, scope := range scopes {
+		if s.Has(scope) {
+			return true
+		}
+	}
+	return false
+}
+
+// String returns the space-delimited representation.
+func (s Scope) String() string {
+	return strings.Join(s, " ")
+}
@@ -0,0 +1,61 @@
+package jwt
+
+import (
+	"encoding/json"
+	"reflect"
+	"testing"
+)
+
+func TestClaimsScopes(t *testing.T) {
+	golden := []struct {
+		payload string
+		want    Scope
+	}{
+		{`{}`, nil},
+		{`{"scope":""}`, Scope{}},
+		{`{"scope":"openid  profile\temail"}`, Scope{"openid", "profile", "email"}},
+		{`{"scope":["openid","profile"]}`, Scope{"openid", "profile"}},
+		{`{"scp":["read"]}`, Scope{"read"}},
+		{`{"scope":"a","scp":["b"]}`, Scope{"a"}},
+		{`{"scope":42,"scp":"b"}`, Scope{"b"}},
+		{`{"scope":["a",42]}`, nil},
+	}
+	for _, gold := range golden {
+		var c Claims
+		if err := json.Unmarshal([]byte(gold.payload), &c.Set); err != nil {
+			t.Fatal(err)
+		}
+		if got := c.Scopes(); !reflect.DeepEqual(got, gold.want) {
+			t.Errorf("%s got scopes %q, want %q", gold.payload, got, gold.want)
+		}
+	}
+}
+
+func TestScopeMatch(t *testing.T) {
+	s := Scope{"openid", "repo:*", "user:email"}
+
+	for _, scope := range []string{"openid", "repo:", "repo:read", "repo:write:all", "user:email"} {
+		if !s.Has(scope) {
+			t.Errorf("%q does not have %q", s, scope)
+		}
+	}
+	for _, scope := range []string{"", "open", "repo", "user:*", "user:emails", "admin"} {
+		if s.Has(scope) {
+			t.Errorf("%q has %q", s, scope)
+		}
+	}
+
+	if !s.HasAll() || !s.HasAll("openid", "repo:read") || s.HasAll("openid", "admin") {
+		t.Error("HasAll mismatch")
+	}
+	if s.HasAny() || !s.HasAny("admin", "repo:read") || s.HasAny("admin", "root") {
+		t.Error("HasAny mismatch")
+	}
+	if !(Scope{"*"}).Has("anything") {
+		t.Error("plain wildcard does not match")
+	}
+
+	if got, want := s.String(), "openid repo:* user:email"; got != want {
+		t.Errorf("got string %q, want %q", got, want)
+	}
+}
@@ -0,0 +1,97 @@
+package jwt
+
+import (
+	"fmt"
+	"sync"
+	"time"
+)
+
+// SourceSecret resolves kid with the SecretSource, if any. The return is nil
+// for unknown key IDs. The secret is a private copy, as the source may retain
+// its return. Callers should wipe the secret after use.
+func (keys *KeyRegister) sourceSecret(kid string) ([]byte, error) {
+	if keys.SecretSource == nil || kid == "" {
+		return nil, nil
+	}
+	secret, err := keys.SecretSource(kid)
+	if err != nil {
+		return nil, fmt.Errorf("jwt: secret source for key ID %q: %w", kid, err)
+	}
+	if len(secret) == 0 {
+		return nil, nil
+	}
+	return append([]byte(nil), secret...), nil
+}
+
+// SecretCache memoizes the HMAC secrets from a source per key ID. Unknown key
+// IDs and errors are not cached. Expired secrets are zeroed, such that they do
+// not linger in memory. The zero value is not usable; Source must be set.
+//
+//	keys.SecretSource = (&jwt.SecretCache{
+//		Source: fetchFromVault,
+//		TTL:    time.Minute,
+//	}).Secret
+//
+// Multiple goroutines may invoke methods on a SecretCache simultaneously.
+type SecretCache struct {
+	// Source resolves a secret by key ID, e.g., from a key management
+	// service. The return is nil for unknown key IDs. Source may wipe the
+	// secret after it returns, as the cache keeps its own copy.
+	Source func(kid string) ([]byte, error)
+
+	// TTL is the maximum age of cache entries.
+	TTL time.Duration
+
+	mutex     sync.Mutex
+	entries   map[string]secretEntry
+	nextSweep time.Time
+}
+
+type secretEntry struct {
+	expires time.Time
+	secret  []byte
+}
+
+// Secret returns either a copy of the cached secret or the result of Source.
+// The signature matches KeyRegister.SecretSource.
+func (cache *SecretCache) Secret(kid string) ([]byte, error) {
+	now := time.Now()
+
+	cache.mutex.Lock()
+	entry, ok := cache.entries[kid]
+	if ok && now.Before(entry.expires) {
+		secret := append([]byte(nil), entry.secret...)
+		cache.mutex.Unlock()
+		return secret, nil
+	}
+	cache.mutex.Unlock()
+
+	secret, err := cache.Source(kid)
+	if err != nil || len(secret) == 0 {
+		return nil, err
+	}
+	entry = secretEntry{
+		expires: now.Add(cache.TTL),
+		secret:  append([]byte(nil), secret...),
+	}
+
+	cache.mutex.Lock()
+	defer cache.mutex.Unlock()
+	if cache.entries == nil {
+		cache.entries = make(map[string]secretEntry)
+	}
+	if now.After(cache.nextSweep) {
+		for k, e := range cache.entries {
+			if !now.Before(e.expires) {
+				wipe(e.secret)
+				delete(cache.entries, k)
+			}
+		}
+		cache.nextSweep = now.Add(cache.TTL)
+	}
+	if old, ok := cache.entries[kid]; ok {
+		wipe(old.secret)
+	}
+	cache.entries[kid] = entry
+	return secret, nil
+}
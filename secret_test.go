@@ -0,0 +1,101 @@
+package jwt
+
+import (
+	"errors"
+	"testing"
+	"time"
+)
+
+func TestSecretSource(t *testing.T) {
+	var calls int
+	cache := &SecretCache{
+		Source: func(kid string) ([]byte, error) {
+			calls++
+			switch kid {
+			case "vault-1":
+				return []byte("guest"), nil
+			case "down":
+				return nil, errors.New("vault sealed")
+			}
+			return nil, nil
+		},
+		TTL: time.Minute,
+	}
+	keys := KeyRegister{SecretSource: cache.Secret}
+
+	c := &Claims{KeyID: "vault-1"}
+	c.Subject = "alice"
+	token, err := c.HMACSign(HS256, []byte("guest"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	for i := 0; i < 3; i++ {
+		got, err := keys.Check(token)
+		if err != nil {
+			t.Fatalf("check %d error: %v", i, err)
+		}
+		if got.Subject != "alice" {
+			t.Errorf("check %d got subject %q", i, got.Subject)
+		}
+	}
+	if calls != 1 {
+		t.Errorf("got %d source calls, want 1", calls)
+	}
+
+	forged, err := c.HMACSign(HS256, []byte("guess"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := keys.Check(forged); err != ErrSigMiss {
+		t.Errorf("got error %v, want %v", err, ErrSigMiss)
+	}
+
+	c.KeyID = "down"
+	token, err = c.HMACSign(HS256, []byte("guest"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := keys.Check(token); err == nil {
+		t.Error("source error not returned")
+	}
+
+	c.KeyID = "unknown"
+	token, err = c.HMACSign(HS256, []byte("guest"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := keys.Check(token); err != ErrSigMiss {
+		t.Errorf("unknown key ID got error %v, want %v", err, ErrSigMiss)
+	}
+}
+
+func TestSecretSourceRetained(t *testing.T) {
+	secret := []byte("retained by source")
+	keys := KeyRegister{SecretSource: func(kid string) ([]byte, error) {
+		return secret, nil // no copy
+	}}
+
+	c := &Claims{KeyID: "k"}
+	c.Subject = "alice"
+	token, err := c.HMACSign(HS256, []byte("retained by source"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	for i := 0; i < 2; i++ {
+		if _, err := keys.Check(token); err != nil {
+			t.Fatalf("check %d error: %v", i, err)
+		}
+	}
+	if string(secret) != "retained by source" {
+		t.Fatalf("source secret modified to %q", secret)
+	}
+
+	c.Subject = "admin"
+	forged, err := c.HMACSign(HS256, make([]byte, len(secret)))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := keys.Check(forged); err != ErrSigMiss {
+		t.Errorf("token with zero key got error %v, want %v", err, ErrSigMiss)
+	}
+}
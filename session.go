@@ -0,0 +1,112 @@
+//go:build !jwtverifyonly
+
+package jwt
+
+import (
+	"net/http"
+	"time"
+)
+
+// SessionManager maintains stateless sessions with HS256 tokens in cookies.
+// Sessions expire after Lifetime of inactivity, as each Load re-issues tokens
+// which are older than RefreshAfter.
+//
+// Multiple goroutines may invoke methods on a SessionManager simultaneously.
+type SessionManager struct {
+	// HMAC signs and checks the session tokens.
+	HMAC *HMAC
+
+	// Cookie is the template for session cookies. Name must be set.
+	// Value, Expires and MaxAge are ignored.
+	Cookie http.Cookie
+
+	// Lifetime is the validity of each session token.
+	Lifetime time.Duration
+
+	// RefreshAfter is the age of session tokens which causes Load to
+	// re-issue. Zero defaults to half the Lifetime.
+	RefreshAfter time.Duration
+
+	// TemporalLeeway controls the tolerance with time constraints.
+	TemporalLeeway time.Duration
+}
+
+// NewSessionManager returns a new instance with secure cookie defaults.
+func NewSessionManager(secret []byte, lifetime time.Duration) (*SessionManager, error) {
+	h, err := NewHMAC(HS256, secret)
+	if err != nil {
+		return nil, err
+	}
+	return &SessionManager{
+		HMAC: h,
+		Cookie: http.Cookie{
+			Name:     "session",
+			Path:     "/",
+			Secure:   true,
+			HttpOnly: true,
+			SameSite: http.SameSiteLaxMode,
+		},
+		Lifetime: lifetime,
+	}, nil
+}
+
+// Load returns the claims of the session from r. The return is ErrNoSession
+// when r has no session cookie. Sessions older than RefreshAfter are extended
+// with a new token on w. Hence, Load must be called before any writes to the
+// body of w.
+func (m *SessionManager) Load(w http.ResponseWriter, r *http.Request) (*Claims, error) {
+	cookie, err := r.Cookie(m.Cookie.Name)
+	if err != nil {
+		return nil, ErrNoSession
+	}
+	claims, err := m.HMAC.Check([]byte(cookie.Value))
+	if err != nil {
+		return nil, err
+	}
+	now := Clock()
+	if claims.Expires == nil {
+		return nil, errNoExpires
+	}
+	if err := claims.AcceptTemporal(now, m.TemporalLeeway); err != nil {
+		return nil, err
+	}
+
+	refreshAfter := m.RefreshAfter
+	if refreshAfter == 0 {
+		refreshAfter = m.Lifetime / 2
+	}
+	if claims.Issued == nil || !now.Before(claims.Issued.Time().Add(refreshAfter)) {
+		if err := m.Save(w, claims); err != nil {
+			return nil, err
+		}
+	}
+	return claims, nil
+}
+
+// Save issues a session token with the claims on w. The issue time and the
+// expiry of c are set conform Lifetime.
+func (m *SessionManager) Save(w http.ResponseWriter, c *Claims) error {
+	now := Clock().Truncate(time.Second)
+	c.Issued = NewNumericTime(now)
+	c.Expires = NewNumericTime(now.Add(m.Lifetime))
+	token, err := m.HMAC.Sign(c)
+	if err != nil {
+		return err
+	}
+
+	cookie := m.Cookie
+	cookie.Value = string(token)
+	cookie.Expires = now.Add(m.Lifetime)
+	cookie.MaxAge = int(m.Lifetime / time.Second)
+	http.SetCookie(w, &cookie)
+	return nil
+}
+
+// Clear ends the session on w.
+func (m *SessionManager) Clear(w http.ResponseWriter) {
+	cookie := m.Cookie
+	cookie.Value = ""
+	cookie.Expires = time.Unix(0, 0)
+	cookie.MaxAge = -1
+	http.SetCookie(w, &cookie)
+}
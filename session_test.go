@@ -0,0 +1,97 @@
+package jwt
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+)
+
+func TestSessionManager(t *testing.T) {
+	m, err := NewSessionManager([]byte("guest"), time.Hour)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// issue
+	var c Claims
+	c.Subject = "alice"
+	resp := httptest.NewRecorder()
+	if err := m.Save(resp, &c); err != nil {
+		t.Fatal("save error:", err)
+	}
+	cookies := resp.Result().Cookies()
+	if len(cookies) != 1 {
+		t.Fatalf("got %d cookies, want 1", len(cookies))
+	}
+	if cookie := cookies[0]; cookie.Name != "session" || !cookie.HttpOnly || !cookie.Secure || cookie.MaxAge != 3600 {
+		t.Errorf("got cookie %+v", cookie)
+	}
+
+	// load fresh
+	req := httptest.NewRequest("GET", "/", nil)
+	req.AddCookie(cookies[0])
+	resp = httptest.NewRecorder()
+	got, err := m.Load(resp, req)
+	if err != nil {
+		t.Fatal("load error:", err)
+	}
+	if got.Subject != "alice" {
+		t.Errorf("got subject %q, want alice", got.Subject)
+	}
+	if n := len(resp.Result().Cookies()); n != 0 {
+		t.Errorf("fresh session got %d cookies, want none", n)
+	}
+
+	// load aged
+	m.RefreshAfter = -time.Second
+	resp = httptest.NewRecorder()
+	if _, err := m.Load(resp, req); err != nil {
+		t.Fatal("load error:", err)
+	}
+	if n := len(resp.Result().Cookies()); n != 1 {
+		t.Errorf("aged session got %d cookies, want a refresh", n)
+	}
+
+	// end
+	resp = httptest.NewRecorder()
+	m.Clear(resp)
+	if cookies := resp.Result().Cookies(); len(cookies) != 1 || cookies[0].MaxAge >= 0 {
+		t.Errorf("clear got cookies %+v", cookies)
+	}
+}
+
+func TestSessionManagerReject(t *testing.T) {
+	m, err := NewSessionManager([]byte("guest"), time.Hour)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	req := httptest.NewRequest("GET", "/", nil)
+	if _, err := m.Load(httptest.NewRecorder(), req); err != ErrNoSession {
+		t.Errorf("no cookie got error %v, want %v", err, ErrNoSession)
+	}
+
+	// token without expiry
+	token, err := m.HMAC.Sign(new(Claims))
+	if err != nil {
+		t.Fatal(err)
+	}
+	req.AddCookie(&http.Cookie{Name: "session", Value: string(token)})
+	if _, err := m.Load(httptest.NewRecorder(), req); err != errNoExpires {
+		t.Errorf("no expiry got error %v, want %v", err, errNoExpires)
+	}
+
+	// expired token
+	var c Claims
+	c.Expires = NewNumericTime(time.Now().Add(-time.Minute))
+	token, err = m.HMAC.Sign(&c)
+	if err != nil {
+		t.Fatal(err)
+	}
+	req = httptest.NewRequest("GET", "/", nil)
+	req.AddCookie(&http.Cookie{Name: "session", Value: string(token)})
+	if _, err := m.Load(httptest.NewRecorder(), req); err != errExpired {
+		t.Errorf("expired got error %v, want %v", err, errExpired)
+	}
+}
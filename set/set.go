@@ -0,0 +1,164 @@
+// Package set implements “Security Event Token (SET)” RFC 8417.
+// Tokens are signed and checked with the regular jwt package. This package
+// only adds the SET specifics on top of jwt.Claims.
+package set
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"strings"
+
+	"github.com/pascaldekloe/jwt"
+)
+
+// MIMEType is the IANA registered media type.
+const MIMEType = "application/secevent+jwt"
+
+// Type is the "typ" header value for SETs.
+const Type = "secevent+jwt"
+
+// Header is the JOSE header addition for SET production. Pass it as one of
+// the extraHeaders to any of the jwt.Claims sign methods.
+//
+// “The "typ" (type) header parameter ... SHOULD be "secevent+jwt".”
+// — “Security Event Token (SET)” RFC 8417, subsection 2.3
+var Header = json.RawMessage(`{"typ":"secevent+jwt"}`)
+
+// EventsClaim is the SET specific claim name.
+const eventsClaim = "events"
+
+var (
+	errNoEvents  = errors.New(`set: events ["events"] claim absent`)
+	errEmpty     = errors.New(`set: events ["events"] claim without any event`)
+	errExpires   = errors.New(`set: expiration time ["exp"] not allowed`)
+	errNoType    = errors.New(`set: JOSE header without "typ"`)
+	errTypeMatch = errors.New(`set: JOSE header "typ" is not ` + Type)
+)
+
+// ErrNoEvent signals the absence of an event type in a SET.
+var ErrNoEvent = errors.New("set: no such event in token")
+
+// Token is a validated SET.
+type Token struct {
+	// Claims are the (signed) statements of the JWT.
+	*jwt.Claims
+
+	// Events maps the event type URI to its payload, which is a
+	// JSON object.
+	Events map[string]json.RawMessage
+
+	// TransactionID is the optional "txn" claim.
+	TransactionID string
+
+	// EventTime is the optional "toe" claim.
+	EventTime *jwt.NumericTime
+}
+
+// Apply sets the events claim on c. Each event payload must encode as a JSON
+// object. Producers must not use an expiration time on SETs.
+func Apply(c *jwt.Claims, events map[string]interface{}) error {
+	if len(events) == 0 {
+		return errEmpty
+	}
+	if c.Expires != nil {
+		return errExpires
+	}
+	for uri, payload := range events {
+		raw, err := json.Marshal(payload)
+		if err != nil {
+			return fmt.Errorf("set: event %q payload: %w", uri, err)
+		}
+		if len(raw) == 0 || raw[0] != '{' {
+			return fmt.Errorf("set: event %q payload is not a JSON object", uri)
+		}
+	}
+
+	if c.Set == nil {
+		c.Set = make(map[string]interface{})
+	}
+	c.Set[eventsClaim] = events
+	return nil
+}
+
+// Check verifies the signature with keys and then validates the claims as a
+// SET with Parse. Use AcceptTemporal and AcceptAudience on the claims to
+// complete the verification.
+func Check(token []byte, keys *jwt.KeyRegister) (*Token, error) {
+	claims, err := keys.Check(token)
+	if err != nil {
+		return nil, err
+	}
+	return Parse(claims)
+}
+
+// Parse validates claims as a SET. The claims are expected to come from one of
+// the jwt check functions, i.e., they should include the RawHeader and Raw.
+func Parse(claims *jwt.Claims) (*Token, error) {
+	var header struct {
+		Typ *string `json:"typ"`
+	}
+	if err := json.Unmarshal(claims.RawHeader, &header); err != nil {
+		return nil, fmt.Errorf("set: malformed JOSE header: %w", err)
+	}
+	if header.Typ == nil {
+		return nil, errNoType
+	}
+	// “... the "application/" prefix SHOULD be omitted ...” and
+	// media types are case-insensitive.
+	// — “JSON Web Signature (JWS)” RFC 7515, subsection 4.1.9
+	typ := *header.Typ
+	if len(typ) > len("application/") && strings.EqualFold(typ[:len("application/")], "application/") {
+		typ = typ[len("application/"):]
+	}
+	if !strings.EqualFold(typ, Type) {
+		return nil, errTypeMatch
+	}
+
+	if claims.Expires != nil {
+		return nil, errExpires
+	}
+	if _, ok := claims.Set["exp"]; ok {
+		return nil, errExpires
+	}
+
+	var payload struct {
+		Events map[string]json.RawMessage `json:"events"`
+		Txn    string                     `json:"txn"`
+		Toe    *jwt.NumericTime           `json:"toe"`
+	}
+	if err := json.Unmarshal(claims.Raw, &payload); err != nil {
+		return nil, fmt.Errorf("set: malformed payload: %w", err)
+	}
+	if payload.Events == nil {
+		return nil, errNoEvents
+	}
+	if len(payload.Events) == 0 {
+		return nil, errEmpty
+	}
+	for uri, raw := range payload.Events {
+		if len(raw) == 0 || raw[0] != '{' {
+			return nil, fmt.Errorf("set: event %q payload is not a JSON object", uri)
+		}
+	}
+
+	return &Token{
+		Claims:        claims,
+		Events:        payload.Events,
+		TransactionID: payload.Txn,
+		EventTime:     payload.Toe,
+	}, nil
+}
+
+// Event decodes the payload of an event type into v, conform json.Unmarshal.
+// The return is ErrNoEvent when the token has no such event type.
+func (t *Token) Event(uri string, v interface{}) error {
+	raw, ok := t.Events[uri]
+	if !ok {
+		return ErrNoEvent
+	}
+	if err := json.Unmarshal(raw, v); err != nil {
+		return fmt.Errorf("set: event %q payload: %w", uri, err)
+	}
+	return nil
+}
@@ -0,0 +1,110 @@
+package set
+
+import (
+	"crypto/ed25519"
+	"encoding/json"
+	"testing"
+	"time"
+
+	"github.com/pascaldekloe/jwt"
+)
+
+var testKey = ed25519.NewKeyFromSeed(make([]byte, ed25519.SeedSize))
+
+var testKeys = &jwt.KeyRegister{EdDSAs: []ed25519.PublicKey{testKey.Public().(ed25519.PublicKey)}}
+
+const testEventURI = "https://schemas.openid.net/secevent/risc/event-type/account-disabled"
+
+func TestRoundTrip(t *testing.T) {
+	var c jwt.Claims
+	c.Issuer = "https://idp.example.com/"
+	c.Issued = jwt.NewNumericTime(time.Unix(1458496404, 0))
+	c.ID = "4d3559ec67504aaba65d40b0363faad8"
+	c.Audiences = []string{"https://scim.example.com/Feeds/98d52461fa5bbc879593b7754"}
+	err := Apply(&c, map[string]interface{}{
+		testEventURI: map[string]string{"reason": "hijacking"},
+	})
+	if err != nil {
+		t.Fatal("apply error:", err)
+	}
+	token, err := c.EdDSASign(testKey, Header)
+	if err != nil {
+		t.Fatal("sign error:", err)
+	}
+
+	got, err := Check(token, testKeys)
+	if err != nil {
+		t.Fatalf("check %q error: %s", token, err)
+	}
+	if got.Issuer != c.Issuer || got.ID != c.ID {
+		t.Errorf("got issuer %q and ID %q, want %q and %q", got.Issuer, got.ID, c.Issuer, c.ID)
+	}
+	var payload struct {
+		Reason string `json:"reason"`
+	}
+	if err := got.Event(testEventURI, &payload); err != nil {
+		t.Fatal("event error:", err)
+	}
+	if payload.Reason != "hijacking" {
+		t.Errorf("got reason %q, want hijacking", payload.Reason)
+	}
+	if err := got.Event("urn:example:absent", &payload); err != ErrNoEvent {
+		t.Errorf("absent event got error %v, want %v", err, ErrNoEvent)
+	}
+}
+
+func TestApplyErrors(t *testing.T) {
+	var c jwt.Claims
+	if err := Apply(&c, nil); err != errEmpty {
+		t.Errorf("no events got error %v, want %v", err, errEmpty)
+	}
+	if err := Apply(&c, map[string]interface{}{testEventURI: "text"}); err == nil {
+		t.Error("string payload accepted")
+	}
+
+	c.Expires = jwt.NewNumericTime(time.Now())
+	if err := Apply(&c, map[string]interface{}{testEventURI: struct{}{}}); err != errExpires {
+		t.Errorf("expiry got error %v, want %v", err, errExpires)
+	}
+}
+
+func TestParseErrors(t *testing.T) {
+	golden := []struct {
+		header, payload string
+		err             error
+	}{
+		{`{"alg":"none"}`, `{"events":{}}`, errNoType},
+		{`{"typ":"JWT"}`, `{"events":{}}`, errTypeMatch},
+		{`{"typ":"secevent+jwt"}`, `{}`, errNoEvents},
+		{`{"typ":"secevent+jwt"}`, `{"events":{}}`, errEmpty},
+		{`{"typ":"application/secevent+jwt"}`, `{"events":{"a":{}},"exp":1}`, errExpires},
+		{`{"typ":"Secevent+JWT"}`, `{"events":{"a":{}},"exp":"soon"}`, errExpires},
+	}
+	for _, gold := range golden {
+		c := &jwt.Claims{
+			RawHeader: json.RawMessage(gold.header),
+			Raw:       json.RawMessage(gold.payload),
+		}
+		if err := json.Unmarshal(c.Raw, &c.Set); err != nil {
+			t.Fatal(err)
+		}
+		if exp, ok := c.Set["exp"].(float64); ok {
+			c.Expires = (*jwt.NumericTime)(&exp)
+		}
+
+		_, err := Parse(c)
+		if err != gold.err {
+			t.Errorf("%s.%s got error %v, want %v", gold.header, gold.payload, err, gold.err)
+		}
+	}
+}
+
+func TestParseEventNotObject(t *testing.T) {
+	c := &jwt.Claims{
+		RawHeader: json.RawMessage(`{"typ":"secevent+jwt"}`),
+		Raw:       json.RawMessage(`{"events":{"a":[]}}`),
+	}
+	if _, err := Parse(c); err == nil {
+		t.Error("array event payload accepted")
+	}
+}
@@ -1,3 +1,5 @@
+//go:build !jwtverifyonly
+
 package jwt
 
 import (
@@ -21,7 +23,7 @@ import (
 //	token                 :≡ tokenWithoutSignature '.' signature-base64
 //
 // The JOSE header (content) can be extended with extraHeaders, in the form of
-// JSON objects. Redundant and/or duplicate keys are applied as provided.
+// JSON objects. Overrides of "alg" or "kid" fail with ErrHeaderOverride.
 func (c *Claims) FormatWithoutSign(alg string, extraHeaders ...json.RawMessage) (tokenWithoutSignature []byte, err error) {
 	return c.newToken(alg, 0, extraHeaders)
 }
@@ -32,30 +34,38 @@ func (c *Claims) FormatWithoutSign(alg string, extraHeaders ...json.RawMessage)
 // ES256, P-384 for ES384 and P-521 for ES512) or risk malformed token production.
 //
 // The JOSE header (content) can be extended with extraHeaders, in the form of
-// JSON objects. Redundant and/or duplicate keys are applied as provided.
+// JSON objects. Overrides of "alg" or "kid" fail with ErrHeaderOverride.
 func (c *Claims) ECDSASign(alg string, key *ecdsa.PrivateKey, extraHeaders ...json.RawMessage) (token []byte, err error) {
+	return AppendECDSASign(nil, c, alg, key, extraHeaders...)
+}
+
+// AppendECDSASign is like Claims.ECDSASign, yet it appends the token to dst.
+// Pooled buffers with sufficient capacity save on allocation. See
+// Claims.TokenSize for sizing.
+func AppendECDSASign(dst []byte, c *Claims, alg string, key *ecdsa.PrivateKey, extraHeaders ...json.RawMessage) ([]byte, error) {
 	hash, err := hashLookup(alg, ECDSAAlgs)
 	if err != nil {
-		return nil, err
+		return dst, err
 	}
 	digest := hash.New()
 
 	// signature contains pair (r, s) as per RFC 7518, subsection 3.4
 	paramLen := (key.Curve.Params().BitSize + 7) / 8
-	token, err = c.newToken(alg, encoding.EncodedLen(paramLen*2), extraHeaders)
+	encSigLen := encoding.EncodedLen(paramLen * 2)
+	buf, err := c.appendToken(dst, alg, encSigLen, extraHeaders, nil)
 	if err != nil {
-		return nil, err
+		return dst, err
 	}
-	digest.Write(token)
+	end := len(buf) + 1 + encSigLen
+	digest.Write(buf[len(dst):])
 
-	buf := token[len(token):]
-	r, s, err := ecdsa.Sign(rand.Reader, key, digest.Sum(buf))
+	r, s, err := ecdsa.Sign(rand.Reader, key, digest.Sum(buf[len(buf):]))
 	if err != nil {
-		return nil, err
+		return dst, err
 	}
 
-	token = append(token, '.')
-	sig := token[len(token):cap(token)]
+	buf = append(buf, '.')
+	sig := buf[len(buf):end]
 	// serialize r and s, using sig as a buffer
 	i := len(sig)
 	for _, word := range s.Bits() {
@@ -77,109 +87,142 @@ func (c *Claims) ECDSASign(alg string, key *ecdsa.PrivateKey, extraHeaders ...js
 
 	// encoder won't overhaul source space
 	encoding.Encode(sig, sig[len(sig)-2*paramLen:])
-	return token[:cap(token)], nil
+	return buf[:end], nil
 }
 
 // EdDSASign updates the Raw fields and returns a new JWT.
 //
 // The JOSE header (content) can be extended with extraHeaders, in the form of
-// JSON objects. Redundant and/or duplicate keys are applied as provided.
+// JSON objects. Overrides of "alg" or "kid" fail with ErrHeaderOverride.
 func (c *Claims) EdDSASign(key ed25519.PrivateKey, extraHeaders ...json.RawMessage) (token []byte, err error) {
-	token, err = c.newToken(EdDSA, encoding.EncodedLen(ed25519.SignatureSize), extraHeaders)
+	return AppendEdDSASign(nil, c, key, extraHeaders...)
+}
+
+// AppendEdDSASign is like Claims.EdDSASign, yet it appends the token to dst.
+// Pooled buffers with sufficient capacity save on allocation. See
+// Claims.TokenSize for sizing.
+func AppendEdDSASign(dst []byte, c *Claims, key ed25519.PrivateKey, extraHeaders ...json.RawMessage) ([]byte, error) {
+	if err := fipsAlg(EdDSA, 0); err != nil {
+		return dst, err
+	}
+	encSigLen := encoding.EncodedLen(ed25519.SignatureSize)
+	buf, err := c.appendToken(dst, EdDSA, encSigLen, extraHeaders, nil)
 	if err != nil {
-		return nil, err
+		return dst, err
 	}
+	end := len(buf) + 1 + encSigLen
 
-	sig := ed25519.Sign(key, token)
+	sig := ed25519.Sign(key, buf[len(dst):])
 
-	token = append(token, '.')
-	encoding.Encode(token[len(token):cap(token)], sig)
-	return token[:cap(token)], nil
+	buf = append(buf, '.')
+	encoding.Encode(buf[len(buf):end], sig)
+	return buf[:end], nil
 }
 
 // HMACSign updates the Raw fields and returns a new JWT.
 // The return is an AlgError when alg is not in HMACAlgs.
 //
 // The JOSE header (content) can be extended with extraHeaders, in the form of
-// JSON objects. Redundant and/or duplicate keys are applied as provided.
+// JSON objects. Overrides of "alg" or "kid" fail with ErrHeaderOverride.
 func (c *Claims) HMACSign(alg string, secret []byte, extraHeaders ...json.RawMessage) (token []byte, err error) {
-	if len(secret) == 0 {
-		return nil, errNoSecret
-	}
+	return AppendHMACSign(nil, c, alg, secret, extraHeaders...)
+}
 
-	hash, err := hashLookup(alg, HMACAlgs)
-	if err != nil {
-		return nil, err
+// AppendHMACSign is like Claims.HMACSign, yet it appends the token to dst.
+// Pooled buffers with sufficient capacity save on allocation. See
+// Claims.TokenSize for sizing.
+func AppendHMACSign(dst []byte, c *Claims, alg string, secret []byte, extraHeaders ...json.RawMessage) ([]byte, error) {
+	if err := checkSecret(secret); err != nil {
+		return dst, err
 	}
-	digest := hmac.New(hash.New, secret)
 
-	token, err = c.newToken(alg, encoding.EncodedLen(digest.Size()), extraHeaders)
+	hash, err := hashLookup(alg, HMACAlgs)
 	if err != nil {
-		return nil, err
+		return dst, err
 	}
-	digest.Write(token)
-
-	token = append(token, '.')
-	i := cap(token) - digest.Size()
-	buf := token[i:i]
-	encoding.Encode(token[len(token):cap(token)], digest.Sum(buf))
-	return token[:cap(token)], nil
+	return appendHMAC(dst, c, alg, hmac.New(hash.New, secret), extraHeaders, nil)
 }
 
 // Sign updates the Raw fields on c and returns a new JWT.
 //
 // The JOSE header (content) can be extended with extraHeaders, in the form of
-// JSON objects. Redundant and/or duplicate keys are applied as provided.
+// JSON objects. Overrides of "alg" or "kid" fail with ErrHeaderOverride.
 func (h *HMAC) Sign(c *Claims, extraHeaders ...json.RawMessage) (token []byte, err error) {
-	digest := h.digests.Get().(hash.Hash)
-	defer h.digests.Put(digest)
-	digest.Reset()
+	return h.AppendSign(nil, c, extraHeaders...)
+}
 
-	token, err = c.newToken(h.alg, encoding.EncodedLen(digest.Size()), extraHeaders)
+// AppendSign is like Sign, yet it appends the token to dst. Pooled buffers with
+// sufficient capacity save on allocation. See Claims.TokenSize for sizing.
+func (h *HMAC) AppendSign(dst []byte, c *Claims, extraHeaders ...json.RawMessage) ([]byte, error) {
+	digest, err := h.digest()
 	if err != nil {
-		return nil, err
+		return dst, err
+	}
+	defer h.release(digest)
+
+	return appendHMAC(dst, c, h.alg, digest, extraHeaders, &h.header)
+}
+
+func appendHMAC(dst []byte, c *Claims, alg string, digest hash.Hash, extraHeaders []json.RawMessage, cache *headerCache) ([]byte, error) {
+	encSigLen := encoding.EncodedLen(digest.Size())
+	buf, err := c.appendToken(dst, alg, encSigLen, extraHeaders, cache)
+	if err != nil {
+		return dst, err
 	}
-	digest.Write(token)
+	end := len(buf) + 1 + encSigLen
+	digest.Write(buf[len(dst):])
 
-	token = append(token, '.')
-	i := cap(token) - digest.Size()
-	buf := token[i:i]
-	encoding.Encode(token[len(token):cap(token)], digest.Sum(buf))
-	return token[:cap(token)], nil
+	buf = append(buf, '.')
+	// calculate the sum in the tail of the signature space
+	i := end - digest.Size()
+	sum := digest.Sum(buf[i:i])
+	encoding.Encode(buf[len(buf):end], sum)
+	return buf[:end], nil
 }
 
 // RSASign updates the Raw fields and returns a new JWT.
 // The return is an AlgError when alg is not in RSAAlgs.
 //
 // The JOSE header (content) can be extended with extraHeaders, in the form of
-// JSON objects. Redundant and/or duplicate keys are applied as provided.
+// JSON objects. Overrides of "alg" or "kid" fail with ErrHeaderOverride.
 func (c *Claims) RSASign(alg string, key *rsa.PrivateKey, extraHeaders ...json.RawMessage) (token []byte, err error) {
+	return AppendRSASign(nil, c, alg, key, extraHeaders...)
+}
+
+// AppendRSASign is like Claims.RSASign, yet it appends the token to dst.
+// Pooled buffers with sufficient capacity save on allocation. See
+// Claims.TokenSize for sizing.
+func AppendRSASign(dst []byte, c *Claims, alg string, key *rsa.PrivateKey, extraHeaders ...json.RawMessage) ([]byte, error) {
 	hash, err := hashLookup(alg, RSAAlgs)
 	if err != nil {
-		return nil, err
+		return dst, err
+	}
+	if err := fipsRSA(&key.PublicKey); err != nil {
+		return dst, err
 	}
 	digest := hash.New()
 
-	token, err = c.newToken(alg, encoding.EncodedLen(key.Size()), extraHeaders)
+	encSigLen := encoding.EncodedLen(key.Size())
+	buf, err := c.appendToken(dst, alg, encSigLen, extraHeaders, nil)
 	if err != nil {
-		return nil, err
+		return dst, err
 	}
-	digest.Write(token)
+	end := len(buf) + 1 + encSigLen
+	digest.Write(buf[len(dst):])
 
 	var sig []byte
-	buf := token[len(token):]
 	if alg != "" && alg[0] == 'P' {
-		sig, err = rsa.SignPSS(rand.Reader, key, hash, digest.Sum(buf), &pSSOptions)
+		sig, err = rsa.SignPSS(rand.Reader, key, hash, digest.Sum(buf[len(buf):]), &pSSOptions)
 	} else {
-		sig, err = rsa.SignPKCS1v15(rand.Reader, key, hash, digest.Sum(buf))
+		sig, err = rsa.SignPKCS1v15(rand.Reader, key, hash, digest.Sum(buf[len(buf):]))
 	}
 	if err != nil {
-		return nil, err
+		return dst, err
 	}
 
-	token = append(token, '.')
-	encoding.Encode(token[len(token):cap(token)], sig)
-	return token[:cap(token)], nil
+	buf = append(buf, '.')
+	encoding.Encode(buf[len(buf):end], sig)
+	return buf[:end], nil
 }
 
 var (
@@ -198,7 +241,33 @@ var (
 	headerRS512 = []byte(`{"alg":"RS512"}`)
 )
 
+// ErrHeaderOverride rejects JOSE header additions with an "alg" member, or
+// with a "kid" member when Claims.KeyID is set. Such duplicates are resolved
+// differently across implementations. Pass AllowOverride as one of the
+// extraHeaders to permit them anyway.
+var ErrHeaderOverride = errors.New("jwt: JOSE header addition overrides a protected member")
+
+// AllowOverride is an extraHeaders option for the sign methods, which permits
+// JOSE header additions with "alg" or "kid" members. It adds nothing to the
+// header itself. The option matches by identity only; an equal value, such as
+// json.RawMessage("{}"), does not opt in. See ErrHeaderOverride for details.
+var AllowOverride = json.RawMessage(`{}`)
+
+// IsAllowOverride matches the AllowOverride option by identity.
+func isAllowOverride(raw json.RawMessage) bool {
+	return len(raw) != 0 && len(AllowOverride) != 0 && &raw[0] == &AllowOverride[0]
+}
+
+// NewToken returns the first two parts of a token, with the exact capacity for
+// the signature part.
 func (c *Claims) newToken(alg string, encSigLen int, extraHeaders []json.RawMessage) ([]byte, error) {
+	return c.appendToken(nil, alg, encSigLen, extraHeaders, nil)
+}
+
+// AppendToken appends the first two parts of a token to dst, with capacity for
+// the signature part. An optional cache for the JOSE header must be dedicated
+// to alg.
+func (c *Claims) appendToken(dst []byte, alg string, encSigLen int, extraHeaders []json.RawMessage, cache *headerCache) ([]byte, error) {
 	var payload interface{}
 	if c.Set == nil {
 		payload = &c.Registered
@@ -286,11 +355,16 @@ func (c *Claims) newToken(alg string, encSigLen int, extraHeaders []json.RawMess
 		}
 
 		if fixed != "" {
-			l := len(fixed) + encoding.EncodedLen(len(c.Raw))
-			token := make([]byte, l, l+1+encSigLen)
-			copy(token, fixed)
-			encoding.Encode(token[len(fixed):], c.Raw)
-			return token, nil
+			return c.appendPrefixed(dst, fixed, encSigLen), nil
+		}
+	}
+
+	// try cached JOSE header
+	cacheable := cache != nil && len(extraHeaders) == 0
+	if cacheable {
+		if e, ok := cache.v.Load().(*cachedHeader); ok && e.kid == c.KeyID {
+			c.RawHeader = e.raw
+			return c.appendPrefixed(dst, e.prefix, encSigLen), nil
 		}
 	}
 
@@ -301,25 +375,83 @@ func (c *Claims) newToken(alg string, encSigLen int, extraHeaders []json.RawMess
 	} else {
 		fmt.Fprintf(&header, `{"alg":%q,"kid":%q}`, alg, c.KeyID)
 	}
+	allowOverride := false
+	for _, raw := range extraHeaders {
+		if isAllowOverride(raw) {
+			allowOverride = true
+		}
+	}
 	for _, raw := range extraHeaders {
+		if isAllowOverride(raw) {
+			continue
+		}
 		if len(raw) == 0 || raw[0] != '{' {
 			return nil, errors.New("jwt: JOSE header addition is not a JSON object")
 		}
+		if !allowOverride {
+			var members map[string]json.RawMessage
+			if err := json.Unmarshal(raw, &members); err != nil {
+				return nil, fmt.Errorf("jwt: malformed JOSE header addition: %w", err)
+			}
+			if _, ok := members["alg"]; ok {
+				return nil, fmt.Errorf(`%w: "alg"`, ErrHeaderOverride)
+			}
+			if _, ok := members["kid"]; ok && c.KeyID != "" {
+				return nil, fmt.Errorf(`%w: "kid"`, ErrHeaderOverride)
+			}
+		}
 		offset := header.Len() - 1
 		header.Truncate(offset)
 		if err := json.Compact(&header, []byte(raw)); err != nil {
 			return nil, fmt.Errorf("jwt: malformed JOSE header addition: %w", err)
 		}
+		if header.Len() == offset+2 {
+			// empty object
+			header.Bytes()[offset] = '}'
+			header.Truncate(offset + 1)
+			continue
+		}
 		header.Bytes()[offset] = ','
 	}
 	c.RawHeader = json.RawMessage(header.Bytes())
 
+	if cacheable {
+		prefix := encoding.EncodeToString(header.Bytes()) + "."
+		cache.v.Store(&cachedHeader{c.KeyID, c.RawHeader, prefix})
+		return c.appendPrefixed(dst, prefix, encSigLen), nil
+	}
+
 	// compose token
 	headerLen := encoding.EncodedLen(header.Len())
 	l := headerLen + 1 + encoding.EncodedLen(len(c.Raw))
-	token := make([]byte, l, l+1+encSigLen)
-	encoding.Encode(token, header.Bytes())
-	token[headerLen] = '.'
-	encoding.Encode(token[headerLen+1:], c.Raw)
+	token := grow(dst, l+1+encSigLen)
+	offset := len(token)
+	token = token[:offset+l]
+	encoding.Encode(token[offset:], header.Bytes())
+	token[offset+headerLen] = '.'
+	encoding.Encode(token[offset+headerLen+1:], c.Raw)
 	return token, nil
 }
+
+// AppendPrefixed appends the encoded header prefix with the payload of c to
+// dst, with capacity for the signature part.
+func (c *Claims) appendPrefixed(dst []byte, prefix string, encSigLen int) []byte {
+	l := len(prefix) + encoding.EncodedLen(len(c.Raw))
+	token := grow(dst, l+1+encSigLen)
+	offset := len(token)
+	token = token[:offset+l]
+	copy(token[offset:], prefix)
+	encoding.Encode(token[offset+len(prefix):], c.Raw)
+	return token
+}
+
+// Grow returns dst with a capacity of at least n more bytes. Allocation is
+// exact, for the capacity arithmetics of the sign functions.
+func grow(dst []byte, n int) []byte {
+	if cap(dst)-len(dst) >= n {
+		return dst
+	}
+	buf := make([]byte, len(dst), len(dst)+n)
+	copy(buf, dst)
+	return buf
+}
@@ -149,6 +149,34 @@ func TestHMACSign(t *testing.T) {
 	}
 }
 
+func TestHMACSignKIDCache(t *testing.T) {
+	h, err := NewHMAC(HS256, []byte("guest"))
+	if err != nil {
+		t.Fatal("NewHMAC error", err)
+	}
+	for _, kid := range []string{"k1", "k1", "k2", "k1", ""} {
+		var c Claims
+		c.Subject = "alice"
+		c.KeyID = kid
+		got, err := h.Sign(&c)
+		if err != nil {
+			t.Fatal("sign error:", err)
+		}
+		gotHeader := string(c.RawHeader)
+
+		want, err := c.HMACSign(HS256, []byte("guest"))
+		if err != nil {
+			t.Fatal("sign error:", err)
+		}
+		if string(got) != string(want) {
+			t.Errorf("key ID %q: got token %q, want %q", kid, got, want)
+		}
+		if gotHeader != string(c.RawHeader) {
+			t.Errorf("key ID %q: got header %s, want %s", kid, gotHeader, c.RawHeader)
+		}
+	}
+}
+
 func TestHMACSignReuse(t *testing.T) {
 	var c Claims
 	c.Subject = "the world's greatest secret agent"
@@ -299,3 +327,130 @@ func TestFormatHeader(t *testing.T) {
 		}
 	}
 }
+
+func TestHeaderOverride(t *testing.T) {
+	golden := []struct {
+		kid   string
+		extra []json.RawMessage
+		want  string // header or error
+	}{
+		{"", []json.RawMessage{json.RawMessage(`{"kid":"k1"}`)}, `{"alg":"HS256","kid":"k1"}`},
+		{"k1", []json.RawMessage{json.RawMessage(`{"typ":"JWT"}`)}, `{"alg":"HS256","kid":"k1","typ":"JWT"}`},
+		{"", []json.RawMessage{json.RawMessage(`{"alg":"none"}`)}, `jwt: JOSE header addition overrides a protected member: "alg"`},
+		{"k1", []json.RawMessage{json.RawMessage(`{"kid":"k2"}`)}, `jwt: JOSE header addition overrides a protected member: "kid"`},
+		{"k1", []json.RawMessage{json.RawMessage(`{"kid":"k2"}`), AllowOverride}, `{"alg":"HS256","kid":"k1","kid":"k2"}`},
+		{"", []json.RawMessage{AllowOverride}, `{"alg":"HS256"}`},
+		{"", []json.RawMessage{json.RawMessage(`{}`)}, `{"alg":"HS256"}`},
+		// equal, yet not identical to AllowOverride
+		{"", []json.RawMessage{json.RawMessage(`{"alg":"none"}`), json.RawMessage(`{}`)}, `jwt: JOSE header addition overrides a protected member: "alg"`},
+	}
+	for _, gold := range golden {
+		var c Claims
+		c.KeyID = gold.kid
+		_, err := c.HMACSign(HS256, []byte("guest"), gold.extra...)
+		if err != nil {
+			if !errors.Is(err, ErrHeaderOverride) {
+				t.Errorf("%q with %q: got error %q, want ErrHeaderOverride", gold.kid, gold.extra, err)
+			} else if err.Error() != gold.want {
+				t.Errorf("%q with %q: got error %q, want %q", gold.kid, gold.extra, err, gold.want)
+			}
+			continue
+		}
+		if got := string(c.RawHeader); got != gold.want {
+			t.Errorf("%q with %q: got header %s, want %s", gold.kid, gold.extra, got, gold.want)
+		}
+	}
+}
+
+func TestAppendSign(t *testing.T) {
+	var c Claims
+	c.Subject = "alice"
+	c.KeyID = "k1"
+	h, err := NewHMAC(HS384, []byte("guest"))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	golden := []struct {
+		name string
+		sign func(dst []byte) ([]byte, error)
+		want func() ([]byte, error)
+	}{
+		{"AppendHMACSign",
+			func(dst []byte) ([]byte, error) { return AppendHMACSign(dst, &c, HS256, []byte("guest")) },
+			func() ([]byte, error) { return c.HMACSign(HS256, []byte("guest")) }},
+		{"HMAC.AppendSign",
+			func(dst []byte) ([]byte, error) { return h.AppendSign(dst, &c) },
+			func() ([]byte, error) { return h.Sign(&c) }},
+		{"AppendEdDSASign",
+			func(dst []byte) ([]byte, error) { return AppendEdDSASign(dst, &c, testKeyEd25519Private) },
+			func() ([]byte, error) { return c.EdDSASign(testKeyEd25519Private) }},
+	}
+	for _, gold := range golden {
+		want, err := gold.want()
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		// with capacity to spare
+		buf := make([]byte, 3, 1024)
+		copy(buf, "pre")
+		got, err := gold.sign(buf)
+		if err != nil {
+			t.Fatalf("%s: error: %s", gold.name, err)
+		}
+		if string(got) != "pre"+string(want) {
+			t.Errorf("%s: got %q, want %q", gold.name, got, "pre"+string(want))
+		}
+		if &got[0] != &buf[0] {
+			t.Errorf("%s: buffer reallocated", gold.name)
+		}
+
+		// without capacity
+		got, err = gold.sign([]byte("pre"))
+		if err != nil {
+			t.Fatalf("%s: error: %s", gold.name, err)
+		}
+		if string(got) != "pre"+string(want) {
+			t.Errorf("%s: got %q, want %q", gold.name, got, "pre"+string(want))
+		}
+	}
+}
+
+func TestAppendSignRandomized(t *testing.T) {
+	var c Claims
+	c.Subject = "alice"
+
+	golden := []struct {
+		name  string
+		sign  func(dst []byte) ([]byte, error)
+		check func(token []byte) (*Claims, error)
+	}{
+		{"AppendECDSASign",
+			func(dst []byte) ([]byte, error) { return AppendECDSASign(dst, &c, ES384, testKeyEC384) },
+			func(token []byte) (*Claims, error) { return ECDSACheck(token, &testKeyEC384.PublicKey) }},
+		{"AppendRSASign",
+			func(dst []byte) ([]byte, error) { return AppendRSASign(dst, &c, PS256, testKeyRSA2048) },
+			func(token []byte) (*Claims, error) { return RSACheck(token, &testKeyRSA2048.PublicKey) }},
+	}
+	for _, gold := range golden {
+		buf := make([]byte, 3, 1024)
+		copy(buf, "pre")
+		got, err := gold.sign(buf)
+		if err != nil {
+			t.Fatalf("%s: error: %s", gold.name, err)
+		}
+		if &got[0] != &buf[0] {
+			t.Errorf("%s: buffer reallocated", gold.name)
+		}
+		if string(got[:3]) != "pre" {
+			t.Errorf("%s: prefix lost: %q", gold.name, got)
+		}
+		claims, err := gold.check(got[3:])
+		if err != nil {
+			t.Errorf("%s: check error: %s", gold.name, err)
+		} else if claims.Subject != "alice" {
+			t.Errorf("%s: got subject %q", gold.name, claims.Subject)
+		}
+	}
+}
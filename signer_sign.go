@@ -0,0 +1,108 @@
+//go:build !jwtverifyonly
+
+package jwt
+
+import (
+	"crypto"
+	"crypto/ecdsa"
+	"crypto/ed25519"
+	"crypto/rand"
+	"crypto/rsa"
+	"encoding/asn1"
+	"encoding/json"
+	"errors"
+	"math/big"
+)
+
+var errSignerSig = errors.New("jwt: crypto.Signer produced a malformed signature")
+
+// SignWith updates the Raw fields and returns a new JWT, signed by signer.
+// Keys in hardware security modules, TPMs or cloud KMS services thus need no
+// export of the private key. The algorithm must match the type of the public
+// key from signer, and the return is an AlgError otherwise. Signer gets the
+// digest of the first two token parts for ECDSA and RSA, with rsa.PSSOptions
+// for the PS algorithms, and it gets the first two token parts as is for
+// EdDSA, conform the crypto.Signer conventions of the standard library.
+func (c *Claims) SignWith(alg string, signer crypto.Signer, extraHeaders ...json.RawMessage) (token []byte, err error) {
+	var hash crypto.Hash
+	var opts crypto.SignerOpts
+	var sigLen int
+	var isECDSA bool
+
+	switch pub := signer.Public().(type) {
+	case *ecdsa.PublicKey:
+		if hash, err = hashLookup(alg, ECDSAAlgs); err != nil {
+			return nil, err
+		}
+		opts = hash
+		sigLen = 2 * ((pub.Curve.Params().BitSize + 7) / 8)
+		isECDSA = true
+
+	case *rsa.PublicKey:
+		if hash, err = hashLookup(alg, RSAAlgs); err != nil {
+			return nil, err
+		}
+		if err := fipsRSA(pub); err != nil {
+			return nil, err
+		}
+		opts = hash
+		if alg != "" && alg[0] == 'P' {
+			opts = &rsa.PSSOptions{SaltLength: pSSOptions.SaltLength, Hash: hash}
+		}
+		sigLen = pub.Size()
+
+	case ed25519.PublicKey:
+		if alg != EdDSA {
+			return nil, AlgError(alg)
+		}
+		if err := fipsAlg(alg, 0); err != nil {
+			return nil, err
+		}
+		opts = crypto.Hash(0)
+		sigLen = ed25519.SignatureSize
+
+	default:
+		return nil, AlgError(alg)
+	}
+
+	encSigLen := encoding.EncodedLen(sigLen)
+	body, err := c.newToken(alg, encSigLen, extraHeaders)
+	if err != nil {
+		return nil, err
+	}
+
+	message := body
+	if hash != 0 {
+		digest := hash.New()
+		digest.Write(body)
+		message = digest.Sum(nil)
+	}
+	sig, err := signer.Sign(rand.Reader, message, opts)
+	if err != nil {
+		return nil, err
+	}
+
+	if isECDSA {
+		// ASN.1 to the pair (r, s) as per RFC 7518, subsection 3.4
+		var pair struct{ R, S *big.Int }
+		rest, err := asn1.Unmarshal(sig, &pair)
+		if err != nil || len(rest) != 0 || pair.R.Sign() <= 0 || pair.S.Sign() <= 0 {
+			return nil, errSignerSig
+		}
+		paramLen := sigLen / 2
+		if pair.R.BitLen() > paramLen*8 || pair.S.BitLen() > paramLen*8 {
+			return nil, errSignerSig
+		}
+		sig = make([]byte, sigLen)
+		pair.R.FillBytes(sig[:paramLen])
+		pair.S.FillBytes(sig[paramLen:])
+	}
+	if len(sig) != sigLen {
+		return nil, errSignerSig
+	}
+
+	end := len(body) + 1 + encSigLen
+	token = append(body, '.')
+	encoding.Encode(token[len(token):end], sig)
+	return token[:end], nil
+}
@@ -0,0 +1,71 @@
+package jwt
+
+import (
+	"crypto"
+	"testing"
+)
+
+func TestSignWith(t *testing.T) {
+	golden := []struct {
+		alg    string
+		signer crypto.Signer
+		check  func(token []byte) (*Claims, error)
+	}{
+		{EdDSA, testKeyEd25519Private, func(token []byte) (*Claims, error) {
+			return EdDSACheck(token, testKeyEd25519Public)
+		}},
+		{ES256, testKeyEC256, func(token []byte) (*Claims, error) {
+			return ECDSACheck(token, &testKeyEC256.PublicKey)
+		}},
+		{ES384, testKeyEC384, func(token []byte) (*Claims, error) {
+			return ECDSACheck(token, &testKeyEC384.PublicKey)
+		}},
+		{ES512, testKeyEC521, func(token []byte) (*Claims, error) {
+			return ECDSACheck(token, &testKeyEC521.PublicKey)
+		}},
+		{RS256, testKeyRSA2048, func(token []byte) (*Claims, error) {
+			return RSACheck(token, &testKeyRSA2048.PublicKey)
+		}},
+		{PS384, testKeyRSA2048, func(token []byte) (*Claims, error) {
+			return RSACheck(token, &testKeyRSA2048.PublicKey)
+		}},
+	}
+
+	for _, gold := range golden {
+		// repeat to cover short r or s with ECDSA
+		for i := 0; i < 20; i++ {
+			c := &Claims{KeyID: "hsm"}
+			c.Subject = "test"
+			token, err := c.SignWith(gold.alg, gold.signer)
+			if err != nil {
+				t.Fatalf("%s: sign error: %s", gold.alg, err)
+			}
+			got, err := gold.check(token)
+			if err != nil {
+				t.Fatalf("%s: check error: %s", gold.alg, err)
+			}
+			if got.Subject != "test" || got.KeyID != "hsm" {
+				t.Errorf("%s: got subject %q and key ID %q", gold.alg, got.Subject, got.KeyID)
+			}
+		}
+	}
+}
+
+func TestSignWithAlgMismatch(t *testing.T) {
+	golden := []struct {
+		alg    string
+		signer crypto.Signer
+	}{
+		{RS256, testKeyEd25519Private},
+		{EdDSA, testKeyEC256},
+		{HS256, testKeyRSA2048},
+		{ES256, testKeyRSA2048},
+		{"none", testKeyEC256},
+	}
+	for _, gold := range golden {
+		_, err := new(Claims).SignWith(gold.alg, gold.signer)
+		if err != AlgError(gold.alg) {
+			t.Errorf("%s with %T: got error %v, want %v", gold.alg, gold.signer, err, AlgError(gold.alg))
+		}
+	}
+}
@@ -0,0 +1,38 @@
+package jwt
+
+import "errors"
+
+// Transport limits common to HTTP implementations.
+const (
+	// CookieLimit is the minimum size of a cookie (name, value and
+	// attributes) which browsers must support, as per RFC 6265,
+	// subsection 6.1.
+	CookieLimit = 4096
+
+	// HeaderLimit is the default size limit of a request header line
+	// in popular proxies and servers, like Apache and NGINX.
+	HeaderLimit = 8192
+)
+
+// Transport advice from TokenSizeAdvice.
+var (
+	ErrCookieLimit = errors.New("jwt: token size exceeds the cookie limit")
+	ErrHeaderLimit = errors.New("jwt: token size exceeds the header line limit")
+)
+
+// TokenSizeAdvice returns ErrHeaderLimit when a token of size n does not fit
+// in an HTTP Authorization header line, or ErrCookieLimit when the token does
+// not fit in a cookie with name cookieName. The return is nil when a token fits
+// in either.
+func TokenSizeAdvice(n int, cookieName string) error {
+	if len("Authorization: Bearer ")+n > HeaderLimit {
+		return ErrHeaderLimit
+	}
+	// conservative attribute estimate:
+	// "; Path=/; Max-Age=86400; HttpOnly; Secure; SameSite=Strict"
+	const attrLen = 64
+	if len(cookieName)+1+n+attrLen > CookieLimit {
+		return ErrCookieLimit
+	}
+	return nil
+}
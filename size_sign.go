@@ -0,0 +1,109 @@
+//go:build !jwtverifyonly
+
+package jwt
+
+import (
+	"crypto/ecdsa"
+	"crypto/ed25519"
+	"crypto/rsa"
+	"encoding/json"
+	"fmt"
+)
+
+// EstimateRSABits is the key size assumed by EstimateTokenSize for RSA
+// algorithms.
+const EstimateRSABits = 2048
+
+// EstimateTokenSize returns the length of a token from any of the sign methods
+// with alg, and with the current state of c. The size is exact for ECDSA, EdDSA
+// and HMAC. RSA signatures are sized conform EstimateRSABits. The claims remain
+// unmodified.
+//
+// The JOSE header (content) can be extended with extraHeaders, in the form of
+// JSON objects. Overrides of "alg" or "kid" fail with ErrHeaderOverride.
+func (c *Claims) EstimateTokenSize(alg string, extraHeaders ...json.RawMessage) (int, error) {
+	var sigLen int
+	if alg == EdDSA {
+		sigLen = ed25519.SignatureSize
+	} else if hash, err := hashLookup(alg, HMACAlgs); err == nil {
+		sigLen = hash.Size()
+	} else if _, err := hashLookup(alg, RSAAlgs); err == nil {
+		sigLen = (EstimateRSABits + 7) / 8
+	} else if _, err := hashLookup(alg, ECDSAAlgs); err == nil {
+		switch alg {
+		case ES256:
+			sigLen = 2 * 32
+		case ES384:
+			sigLen = 2 * 48
+		default:
+			sigLen = 2 * 66
+		}
+	} else {
+		return 0, err
+	}
+
+	return c.sizeWithSig(alg, sigLen, extraHeaders)
+}
+
+// TokenSize returns the exact length of a token from the sign methods with alg
+// and key, and with the current state of c. The key can be any of the types
+// accepted by the sign methods, either private or public, including []byte and
+// *HMAC. The return is an AlgError when alg does not apply to the key type. The
+// claims remain unmodified. Use the size for buffers to the Append functions,
+// such as AppendRSASign, to prevent any copies.
+//
+// The JOSE header (content) can be extended with extraHeaders, in the form of
+// JSON objects. Overrides of "alg" or "kid" fail with ErrHeaderOverride.
+func (c *Claims) TokenSize(alg string, key interface{}, extraHeaders ...json.RawMessage) (int, error) {
+	var sigLen int
+	switch key := key.(type) {
+	case ed25519.PrivateKey, ed25519.PublicKey:
+		if alg != EdDSA {
+			return 0, AlgError(alg)
+		}
+		sigLen = ed25519.SignatureSize
+	case *ecdsa.PrivateKey:
+		return c.TokenSize(alg, &key.PublicKey, extraHeaders...)
+	case *ecdsa.PublicKey:
+		if _, err := hashLookup(alg, ECDSAAlgs); err != nil {
+			return 0, err
+		}
+		sigLen = 2 * ((key.Curve.Params().BitSize + 7) / 8)
+	case *rsa.PrivateKey:
+		return c.TokenSize(alg, &key.PublicKey, extraHeaders...)
+	case *rsa.PublicKey:
+		if _, err := hashLookup(alg, RSAAlgs); err != nil {
+			return 0, err
+		}
+		sigLen = key.Size()
+	case []byte, *HMAC:
+		if h, ok := key.(*HMAC); ok && h.alg != alg {
+			return 0, AlgError(alg)
+		}
+		hash, err := hashLookup(alg, HMACAlgs)
+		if err != nil {
+			return 0, err
+		}
+		sigLen = hash.Size()
+	default:
+		return 0, fmt.Errorf("jwt: unsupported key type %T", key)
+	}
+	return c.sizeWithSig(alg, sigLen, extraHeaders)
+}
+
+// SizeWithSig returns the token length with a signature of sigLen bytes.
+func (c *Claims) sizeWithSig(alg string, sigLen int, extraHeaders []json.RawMessage) (int, error) {
+	// work on a copy
+	dup := *c
+	if c.Set != nil {
+		dup.Set = make(map[string]interface{}, len(c.Set)+7)
+		for k, v := range c.Set {
+			dup.Set[k] = v
+		}
+	}
+	token, err := dup.newToken(alg, 0, extraHeaders)
+	if err != nil {
+		return 0, err
+	}
+	return len(token) + 1 + encoding.EncodedLen(sigLen), nil
+}
@@ -0,0 +1,111 @@
+package jwt
+
+import (
+	"encoding/json"
+	"strings"
+	"testing"
+)
+
+func TestEstimateTokenSize(t *testing.T) {
+	c := Claims{
+		Registered: Registered{Subject: "alice", Audiences: []string{"a", "b"}},
+		Set:        map[string]interface{}{"x": strings.Repeat("y", 99)},
+		KeyID:      "№1",
+	}
+	extra := json.RawMessage(`{"typ":"JWT"}`)
+
+	sign := map[string]func() ([]byte, error){
+		ES256: func() ([]byte, error) { return c.ECDSASign(ES256, testKeyEC256, extra) },
+		ES384: func() ([]byte, error) { return c.ECDSASign(ES384, testKeyEC384, extra) },
+		ES512: func() ([]byte, error) { return c.ECDSASign(ES512, testKeyEC521, extra) },
+		EdDSA: func() ([]byte, error) { return c.EdDSASign(testKeyEd25519Private, extra) },
+		HS256: func() ([]byte, error) { return c.HMACSign(HS256, []byte("guest"), extra) },
+		HS512: func() ([]byte, error) { return c.HMACSign(HS512, []byte("guest"), extra) },
+		PS256: func() ([]byte, error) { return c.RSASign(PS256, testKeyRSA2048, extra) },
+	}
+	for alg, f := range sign {
+		got, err := c.EstimateTokenSize(alg, extra)
+		if err != nil {
+			t.Errorf("%s estimate error: %s", alg, err)
+			continue
+		}
+		if _, ok := c.Set["sub"]; ok {
+			t.Fatalf("%s estimate modified the claims set", alg)
+		}
+		token, err := f()
+		if err != nil {
+			t.Fatalf("%s sign error: %s", alg, err)
+		}
+		delete(c.Set, "sub")
+		delete(c.Set, "aud")
+		if got != len(token) {
+			t.Errorf("%s got estimate %d, want %d", alg, got, len(token))
+		}
+	}
+
+	if _, err := c.EstimateTokenSize("none"); err != AlgError("none") {
+		t.Errorf("got error %v, want %v", err, AlgError("none"))
+	}
+}
+
+func TestTokenSize(t *testing.T) {
+	c := Claims{
+		Registered: Registered{Subject: "alice"},
+		KeyID:      "k1",
+	}
+	h, err := NewHMAC(HS384, []byte("guest"))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	golden := []struct {
+		alg  string
+		key  interface{}
+		sign func() ([]byte, error)
+	}{
+		{ES512, testKeyEC521, func() ([]byte, error) { return c.ECDSASign(ES512, testKeyEC521) }},
+		{EdDSA, testKeyEd25519Public, func() ([]byte, error) { return c.EdDSASign(testKeyEd25519Private) }},
+		{HS256, []byte("guest"), func() ([]byte, error) { return c.HMACSign(HS256, []byte("guest")) }},
+		{HS384, h, func() ([]byte, error) { return h.Sign(&c) }},
+		{PS256, testKeyRSA4096, func() ([]byte, error) { return c.RSASign(PS256, testKeyRSA4096) }},
+		{RS256, &testKeyRSA1024.PublicKey, func() ([]byte, error) { return c.RSASign(RS256, testKeyRSA1024) }},
+	}
+	for _, gold := range golden {
+		got, err := c.TokenSize(gold.alg, gold.key)
+		if err != nil {
+			t.Errorf("%s size error: %s", gold.alg, err)
+			continue
+		}
+		token, err := gold.sign()
+		if err != nil {
+			t.Fatalf("%s sign error: %s", gold.alg, err)
+		}
+		if got != len(token) {
+			t.Errorf("%s got size %d, want %d", gold.alg, got, len(token))
+		}
+	}
+
+	if _, err := c.TokenSize(ES256, testKeyRSA2048); err != AlgError(ES256) {
+		t.Errorf("RSA key with ES256 got error %v, want %v", err, AlgError(ES256))
+	}
+	if _, err := c.TokenSize(HS256, h); err != AlgError(HS256) {
+		t.Errorf("HS384 instance with HS256 got error %v, want %v", err, AlgError(HS256))
+	}
+}
+
+func TestTokenSizeAdvice(t *testing.T) {
+	golden := []struct {
+		n    int
+		want error
+	}{
+		{1000, nil},
+		{4050, ErrCookieLimit},
+		{8000, ErrCookieLimit},
+		{8192, ErrHeaderLimit},
+	}
+	for _, gold := range golden {
+		if err := TokenSizeAdvice(gold.n, "session"); err != gold.want {
+			t.Errorf("size %d got error %v, want %v", gold.n, err, gold.want)
+		}
+	}
+}
@@ -0,0 +1,79 @@
+package jwt
+
+import (
+	"sync"
+	"time"
+)
+
+// ClockSkew is invoked by Handler for each token with an issue time, once the
+// signature checks out. The skew is the verification time minus the issue
+// time, which includes any transport and queueing delays. Negative values
+// reveal issuers with clocks ahead. Nil disables. See SkewStats for a
+// collector.
+var ClockSkew func(issuer string, skew time.Duration)
+
+// ObserveSkew passes the Issued claim, if any, to the ClockSkew hook, if any.
+func (r *Registered) observeSkew(now time.Time) {
+	if f := ClockSkew; f != nil && r.Issued != nil {
+		f(r.Issuer, now.Sub(r.Issued.Time()))
+	}
+}
+
+// SkewStat is the observed clock skew of an issuer.
+type SkewStat struct {
+	Count    uint64        // number of observations
+	Min, Max time.Duration // extremes
+	Sum      time.Duration // total, for the mean
+}
+
+// Mean returns the average skew.
+func (s SkewStat) Mean() time.Duration {
+	if s.Count == 0 {
+		return 0
+	}
+	return s.Sum / time.Duration(s.Count)
+}
+
+// SkewStats collects clock skew per issuer. The zero value is ready for use.
+// Install with jwt.ClockSkew = stats.Observe.
+//
+// Multiple goroutines may invoke methods on a SkewStats simultaneously.
+type SkewStats struct {
+	mutex     sync.Mutex
+	perIssuer map[string]*SkewStat
+}
+
+// Observe records a skew for issuer. The signature matches ClockSkew.
+func (stats *SkewStats) Observe(issuer string, skew time.Duration) {
+	stats.mutex.Lock()
+	defer stats.mutex.Unlock()
+
+	s, ok := stats.perIssuer[issuer]
+	if !ok {
+		if stats.perIssuer == nil {
+			stats.perIssuer = make(map[string]*SkewStat)
+		}
+		s = &SkewStat{Min: skew, Max: skew}
+		stats.perIssuer[issuer] = s
+	}
+	s.Count++
+	s.Sum += skew
+	if skew < s.Min {
+		s.Min = skew
+	}
+	if skew > s.Max {
+		s.Max = skew
+	}
+}
+
+// Snapshot returns a copy of the statistics per issuer.
+func (stats *SkewStats) Snapshot() map[string]SkewStat {
+	stats.mutex.Lock()
+	defer stats.mutex.Unlock()
+
+	m := make(map[string]SkewStat, len(stats.perIssuer))
+	for issuer, s := range stats.perIssuer {
+		m[issuer] = *s
+	}
+	return m
+}
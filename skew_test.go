@@ -0,0 +1,52 @@
+package jwt
+
+import (
+	"crypto/ed25519"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+)
+
+func TestSkewStats(t *testing.T) {
+	var stats SkewStats
+	ClockSkew = stats.Observe
+	defer func() { ClockSkew = nil }()
+	now := time.Unix(1537622794, 0)
+	defer func(clock func() time.Time) { Clock = clock }(Clock)
+	Clock = func() time.Time { return now }
+
+	h := &Handler{
+		Target:         http.HandlerFunc(func(http.ResponseWriter, *http.Request) {}),
+		Keys:           &KeyRegister{EdDSAs: []ed25519.PublicKey{testKeyEd25519Public}},
+		TemporalLeeway: time.Minute,
+	}
+	serve := func(c *Claims) {
+		req := httptest.NewRequest("GET", "/", nil)
+		if err := c.EdDSASignHeader(req, testKeyEd25519Private); err != nil {
+			t.Fatal(err)
+		}
+		h.ServeHTTP(httptest.NewRecorder(), req)
+	}
+	for _, offset := range []time.Duration{-2 * time.Second, 3 * time.Second, 5 * time.Second} {
+		serve(&Claims{Registered: Registered{Issuer: "a", Issued: NewNumericTime(now.Add(offset))}})
+	}
+	serve(&Claims{Registered: Registered{Issuer: "b"}})
+
+	// pure methods don't observe
+	r := Registered{Issuer: "c", Issued: NewNumericTime(now)}
+	r.AcceptTemporal(now, 0)
+	r.TTL(now)
+
+	got := stats.Snapshot()
+	if len(got) != 1 {
+		t.Fatalf("got %d issuers, want 1", len(got))
+	}
+	want := SkewStat{Count: 3, Min: -5 * time.Second, Max: 2 * time.Second, Sum: -6 * time.Second}
+	if got["a"] != want {
+		t.Errorf("got %+v, want %+v", got["a"], want)
+	}
+	if mean := got["a"].Mean(); mean != -2*time.Second {
+		t.Errorf("got mean %s, want -2s", mean)
+	}
+}
@@ -0,0 +1,116 @@
+package jwt
+
+import (
+	"strconv"
+	"strings"
+	"time"
+)
+
+// Authentication claims from “OpenID Connect Core 1.0”, section 2.
+const (
+	acr      = "acr"
+	amr      = "amr"
+	authTime = "auth_time"
+)
+
+// ACR returns the "acr" (Authentication Context Class Reference) claim.
+func (c *Claims) ACR() (class string, ok bool) {
+	return c.String(acr)
+}
+
+// AMR returns the "amr" (Authentication Methods References) claim. Elements
+// other than strings are omitted.
+func (c *Claims) AMR() []string {
+	array, ok := c.Set[amr].([]interface{})
+	if !ok {
+		return nil
+	}
+	methods := make([]string, 0, len(array))
+	for _, o := range array {
+		if s, ok := o.(string); ok {
+			methods = append(methods, s)
+		}
+	}
+	return methods
+}
+
+// AuthTime returns the "auth_time" claim, which is the time of the end-user
+// authentication.
+func (c *Claims) AuthTime() (t time.Time, ok bool) {
+	f, ok := c.Number(authTime)
+	if !ok {
+		return time.Time{}, false
+	}
+	n := NumericTime(f)
+	return n.Time(), true
+}
+
+// StepUpError signals an authentication level below the requirements. Clients
+// should obtain a new token with ACRValues and MaxAge, conform “OAuth 2.0
+// Step Up Authentication Challenge Protocol” RFC 9470.
+type StepUpError struct {
+	ACRValues []string      // accepted classes, if any
+	MaxAge    time.Duration // authentication age limit, if any
+	Reason    string        // description
+}
+
+// Error honors the error interface.
+func (e *StepUpError) Error() string {
+	return "jwt: insufficient user authentication: " + e.Reason
+}
+
+// Challenge returns the WWW-Authenticate value.
+func (e *StepUpError) challenge() string {
+	var buf strings.Builder
+	buf.WriteString(`Bearer error="insufficient_user_authentication", error_description=`)
+	buf.WriteString(strconv.QuoteToASCII(e.Error()))
+	if len(e.ACRValues) != 0 {
+		buf.WriteString(", acr_values=")
+		buf.WriteString(strconv.QuoteToASCII(strings.Join(e.ACRValues, " ")))
+	}
+	if e.MaxAge != 0 {
+		buf.WriteString(", max_age=")
+		buf.WriteString(strconv.FormatInt(int64(e.MaxAge/time.Second), 10))
+	}
+	return buf.String()
+}
+
+// AcceptAuthentication verifies the ACRValues, AMR and MaxAuthAge of e.
+func (e *Expect) acceptAuthentication(c *Claims) error {
+	if len(e.ACRValues) == 0 && len(e.AMR) == 0 && e.MaxAuthAge == 0 {
+		return nil
+	}
+	fail := func(reason string) error {
+		return &StepUpError{ACRValues: e.ACRValues, MaxAge: e.MaxAuthAge, Reason: reason}
+	}
+
+	if len(e.ACRValues) != 0 {
+		class, ok := c.ACR()
+		if !ok {
+			return fail(`authentication context class ["acr"] absent`)
+		}
+		if !contains(e.ACRValues, class) {
+			return fail("authentication context class " + strconv.Quote(class) + " not accepted")
+		}
+	}
+
+	if len(e.AMR) != 0 {
+		methods := c.AMR()
+		for _, m := range e.AMR {
+			if !contains(methods, m) {
+				return fail("authentication method " + strconv.Quote(m) + " absent")
+			}
+		}
+	}
+
+	if e.MaxAuthAge != 0 {
+		t, ok := c.AuthTime()
+		if !ok {
+			return fail(`authentication time ["auth_time"] absent`)
+		}
+		if Clock().Sub(t) > e.MaxAuthAge {
+			return fail("authentication time exceeds the maximum age")
+		}
+	}
+	return nil
+}
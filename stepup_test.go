@@ -0,0 +1,78 @@
+package jwt
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+)
+
+func TestStepUp(t *testing.T) {
+	defer func(clock func() time.Time) { Clock = clock }(Clock)
+	Clock = func() time.Time { return time.Unix(1000, 0) }
+
+	golden := []struct {
+		set    map[string]interface{}
+		expect Expect
+		want   string // error reason
+	}{
+		{nil, Expect{}, ""},
+		{map[string]interface{}{"acr": "mfa"}, Expect{ACRValues: []string{"mfa"}}, ""},
+		{nil, Expect{ACRValues: []string{"mfa"}}, `authentication context class ["acr"] absent`},
+		{map[string]interface{}{"acr": "pwd"}, Expect{ACRValues: []string{"mfa", "hwk"}}, `authentication context class "pwd" not accepted`},
+		{map[string]interface{}{"amr": []interface{}{"pwd", "otp"}}, Expect{AMR: []string{"otp"}}, ""},
+		{map[string]interface{}{"amr": []interface{}{"pwd"}}, Expect{AMR: []string{"otp"}}, `authentication method "otp" absent`},
+		{map[string]interface{}{"auth_time": 900.0}, Expect{MaxAuthAge: 2 * time.Minute}, ""},
+		{map[string]interface{}{"auth_time": 800.0}, Expect{MaxAuthAge: 2 * time.Minute}, "authentication time exceeds the maximum age"},
+		{nil, Expect{MaxAuthAge: time.Minute}, `authentication time ["auth_time"] absent`},
+	}
+	for _, gold := range golden {
+		c := Claims{Set: gold.set}
+		err := gold.expect.Accept(&c)
+		switch e := err.(type) {
+		case nil:
+			if gold.want != "" {
+				t.Errorf("%v with %+v: got no error, want %q", gold.set, gold.expect, gold.want)
+			}
+		case *StepUpError:
+			if e.Reason != gold.want {
+				t.Errorf("%v with %+v: got reason %q, want %q", gold.set, gold.expect, e.Reason, gold.want)
+			}
+		default:
+			t.Errorf("%v with %+v: got error %v, want StepUpError", gold.set, gold.expect, err)
+		}
+	}
+}
+
+func TestHandlerStepUp(t *testing.T) {
+	h := &Handler{
+		Target: http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {}),
+		Keys:   &KeyRegister{Secrets: [][]byte{[]byte("guest")}},
+		RouteClaims: map[string]Expect{
+			"/admin/": {ACRValues: []string{"mfa"}, MaxAuthAge: 5 * time.Minute},
+		},
+	}
+
+	var c Claims
+	c.Set = map[string]interface{}{"acr": "pwd"}
+	token, err := c.HMACSign(HS256, []byte("guest"))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	r := httptest.NewRequest(http.MethodGet, "/admin/users", nil)
+	r.Header.Set("Authorization", "Bearer "+string(token))
+	w := httptest.NewRecorder()
+	h.ServeHTTP(w, r)
+	if w.Code != http.StatusUnauthorized {
+		t.Errorf("got status %d, want 401", w.Code)
+	}
+	const want = `Bearer error="insufficient_user_authentication", error_description="jwt: insufficient user authentication: authentication context class \"pwd\" not accepted", acr_values="mfa", max_age=300`
+	if got := w.Header().Get("WWW-Authenticate"); got != want {
+		t.Errorf("got WWW-Authenticate %q, want %q", got, want)
+	}
+
+	if code, _ := OAuthError(&StepUpError{}); code != InsufficientUserAuthentication {
+		t.Errorf("got OAuth error code %q, want %q", code, InsufficientUserAuthentication)
+	}
+}
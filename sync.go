@@ -0,0 +1,136 @@
+package jwt
+
+import (
+	"context"
+	"net/http"
+	"sync"
+)
+
+// SyncRegister is a KeyRegister which can be updated while in use. The zero
+// value is ready for use, without any keys.
+//
+// Multiple goroutines may invoke methods on a SyncRegister simultaneously.
+type SyncRegister struct {
+	mutex sync.RWMutex
+	keys  KeyRegister
+}
+
+// Check applies KeyRegister.Check with the current keys.
+func (r *SyncRegister) Check(token []byte) (*Claims, error) {
+	r.mutex.RLock()
+	defer r.mutex.RUnlock()
+	return r.keys.Check(token)
+}
+
+// CheckContext applies KeyRegister.CheckContext with the current keys.
+func (r *SyncRegister) CheckContext(ctx context.Context, token []byte) (*Claims, error) {
+	r.mutex.RLock()
+	defer r.mutex.RUnlock()
+	return r.keys.CheckContext(ctx, token)
+}
+
+// CheckHeader applies KeyRegister.CheckHeader with the current keys.
+func (r *SyncRegister) CheckHeader(req *http.Request) (*Claims, error) {
+	token, err := BearerToken(req.Header)
+	if err != nil {
+		return nil, err
+	}
+	return r.Check([]byte(token))
+}
+
+// Add installs a key with an optional key ID. Supported types are the public
+// and private keys of ECDSA, EdDSA and RSA, plus *HMAC, secrets as []byte and
+// any Algorithm.
+// Private keys are reduced to their public counterpart.
+func (r *SyncRegister) Add(key interface{}, kid string) error {
+	r.mutex.Lock()
+	defer r.mutex.Unlock()
+	return r.keys.add(key, kid)
+}
+
+// Remove uninstalls each key with the key ID, and it returns the number of keys
+// removed. The empty string matches nothing.
+func (r *SyncRegister) Remove(kid string) (keysRemoved int) {
+	if kid == "" {
+		return 0
+	}
+	r.mutex.Lock()
+	defer r.mutex.Unlock()
+	return r.keys.RemoveKeyID(kid)
+}
+
+// RemoveKey applies KeyRegister.RemoveKey on the current keys.
+func (r *SyncRegister) RemoveKey(key interface{}) (keysRemoved int) {
+	r.mutex.Lock()
+	defer r.mutex.Unlock()
+	return r.keys.RemoveKey(key)
+}
+
+// Replace installs the content of keys, as a whole, replacing all previous
+// keys. The slices of keys must not be modified afterwards.
+func (r *SyncRegister) Replace(keys *KeyRegister) {
+	r.mutex.Lock()
+	defer r.mutex.Unlock()
+	r.keys = *keys
+}
+
+// Rotate installs a key with an optional key ID, and it uninstalls each key
+// with a key ID in retire, all in one step. Checks observe either the previous
+// or the new set of keys, never a mix of both. Retirement goes first, such that
+// a key may replace another with the same key ID. Nothing changes on error.
+func (r *SyncRegister) Rotate(key interface{}, kid string, retire ...string) (keysRemoved int, err error) {
+	r.mutex.Lock()
+	defer r.mutex.Unlock()
+	keys := r.keys
+	for _, id := range retire {
+		if id != "" {
+			keysRemoved += keys.RemoveKeyID(id)
+		}
+	}
+	if err := keys.add(key, kid); err != nil {
+		return 0, err
+	}
+	r.keys = keys
+	return keysRemoved, nil
+}
+
+// Merge applies KeyRegister.Merge on the current keys.
+func (r *SyncRegister) Merge(other *KeyRegister) (keysAdded int, err error) {
+	r.mutex.Lock()
+	defer r.mutex.Unlock()
+	return r.keys.Merge(other)
+}
+
+// LoadPEM applies KeyRegister.LoadPEM on the current keys.
+func (r *SyncRegister) LoadPEM(text, password []byte) (keysAdded int, err error) {
+	r.mutex.Lock()
+	defer r.mutex.Unlock()
+	return r.keys.LoadPEM(text, password)
+}
+
+// LoadJWK applies KeyRegister.LoadJWK on the current keys.
+func (r *SyncRegister) LoadJWK(data []byte) (keysAdded int, err error) {
+	r.mutex.Lock()
+	defer r.mutex.Unlock()
+	return r.keys.LoadJWK(data)
+}
+
+// Keys returns a snapshot of the current keys, e.g., for KeyRegister.JWKS. The
+// slices of the snapshot must not be modified, yet appends are safe.
+func (r *SyncRegister) Keys() *KeyRegister {
+	r.mutex.RLock()
+	defer r.mutex.RUnlock()
+	keys := r.keys
+	keys.ECDSAs, keys.ECDSAIDs = capped(keys.ECDSAs), capped(keys.ECDSAIDs)
+	keys.EdDSAs, keys.EdDSAIDs = capped(keys.EdDSAs), capped(keys.EdDSAIDs)
+	keys.RSAs, keys.RSAIDs = capped(keys.RSAs), capped(keys.RSAIDs)
+	keys.HMACs, keys.HMACIDs = capped(keys.HMACs), capped(keys.HMACIDs)
+	keys.Secrets, keys.SecretIDs = capped(keys.Secrets), capped(keys.SecretIDs)
+	keys.Algorithms, keys.AlgorithmIDs = capped(keys.Algorithms), capped(keys.AlgorithmIDs)
+	return &keys
+}
+
+// Capped limits the capacity to the length, such that any append copies.
+func capped[T any](a []T) []T {
+	return a[:len(a):len(a)]
+}
@@ -0,0 +1,159 @@
+package jwt
+
+import (
+	"sync"
+	"testing"
+)
+
+func TestSyncRegister(t *testing.T) {
+	var keys SyncRegister
+	if err := keys.Add(testKeyEd25519Private, "ed"); err != nil {
+		t.Fatal("add error:", err)
+	}
+	if err := keys.Add([]byte("secret"), "hs"); err != nil {
+		t.Fatal("add error:", err)
+	}
+	if err := keys.Add("wrong", ""); err == nil {
+		t.Error("add of string got no error")
+	}
+
+	c := Claims{KeyID: "ed"}
+	edToken, err := c.EdDSASign(testKeyEd25519Private)
+	if err != nil {
+		t.Fatal(err)
+	}
+	c.KeyID = "hs"
+	hsToken, err := c.HMACSign(HS256, []byte("secret"))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	var wg sync.WaitGroup
+	for i := 0; i < 4; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for j := 0; j < 100; j++ {
+				if _, err := keys.Check(edToken); err != nil {
+					t.Error("EdDSA check error:", err)
+					return
+				}
+			}
+		}()
+	}
+	for j := 0; j < 100; j++ {
+		keys.Add([]byte("other"), "tmp")
+		keys.Remove("tmp")
+	}
+	wg.Wait()
+
+	if n := keys.Remove("hs"); n != 1 {
+		t.Errorf("removed %d keys, want 1", n)
+	}
+	if _, err := keys.Check(hsToken); err != ErrSigMiss {
+		t.Errorf("check after remove got error %v, want %v", err, ErrSigMiss)
+	}
+	if _, err := keys.Check(edToken); err != nil {
+		t.Error("check of remaining key error:", err)
+	}
+
+	keys.Replace(&KeyRegister{Secrets: [][]byte{[]byte("secret")}})
+	if _, err := keys.Check(hsToken); err != nil {
+		t.Error("check after replace error:", err)
+	}
+	if _, err := keys.Check(edToken); err != ErrSigMiss {
+		t.Errorf("replaced key got error %v, want %v", err, ErrSigMiss)
+	}
+}
+
+func TestSyncRegisterRotate(t *testing.T) {
+	var keys SyncRegister
+	if err := keys.Add([]byte("old secret"), "1"); err != nil {
+		t.Fatal("add error:", err)
+	}
+
+	var c Claims
+	oldToken, err := c.HMACSign(HS256, []byte("old secret"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	newToken, err := c.HMACSign(HS256, []byte("new secret"))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	var wg sync.WaitGroup
+	wg.Add(1)
+	go func() {
+		defer wg.Done()
+		for j := 0; j < 100; j++ {
+			// either the old or the new key, never none
+			_, oldErr := keys.Check(oldToken)
+			_, newErr := keys.Check(newToken)
+			if oldErr != nil && newErr != nil {
+				t.Error("no key during rotation:", oldErr)
+				return
+			}
+		}
+	}()
+	for j := 0; j < 50; j++ {
+		if _, err := keys.Rotate([]byte("new secret"), "1", "1"); err != nil {
+			t.Fatal("rotate error:", err)
+		}
+		if _, err := keys.Rotate([]byte("old secret"), "1", "1"); err != nil {
+			t.Fatal("rotate error:", err)
+		}
+	}
+	wg.Wait()
+
+	n, err := keys.Rotate([]byte("new secret"), "2", "1")
+	if err != nil {
+		t.Fatal("rotate error:", err)
+	}
+	if n != 1 {
+		t.Errorf("rotate removed %d keys, want 1", n)
+	}
+	if _, err := keys.Check(oldToken); err != ErrSigMiss {
+		t.Errorf("retired key got error %v, want %v", err, ErrSigMiss)
+	}
+	if _, err := keys.Check(newToken); err != nil {
+		t.Error("rotated key error:", err)
+	}
+
+	// failed rotation keeps all
+	if _, err := keys.Rotate("wrong", "3", "2"); err == nil {
+		t.Error("rotate with string got no error")
+	}
+	if _, err := keys.Check(newToken); err != nil {
+		t.Error("check after failed rotation error:", err)
+	}
+
+	snapshot := keys.Keys()
+	if len(snapshot.Secrets) != 1 || snapshot.SecretIDs[0] != "2" {
+		t.Errorf("got snapshot secrets %q with key IDs %q", snapshot.Secrets, snapshot.SecretIDs)
+	}
+	snapshot.Secrets = append(snapshot.Secrets, []byte("other"))
+	if got := keys.Keys(); len(got.Secrets) != 1 {
+		t.Errorf("append on snapshot changed register to %q", got.Secrets)
+	}
+}
+
+func TestSyncRegisterLoad(t *testing.T) {
+	var keys SyncRegister
+	n, err := keys.LoadJWK([]byte(`{"kty":"oct","k":"c2VjcmV0","kid":"hs"}`))
+	if err != nil {
+		t.Fatal("load error:", err)
+	}
+	if n != 1 {
+		t.Errorf("got %d keys added, want 1", n)
+	}
+
+	c := Claims{KeyID: "hs"}
+	token, err := c.HMACSign(HS256, []byte("secret"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := keys.Check(token); err != nil {
+		t.Error("check error:", err)
+	}
+}
@@ -0,0 +1,37 @@
+package jwt
+
+import "regexp"
+
+// Tenant identification is configured with package-level settings. Any
+// modifications should be made before first use to prevent data races, i.e.,
+// customise from either main or init.
+var (
+	// TenantIssuer extracts the tenant identifier from the issuer claim
+	// with its first capture group, when set. For example, the pattern
+	// `^https://login\.microsoftonline\.com/([^/]+)/v2\.0$` extracts the
+	// directory of Microsoft Entra ID tokens.
+	TenantIssuer *regexp.Regexp
+
+	// TenantClaim is the name of a claim with the tenant identifier as
+	// a string. TenantIssuer takes precedence, if any. The empty string
+	// disables the lookup.
+	TenantClaim = "tid"
+)
+
+// Tenant returns the tenant identifier conform TenantIssuer and TenantClaim.
+// The return is false when neither applies.
+func (c *Claims) Tenant() (tenant string, ok bool) {
+	if TenantIssuer != nil {
+		match := TenantIssuer.FindStringSubmatch(c.Issuer)
+		if len(match) > 1 && match[1] != "" {
+			return match[1], true
+		}
+	}
+	if TenantClaim != "" {
+		tenant, ok = c.String(TenantClaim)
+		if ok && tenant != "" {
+			return tenant, true
+		}
+	}
+	return "", false
+}
@@ -0,0 +1,36 @@
+package jwt
+
+import (
+	"regexp"
+	"testing"
+)
+
+func TestClaimsTenant(t *testing.T) {
+	defer func(issuer *regexp.Regexp, claim string) {
+		TenantIssuer, TenantClaim = issuer, claim
+	}(TenantIssuer, TenantClaim)
+
+	c := Claims{
+		Registered: Registered{Issuer: "https://login.example.com/acme/v2.0"},
+		Set:        map[string]interface{}{"tid": "globex", "org": "initech"},
+	}
+	if got, ok := c.Tenant(); !ok || got != "globex" {
+		t.Errorf("default got tenant %q (%t), want globex", got, ok)
+	}
+
+	TenantIssuer = regexp.MustCompile(`^https://login\.example\.com/([^/]+)/v2\.0$`)
+	if got, ok := c.Tenant(); !ok || got != "acme" {
+		t.Errorf("issuer pattern got tenant %q (%t), want acme", got, ok)
+	}
+
+	c.Issuer = "https://other.example.com/"
+	TenantClaim = "org"
+	if got, ok := c.Tenant(); !ok || got != "initech" {
+		t.Errorf("issuer miss got tenant %q (%t), want initech fallback", got, ok)
+	}
+
+	TenantClaim = ""
+	if got, ok := c.Tenant(); ok {
+		t.Errorf("disabled claim got tenant %q", got)
+	}
+}
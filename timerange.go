@@ -0,0 +1,86 @@
+package jwt
+
+import (
+	"fmt"
+	"math"
+	"time"
+)
+
+// NumericTimeRange constrains the "exp", "nbf" and "iat" claims on parse.
+type NumericTimeRange struct {
+	// Min and Max are inclusive bounds. The zero value disables a bound.
+	Min, Max time.Time
+}
+
+// SaneTimeRange covers 1970 up to 2200.
+var SaneTimeRange = &NumericTimeRange{
+	Min: time.Unix(0, 0),
+	Max: time.Date(2200, 1, 1, 0, 0, 0, 0, time.UTC),
+}
+
+// StrictTime enforces a range when set. Tokens fail with a TimeRangeError on
+// any "exp", "nbf" or "iat" claim outside of the range, on any such claim
+// which is not a number, and on any value which exceeds the float64 precision
+// of whole seconds (2⁵³), as those get truncated silently. Any modifications
+// should be made before first use, i.e., from either main or init.
+var StrictTime *NumericTimeRange
+
+// MaxExactSeconds is the largest NumericTime with whole-second precision.
+const maxExactSeconds = 1 << 53
+
+// TimeRangeError is a NumericTime claim rejected by StrictTime.
+type TimeRangeError struct {
+	Claim string      // name
+	Value interface{} // as parsed from JSON
+}
+
+// Error honors the error interface.
+func (e *TimeRangeError) Error() string {
+	if f, ok := e.Value.(float64); ok {
+		if math.Abs(f) >= maxExactSeconds {
+			return fmt.Sprintf("jwt: %q claim %g exceeds float64 precision of seconds", e.Claim, f)
+		}
+		n := NumericTime(f)
+		return fmt.Sprintf("jwt: %q claim %s out of range", e.Claim, n.String())
+	}
+	return fmt.Sprintf("jwt: %q claim is not a number; got %T", e.Claim, e.Value)
+}
+
+// Accept returns a TimeRangeError when n is out of range.
+func (r *NumericTimeRange) accept(claim string, n *NumericTime) error {
+	if n == nil {
+		return nil
+	}
+	f := float64(*n)
+	if math.IsNaN(f) || math.IsInf(f, 0) || math.Abs(f) >= maxExactSeconds {
+		return &TimeRangeError{Claim: claim, Value: f}
+	}
+	if !r.Min.IsZero() && f < float64(r.Min.Unix()) {
+		return &TimeRangeError{Claim: claim, Value: f}
+	}
+	if !r.Max.IsZero() && f > float64(r.Max.Unix()) {
+		return &TimeRangeError{Claim: claim, Value: f}
+	}
+	return nil
+}
+
+// CheckTimes applies StrictTime, if any, after applyPayload. Claims which did
+// not move to Registered are of the wrong type.
+func (c *Claims) checkTimes() error {
+	r := StrictTime
+	if r == nil {
+		return nil
+	}
+	for _, name := range [...]string{expires, notBefore, issued} {
+		if v, ok := c.Set[name]; ok {
+			return &TimeRangeError{Claim: name, Value: v}
+		}
+	}
+	if err := r.accept(expires, c.Expires); err != nil {
+		return err
+	}
+	if err := r.accept(notBefore, c.NotBefore); err != nil {
+		return err
+	}
+	return r.accept(issued, c.Issued)
+}
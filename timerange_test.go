@@ -0,0 +1,56 @@
+package jwt
+
+import (
+	"errors"
+	"testing"
+	"time"
+)
+
+func TestStrictTime(t *testing.T) {
+	defer func() { StrictTime = nil }()
+	StrictTime = SaneTimeRange
+
+	tests := []struct {
+		payload string
+		claim   string // rejected, if any
+	}{
+		{`{"exp":1600000000,"nbf":1600000000.5,"iat":0}`, ""},
+		{`{"sub":"x"}`, ""},
+		{`{"exp":-1}`, expires},
+		{`{"nbf":7258118401}`, notBefore},
+		{`{"iat":9007199254740993}`, issued},
+		{`{"exp":"1600000000"}`, expires},
+		{`{"exp":1600000000,"iat":null}`, issued},
+	}
+	for _, test := range tests {
+		token := "eyJhbGciOiJub25lIn0." + encoding.EncodeToString([]byte(test.payload)) + "."
+		c, err := ParseWithoutCheck([]byte(token))
+		if test.claim == "" {
+			if err != nil {
+				t.Errorf("%s: got error %q", test.payload, err)
+			} else if c == nil {
+				t.Errorf("%s: got no claims", test.payload)
+			}
+			continue
+		}
+		var e *TimeRangeError
+		if !errors.As(err, &e) {
+			t.Errorf("%s: got error %v, want a TimeRangeError", test.payload, err)
+			continue
+		}
+		if e.Claim != test.claim {
+			t.Errorf("%s: got claim %q, want %q", test.payload, e.Claim, test.claim)
+		}
+	}
+}
+
+func TestStrictTimeDisabled(t *testing.T) {
+	token := "eyJhbGciOiJub25lIn0." + encoding.EncodeToString([]byte(`{"exp":-1,"iat":"x"}`)) + "."
+	c, err := ParseWithoutCheck([]byte(token))
+	if err != nil {
+		t.Fatal("got error:", err)
+	}
+	if got := c.Expires.Time(); !got.Equal(time.Unix(-1, 0)) {
+		t.Errorf("got expiry %s, want one second before epoch", got)
+	}
+}
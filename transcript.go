@@ -0,0 +1,128 @@
+//go:build !jwtverifyonly
+
+package jwt
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"time"
+)
+
+// TranscriptType is the "typ" header parameter of verification transcripts.
+const TranscriptType = "verification-transcript+jwt"
+
+// TranscriptHeader is the extra JOSE header of verification transcripts.
+var TranscriptHeader = json.RawMessage(`{"typ":"` + TranscriptType + `"}`)
+
+// Transcript has the claims of a verification transcript, as decoded with
+// As[Transcript].
+type Transcript struct {
+	Issued      *NumericTime `json:"iat"`
+	TokenSHA256 string       `json:"token_sha256"` // base64url
+	Accepted    bool         `json:"accepted"`
+	Error       string       `json:"error,omitempty"`
+	Policy      string       `json:"policy,omitempty"`
+	KeyType     string       `json:"key_type,omitempty"`  // as in KeyStat
+	KeyIndex    *int         `json:"key_index,omitempty"` // as in KeyStat
+	KeyID       string       `json:"key_id,omitempty"`    // as in KeyStat
+}
+
+var errNoTranscriptEmit = errors.New("jwt: transcript Emit not set")
+
+// Transcriber verifies tokens and it emits a signed transcript of each result,
+// for workflows which must prove later that a token was (or was not) accepted
+// under a given policy.
+type Transcriber struct {
+	// Sign issues the transcripts with a service key.
+	Sign SignFunc
+
+	// Emit receives each transcript, e.g., to append it to an audit
+	// log. Errors fail the verification, as the acceptance can not be
+	// proven otherwise.
+	Emit func(transcript []byte) error
+
+	// Policy identifies the verification rules, e.g., a version or a
+	// hash of the configuration.
+	Policy string
+
+	// TemporalLeeway controls the tolerance with time constraints.
+	TemporalLeeway time.Duration
+
+	// Expect defines additional claim requirements, if any.
+	Expect *Expect
+}
+
+// Check verifies token with keys, with the time constraints, and with any
+// Expect. The outcome is recorded with Emit.
+func (t *Transcriber) Check(keys *KeyRegister, token []byte) (*Claims, error) {
+	return t.CheckContext(context.Background(), keys, token)
+}
+
+// CheckHeader applies Check on the bearer token of an HTTP request.
+func (t *Transcriber) CheckHeader(keys *KeyRegister, r *http.Request) (*Claims, error) {
+	token, err := BearerToken(r.Header)
+	if err != nil {
+		return nil, err
+	}
+	return t.CheckContext(r.Context(), keys, []byte(token))
+}
+
+// CheckContext is like Check, yet bound to ctx. See KeyRegister.CheckContext.
+func (t *Transcriber) CheckContext(ctx context.Context, keys *KeyRegister, token []byte) (*Claims, error) {
+	now := Clock()
+	c, key, err := keys.check(ctx, token)
+	if key != nil {
+		keys.used(key)
+	}
+	if err == nil {
+		err = c.AcceptTemporal(now, t.TemporalLeeway)
+	}
+	if err == nil && t.Expect != nil {
+		err = t.Expect.Accept(c)
+	}
+	if err != nil && err == ctx.Err() {
+		return nil, err // no outcome
+	}
+
+	sum := sha256.Sum256(token)
+	var record Claims
+	record.Issued = NewNumericTime(now.Truncate(time.Second))
+	record.Set = map[string]interface{}{
+		"token_sha256": encoding.EncodeToString(sum[:]),
+		"accepted":     err == nil,
+	}
+	if err != nil {
+		record.Set["error"] = err.Error()
+	}
+	if t.Policy != "" {
+		record.Set["policy"] = t.Policy
+	}
+	if key != nil {
+		if stat, ok := keys.describe(key); ok {
+			record.Set["key_type"] = stat.Type
+			record.Set["key_index"] = stat.Index
+			if stat.KeyID != "" {
+				record.Set["key_id"] = stat.KeyID
+			}
+		}
+	}
+
+	transcript, signErr := t.Sign(&record, TranscriptHeader)
+	if signErr != nil {
+		return nil, signErr
+	}
+	if t.Emit == nil {
+		return nil, errNoTranscriptEmit
+	}
+	if emitErr := t.Emit(transcript); emitErr != nil {
+		return nil, emitErr
+	}
+
+	if err != nil {
+		return nil, err
+	}
+	return c, nil
+}
@@ -0,0 +1,75 @@
+package jwt
+
+import (
+	"crypto/ed25519"
+	"encoding/json"
+	"testing"
+	"time"
+)
+
+func TestTranscriber(t *testing.T) {
+	var transcripts [][]byte
+	tr := Transcriber{
+		Sign: func(c *Claims, extraHeaders ...json.RawMessage) ([]byte, error) {
+			return c.EdDSASign(testKeyEd25519Private, extraHeaders...)
+		},
+		Emit: func(transcript []byte) error {
+			transcripts = append(transcripts, transcript)
+			return nil
+		},
+		Policy: "v7",
+	}
+	keys := KeyRegister{
+		Secrets:   [][]byte{[]byte("old"), []byte("guest")},
+		SecretIDs: []string{"", "main"},
+	}
+
+	var c Claims
+	c.Subject = "alice"
+	token, err := c.HMACSign(HS256, []byte("guest"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := tr.Check(&keys, token); err != nil {
+		t.Fatal("check error:", err)
+	}
+
+	c.Expires = NewNumericTime(time.Now().Add(-time.Hour))
+	expired, err := c.HMACSign(HS256, []byte("guest"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := tr.Check(&keys, expired); err != errExpired {
+		t.Errorf("expired token got error %v, want %v", err, errExpired)
+	}
+
+	if len(transcripts) != 2 {
+		t.Fatalf("got %d transcripts, want 2", len(transcripts))
+	}
+	serviceKeys := KeyRegister{EdDSAs: []ed25519.PublicKey{testKeyEd25519Public}}
+	var got []Transcript
+	for _, transcript := range transcripts {
+		claims, err := serviceKeys.Check(transcript)
+		if err != nil {
+			t.Fatal("transcript check error:", err)
+		}
+		record, err := As[Transcript](claims)
+		if err != nil {
+			t.Fatal("transcript decode error:", err)
+		}
+		got = append(got, record)
+	}
+
+	if !got[0].Accepted || got[0].Error != "" || got[0].Policy != "v7" {
+		t.Errorf("got accepted transcript %+v", got[0])
+	}
+	if got[0].KeyType != "secret" || got[0].KeyIndex == nil || *got[0].KeyIndex != 1 || got[0].KeyID != "main" {
+		t.Errorf("got key %q %v %q, want secret 1 main", got[0].KeyType, got[0].KeyIndex, got[0].KeyID)
+	}
+	if got[1].Accepted || got[1].Error != errExpired.Error() {
+		t.Errorf("got rejected transcript %+v", got[1])
+	}
+	if got[0].TokenSHA256 == got[1].TokenSHA256 || len(got[0].TokenSHA256) != 43 {
+		t.Errorf("got token hashes %q and %q", got[0].TokenSHA256, got[1].TokenSHA256)
+	}
+}
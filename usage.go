@@ -0,0 +1,139 @@
+package jwt
+
+import (
+	"sync"
+	"time"
+)
+
+// KeyUsage tracks the use of keys from a KeyRegister. The zero value is ready
+// for use. Keys are tracked by their identity, i.e., the memory address, which
+// means that a reload of the same key starts with a clean slate.
+//
+// Multiple goroutines may invoke methods on a KeyUsage simultaneously.
+type KeyUsage struct {
+	mutex  sync.Mutex
+	perKey map[interface{}]*KeyStat
+}
+
+// KeyStat has the verification statistics of a key.
+type KeyStat struct {
+	Type  string // one of "ECDSA", "EdDSA", "RSA", "HMAC" or "secret"
+	Index int    // position in the respective KeyRegister slice
+	KeyID string // optional
+
+	Verifications uint64    // number of successful signature checks
+	LastUsed      time.Time // latest verification, if any
+}
+
+// KeyID returns the identity of a key. Slices are identified by their backing
+// array. The return is nil for empty slices.
+func keyID(key []byte) interface{} {
+	if len(key) == 0 {
+		return nil
+	}
+	return &key[0]
+}
+
+// Used records a successful verification with the key identity.
+func (keys *KeyRegister) used(id interface{}) {
+	u := keys.Usage
+	if u == nil || id == nil {
+		return
+	}
+	now := time.Now()
+
+	u.mutex.Lock()
+	defer u.mutex.Unlock()
+	stat, ok := u.perKey[id]
+	if !ok {
+		if u.perKey == nil {
+			u.perKey = make(map[interface{}]*KeyStat)
+		}
+		stat = new(KeyStat)
+		u.perKey[id] = stat
+	}
+	stat.Verifications++
+	stat.LastUsed = now
+}
+
+// UsageStats returns the statistics of each key in the register, including the
+// ones without any use. Keys with a zero LastUsed may be safe to retire. The
+// return is nil when Usage is not set.
+func (keys *KeyRegister) UsageStats() []KeyStat {
+	u := keys.Usage
+	if u == nil {
+		return nil
+	}
+	u.mutex.Lock()
+	defer u.mutex.Unlock()
+
+	var stats []KeyStat
+	add := func(typ string, i int, ids []string, id interface{}) {
+		var stat KeyStat
+		if p, ok := u.perKey[id]; ok && id != nil {
+			stat = *p
+		}
+		stat.Type, stat.Index = typ, i
+		if i < len(ids) {
+			stat.KeyID = ids[i]
+		}
+		stats = append(stats, stat)
+	}
+	for i, key := range keys.ECDSAs {
+		add("ECDSA", i, keys.ECDSAIDs, key)
+	}
+	for i, key := range keys.EdDSAs {
+		add("EdDSA", i, keys.EdDSAIDs, keyID(key))
+	}
+	for i, key := range keys.RSAs {
+		add("RSA", i, keys.RSAIDs, key)
+	}
+	for i, h := range keys.HMACs {
+		add("HMAC", i, keys.HMACIDs, h)
+	}
+	for i, secret := range keys.Secrets {
+		add("secret", i, keys.SecretIDs, keyID(secret))
+	}
+	return stats
+}
+
+// Describe returns the KeyStat identification of a key identity, without any
+// statistics.
+func (keys *KeyRegister) describe(id interface{}) (stat KeyStat, ok bool) {
+	find := func(typ string, i int, ids []string, keyID interface{}) bool {
+		if keyID != id {
+			return false
+		}
+		stat.Type, stat.Index = typ, i
+		if i < len(ids) {
+			stat.KeyID = ids[i]
+		}
+		return true
+	}
+	for i, key := range keys.ECDSAs {
+		if find("ECDSA", i, keys.ECDSAIDs, key) {
+			return stat, true
+		}
+	}
+	for i, key := range keys.EdDSAs {
+		if find("EdDSA", i, keys.EdDSAIDs, keyID(key)) {
+			return stat, true
+		}
+	}
+	for i, key := range keys.RSAs {
+		if find("RSA", i, keys.RSAIDs, key) {
+			return stat, true
+		}
+	}
+	for i, h := range keys.HMACs {
+		if find("HMAC", i, keys.HMACIDs, h) {
+			return stat, true
+		}
+	}
+	for i, secret := range keys.Secrets {
+		if find("secret", i, keys.SecretIDs, keyID(secret)) {
+			return stat, true
+		}
+	}
+	return KeyStat{}, false
+}
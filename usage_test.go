@@ -0,0 +1,39 @@
+package jwt
+
+import (
+	"crypto/ed25519"
+	"testing"
+)
+
+func TestKeyUsage(t *testing.T) {
+	keys := KeyRegister{
+		EdDSAs:    []ed25519.PublicKey{testKeyEd25519Public},
+		Secrets:   [][]byte{[]byte("old"), []byte("new")},
+		SecretIDs: []string{"", "2"},
+		Usage:     new(KeyUsage),
+	}
+
+	token, err := new(Claims).HMACSign(HS256, []byte("new"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	for i := 0; i < 3; i++ {
+		if _, err := keys.Check(token); err != nil {
+			t.Fatal("check error:", err)
+		}
+	}
+
+	stats := keys.UsageStats()
+	if len(stats) != 3 {
+		t.Fatalf("got %d stats, want 3", len(stats))
+	}
+	for _, s := range stats {
+		if s.Type == "secret" && s.Index == 1 {
+			if s.Verifications != 3 || s.LastUsed.IsZero() || s.KeyID != "2" {
+				t.Errorf("got used key stat %+v, want 3 verifications with key ID 2", s)
+			}
+		} else if s.Verifications != 0 || !s.LastUsed.IsZero() {
+			t.Errorf("got unused key stat %+v", s)
+		}
+	}
+}
@@ -0,0 +1,149 @@
+package jwt
+
+import (
+	"encoding/json"
+	"fmt"
+	"math"
+	"net/url"
+	"strconv"
+)
+
+// Values returns the claims as URL query values, for systems which exchange
+// assertions as form posts. The typing rules are as follows.
+//
+//   - Registered strings, i.e., "iss", "sub" and "jti", are one value each.
+//   - The "aud" claim has one value per audience.
+//   - Registered times, i.e., "exp", "nbf" and "iat", are one value each, in
+//     decimal seconds, e.g., "1700000000" or "1700000000.5".
+//   - Strings from Set are one value each.
+//   - Arrays of strings from Set have one value per element.
+//   - Any other value from Set is one value, in its JSON encoding.
+//
+// Registered fields take precedence over Set, as with signing. Empty strings
+// and empty arrays are omitted. See ClaimsFromValues for the reverse.
+func (c *Claims) Values() url.Values {
+	v := make(url.Values)
+	for name, value := range c.Set {
+		if s, ok := value.(string); ok {
+			if s != "" {
+				v[name] = []string{s}
+			}
+			continue
+		}
+		switch value.(type) {
+		case []string, []interface{}:
+			if a := stringsClaim(value); a != nil {
+				if len(a) != 0 {
+					v[name] = append([]string(nil), a...)
+				}
+				continue
+			}
+		}
+		text, err := json.Marshal(value)
+		if err != nil {
+			continue // not representable
+		}
+		v[name] = []string{string(text)}
+	}
+
+	if c.Issuer != "" {
+		v[issuer] = []string{c.Issuer}
+	}
+	if c.Subject != "" {
+		v[subject] = []string{c.Subject}
+	}
+	if len(c.Audiences) != 0 {
+		v[audience] = append([]string(nil), c.Audiences...)
+	}
+	if c.Expires != nil {
+		v[expires] = []string{formatNumericTime(*c.Expires)}
+	}
+	if c.NotBefore != nil {
+		v[notBefore] = []string{formatNumericTime(*c.NotBefore)}
+	}
+	if c.Issued != nil {
+		v[issued] = []string{formatNumericTime(*c.Issued)}
+	}
+	if c.ID != "" {
+		v[id] = []string{c.ID}
+	}
+	return v
+}
+
+func formatNumericTime(n NumericTime) string {
+	return strconv.FormatFloat(float64(n), 'f', -1, 64)
+}
+
+// ClaimsFromValues returns the claims of URL query values, conform the typing
+// rules of Claims.Values. Values for "exp", "nbf" and "iat" must be decimal
+// seconds, and the other registered claims must be one value, except for
+// "aud". Names with one value go in Set as a string, and names with multiple
+// values go in Set as an array of strings. Values are never read as JSON. Thus,
+// anything other than strings and arrays of strings, including arrays with one
+// element, come back as strings. Empty values are ignored.
+func ClaimsFromValues(v url.Values) (*Claims, error) {
+	c := &Claims{Set: make(map[string]interface{})}
+	for name, values := range v {
+		// filter empty values
+		var a []string
+		for _, s := range values {
+			if s != "" {
+				a = append(a, s)
+			}
+		}
+		if len(a) == 0 {
+			continue
+		}
+
+		switch name {
+		case audience:
+			c.Audiences = a
+			continue
+		case expires, notBefore, issued:
+			if len(a) != 1 {
+				return nil, fmt.Errorf("jwt: %d values for %q, want 1", len(a), name)
+			}
+			f, err := strconv.ParseFloat(a[0], 64)
+			if err != nil {
+				return nil, fmt.Errorf("jwt: malformed %q value: %w", name, err)
+			}
+			if math.IsNaN(f) || math.IsInf(f, 0) {
+				return nil, fmt.Errorf("jwt: malformed %q value %q", name, a[0])
+			}
+			n := NumericTime(f)
+			switch name {
+			case expires:
+				c.Expires = &n
+			case notBefore:
+				c.NotBefore = &n
+			default:
+				c.Issued = &n
+			}
+			continue
+		case issuer, subject, id:
+			if len(a) != 1 {
+				return nil, fmt.Errorf("jwt: %d values for %q, want 1", len(a), name)
+			}
+			switch name {
+			case issuer:
+				c.Issuer = a[0]
+			case subject:
+				c.Subject = a[0]
+			default:
+				c.ID = a[0]
+			}
+			continue
+		}
+
+		if len(a) == 1 {
+			c.Set[name] = a[0]
+		} else {
+			array := make([]interface{}, len(a))
+			for i, s := range a {
+				array[i] = s
+			}
+			c.Set[name] = array
+		}
+	}
+	return c, nil
+}
@@ -0,0 +1,106 @@
+package jwt
+
+import (
+	"net/url"
+	"reflect"
+	"testing"
+	"time"
+)
+
+func TestValuesRoundTrip(t *testing.T) {
+	var c Claims
+	c.Issuer = "https://issuer.example.com"
+	c.Subject = "alice"
+	c.Audiences = []string{"api1", "api2"}
+	c.Expires = NewNumericTime(time.Unix(1700000000, 5e8))
+	c.NotBefore = NewNumericTime(time.Unix(1600000000, 0))
+	c.ID = "42"
+	c.Set = map[string]interface{}{
+		"scope": "read write",
+		"roles": []interface{}{"reader", "writer"},
+	}
+
+	v := c.Values()
+	want := url.Values{
+		"iss":   {"https://issuer.example.com"},
+		"sub":   {"alice"},
+		"aud":   {"api1", "api2"},
+		"exp":   {"1700000000.5"},
+		"nbf":   {"1600000000"},
+		"jti":   {"42"},
+		"scope": {"read write"},
+		"roles": {"reader", "writer"},
+	}
+	if !reflect.DeepEqual(v, want) {
+		t.Errorf("got values %v, want %v", v, want)
+	}
+
+	got, err := ClaimsFromValues(v)
+	if err != nil {
+		t.Fatal("from values error:", err)
+	}
+	if !reflect.DeepEqual(got.Registered, c.Registered) {
+		t.Errorf("got registered %+v, want %+v", got.Registered, c.Registered)
+	}
+	if !reflect.DeepEqual(got.Set, c.Set) {
+		t.Errorf("got set %#v, want %#v", got.Set, c.Set)
+	}
+
+	// reverse direction
+	if again := got.Values(); !reflect.DeepEqual(again, v) {
+		t.Errorf("second round got values %v, want %v", again, v)
+	}
+}
+
+func TestValuesTyping(t *testing.T) {
+	c := Claims{Set: map[string]interface{}{
+		"n":      42.0,
+		"ok":     true,
+		"obj":    map[string]interface{}{"a": 1.0},
+		"single": []interface{}{"x"},
+		"mixed":  []interface{}{"x", 1.0},
+		"empty":  "",
+	}}
+	v := c.Values()
+	want := url.Values{
+		"n":      {"42"},
+		"ok":     {"true"},
+		"obj":    {`{"a":1}`},
+		"single": {"x"},
+		"mixed":  {`["x",1]`},
+	}
+	if !reflect.DeepEqual(v, want) {
+		t.Errorf("got values %v, want %v", v, want)
+	}
+
+	// no JSON interpretation
+	got, err := ClaimsFromValues(v)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got.Set["n"] != "42" || got.Set["single"] != "x" {
+		t.Errorf("got set %#v, want strings only", got.Set)
+	}
+}
+
+func TestClaimsFromValuesErrors(t *testing.T) {
+	golden := []url.Values{
+		{"exp": {"soon"}},
+		{"nbf": {"NaN"}},
+		{"iat": {"1", "2"}},
+		{"sub": {"alice", "bob"}},
+	}
+	for _, v := range golden {
+		if _, err := ClaimsFromValues(v); err == nil {
+			t.Errorf("%v got no error", v)
+		}
+	}
+
+	c, err := ClaimsFromValues(url.Values{"sub": {"", "alice"}, "x": {""}})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if c.Subject != "alice" || len(c.Set) != 0 {
+		t.Errorf("empty values got subject %q and set %v", c.Subject, c.Set)
+	}
+}
@@ -0,0 +1,120 @@
+//go:build !jwtverifyonly
+
+package vault
+
+import (
+	"context"
+	"crypto"
+	"crypto/rsa"
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"strings"
+
+	"github.com/pascaldekloe/jwt"
+)
+
+// Signer issues tokens with the latest version of a Transit key, as of its
+// construction. Multiple goroutines may invoke methods on a Signer
+// simultaneously.
+type Signer struct {
+	client  *Client
+	name    string
+	version int
+	alg     string
+	public  crypto.PublicKey
+}
+
+// NewSigner returns a Signer for the Transit key with name. The algorithm
+// defaults to the key type when empty, as with Algorithm. RSA keys can use any
+// of the RS and PS algorithms.
+func (c *Client) NewSigner(ctx context.Context, name, alg string) (*Signer, error) {
+	info, err := c.readKey(ctx, name)
+	if err != nil {
+		return nil, err
+	}
+	if alg == "" {
+		alg, _ = Algorithm(info.typ)
+	}
+	return &Signer{
+		client:  c,
+		name:    name,
+		version: info.latest,
+		alg:     alg,
+		public:  info.public[info.latest],
+	}, nil
+}
+
+// KeyID returns the key ID in use, which matches LoadKeys.
+func (s *Signer) KeyID() string { return KeyID(s.name, s.version) }
+
+// Alg returns the JWT algorithm in use.
+func (s *Signer) Alg() string { return s.alg }
+
+// Public returns the public key of the key version.
+func (s *Signer) Public() crypto.PublicKey { return s.public }
+
+// Sign updates the Raw fields and returns a new JWT, signed by Vault. Claims
+// get the key ID of the Signer. See jwt.Claims.SignWith for details.
+func (s *Signer) Sign(ctx context.Context, c *jwt.Claims, extraHeaders ...json.RawMessage) (token []byte, err error) {
+	c.KeyID = s.KeyID()
+	return c.SignWith(s.alg, &transitSigner{ctx: ctx, signer: s}, extraHeaders...)
+}
+
+// TransitSigner binds a context to crypto.Signer.
+type transitSigner struct {
+	ctx    context.Context
+	signer *Signer
+}
+
+// Public implements crypto.Signer.
+func (t *transitSigner) Public() crypto.PublicKey { return t.signer.public }
+
+// Sign implements crypto.Signer.
+func (t *transitSigner) Sign(_ io.Reader, message []byte, opts crypto.SignerOpts) ([]byte, error) {
+	req := map[string]interface{}{
+		"input":                base64.StdEncoding.EncodeToString(message),
+		"key_version":          t.signer.version,
+		"marshaling_algorithm": "asn1",
+	}
+	path := t.signer.client.path("sign", t.signer.name)
+	if hash := opts.HashFunc(); hash != 0 {
+		req["prehashed"] = true
+		switch hash {
+		case crypto.SHA256:
+			path += "/sha2-256"
+		case crypto.SHA384:
+			path += "/sha2-384"
+		case crypto.SHA512:
+			path += "/sha2-512"
+		default:
+			return nil, fmt.Errorf("vault: hash %s not supported", hash)
+		}
+		if pss, ok := opts.(*rsa.PSSOptions); ok {
+			req["signature_algorithm"] = "pss"
+			if pss.SaltLength == rsa.PSSSaltLengthEqualsHash {
+				req["salt_length"] = "hash"
+			}
+		} else if strings.HasPrefix(t.signer.alg, "RS") {
+			req["signature_algorithm"] = "pkcs1v15"
+		}
+	}
+
+	var resp struct {
+		Data struct {
+			Signature string `json:"signature"`
+		} `json:"data"`
+	}
+	if err := t.signer.client.do(t.ctx, http.MethodPost, path, req, &resp); err != nil {
+		return nil, err
+	}
+	// format "vault:v<version>:<base64>"
+	s := resp.Data.Signature
+	prefix := fmt.Sprintf("vault:v%d:", t.signer.version)
+	if !strings.HasPrefix(s, prefix) {
+		return nil, fmt.Errorf("vault: signature %q without prefix %q", s, prefix)
+	}
+	return base64.StdEncoding.DecodeString(s[len(prefix):])
+}
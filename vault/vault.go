@@ -0,0 +1,338 @@
+// Package vault provides token issuance with keys from the Transit secrets
+// engine of HashiCorp Vault, without any dependency on the Vault SDK. Private
+// keys never leave Vault.
+package vault
+
+import (
+	"bytes"
+	"context"
+	"crypto"
+	"crypto/ecdsa"
+	"crypto/ed25519"
+	"crypto/rsa"
+	"crypto/x509"
+	"encoding/base64"
+	"encoding/json"
+	"encoding/pem"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/pascaldekloe/jwt"
+)
+
+var errExpired = errors.New("vault: token expired")
+
+// Client accesses the Transit secrets engine. The Vault token is renewed when
+// half of its time-to-live passed, if renewable.
+//
+// Multiple goroutines may invoke methods on a Client simultaneously.
+type Client struct {
+	// Addr is the base URL of Vault, e.g., "https://vault.example:8200".
+	Addr string
+
+	// Mount is the path of the Transit engine. The zero value defaults
+	// to "transit".
+	Mount string
+
+	// Namespace is the Vault Enterprise namespace, if any.
+	Namespace string
+
+	// HTTPClient defaults to http.DefaultClient when nil.
+	HTTPClient *http.Client
+
+	mutex     sync.Mutex
+	token     string
+	looked    bool      // time-to-live known
+	renewable bool      // token renewable
+	renewAt   time.Time // zero for never
+	expires   time.Time // zero for never
+}
+
+// NewClient returns a new Client for the Vault at addr.
+func NewClient(addr, token string) *Client {
+	return &Client{Addr: addr, token: token}
+}
+
+// KeyID returns the key ID of a Transit key version, e.g., "jwt:v3".
+func KeyID(name string, version int) string {
+	return name + ":v" + strconv.Itoa(version)
+}
+
+// Algorithm returns the default JWT algorithm of a Transit key type.
+func Algorithm(keyType string) (alg string, ok bool) {
+	switch keyType {
+	case "ecdsa-p256":
+		return jwt.ES256, true
+	case "ecdsa-p384":
+		return jwt.ES384, true
+	case "ecdsa-p521":
+		return jwt.ES512, true
+	case "ed25519":
+		return jwt.EdDSA, true
+	case "rsa-2048", "rsa-3072", "rsa-4096":
+		return jwt.RS256, true
+	}
+	return "", false
+}
+
+// LoadKeys adds the public keys of each version of each Transit key to the
+// register, with KeyID as the key ID. Keys which are present already, with the
+// same key ID, are skipped, as with jwt.KeyRegister.Merge.
+func (c *Client) LoadKeys(ctx context.Context, keys *jwt.KeyRegister, names ...string) (keysAdded int, err error) {
+	var staged jwt.KeyRegister
+	for _, name := range names {
+		info, err := c.readKey(ctx, name)
+		if err != nil {
+			return 0, err
+		}
+		for _, version := range info.versions() {
+			kid := KeyID(name, version)
+			switch t := info.public[version].(type) {
+			case *ecdsa.PublicKey:
+				staged.ECDSAs = append(staged.ECDSAs, t)
+				staged.ECDSAIDs = append(staged.ECDSAIDs, kid)
+			case ed25519.PublicKey:
+				staged.EdDSAs = append(staged.EdDSAs, t)
+				staged.EdDSAIDs = append(staged.EdDSAIDs, kid)
+			case *rsa.PublicKey:
+				staged.RSAs = append(staged.RSAs, t)
+				staged.RSAIDs = append(staged.RSAIDs, kid)
+			}
+		}
+	}
+	return keys.Merge(&staged)
+}
+
+// KeyInfo has the public side of a Transit key.
+type keyInfo struct {
+	typ    string
+	latest int
+	public map[int]crypto.PublicKey
+}
+
+// Versions returns the key versions in ascending order.
+func (info *keyInfo) versions() []int {
+	versions := make([]int, 0, len(info.public))
+	for v := range info.public {
+		versions = append(versions, v)
+	}
+	sort.Ints(versions)
+	return versions
+}
+
+func (c *Client) readKey(ctx context.Context, name string) (*keyInfo, error) {
+	var resp struct {
+		Data struct {
+			Type          string                     `json:"type"`
+			LatestVersion int                        `json:"latest_version"`
+			Keys          map[string]json.RawMessage `json:"keys"`
+		} `json:"data"`
+	}
+	if err := c.do(ctx, http.MethodGet, c.path("keys", name), nil, &resp); err != nil {
+		return nil, err
+	}
+	if _, ok := Algorithm(resp.Data.Type); !ok {
+		return nil, fmt.Errorf("vault: key %q of unsupported type %q", name, resp.Data.Type)
+	}
+
+	info := &keyInfo{
+		typ:    resp.Data.Type,
+		latest: resp.Data.LatestVersion,
+		public: make(map[int]crypto.PublicKey, len(resp.Data.Keys)),
+	}
+	for s, raw := range resp.Data.Keys {
+		version, err := strconv.Atoi(s)
+		if err != nil {
+			return nil, fmt.Errorf("vault: key %q with malformed version %q", name, s)
+		}
+		var v struct {
+			PublicKey string `json:"public_key"`
+		}
+		if err := json.Unmarshal(raw, &v); err != nil {
+			return nil, fmt.Errorf("vault: key %q version %d: %w", name, version, err)
+		}
+		pub, err := parsePublicKey(info.typ, v.PublicKey)
+		if err != nil {
+			return nil, fmt.Errorf("vault: key %q version %d: %w", name, version, err)
+		}
+		info.public[version] = pub
+	}
+	if _, ok := info.public[info.latest]; !ok {
+		return nil, fmt.Errorf("vault: key %q without latest version %d", name, info.latest)
+	}
+	return info, nil
+}
+
+// ParsePublicKey decodes the public_key of a Transit key version, which is
+// base64 for Ed25519 and PEM otherwise.
+func parsePublicKey(typ, s string) (crypto.PublicKey, error) {
+	if typ == "ed25519" {
+		b, err := base64.StdEncoding.DecodeString(s)
+		if err != nil {
+			return nil, err
+		}
+		if len(b) != ed25519.PublicKeySize {
+			return nil, errors.New("malformed Ed25519 public key")
+		}
+		return ed25519.PublicKey(b), nil
+	}
+
+	block, _ := pem.Decode([]byte(s))
+	if block == nil {
+		return nil, errors.New("public key not in PEM")
+	}
+	pub, err := x509.ParsePKIXPublicKey(block.Bytes)
+	if err != nil {
+		return nil, err
+	}
+	switch pub.(type) {
+	case *ecdsa.PublicKey, *rsa.PublicKey:
+		return pub, nil
+	}
+	return nil, fmt.Errorf("unsupported public key type %T", pub)
+}
+
+func (c *Client) path(elems ...string) string {
+	mount := c.Mount
+	if mount == "" {
+		mount = "transit"
+	}
+	var buf strings.Builder
+	buf.WriteString("/v1/")
+	buf.WriteString(strings.Trim(mount, "/"))
+	for _, e := range elems {
+		buf.WriteByte('/')
+		buf.WriteString(url.PathEscape(e))
+	}
+	return buf.String()
+}
+
+// AuthToken returns the Vault token, after renewal when due.
+func (c *Client) authToken(ctx context.Context) (string, error) {
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+
+	now := jwt.Clock()
+	if !c.looked {
+		var resp struct {
+			Data struct {
+				TTL       int64 `json:"ttl"`
+				Renewable bool  `json:"renewable"`
+			} `json:"data"`
+		}
+		if err := c.send(ctx, http.MethodGet, "/v1/auth/token/lookup-self", c.token, nil, &resp); err != nil {
+			return "", err
+		}
+		c.looked = true
+		c.renewable = resp.Data.Renewable
+		c.schedule(now, time.Duration(resp.Data.TTL)*time.Second)
+		return c.token, nil
+	}
+
+	if c.renewAt.IsZero() || now.Before(c.renewAt) {
+		return c.token, nil
+	}
+
+	var resp struct {
+		Auth struct {
+			LeaseDuration int64 `json:"lease_duration"`
+			Renewable     bool  `json:"renewable"`
+		} `json:"auth"`
+	}
+	err := c.send(ctx, http.MethodPost, "/v1/auth/token/renew-self", c.token, struct{}{}, &resp)
+	if err != nil {
+		if !now.Before(c.expires) {
+			return "", fmt.Errorf("%w; renewal failed: %v", errExpired, err)
+		}
+		// retry later, as the token is still valid
+		c.renewAt = now.Add(c.expires.Sub(now) / 2)
+		return c.token, nil
+	}
+	c.renewable = resp.Auth.Renewable
+	c.schedule(now, time.Duration(resp.Auth.LeaseDuration)*time.Second)
+	return c.token, nil
+}
+
+// Schedule sets the renewal for a time-to-live, with zero for no expiry.
+func (c *Client) schedule(now time.Time, ttl time.Duration) {
+	if ttl <= 0 {
+		c.expires, c.renewAt = time.Time{}, time.Time{}
+		return
+	}
+	c.expires = now.Add(ttl)
+	if c.renewable {
+		c.renewAt = now.Add(ttl / 2)
+	} else {
+		c.renewAt = time.Time{}
+	}
+}
+
+// Do sends an authenticated request.
+func (c *Client) do(ctx context.Context, method, path string, body, dst interface{}) error {
+	token, err := c.authToken(ctx)
+	if err != nil {
+		return err
+	}
+	return c.send(ctx, method, path, token, body, dst)
+}
+
+// Send exchanges JSON with Vault.
+func (c *Client) send(ctx context.Context, method, path, token string, body, dst interface{}) error {
+	var r io.Reader
+	if body != nil {
+		b, err := json.Marshal(body)
+		if err != nil {
+			return err
+		}
+		r = bytes.NewReader(b)
+	}
+	req, err := http.NewRequestWithContext(ctx, method, strings.TrimRight(c.Addr, "/")+path, r)
+	if err != nil {
+		return err
+	}
+	req.Header.Set("X-Vault-Token", token)
+	req.Header.Set("X-Vault-Request", "true")
+	if c.Namespace != "" {
+		req.Header.Set("X-Vault-Namespace", c.Namespace)
+	}
+	if body != nil {
+		req.Header.Set("Content-Type", "application/json")
+	}
+
+	client := c.HTTPClient
+	if client == nil {
+		client = http.DefaultClient
+	}
+	resp, err := client.Do(req)
+	if err != nil {
+		return fmt.Errorf("vault: %s %s: %w", method, path, err)
+	}
+	defer resp.Body.Close()
+	data, err := io.ReadAll(io.LimitReader(resp.Body, 1<<20))
+	if err != nil {
+		return fmt.Errorf("vault: %s %s: %w", method, path, err)
+	}
+
+	if resp.StatusCode/100 != 2 {
+		var e struct {
+			Errors []string `json:"errors"`
+		}
+		json.Unmarshal(data, &e)
+		if len(e.Errors) != 0 {
+			return fmt.Errorf("vault: %s %s: %s: %s", method, path, resp.Status, strings.Join(e.Errors, "; "))
+		}
+		return fmt.Errorf("vault: %s %s: %s", method, path, resp.Status)
+	}
+	if err := json.Unmarshal(data, dst); err != nil {
+		return fmt.Errorf("vault: %s %s: malformed response: %w", method, path, err)
+	}
+	return nil
+}
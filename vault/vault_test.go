@@ -0,0 +1,240 @@
+package vault
+
+import (
+	"context"
+	"crypto"
+	"crypto/ecdsa"
+	"crypto/ed25519"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/x509"
+	"encoding/base64"
+	"encoding/json"
+	"encoding/pem"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/pascaldekloe/jwt"
+)
+
+// FakeVault serves the Transit engine with a key per type.
+type fakeVault struct {
+	t      *testing.T
+	keys   map[string]crypto.Signer // by name, with type as the name
+	lookup int32
+	renew  int32
+}
+
+func newFakeVault(t *testing.T) *fakeVault {
+	ec, err := ecdsa.GenerateKey(elliptic.P384(), rand.Reader)
+	if err != nil {
+		t.Fatal(err)
+	}
+	_, ed, err := ed25519.GenerateKey(rand.Reader)
+	if err != nil {
+		t.Fatal(err)
+	}
+	rsaKey, err := rsa.GenerateKey(rand.Reader, 2048)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return &fakeVault{t: t, keys: map[string]crypto.Signer{
+		"ecdsa-p384": ec,
+		"ed25519":    ed,
+		"rsa-2048":   rsaKey,
+	}}
+}
+
+func (f *fakeVault) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	if r.Header.Get("X-Vault-Token") != "s.test" {
+		w.WriteHeader(http.StatusForbidden)
+		w.Write([]byte(`{"errors":["permission denied"]}`))
+		return
+	}
+
+	switch path := r.URL.Path; {
+	case path == "/v1/auth/token/lookup-self":
+		atomic.AddInt32(&f.lookup, 1)
+		w.Write([]byte(`{"data":{"ttl":60,"renewable":true}}`))
+
+	case path == "/v1/auth/token/renew-self":
+		atomic.AddInt32(&f.renew, 1)
+		w.Write([]byte(`{"auth":{"lease_duration":60,"renewable":true}}`))
+
+	case strings.HasPrefix(path, "/v1/transit/keys/"):
+		name := strings.TrimPrefix(path, "/v1/transit/keys/")
+		key, ok := f.keys[name]
+		if !ok {
+			w.WriteHeader(http.StatusNotFound)
+			w.Write([]byte(`{"errors":[]}`))
+			return
+		}
+		var public string
+		if pub, ok := key.Public().(ed25519.PublicKey); ok {
+			public = base64.StdEncoding.EncodeToString(pub)
+		} else {
+			der, err := x509.MarshalPKIXPublicKey(key.Public())
+			if err != nil {
+				f.t.Fatal(err)
+			}
+			public = string(pem.EncodeToMemory(&pem.Block{Type: "PUBLIC KEY", Bytes: der}))
+		}
+		json.NewEncoder(w).Encode(map[string]interface{}{
+			"data": map[string]interface{}{
+				"type":           name,
+				"latest_version": 2,
+				"keys": map[string]interface{}{
+					"2": map[string]string{"public_key": public},
+				},
+			},
+		})
+
+	case strings.HasPrefix(path, "/v1/transit/sign/"):
+		var req struct {
+			Input     []byte `json:"input"`
+			Prehashed bool   `json:"prehashed"`
+			Version   int    `json:"key_version"`
+			SigAlg    string `json:"signature_algorithm"`
+			Salt      string `json:"salt_length"`
+		}
+		if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+			f.t.Fatal(err)
+		}
+		if req.Version != 2 {
+			f.t.Errorf("got key version %d, want 2", req.Version)
+		}
+		name, hashName, _ := strings.Cut(strings.TrimPrefix(path, "/v1/transit/sign/"), "/")
+		var opts crypto.SignerOpts = map[string]crypto.Hash{
+			"":         0,
+			"sha2-256": crypto.SHA256,
+			"sha2-384": crypto.SHA384,
+			"sha2-512": crypto.SHA512,
+		}[hashName]
+		if req.SigAlg == "pss" {
+			if req.Salt != "hash" {
+				f.t.Errorf("got salt length %q, want hash", req.Salt)
+			}
+			opts = &rsa.PSSOptions{SaltLength: rsa.PSSSaltLengthEqualsHash, Hash: opts.HashFunc()}
+		}
+		if req.Prehashed != (opts.HashFunc() != 0) {
+			f.t.Errorf("got prehashed %t for hash %q", req.Prehashed, hashName)
+		}
+		sig, err := f.keys[name].Sign(rand.Reader, req.Input, opts)
+		if err != nil {
+			f.t.Fatal(err)
+		}
+		json.NewEncoder(w).Encode(map[string]interface{}{
+			"data": map[string]string{
+				"signature": "vault:v2:" + base64.StdEncoding.EncodeToString(sig),
+			},
+		})
+
+	default:
+		http.NotFound(w, r)
+	}
+}
+
+func TestSignAndLoadKeys(t *testing.T) {
+	vault := newFakeVault(t)
+	srv := httptest.NewServer(vault)
+	defer srv.Close()
+	client := NewClient(srv.URL, "s.test")
+
+	var keys jwt.KeyRegister
+	n, err := client.LoadKeys(context.Background(), &keys, "ecdsa-p384", "ed25519", "rsa-2048")
+	if err != nil {
+		t.Fatal("load error:", err)
+	}
+	if n != 3 {
+		t.Errorf("got %d keys added, want 3", n)
+	}
+
+	golden := []struct{ name, alg, want string }{
+		{"ecdsa-p384", "", jwt.ES384},
+		{"ed25519", "", jwt.EdDSA},
+		{"rsa-2048", "", jwt.RS256},
+		{"rsa-2048", jwt.PS512, jwt.PS512},
+	}
+	for _, gold := range golden {
+		signer, err := client.NewSigner(context.Background(), gold.name, gold.alg)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if signer.Alg() != gold.want {
+			t.Errorf("%s: got algorithm %q, want %q", gold.name, signer.Alg(), gold.want)
+		}
+		var c jwt.Claims
+		c.Subject = "test"
+		token, err := signer.Sign(context.Background(), &c)
+		if err != nil {
+			t.Fatalf("%s %s: sign error: %s", gold.name, gold.want, err)
+		}
+		got, err := keys.Check(token)
+		if err != nil {
+			t.Fatalf("%s %s: check error: %s", gold.name, gold.want, err)
+		}
+		if got.KeyID != gold.name+":v2" || got.Subject != "test" {
+			t.Errorf("%s %s: got key ID %q and subject %q", gold.name, gold.want, got.KeyID, got.Subject)
+		}
+	}
+
+	if _, err := client.NewSigner(context.Background(), "absent", ""); err == nil {
+		t.Error("no error for absent key")
+	}
+}
+
+func TestTokenRenewal(t *testing.T) {
+	defer func() { jwt.Clock = time.Now }()
+	now := time.Unix(1e9, 0)
+	jwt.Clock = func() time.Time { return now }
+
+	vault := newFakeVault(t)
+	srv := httptest.NewServer(vault)
+	defer srv.Close()
+	client := NewClient(srv.URL, "s.test")
+
+	read := func() {
+		t.Helper()
+		if _, err := client.readKey(context.Background(), "ed25519"); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	read()
+	read()
+	if vault.lookup != 1 || vault.renew != 0 {
+		t.Errorf("got %d lookups and %d renewals, want 1 and 0", vault.lookup, vault.renew)
+	}
+	now = now.Add(29 * time.Second)
+	read()
+	if vault.renew != 0 {
+		t.Errorf("got %d renewals before half of the time-to-live", vault.renew)
+	}
+	now = now.Add(time.Second)
+	read()
+	read()
+	if vault.renew != 1 {
+		t.Errorf("got %d renewals at half of the time-to-live, want 1", vault.renew)
+	}
+	now = now.Add(30 * time.Second)
+	read()
+	if vault.lookup != 1 || vault.renew != 2 {
+		t.Errorf("got %d lookups and %d renewals, want 1 and 2", vault.lookup, vault.renew)
+	}
+}
+
+func TestErrors(t *testing.T) {
+	srv := httptest.NewServer(newFakeVault(t))
+	defer srv.Close()
+
+	_, err := NewClient(srv.URL, "s.wrong").readKey(context.Background(), "ed25519")
+	want := "vault: GET /v1/auth/token/lookup-self: 403 Forbidden: permission denied"
+	if err == nil || err.Error() != want {
+		t.Errorf("got error %v, want %q", err, want)
+	}
+}
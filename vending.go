@@ -0,0 +1,148 @@
+//go:build !jwtverifyonly
+
+package jwt
+
+import (
+	"errors"
+	"fmt"
+	"sync"
+	"time"
+)
+
+var errVendNoSubject = errors.New(`jwt: subject ["sub"] absent; quota needs an owner`)
+
+// QuotaError signals an issue denied by the limits of a Vendor.
+type QuotaError struct {
+	Subject    string        // the principal denied
+	RetryAfter time.Duration // time until an issue is permitted
+}
+
+// Error honors the error interface.
+func (e *QuotaError) Error() string {
+	return fmt.Sprintf("jwt: issue quota of subject %q exceeded; retry after %s", e.Subject, e.RetryAfter)
+}
+
+// MintLimits constrains the number of issues per subject.
+type MintLimits struct {
+	// Quota is the maximum number of issues within Window. Quota
+	// applies only when both Quota and Window are non-zero.
+	Quota  int
+	Window time.Duration
+
+	// MinInterval is the minimum duration between two issues, if any.
+	MinInterval time.Duration
+}
+
+// MintStore tracks the issues per subject.
+type MintStore interface {
+	// Mint registers an issue to subject at now, unless the limits deny
+	// such. The return is zero on success, or the duration until the
+	// next issue is permitted otherwise.
+	Mint(subject string, now time.Time, limits MintLimits) (wait time.Duration, err error)
+}
+
+// Vendor issues tokens with limits per subject, which protects shared issuers
+// from runaway clients.
+//
+// Multiple goroutines may invoke methods on a Vendor simultaneously.
+type Vendor struct {
+	// Sign issues the token, e.g., with Claims.EdDSASign.
+	Sign func(*Claims) (token []byte, err error)
+
+	// Store tracks the issues. See MemoryMintStore for a single-node
+	// setup. Multiple issuers can share a distributed implementation.
+	Store MintStore
+
+	MintLimits
+}
+
+// Issue returns a new token from Sign, unless the limits deny an issue to the
+// subject of c. The return is a *QuotaError in such case. Claims without a
+// subject are rejected, as they can not be accounted for. Note that failures
+// from Sign count as an issue nonetheless.
+func (v *Vendor) Issue(c *Claims) (token []byte, err error) {
+	if c.Subject == "" {
+		return nil, errVendNoSubject
+	}
+	wait, err := v.Store.Mint(c.Subject, Clock(), v.MintLimits)
+	if err != nil {
+		return nil, err
+	}
+	if wait > 0 {
+		return nil, &QuotaError{Subject: c.Subject, RetryAfter: wait}
+	}
+	return v.Sign(c)
+}
+
+// MemoryMintStore is an in-memory MintStore. The zero value is ready for use.
+// Idle subjects are removed periodically.
+//
+// Multiple goroutines may invoke methods on a MemoryMintStore simultaneously.
+type MemoryMintStore struct {
+	mutex     sync.Mutex
+	subjects  map[string]*mintLog
+	nextSweep time.Time
+}
+
+// MintLog has the issue times of a subject in chronological order.
+type mintLog struct {
+	last   time.Time
+	issues []time.Time // within the quota window only
+}
+
+// Mint honors the MintStore interface.
+func (store *MemoryMintStore) Mint(subject string, now time.Time, limits MintLimits) (wait time.Duration, err error) {
+	retention := limits.Window
+	if limits.MinInterval > retention {
+		retention = limits.MinInterval
+	}
+
+	store.mutex.Lock()
+	defer store.mutex.Unlock()
+	if store.subjects == nil {
+		store.subjects = make(map[string]*mintLog)
+	}
+	if now.After(store.nextSweep) {
+		for k, l := range store.subjects {
+			if now.Sub(l.last) >= retention {
+				delete(store.subjects, k)
+			}
+		}
+		store.nextSweep = now.Add(time.Minute)
+	}
+
+	l, ok := store.subjects[subject]
+	if !ok {
+		l = new(mintLog)
+		store.subjects[subject] = l
+	}
+
+	// drop issues outside of the window
+	var i int
+	for i < len(l.issues) && now.Sub(l.issues[i]) >= limits.Window {
+		i++
+	}
+	l.issues = l.issues[i:]
+
+	if limits.MinInterval > 0 && !l.last.IsZero() {
+		if d := l.last.Add(limits.MinInterval).Sub(now); d > wait {
+			wait = d
+		}
+	}
+	quotaApplies := limits.Quota > 0 && limits.Window > 0
+	if quotaApplies && len(l.issues) >= limits.Quota {
+		oldest := l.issues[len(l.issues)-limits.Quota]
+		if d := oldest.Add(limits.Window).Sub(now); d > wait {
+			wait = d
+		}
+	}
+	if wait > 0 {
+		return wait, nil
+	}
+
+	l.last = now
+	if quotaApplies {
+		l.issues = append(l.issues, now)
+	}
+	return 0, nil
+}
@@ -0,0 +1,97 @@
+package jwt
+
+import (
+	"errors"
+	"testing"
+	"time"
+)
+
+func TestVendor(t *testing.T) {
+	defer func() { Clock = time.Now }()
+	now := time.Unix(1e9, 0)
+	Clock = func() time.Time { return now }
+
+	v := Vendor{
+		Sign: func(c *Claims) ([]byte, error) {
+			return c.EdDSASign(testKeyEd25519Private)
+		},
+		Store: new(MemoryMintStore),
+		MintLimits: MintLimits{
+			Quota:       3,
+			Window:      time.Minute,
+			MinInterval: time.Second,
+		},
+	}
+
+	issue := func(subject string) error {
+		c := new(Claims)
+		c.Subject = subject
+		_, err := v.Issue(c)
+		return err
+	}
+	wantWait := func(subject string, want time.Duration) {
+		t.Helper()
+		err := issue(subject)
+		var quota *QuotaError
+		if !errors.As(err, &quota) {
+			t.Fatalf("%s: got error %v, want a *QuotaError", subject, err)
+		}
+		if quota.Subject != subject || quota.RetryAfter != want {
+			t.Errorf("got subject %q retry after %s, want %q after %s", quota.Subject, quota.RetryAfter, subject, want)
+		}
+	}
+
+	if err := issue("alice"); err != nil {
+		t.Fatal(err)
+	}
+	// minimum interval
+	now = now.Add(400 * time.Millisecond)
+	wantWait("alice", 600*time.Millisecond)
+	// other subjects are independent
+	if err := issue("bob"); err != nil {
+		t.Fatal(err)
+	}
+
+	now = now.Add(time.Second)
+	if err := issue("alice"); err != nil {
+		t.Fatal(err)
+	}
+	now = now.Add(time.Second)
+	if err := issue("alice"); err != nil {
+		t.Fatal(err)
+	}
+	// quota of 3 per minute reached, with the first at -2.4 s
+	now = now.Add(time.Second)
+	wantWait("alice", time.Minute-3400*time.Millisecond)
+
+	// denials don't count
+	now = now.Add(time.Minute - 3400*time.Millisecond)
+	if err := issue("alice"); err != nil {
+		t.Error("after window:", err)
+	}
+
+	if err := issue(""); err != errVendNoSubject {
+		t.Errorf("got error %v, want %v", err, errVendNoSubject)
+	}
+}
+
+func TestMemoryMintStoreSweep(t *testing.T) {
+	store := new(MemoryMintStore)
+	limits := MintLimits{Quota: 1, Window: time.Second}
+	start := time.Unix(1e9, 0)
+	for _, s := range []string{"a", "b", "c"} {
+		if wait, err := store.Mint(s, start, limits); wait != 0 || err != nil {
+			t.Fatalf("got wait %s, error %v", wait, err)
+		}
+	}
+	if wait, _ := store.Mint("a", start.Add(time.Millisecond), limits); wait != time.Second-time.Millisecond {
+		t.Errorf("got wait %s, want 999ms", wait)
+	}
+
+	if wait, _ := store.Mint("d", start.Add(2*time.Minute), limits); wait != 0 {
+		t.Errorf("got wait %s", wait)
+	}
+	if n := len(store.subjects); n != 1 {
+		t.Errorf("got %d subjects after sweep, want 1", n)
+	}
+}
@@ -0,0 +1,80 @@
+package jwt
+
+import (
+	"errors"
+	"fmt"
+	"math"
+)
+
+// VersionClaim is the name of the schema version claim, as an unsigned
+// integer. Tokens without the claim are of version zero.
+const VersionClaim = "ver"
+
+var errVersionClaim = errors.New(`jwt: schema version ["ver"] not an unsigned integer`)
+
+// Version returns the schema version of the claims, with zero when absent. The
+// return is false for malformed values.
+func (c *Claims) Version() (version int, ok bool) {
+	v, present := c.Set[VersionClaim]
+	if !present {
+		return 0, true
+	}
+	f, isNumber := v.(float64)
+	if !isNumber || f < 0 || f > math.MaxInt32 || f != math.Trunc(f) {
+		return 0, false
+	}
+	return int(f), true
+}
+
+// Migrations upgrade the claims of older schema versions to the current one.
+// Long-lived tokens of previous deployments thus remain usable after changes
+// in the claim layout. Migrations is a ClaimMapper for use with Handler.Mapper.
+type Migrations struct {
+	// Current is the schema version of issues.
+	Current int
+
+	// Steps maps each schema version to the function which upgrades
+	// claims to the next version, i.e., Steps[0] upgrades version 0
+	// to version 1. Steps must cover all versions in use, up to the
+	// current one.
+	Steps map[int]func(*Claims) error
+}
+
+// Stamp sets the VersionClaim to the Current version, for use before signing.
+func (m *Migrations) Stamp(c *Claims) {
+	if c.Set == nil {
+		c.Set = make(map[string]interface{})
+	}
+	c.Set[VersionClaim] = float64(m.Current)
+}
+
+// Migrate applies each step from the version of c up to the Current version.
+// The VersionClaim is updated accordingly. Claims with a version beyond the
+// Current one are rejected, as they come from a newer deployment.
+func (m *Migrations) Migrate(c *Claims) error {
+	v, ok := c.Version()
+	if !ok {
+		return errVersionClaim
+	}
+	if v > m.Current {
+		return fmt.Errorf("jwt: schema version %d beyond current %d", v, m.Current)
+	}
+	for ; v < m.Current; v++ {
+		step, ok := m.Steps[v]
+		if !ok {
+			return fmt.Errorf("jwt: no migration from schema version %d", v)
+		}
+		if err := step(c); err != nil {
+			return fmt.Errorf("jwt: migration from schema version %d: %w", v, err)
+		}
+	}
+	if v != 0 {
+		m.Stamp(c)
+	}
+	return nil
+}
+
+// MapClaims honors the ClaimMapper interface with Migrate.
+func (m *Migrations) MapClaims(c *Claims) error {
+	return m.Migrate(c)
+}
@@ -0,0 +1,99 @@
+package jwt
+
+import (
+	"errors"
+	"testing"
+)
+
+func TestMigrations(t *testing.T) {
+	m := Migrations{
+		Current: 2,
+		Steps: map[int]func(*Claims) error{
+			// version 1 renamed "group" to "groups", as an array
+			0: func(c *Claims) error {
+				if g, ok := c.Set["group"].(string); ok {
+					delete(c.Set, "group")
+					c.Set["groups"] = []interface{}{g}
+				}
+				return nil
+			},
+			// version 2 moved the tenant into the issuer
+			1: func(c *Claims) error {
+				tenant, ok := c.Set["tid"].(string)
+				if !ok {
+					return errors.New("tenant absent")
+				}
+				delete(c.Set, "tid")
+				c.Issuer += "/" + tenant
+				return nil
+			},
+		},
+	}
+
+	var c Claims
+	c.Issuer = "https://idp.example"
+	c.Set = map[string]interface{}{"group": "ops", "tid": "acme"}
+	if err := m.MapClaims(&c); err != nil {
+		t.Fatal(err)
+	}
+	if v, ok := c.Version(); v != 2 || !ok {
+		t.Errorf("got version %d (%t), want 2", v, ok)
+	}
+	if c.Issuer != "https://idp.example/acme" || len(c.Set) != 2 || c.Groups()[0] != "ops" {
+		t.Errorf("got issuer %q and set %v", c.Issuer, c.Set)
+	}
+
+	// current
+	before := len(c.Set)
+	if err := m.Migrate(&c); err != nil || len(c.Set) != before {
+		t.Errorf("current version got error %v and set %v", err, c.Set)
+	}
+
+	// step error
+	c = Claims{Set: map[string]interface{}{"ver": 1.0}}
+	if err := m.Migrate(&c); err == nil || err.Error() != "jwt: migration from schema version 1: tenant absent" {
+		t.Errorf("got error %v", err)
+	}
+
+	golden := []struct {
+		ver  interface{}
+		want string
+	}{
+		{3.0, "jwt: schema version 3 beyond current 2"},
+		{1.5, errVersionClaim.Error()},
+		{-1.0, errVersionClaim.Error()},
+		{"1", errVersionClaim.Error()},
+	}
+	for _, gold := range golden {
+		c := Claims{Set: map[string]interface{}{"ver": gold.ver}}
+		if err := m.Migrate(&c); err == nil || err.Error() != gold.want {
+			t.Errorf("version %#v got error %v, want %q", gold.ver, err, gold.want)
+		}
+	}
+
+	m.Current = 3
+	c = Claims{Set: map[string]interface{}{"ver": 2.0}}
+	if err := m.Migrate(&c); err == nil || err.Error() != "jwt: no migration from schema version 2" {
+		t.Errorf("got error %v", err)
+	}
+}
+
+func TestMigrationsStamp(t *testing.T) {
+	m := Migrations{Current: 4}
+	var c Claims
+	m.Stamp(&c)
+	token, err := c.EdDSASign(testKeyEd25519Private)
+	if err != nil {
+		t.Fatal(err)
+	}
+	got, err := EdDSACheck(token, testKeyEd25519Public)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if v, ok := got.Version(); v != 4 || !ok {
+		t.Errorf("got version %d (%t), want 4", v, ok)
+	}
+	if err := m.Migrate(got); err != nil {
+		t.Error("migrate error:", err)
+	}
+}
@@ -6,8 +6,9 @@ import (
 	"crypto/ed25519"
 	"crypto/rsa"
 	"errors"
-	"fmt"
 	"net/http"
+	"net/url"
+	"path"
 	"strconv"
 	"strings"
 	"time"
@@ -22,8 +23,14 @@ const OAuthURN = "urn:ietf:params:oauth:token-type:jwt"
 // ErrNoHeader signals an HTTP request without authorization.
 var ErrNoHeader = errors.New("jwt: no HTTP authorization header")
 
+// ErrNoSession signals an HTTP request without a session cookie.
+var ErrNoSession = errors.New("jwt: no session cookie")
+
 var errNotBearer = errors.New("jwt: not HTTP Bearer scheme")
 
+// ErrFuncDenied is the ReportOnly outcome of a Handler.Func drop.
+var errFuncDenied = errors.New("jwt: request dropped by handler function")
+
 // ECDSACheckHeader applies ECDSACheck on an HTTP request.
 // Specifically it looks for a bearer token in the Authorization header.
 func ECDSACheckHeader(r *http.Request, key *ecdsa.PublicKey) (*Claims, error) {
@@ -105,63 +112,13 @@ func BearerToken(h http.Header) (token string, err error) {
 	return s[len(prefix):], nil
 }
 
-// ECDSASignHeader applies ECDSASign on an HTTP request.
-// Specifically it sets a bearer token in the Authorization header.
-func (c *Claims) ECDSASignHeader(r *http.Request, alg string, key *ecdsa.PrivateKey) error {
-	token, err := c.ECDSASign(alg, key)
-	if err != nil {
-		return err
-	}
-	r.Header.Set("Authorization", "Bearer "+string(token))
-	return nil
-}
-
-// EdDSASignHeader applies ECDSASign on an HTTP request.
-// Specifically it sets a bearer token in the Authorization header.
-func (c *Claims) EdDSASignHeader(r *http.Request, key ed25519.PrivateKey) error {
-	token, err := c.EdDSASign(key)
-	if err != nil {
-		return err
-	}
-	r.Header.Set("Authorization", "Bearer "+string(token))
-	return nil
-}
-
-// HMACSignHeader applies HMACSign on an HTTP request.
-// Specifically it sets a bearer token in the Authorization header.
-func (c *Claims) HMACSignHeader(r *http.Request, alg string, secret []byte) error {
-	token, err := c.HMACSign(alg, secret)
-	if err != nil {
-		return err
-	}
-	r.Header.Set("Authorization", "Bearer "+string(token))
-	return nil
-}
-
-// SignHeader applies Sign on an HTTP request.
-// Specifically it sets a bearer token in the Authorization header.
-func (h *HMAC) SignHeader(c *Claims, r *http.Request) error {
-	token, err := h.Sign(c)
-	if err != nil {
-		return err
-	}
-	r.Header.Set("Authorization", "Bearer "+string(token))
-	return nil
-}
-
-// RSASignHeader applies RSASign on an HTTP request.
-// Specifically it sets a bearer token in the Authorization header.
-func (c *Claims) RSASignHeader(r *http.Request, alg string, key *rsa.PrivateKey) error {
-	token, err := c.RSASign(alg, key)
-	if err != nil {
-		return err
-	}
-	r.Header.Set("Authorization", "Bearer "+string(token))
-	return nil
-}
-
 // Handler protects an http.Handler with security enforcements.
 // Requests are only passed to Target if the JWT checks out.
+//
+// Verification never reads the request body. Rejections take place before any
+// body transfer for clients which send "Expect: 100-continue", as the server
+// sends "100 Continue" on the first read only. See CloseOnDeny for clients
+// which do not wait.
 type Handler struct {
 	// Target is the secured service.
 	Target http.Handler
@@ -175,6 +132,17 @@ type Handler struct {
 	// 401 (Unauthorized) and a description.
 	HeaderBinding map[string]string
 
+	// HeaderJoin enables HeaderBinding of JSON arrays with strings only,
+	// including the "aud" claim, when not empty. The elements are joined
+	// with HeaderJoin as a separator, e.g., ", ".
+	HeaderJoin string
+
+	// HeaderEscape applies percent-encoding on the values of
+	// HeaderBinding, including any separator characters within the
+	// elements of an array. Escaping keeps the header content in
+	// (visible) ASCII.
+	HeaderEscape bool
+
 	// HeaderPrefix is an optional constraint for JWT claim binding.
 	// Any client headers that match the prefix are removed from the
 	// request.
@@ -188,6 +156,57 @@ type Handler struct {
 	// TemporalLeeway controls the tolerance with time constraints.
 	TemporalLeeway time.Duration
 
+	// RateLimitKey propagates a key for rate limiting to Target, when
+	// set. Requests without the claim are rejected with status code 401
+	// (Unauthorized).
+	RateLimitKey *RateLimitKey
+
+	// TokenDeadline caps the context deadline of each request passed
+	// to Target at the expiration time of the token, when set. See
+	// ContextWithTokenDeadline for details.
+	TokenDeadline bool
+
+	// Fingerprint verifies the client binding of each request, when
+	// set. Violations are rejected with status code 401 (Unauthorized).
+	Fingerprint *Fingerprint
+
+	// Mapper transforms the claims of each request, when set. The
+	// mapping takes place after the JWT validation, before any of the
+	// claim requirements. Errors are rejected with status code 401
+	// (Unauthorized).
+	Mapper ClaimMapper
+
+	// Enrich adds claims from external sources, when set. It is called
+	// after any Mapper, before the claim requirements, with the context
+	// of the request. Errors are rejected with status code 503 (Service
+	// Unavailable). See EnrichCache for memoization.
+	Enrich func(ctx context.Context, c *Claims) error
+
+	// Expect defines additional claim requirements, if any. Requests
+	// which lack any of the required scopes are rejected with status
+	// code 403 (Forbidden). Other violations are rejected with status
+	// code 401 (Unauthorized).
+	Expect *Expect
+
+	// RouteClaims defines additional claim requirements per request
+	// path, on top of any Expect. A pattern ending in a slash matches
+	// the entire subtree, and other patterns match the exact path only.
+	// The longest pattern wins. Paths without a match have no additional
+	// requirements. Request paths are cleaned before the match, such that
+	// "//admin/" and "/a/../admin/" both match pattern "/admin/". Unlike
+	// http.ServeMux, patterns have no host, method or wildcards.
+	RouteClaims map[string]Expect
+
+	// IssuedStore enforces issue time monotonicity per issuer and
+	// subject, when set. See Claims.AcceptIssued for details. The store
+	// advances after all other checks, including Func, such that denied
+	// requests leave the latest issue time as is.
+	IssuedStore IssuedStore
+
+	// Exporter receives each token which passes all verification, when
+	// set. See Exporter for details.
+	Exporter *Exporter
+
 	// When not nil, then Func is called after the JWT validation
 	// succeeds and before any header bindings. Target is skipped
 	// [request drop] when the return is false.
@@ -195,12 +214,83 @@ type Handler struct {
 	// as a filter or as an extended http.HandlerFunc.
 	Func func(http.ResponseWriter, *http.Request, *Claims) (pass bool)
 
+	// ShapeResponse is called once for each response from Target, right
+	// before the header is sent, when set. The hook may annotate the
+	// response with header modifications on w, e.g., X-RateLimit-Limit
+	// from a plan claim. A false return filters the response: anything
+	// from Target is discarded, and the hook should respond with w
+	// instead. Status is 200 (OK) for responses without WriteHeader.
+	ShapeResponse func(w http.ResponseWriter, status int, c *Claims) (pass bool)
+
+	// CloseOnDeny sets "Connection: close" on rejections of requests
+	// with a body, when set. The server then drops the connection after
+	// the response, instead of reading the remainder of the body, which
+	// cuts off clients that stream large bodies without authorization.
+	CloseOnDeny bool
+
 	// Error sends a custom response. Nil defaults to http.Error.
 	// The appropriate WWW-Authenticate value is already present.
 	Error func(w http.ResponseWriter, error string, statusCode int)
+
+	// ReportOnly disables enforcement when set, for staged rollouts on
+	// existing traffic. Each request gets the full verification, and
+	// ReportOnly receives the outcome, with a nil error for success.
+	// Requests which fail verification are passed to Target nonetheless,
+	// without any claims in the context, without any headers from
+	// HeaderBinding or RateLimitKey, and with the error for Unverified.
+	// Func does not respond in this mode; a drop counts as a failure.
+	ReportOnly func(r *http.Request, err error)
+}
+
+// UnverifiedKey is the context key of an error from Handler.ReportOnly.
+type unverifiedKey struct{}
+
+// Unverified returns the reason why a request was passed by a Handler in
+// ReportOnly mode without verification, with nil for requests which did pass
+// verification.
+func Unverified(ctx context.Context) error {
+	err, _ := ctx.Value(unverifiedKey{}).(error)
+	return err
+}
+
+// ReportWriter captures the outcome of a Handler in ReportOnly mode.
+type reportWriter struct {
+	http.ResponseWriter
+	err    error // denial, if any
+	passed bool  // verification success reported
+}
+
+// DiscardWriter isolates Handler.Func in ReportOnly mode.
+type discardWriter struct {
+	header http.Header
+}
+
+// Header implements http.ResponseWriter.
+func (w *discardWriter) Header() http.Header { return w.header }
+
+// Write implements http.ResponseWriter.
+func (w *discardWriter) Write(p []byte) (int, error) { return len(p), nil }
+
+// WriteHeader implements http.ResponseWriter.
+func (w *discardWriter) WriteHeader(statusCode int) {}
+
+// Passed reports success when in ReportOnly mode, and it returns the original
+// response writer.
+func (h *Handler) passed(w http.ResponseWriter, r *http.Request) http.ResponseWriter {
+	rw, ok := w.(*reportWriter)
+	if !ok {
+		return w
+	}
+	rw.passed = true
+	h.ReportOnly(r, nil)
+	return rw.ResponseWriter
 }
 
 func (h *Handler) error(w http.ResponseWriter, error string, statusCode int) {
+	if rw, ok := w.(*reportWriter); ok {
+		rw.err = errors.New(error)
+		return
+	}
 	if h.Error != nil {
 		h.Error(w, error, statusCode)
 	} else {
@@ -208,28 +298,198 @@ func (h *Handler) error(w http.ResponseWriter, error string, statusCode int) {
 	}
 }
 
+// Deny rejects a request with status code 403 (Forbidden) for a ScopeError or
+// a RoleError, or with status code 401 (Unauthorized) otherwise.
+func (h *Handler) deny(w http.ResponseWriter, err error) {
+	if rw, ok := w.(*reportWriter); ok {
+		rw.err = err
+		return
+	}
+	w.Header().Set("WWW-Authenticate", BearerChallenge(err))
+	if code, _ := OAuthError(err); code == InsufficientScope {
+		h.error(w, err.Error(), http.StatusForbidden)
+	} else {
+		h.error(w, err.Error(), http.StatusUnauthorized)
+	}
+}
+
+// RouteExpect returns the RouteClaims entry with the longest pattern that
+// matches path.
+func (h *Handler) routeExpect(p string) (e *Expect, ok bool) {
+	p = cleanPath(p)
+	var best string
+	for pattern := range h.RouteClaims {
+		if ok && len(pattern) <= len(best) {
+			continue
+		}
+		if pattern == p || strings.HasSuffix(pattern, "/") && strings.HasPrefix(p, pattern) {
+			best, ok = pattern, true
+		}
+	}
+	if !ok {
+		return nil, false
+	}
+	match := h.RouteClaims[best]
+	return &match, true
+}
+
+// CleanPath returns the canonical form of p, as http.ServeMux does. The
+// trailing slash, if any, is retained.
+func cleanPath(p string) string {
+	if p == "" {
+		return "/"
+	}
+	if p[0] != '/' {
+		p = "/" + p
+	}
+	cleaned := path.Clean(p)
+	if p[len(p)-1] == '/' && cleaned != "/" {
+		cleaned += "/"
+	}
+	return cleaned
+}
+
+// HeaderValue returns the HeaderBinding representation of a claim.
+func (h *Handler) headerValue(claims *Claims, name string) (string, bool) {
+	s, ok := claims.String(name)
+	if ok {
+		if h.HeaderEscape {
+			s = url.PathEscape(s)
+		}
+		return s, true
+	}
+	if h.HeaderJoin == "" {
+		return "", false
+	}
+
+	var elements []string
+	if name == audience && len(claims.Audiences) != 0 {
+		elements = claims.Audiences
+	} else {
+		array, ok := claims.Set[name].([]interface{})
+		if !ok {
+			return "", false
+		}
+		for _, o := range array {
+			s, ok := o.(string)
+			if !ok {
+				return "", false
+			}
+			elements = append(elements, s)
+		}
+	}
+
+	var buf strings.Builder
+	for i, s := range elements {
+		if i != 0 {
+			buf.WriteString(h.HeaderJoin)
+		}
+		if h.HeaderEscape {
+			s = url.PathEscape(s)
+		}
+		buf.WriteString(s)
+	}
+	return buf.String(), true
+}
+
 // ServeHTTP honors the http.Handler interface.
 func (h *Handler) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	if h.ReportOnly == nil {
+		h.serve(w, r)
+		return
+	}
+
+	rw := reportWriter{ResponseWriter: w}
+	h.serve(&rw, r)
+	if rw.passed {
+		return
+	}
+	h.ReportOnly(r, rw.err)
+
+	// strip any bindings, including the ones from clients
+	headerPrefix := http.CanonicalHeaderKey(h.HeaderPrefix)
+	if headerPrefix != "" {
+		for name := range r.Header {
+			if strings.HasPrefix(name, headerPrefix) {
+				delete(r.Header, name)
+			}
+		}
+	}
+	for _, headerName := range h.HeaderBinding {
+		r.Header.Del(headerName)
+	}
+	if h.RateLimitKey != nil && h.RateLimitKey.Header != "" {
+		r.Header.Del(h.RateLimitKey.Header)
+	}
+	w.Header().Del("Connection")
+
+	h.Target.ServeHTTP(w, r.WithContext(context.WithValue(r.Context(), unverifiedKey{}, rw.err)))
+}
+
+// Serve applies the verification, and it passes r to Target on success.
+func (h *Handler) serve(w http.ResponseWriter, r *http.Request) {
+	// any rejection closes; removed before Target
+	closeOnDeny := h.CloseOnDeny && r.Body != nil && r.Body != http.NoBody && r.ContentLength != 0
+	if closeOnDeny {
+		w.Header().Set("Connection", "close")
+	}
+
 	// verify claims
 	claims, err := h.Keys.CheckHeader(r)
 	if err != nil {
-		if err == ErrNoHeader {
-			w.Header().Set("WWW-Authenticate", "Bearer")
-		} else {
-			w.Header().Set("WWW-Authenticate", `Bearer error="invalid_token", error_description=`+strconv.QuoteToASCII(err.Error()))
-		}
-		h.error(w, err.Error(), http.StatusUnauthorized)
+		h.deny(w, err)
 		return
 	}
 
 	// verify time constraints
-	err = claims.AcceptTemporal(time.Now(), h.TemporalLeeway)
+	now := Clock()
+	claims.observeSkew(now)
+	err = claims.AcceptTemporal(now, h.TemporalLeeway)
 	if err != nil {
-		w.Header().Set("WWW-Authenticate", fmt.Sprintf(`Bearer error="invalid_token", error_description=%q`, err))
-		h.error(w, err.Error(), http.StatusUnauthorized)
+		h.deny(w, err)
 		return
 	}
 
+	// verify client binding
+	if h.Fingerprint != nil {
+		if err := h.Fingerprint.Accept(claims, r); err != nil {
+			h.deny(w, err)
+			return
+		}
+	}
+
+	// normalize claims
+	if h.Mapper != nil {
+		if err := h.Mapper.MapClaims(claims); err != nil {
+			h.deny(w, err)
+			return
+		}
+	}
+
+	// add external claims
+	if h.Enrich != nil {
+		if err := h.Enrich(r.Context(), claims); err != nil {
+			h.error(w, err.Error(), http.StatusServiceUnavailable)
+			return
+		}
+	}
+
+	// verify claim requirements
+	if h.Expect != nil {
+		if err := h.Expect.Accept(claims); err != nil {
+			h.deny(w, err)
+			return
+		}
+	}
+
+	// verify route requirements
+	if e, ok := h.routeExpect(r.URL.Path); ok {
+		if err := e.Accept(claims); err != nil {
+			h.deny(w, err)
+			return
+		}
+	}
+
 	// filter request headers
 	headerPrefix := http.CanonicalHeaderKey(h.HeaderPrefix)
 	if headerPrefix != "" {
@@ -241,8 +501,20 @@ func (h *Handler) ServeHTTP(w http.ResponseWriter, r *http.Request) {
 	}
 
 	// apply the custom function when set
-	if h.Func != nil && !h.Func(w, r, claims) {
-		return
+	if h.Func != nil {
+		if rw, ok := w.(*reportWriter); ok {
+			// drops are not enforced
+			dw := discardWriter{header: make(http.Header)}
+			if !h.Func(&dw, r, claims) {
+				rw.err = errFuncDenied
+				return
+			}
+			for name, values := range dw.header {
+				w.Header()[name] = values
+			}
+		} else if !h.Func(w, r, claims) {
+			return
+		}
 	}
 
 	// claim propagation
@@ -253,20 +525,210 @@ func (h *Handler) ServeHTTP(w http.ResponseWriter, r *http.Request) {
 			return
 		}
 
-		s, ok := claims.String(claimName)
+		s, ok := h.headerValue(claims, claimName)
 		if !ok {
-			msg := "jwt: want string for claim " + claimName
-			w.Header().Set("WWW-Authenticate", `Bearer error="invalid_token", error_description=`+strconv.QuoteToASCII(msg))
-			h.error(w, msg, http.StatusUnauthorized)
+			h.deny(w, errors.New("jwt: want string for claim "+claimName))
 			return
 		}
 		r.Header[headerName] = []string{s}
 	}
 
+	// rate limit propagation
+	if k := h.RateLimitKey; k != nil {
+		key, ok := k.Key(claims)
+		if !ok {
+			h.deny(w, errRateLimitClaim)
+			return
+		}
+		if k.Header != "" {
+			headerName := http.CanonicalHeaderKey(k.Header)
+			if !strings.HasPrefix(headerName, headerPrefix) {
+				h.error(w, "jwt: prefix mismatch in rate limit header", http.StatusInternalServerError)
+				return
+			}
+			r.Header[headerName] = []string{key}
+		}
+		if k.ContextKey != nil {
+			r = r.WithContext(context.WithValue(r.Context(), k.ContextKey, key))
+		}
+	}
+
+	// verify issue time order, as the last check, because it advances
+	if h.IssuedStore != nil {
+		if err := claims.AcceptIssued(h.IssuedStore); err != nil {
+			h.deny(w, err)
+			return
+		}
+	}
+
 	// place claims in request context
 	if h.ContextKey != nil {
 		r = r.WithContext(context.WithValue(r.Context(), h.ContextKey, claims))
 	}
+	if h.TokenDeadline {
+		ctx, cancel := ContextWithTokenDeadline(r.Context(), claims)
+		defer cancel()
+		r = r.WithContext(ctx)
+	}
+
+	if closeOnDeny {
+		w.Header().Del("Connection")
+	}
+
+	// record for audit
+	if h.Exporter != nil {
+		if token, err := BearerToken(r.Header); err == nil {
+			h.Exporter.Export([]byte(token), claims)
+		}
+	}
+
+	w = h.passed(w, r)
+	if h.ShapeResponse == nil {
+		h.Target.ServeHTTP(w, r)
+		return
+	}
+	sw := &shapeWriter{ResponseWriter: w, shape: h.ShapeResponse, claims: claims}
+	h.Target.ServeHTTP(sw, r)
+	sw.WriteHeader(http.StatusOK) // no-op when written already
+}
+
+// ShapeWriter applies Handler.ShapeResponse.
+type shapeWriter struct {
+	http.ResponseWriter
+	shape  func(w http.ResponseWriter, status int, c *Claims) (pass bool)
+	claims *Claims
+
+	shaped   bool // hook called
+	filtered bool // discard
+}
+
+// WriteHeader implements http.ResponseWriter.
+func (w *shapeWriter) WriteHeader(statusCode int) {
+	if w.shaped {
+		return
+	}
+	w.shaped = true
+	if !w.shape(w.ResponseWriter, statusCode, w.claims) {
+		w.filtered = true
+		return
+	}
+	w.ResponseWriter.WriteHeader(statusCode)
+}
+
+// Write implements http.ResponseWriter.
+func (w *shapeWriter) Write(p []byte) (n int, err error) {
+	w.WriteHeader(http.StatusOK)
+	if w.filtered {
+		return len(p), nil // discard
+	}
+	return w.ResponseWriter.Write(p)
+}
 
-	h.Target.ServeHTTP(w, r)
+// Flush implements http.Flusher.
+func (w *shapeWriter) Flush() {
+	w.WriteHeader(http.StatusOK)
+	if f, ok := w.ResponseWriter.(http.Flusher); ok && !w.filtered {
+		f.Flush()
+	}
+}
+
+// Unwrap provides http.ResponseController support.
+func (w *shapeWriter) Unwrap() http.ResponseWriter {
+	return w.ResponseWriter
+}
+
+// ClampCacheControl limits the freshness lifetime of a response, authorized
+// with c, to the remaining validity of c at now. Any max-age and s-maxage in
+// the Cache-Control of h is reduced as needed, and a max-age is added when
+// absent. Invalid claims, including expiry, get no-store instead.
+func ClampCacheControl(h http.Header, c *Claims, now time.Time) {
+	ttl, err := c.TTL(now)
+	if err != nil {
+		h.Set("Cache-Control", "no-store")
+		return
+	}
+	limit := int64(ttl / time.Second)
+
+	var directives []string
+	var hasMaxAge bool
+	for _, v := range h.Values("Cache-Control") {
+		for _, d := range strings.Split(v, ",") {
+			d = strings.TrimSpace(d)
+			if d == "" {
+				continue
+			}
+			if i := strings.IndexByte(d, '='); i > 0 {
+				name := strings.ToLower(d[:i])
+				if name == "max-age" || name == "s-maxage" {
+					hasMaxAge = hasMaxAge || name == "max-age"
+					age, err := strconv.ParseInt(strings.Trim(d[i+1:], `"`), 10, 64)
+					if err != nil || age > limit {
+						age = limit
+					}
+					d = name + "=" + strconv.FormatInt(age, 10)
+				}
+			}
+			directives = append(directives, d)
+		}
+	}
+	if !hasMaxAge {
+		directives = append(directives, "max-age="+strconv.FormatInt(limit, 10))
+	}
+	h.Set("Cache-Control", strings.Join(directives, ", "))
+}
+
+// OAuth error codes from “The OAuth 2.0 Authorization Framework: Bearer Token
+// Usage” RFC 6750, subsection 3.1.
+const (
+	InvalidRequest    = "invalid_request"    // HTTP 400 (Bad Request)
+	InvalidToken      = "invalid_token"      // HTTP 401 (Unauthorized)
+	InsufficientScope = "insufficient_scope" // HTTP 403 (Forbidden)
+
+	// RFC 9470, subsection 3
+	InsufficientUserAuthentication = "insufficient_user_authentication" // HTTP 401 (Unauthorized)
+)
+
+// OAuthError maps an error from this package to an OAuth error code with a
+// description. The code is empty for the absence of authentication, as in
+// ErrNoHeader and ErrNoSession. “If the request lacks any authentication
+// information (e.g., the client was unaware that authentication is necessary
+// or attempted using an unsupported authentication method), the resource
+// server SHOULD NOT include an error code or other error information.”
+// Authorization with a scheme other than Bearer counts as an invalid token.
+// BearerChallenge, and thus Handler, apply the same mapping.
+func OAuthError(err error) (code, description string) {
+	var scope ScopeError
+	var role RoleError
+	var stepUp *StepUpError
+	switch {
+	case err == nil:
+		return "", ""
+	case errors.Is(err, ErrNoHeader), errors.Is(err, ErrNoSession):
+		code = ""
+	case errors.As(err, &scope), errors.As(err, &role):
+		code = InsufficientScope
+	case errors.As(err, &stepUp):
+		code = InsufficientUserAuthentication
+	default:
+		code = InvalidToken
+	}
+	return code, err.Error()
+}
+
+// BearerChallenge returns the WWW-Authenticate value for an error from this
+// package, as applied by Handler. The error code is conform OAuthError.
+func BearerChallenge(err error) string {
+	var scope ScopeError
+	var stepUp *StepUpError
+	code, description := OAuthError(err)
+	switch {
+	case code == "":
+		return "Bearer"
+	case errors.As(err, &scope):
+		return `Bearer error="insufficient_scope", scope=` + strconv.QuoteToASCII(string(scope))
+	case errors.As(err, &stepUp):
+		return stepUp.challenge()
+	default:
+		return `Bearer error="` + code + `", error_description=` + strconv.QuoteToASCII(description)
+	}
 }
@@ -0,0 +1,65 @@
+//go:build !jwtverifyonly
+
+package jwt
+
+import (
+	"crypto/ecdsa"
+	"crypto/ed25519"
+	"crypto/rsa"
+	"net/http"
+)
+
+// ECDSASignHeader applies ECDSASign on an HTTP request.
+// Specifically it sets a bearer token in the Authorization header.
+func (c *Claims) ECDSASignHeader(r *http.Request, alg string, key *ecdsa.PrivateKey) error {
+	token, err := c.ECDSASign(alg, key)
+	if err != nil {
+		return err
+	}
+	r.Header.Set("Authorization", "Bearer "+string(token))
+	return nil
+}
+
+// EdDSASignHeader applies ECDSASign on an HTTP request.
+// Specifically it sets a bearer token in the Authorization header.
+func (c *Claims) EdDSASignHeader(r *http.Request, key ed25519.PrivateKey) error {
+	token, err := c.EdDSASign(key)
+	if err != nil {
+		return err
+	}
+	r.Header.Set("Authorization", "Bearer "+string(token))
+	return nil
+}
+
+// HMACSignHeader applies HMACSign on an HTTP request.
+// Specifically it sets a bearer token in the Authorization header.
+func (c *Claims) HMACSignHeader(r *http.Request, alg string, secret []byte) error {
+	token, err := c.HMACSign(alg, secret)
+	if err != nil {
+		return err
+	}
+	r.Header.Set("Authorization", "Bearer "+string(token))
+	return nil
+}
+
+// SignHeader applies Sign on an HTTP request.
+// Specifically it sets a bearer token in the Authorization header.
+func (h *HMAC) SignHeader(c *Claims, r *http.Request) error {
+	token, err := h.Sign(c)
+	if err != nil {
+		return err
+	}
+	r.Header.Set("Authorization", "Bearer "+string(token))
+	return nil
+}
+
+// RSASignHeader applies RSASign on an HTTP request.
+// Specifically it sets a bearer token in the Authorization header.
+func (c *Claims) RSASignHeader(r *http.Request, alg string, key *rsa.PrivateKey) error {
+	token, err := c.RSASign(alg, key)
+	if err != nil {
+		return err
+	}
+	r.Header.Set("Authorization", "Bearer "+string(token))
+	return nil
+}
@@ -10,7 +10,9 @@ import (
 	"net/http"
 	"net/http/httptest"
 	"strings"
+	"sync/atomic"
 	"testing"
+	"time"
 )
 
 func TestCheckHeaders(t *testing.T) {
@@ -226,6 +228,47 @@ func TestHandlerHeaders(t *testing.T) {
 	}
 }
 
+func TestHandlerHeaderJoin(t *testing.T) {
+	golden := []struct {
+		join   string
+		escape bool
+		want   string
+	}{
+		{"", false, ""},
+		{", ", false, "https://a.example, b c, d,e"},
+		{",", true, "https:%2F%2Fa.example,b%20c,d%2Ce"},
+	}
+	for _, gold := range golden {
+		req := httptest.NewRequest("GET", "/", nil)
+		claims := Claims{Registered: Registered{Audiences: []string{"https://a.example", "b c", "d,e"}}}
+		if err := claims.EdDSASignHeader(req, testKeyEd25519Private); err != nil {
+			t.Fatal(err)
+		}
+
+		var got string
+		handler := Handler{
+			Target: http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
+				got = req.Header.Get("X-Audience")
+			}),
+			Keys:          &KeyRegister{EdDSAs: []ed25519.PublicKey{testKeyEd25519Public}},
+			HeaderBinding: map[string]string{"aud": "X-Audience"},
+			HeaderJoin:    gold.join,
+			HeaderEscape:  gold.escape,
+		}
+		resp := httptest.NewRecorder()
+		handler.ServeHTTP(resp, req)
+		if gold.want == "" {
+			if resp.Code != http.StatusUnauthorized {
+				t.Errorf("join %q got HTTP %d, want 401", gold.join, resp.Code)
+			}
+			continue
+		}
+		if got != gold.want {
+			t.Errorf("join %q got header %q, want %q", gold.join, got, gold.want)
+		}
+	}
+}
+
 func TestHandlerHeaderPrefixBindingMismatch(t *testing.T) {
 	req := httptest.NewRequest("GET", "/", nil)
 	if err := new(Claims).EdDSASignHeader(req, testKeyEd25519Private); err != nil {
@@ -334,3 +377,424 @@ func TestHandleSchemaMiss(t *testing.T) {
 		t.Errorf("got WWW-Authenticate %q, want %q", header, want)
 	}
 }
+
+func TestHandleExpect(t *testing.T) {
+	handler := &Handler{
+		Target: http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
+			fmt.Fprintln(w, "✓ handler")
+		}),
+		Keys:   &KeyRegister{EdDSAs: []ed25519.PublicKey{testKeyEd25519Public}},
+		Expect: &Expect{Scopes: []string{"read"}, Tenants: []string{"acme"}},
+	}
+
+	golden := []struct {
+		set    map[string]interface{}
+		code   int
+		header string
+	}{
+		{map[string]interface{}{"tid": "acme", "scope": "read"}, http.StatusOK, ""},
+		{map[string]interface{}{"tid": "acme", "scope": "write"}, http.StatusForbidden,
+			`Bearer error="insufficient_scope", scope="read"`},
+		{map[string]interface{}{"tid": "globex", "scope": "read"}, http.StatusUnauthorized,
+			`Bearer error="invalid_token", error_description="jwt: tenant not accepted"`},
+	}
+	for _, gold := range golden {
+		req := httptest.NewRequest("GET", "/", nil)
+		if err := (&Claims{Set: gold.set}).EdDSASignHeader(req, testKeyEd25519Private); err != nil {
+			t.Fatal(err)
+		}
+		resp := httptest.NewRecorder()
+		handler.ServeHTTP(resp, req)
+		if resp.Code != gold.code {
+			t.Errorf("%v got HTTP %d, want %d", gold.set, resp.Code, gold.code)
+		}
+		if got := resp.Header().Get("WWW-Authenticate"); got != gold.header {
+			t.Errorf("%v got WWW-Authenticate %q, want %q", gold.set, got, gold.header)
+		}
+	}
+}
+
+func TestHandleRouteClaims(t *testing.T) {
+	handler := &Handler{
+		Target: http.HandlerFunc(func(http.ResponseWriter, *http.Request) {}),
+		Keys:   &KeyRegister{EdDSAs: []ed25519.PublicKey{testKeyEd25519Public}},
+		RouteClaims: map[string]Expect{
+			"/admin/":       {Scopes: []string{"admin"}},
+			"/admin/status": {Scopes: []string{"status"}},
+			"/public":       {},
+		},
+	}
+
+	golden := []struct {
+		path  string
+		scope string
+		code  int
+	}{
+		{"/public", "", http.StatusOK},
+		{"/other", "", http.StatusOK},
+		{"/admin", "", http.StatusOK},
+		{"/admin/", "admin", http.StatusOK},
+		{"/admin/users", "admin", http.StatusOK},
+		{"/admin/users", "status", http.StatusForbidden},
+		{"/admin/status", "status", http.StatusOK},
+		{"/admin/status", "admin", http.StatusForbidden},
+		{"/admin/status/", "admin", http.StatusOK},
+		{"//admin/users", "", http.StatusForbidden},
+		{"/public/../admin/users", "", http.StatusForbidden},
+		{"/admin/./status", "admin", http.StatusForbidden},
+		{"/admin//status", "status", http.StatusOK},
+	}
+	for _, gold := range golden {
+		req := httptest.NewRequest("GET", gold.path, nil)
+		c := Claims{Set: map[string]interface{}{"scope": gold.scope}}
+		if err := c.EdDSASignHeader(req, testKeyEd25519Private); err != nil {
+			t.Fatal(err)
+		}
+		resp := httptest.NewRecorder()
+		handler.ServeHTTP(resp, req)
+		if resp.Code != gold.code {
+			t.Errorf("%s with scope %q got HTTP %d, want %d", gold.path, gold.scope, resp.Code, gold.code)
+		}
+	}
+}
+
+func TestHandleIssuedStore(t *testing.T) {
+	handler := &Handler{
+		Target:       http.HandlerFunc(func(http.ResponseWriter, *http.Request) {}),
+		Keys:         &KeyRegister{EdDSAs: []ed25519.PublicKey{testKeyEd25519Public}},
+		IssuedStore:  new(MemoryIssuedStore),
+		RateLimitKey: &RateLimitKey{Claim: "tenant"},
+	}
+
+	for _, gold := range []struct {
+		issued NumericTime
+		tenant string
+		code   int
+	}{
+		{1537622794, "a", 200},
+		{1537622795, "a", 200},
+		{1537622794, "a", 401},
+		{1537622796, "", 401}, // denied; no advance
+		{1537622795, "a", 200},
+	} {
+		req := httptest.NewRequest("GET", "/", nil)
+		c := Claims{Registered: Registered{Subject: "alice", Issued: &gold.issued}}
+		if gold.tenant != "" {
+			c.Set = map[string]interface{}{"tenant": gold.tenant}
+		}
+		if err := c.EdDSASignHeader(req, testKeyEd25519Private); err != nil {
+			t.Fatal(err)
+		}
+		resp := httptest.NewRecorder()
+		handler.ServeHTTP(resp, req)
+		if resp.Code != gold.code {
+			t.Errorf("issued %s got HTTP %d, want %d", gold.issued.String(), resp.Code, gold.code)
+		}
+	}
+}
+
+func TestOAuthError(t *testing.T) {
+	golden := []struct {
+		err  error
+		code string
+	}{
+		{ErrNoHeader, ""},
+		{ErrNoSession, ""},
+		{errNotBearer, InvalidToken},
+		{ScopeError("admin"), InsufficientScope},
+		{fmt.Errorf("wrapped: %w", ScopeError("admin")), InsufficientScope},
+		{ErrSigMiss, InvalidToken},
+		{errExpired, InvalidToken},
+		{AlgError("none"), InvalidToken},
+	}
+	for _, gold := range golden {
+		code, desc := OAuthError(gold.err)
+		if code != gold.code {
+			t.Errorf("%v got code %q, want %q", gold.err, code, gold.code)
+		}
+		if desc != gold.err.Error() {
+			t.Errorf("%v got description %q", gold.err, desc)
+		}
+
+		// same code in challenge
+		challenge := BearerChallenge(gold.err)
+		if gold.code == "" {
+			if challenge != "Bearer" {
+				t.Errorf("%v got challenge %q, want Bearer", gold.err, challenge)
+			}
+		} else if want := `Bearer error="` + gold.code + `"`; !strings.HasPrefix(challenge, want) {
+			t.Errorf("%v got challenge %q, want prefix %q", gold.err, challenge, want)
+		}
+	}
+
+	if code, desc := OAuthError(nil); code != "" || desc != "" {
+		t.Errorf("nil error got %q, %q", code, desc)
+	}
+}
+
+func TestClampCacheControl(t *testing.T) {
+	now := time.Unix(1537622794, 0)
+	c := &Claims{Registered: Registered{Expires: NewNumericTime(now.Add(time.Minute))}}
+
+	golden := []struct{ in, want string }{
+		{"", "max-age=60"},
+		{"private", "private, max-age=60"},
+		{"public, max-age=30", "public, max-age=30"},
+		{"public, max-age=3600, s-maxage=7200", "public, max-age=60, s-maxage=60"},
+		{"Max-Age=\"120\", no-transform", "max-age=60, no-transform"},
+	}
+	for _, gold := range golden {
+		h := make(http.Header)
+		if gold.in != "" {
+			h.Set("Cache-Control", gold.in)
+		}
+		ClampCacheControl(h, c, now)
+		if got := h.Get("Cache-Control"); got != gold.want {
+			t.Errorf("%q got %q, want %q", gold.in, got, gold.want)
+		}
+	}
+
+	h := http.Header{"Cache-Control": {"max-age=3600"}}
+	ClampCacheControl(h, c, now.Add(time.Hour))
+	if got := h.Get("Cache-Control"); got != "no-store" {
+		t.Errorf("expired got %q, want no-store", got)
+	}
+}
+
+// CountingReader counts read invocations.
+type countingReader struct {
+	r     *strings.Reader
+	reads int32
+}
+
+func (r *countingReader) Read(p []byte) (int, error) {
+	atomic.AddInt32(&r.reads, 1)
+	return r.r.Read(p)
+}
+
+func TestHandleExpectContinue(t *testing.T) {
+	srv := httptest.NewServer(&Handler{
+		Keys:   &KeyRegister{EdDSAs: []ed25519.PublicKey{testKeyEd25519Public}},
+		Target: http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {}),
+	})
+	defer srv.Close()
+	client := &http.Client{Transport: &http.Transport{ExpectContinueTimeout: time.Minute}}
+
+	body := &countingReader{r: strings.NewReader(strings.Repeat("x", 1<<16))}
+	req, err := http.NewRequest("POST", srv.URL, body)
+	if err != nil {
+		t.Fatal(err)
+	}
+	req.ContentLength = 1 << 16
+	req.Header.Set("Expect", "100-continue")
+	resp, err := client.Do(req)
+	if err != nil {
+		t.Fatal(err)
+	}
+	resp.Body.Close()
+	if resp.StatusCode != http.StatusUnauthorized {
+		t.Errorf("got HTTP %q, want 401", resp.Status)
+	}
+	if n := atomic.LoadInt32(&body.reads); n != 0 {
+		t.Errorf("client body read %d times, want none", n)
+	}
+}
+
+func TestHandleCloseOnDeny(t *testing.T) {
+	h := &Handler{
+		Keys:        &KeyRegister{EdDSAs: []ed25519.PublicKey{testKeyEd25519Public}},
+		CloseOnDeny: true,
+		Target:      http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {}),
+	}
+
+	w := httptest.NewRecorder()
+	h.ServeHTTP(w, httptest.NewRequest("POST", "/", strings.NewReader("payload")))
+	if w.Code != http.StatusUnauthorized || w.Header().Get("Connection") != "close" {
+		t.Errorf("rejection got HTTP %d with Connection %q, want 401 with close", w.Code, w.Header().Get("Connection"))
+	}
+
+	// no body
+	w = httptest.NewRecorder()
+	h.ServeHTTP(w, httptest.NewRequest("GET", "/", nil))
+	if got := w.Header().Get("Connection"); got != "" {
+		t.Errorf("rejection without body got Connection %q", got)
+	}
+
+	req := httptest.NewRequest("POST", "/", strings.NewReader("payload"))
+	if err := new(Claims).EdDSASignHeader(req, testKeyEd25519Private); err != nil {
+		t.Fatal(err)
+	}
+	w = httptest.NewRecorder()
+	h.ServeHTTP(w, req)
+	if w.Code != http.StatusOK || w.Header().Get("Connection") != "" {
+		t.Errorf("pass got HTTP %d with Connection %q, want 200 without", w.Code, w.Header().Get("Connection"))
+	}
+}
+
+func TestHandleReportOnly(t *testing.T) {
+	var reports []error
+	h := &Handler{
+		Keys:         &KeyRegister{EdDSAs: []ed25519.PublicKey{testKeyEd25519Public}},
+		HeaderPrefix: "Verified-",
+		HeaderBinding: map[string]string{
+			"sub": "Verified-Subject",
+		},
+		ContextKey:  "claims",
+		Expect:      &Expect{Audience: "api"},
+		CloseOnDeny: true,
+		ReportOnly: func(r *http.Request, err error) {
+			reports = append(reports, err)
+		},
+		Target: http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			_, hasClaims := r.Context().Value("claims").(*Claims)
+			fmt.Fprintf(w, "claims=%t subject=%q unverified=%v", hasClaims, r.Header.Get("Verified-Subject"), Unverified(r.Context()))
+		}),
+	}
+
+	golden := []struct {
+		audience string
+		sign     bool
+		want     string
+		wantErr  bool
+	}{
+		{"api", true, `claims=true subject="alice" unverified=<nil>`, false},
+		{"other", true, `claims=false subject="" unverified=jwt: audience ["aud"] not accepted`, true},
+		{"", false, `claims=false subject="" unverified=jwt: no HTTP authorization header`, true},
+	}
+	for _, gold := range golden {
+		reports = nil
+		req := httptest.NewRequest("POST", "/", strings.NewReader("payload"))
+		req.Header.Set("Verified-Subject", "spoofed")
+		if gold.sign {
+			var c Claims
+			c.Subject = "alice"
+			c.Audiences = []string{gold.audience}
+			if err := c.EdDSASignHeader(req, testKeyEd25519Private); err != nil {
+				t.Fatal(err)
+			}
+		}
+
+		w := httptest.NewRecorder()
+		h.ServeHTTP(w, req)
+		if w.Code != http.StatusOK {
+			t.Errorf("audience %q: got HTTP %d, want 200", gold.audience, w.Code)
+		}
+		if got := w.Body.String(); !strings.HasPrefix(got, gold.want) {
+			t.Errorf("audience %q: got body %q, want %q", gold.audience, got, gold.want)
+		}
+		if got := w.Header().Get("Connection"); got != "" {
+			t.Errorf("audience %q: got Connection %q", gold.audience, got)
+		}
+		if got := w.Header().Get("WWW-Authenticate"); got != "" {
+			t.Errorf("audience %q: got WWW-Authenticate %q", gold.audience, got)
+		}
+		if len(reports) != 1 || (reports[0] != nil) != gold.wantErr {
+			t.Errorf("audience %q: got reports %v", gold.audience, reports)
+		}
+	}
+}
+
+func TestHandleReportOnlyFunc(t *testing.T) {
+	var reports []error
+	h := &Handler{
+		Keys: &KeyRegister{EdDSAs: []ed25519.PublicKey{testKeyEd25519Public}},
+		Func: func(w http.ResponseWriter, r *http.Request, c *Claims) (pass bool) {
+			if c.Subject != "alice" {
+				http.Error(w, "not alice", http.StatusForbidden)
+				return false
+			}
+			w.Header().Set("X-Func", "passed")
+			return true
+		},
+		ReportOnly: func(r *http.Request, err error) {
+			reports = append(reports, err)
+		},
+		Target: http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			fmt.Fprintf(w, "unverified=%v", Unverified(r.Context()))
+		}),
+	}
+
+	golden := []struct {
+		subject, want, wantHeader string
+		wantErr                   error
+	}{
+		{"alice", "unverified=<nil>", "passed", nil},
+		{"bob", "unverified=" + errFuncDenied.Error(), "", errFuncDenied},
+	}
+	for _, gold := range golden {
+		reports = nil
+		req := httptest.NewRequest("GET", "/", nil)
+		c := Claims{Registered: Registered{Subject: gold.subject}}
+		if err := c.EdDSASignHeader(req, testKeyEd25519Private); err != nil {
+			t.Fatal(err)
+		}
+
+		w := httptest.NewRecorder()
+		h.ServeHTTP(w, req)
+		if w.Code != http.StatusOK || w.Body.String() != gold.want {
+			t.Errorf("subject %q: got HTTP %d %q, want 200 %q", gold.subject, w.Code, w.Body, gold.want)
+		}
+		if got := w.Header().Get("X-Func"); got != gold.wantHeader {
+			t.Errorf("subject %q: got X-Func %q, want %q", gold.subject, got, gold.wantHeader)
+		}
+		if len(reports) != 1 || reports[0] != gold.wantErr {
+			t.Errorf("subject %q: got reports %v, want [%v]", gold.subject, reports, gold.wantErr)
+		}
+	}
+}
+
+func TestHandleShapeResponse(t *testing.T) {
+	h := &Handler{
+		Keys: &KeyRegister{EdDSAs: []ed25519.PublicKey{testKeyEd25519Public}},
+		ShapeResponse: func(w http.ResponseWriter, status int, c *Claims) (pass bool) {
+			plan, _ := c.String("plan")
+			switch plan {
+			case "free":
+				w.Header().Set("X-RateLimit-Limit", "10")
+			case "revoked":
+				http.Error(w, "plan revoked", http.StatusPaymentRequired)
+				return false
+			}
+			w.Header().Set("X-Status-Seen", fmt.Sprint(status))
+			return true
+		},
+		Target: http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			if r.URL.Path == "/silent" {
+				return
+			}
+			w.WriteHeader(http.StatusAccepted)
+			fmt.Fprint(w, "target body")
+		}),
+	}
+
+	golden := []struct {
+		plan, path string
+		status     int
+		body       string
+		limit      string
+		seen       string
+	}{
+		{"free", "/", http.StatusAccepted, "target body", "10", "202"},
+		{"pro", "/", http.StatusAccepted, "target body", "", "202"},
+		{"pro", "/silent", http.StatusOK, "", "", "200"},
+		{"revoked", "/", http.StatusPaymentRequired, "plan revoked\n", "", ""},
+	}
+	for _, gold := range golden {
+		req := httptest.NewRequest("GET", gold.path, nil)
+		c := Claims{Set: map[string]interface{}{"plan": gold.plan}}
+		if err := c.EdDSASignHeader(req, testKeyEd25519Private); err != nil {
+			t.Fatal(err)
+		}
+		w := httptest.NewRecorder()
+		h.ServeHTTP(w, req)
+		if w.Code != gold.status || w.Body.String() != gold.body {
+			t.Errorf("plan %q on %s: got HTTP %d with body %q, want %d with %q", gold.plan, gold.path, w.Code, w.Body, gold.status, gold.body)
+		}
+		if got := w.Header().Get("X-RateLimit-Limit"); got != gold.limit {
+			t.Errorf("plan %q on %s: got X-RateLimit-Limit %q, want %q", gold.plan, gold.path, got, gold.limit)
+		}
+		if got := w.Header().Get("X-Status-Seen"); got != gold.seen {
+			t.Errorf("plan %q on %s: hook got status %q, want %q", gold.plan, gold.path, got, gold.seen)
+		}
+	}
+}
@@ -0,0 +1,154 @@
+package jwt
+
+import (
+	"crypto/ecdsa"
+	"crypto/ed25519"
+	"crypto/rsa"
+	"errors"
+	"hash"
+	"math/big"
+	"sync/atomic"
+)
+
+// ErrWiped signals use of an HMAC after Wipe.
+var errWiped = errors.New("jwt: HMAC secret wiped")
+
+// HMACPads returns the inner and the outer pad of RFC 2104 for secret.
+func hmacPads(digest hash.Hash, secret []byte) (ipad, opad []byte) {
+	blockSize := digest.BlockSize()
+	ipad = make([]byte, blockSize)
+	opad = make([]byte, blockSize)
+	if len(secret) > blockSize {
+		digest.Write(secret)
+		sum := digest.Sum(nil)
+		copy(ipad, sum)
+		wipe(sum)
+		digest.Reset()
+	} else {
+		copy(ipad, secret)
+	}
+	copy(opad, ipad)
+	for i := range ipad {
+		ipad[i] ^= 0x36
+		opad[i] ^= 0x5c
+	}
+	return ipad, opad
+}
+
+// HMACDigest is an HMAC implementation which keeps the key pads external. Pooled
+// instances are unkeyed, i.e., they retain no state of the secret.
+type hmacDigest struct {
+	inner, outer hash.Hash
+	ipad, opad   []byte
+	sum          [64]byte // inner hash scratch
+}
+
+// Write honors the hash.Hash interface.
+func (d *hmacDigest) Write(p []byte) (int, error) { return d.inner.Write(p) }
+
+// Size honors the hash.Hash interface.
+func (d *hmacDigest) Size() int { return d.outer.Size() }
+
+// BlockSize honors the hash.Hash interface.
+func (d *hmacDigest) BlockSize() int { return d.inner.BlockSize() }
+
+// Reset honors the hash.Hash interface.
+func (d *hmacDigest) Reset() {
+	d.inner.Reset()
+	d.inner.Write(d.ipad)
+}
+
+// Sum honors the hash.Hash interface.
+func (d *hmacDigest) Sum(b []byte) []byte {
+	in := d.inner.Sum(d.sum[:0])
+	d.outer.Reset()
+	d.outer.Write(d.opad)
+	d.outer.Write(in)
+	return d.outer.Sum(b)
+}
+
+// Digest returns a keyed instance from the pool. Instances must be released
+// after use.
+func (h *HMAC) digest() (*hmacDigest, error) {
+	if atomic.LoadUint32(&h.wiped) != 0 {
+		return nil, errWiped
+	}
+	poolEvent(PoolHMAC, PoolGet)
+	d := h.digests.Get().(*hmacDigest)
+	d.ipad, d.opad = h.ipad, h.opad
+	d.Reset()
+	return d, nil
+}
+
+// Release clears d from any key state, and it puts d back into the pool.
+func (h *HMAC) release(d *hmacDigest) {
+	d.inner.Reset()
+	d.outer.Reset()
+	d.ipad, d.opad = nil, nil
+	for i := range d.sum {
+		d.sum[i] = 0
+	}
+	h.digests.Put(d)
+	poolEvent(PoolHMAC, PoolPut)
+}
+
+// Wipe overwrites the copy of the secret with zeros. Any use of h after Wipe
+// fails. Invocations concurrent with signing or verification may cause false
+// negatives.
+func (h *HMAC) Wipe() {
+	atomic.StoreUint32(&h.wiped, 1)
+	wipe(h.ipad)
+	wipe(h.opad)
+}
+
+// Wipe removes the HMACs and the Secrets from the register, after overwriting
+// their content with zeros. Public keys remain in place. The secrets are shared
+// with whoever provided them, so wipe only after their last use. Concurrent use
+// of the register during Wipe is not permitted.
+func (keys *KeyRegister) Wipe() {
+	for _, h := range keys.HMACs {
+		h.Wipe()
+	}
+	for _, secret := range keys.Secrets {
+		wipe(secret)
+	}
+	keys.HMACs, keys.HMACIDs = nil, nil
+	keys.Secrets, keys.SecretIDs = nil, nil
+}
+
+// Wipe overwrites b with zeros.
+func wipe(b []byte) {
+	for i := range b {
+		b[i] = 0
+	}
+}
+
+// WipePrivateKey overwrites the private components of key, if any, with zeros.
+// Precomputed values internal to the crypto packages are out of reach.
+func wipePrivateKey(key interface{}) {
+	switch t := key.(type) {
+	case ed25519.PrivateKey:
+		wipe(t)
+	case *ecdsa.PrivateKey:
+		wipeInt(t.D)
+	case *rsa.PrivateKey:
+		wipeInt(t.D)
+		for _, p := range t.Primes {
+			wipeInt(p)
+		}
+		wipeInt(t.Precomputed.Dp)
+		wipeInt(t.Precomputed.Dq)
+		wipeInt(t.Precomputed.Qinv)
+	}
+}
+
+func wipeInt(i *big.Int) {
+	if i == nil {
+		return
+	}
+	words := i.Bits()
+	for j := range words {
+		words[j] = 0
+	}
+	i.SetInt64(0)
+}
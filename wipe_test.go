@@ -0,0 +1,92 @@
+package jwt
+
+import (
+	"bytes"
+	"crypto/ed25519"
+	"crypto/hmac"
+	"crypto/sha512"
+	"sync/atomic"
+	"testing"
+)
+
+func TestHMACPads(t *testing.T) {
+	// longer than the block size gets hashed first
+	secret := bytes.Repeat([]byte{'s'}, 200)
+	h, err := NewHMAC(HS512, secret)
+	if err != nil {
+		t.Fatal(err)
+	}
+	d, err := h.digest()
+	if err != nil {
+		t.Fatal(err)
+	}
+	d.Write([]byte("msg"))
+	got := d.Sum(nil)
+	h.release(d)
+
+	want := hmac.New(sha512.New, secret)
+	want.Write([]byte("msg"))
+	if !hmac.Equal(got, want.Sum(nil)) {
+		t.Errorf("got HMAC %x, want %x", got, want.Sum(nil))
+	}
+}
+
+func TestHMACWipe(t *testing.T) {
+	h, err := NewHMAC(HS256, []byte("guest"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	c := new(Claims)
+	c.Subject = "alice"
+	token, err := h.Sign(c)
+	if err != nil {
+		t.Fatal(err)
+	}
+	keys := KeyRegister{HMACs: []*HMAC{h}}
+	if _, err := keys.Check(token); err != nil {
+		t.Fatal("check before wipe:", err)
+	}
+
+	h.Wipe()
+	for _, b := range [][]byte{h.ipad, h.opad} {
+		if !bytes.Equal(b, make([]byte, len(b))) {
+			t.Errorf("pad not wiped: %x", b)
+		}
+	}
+	if _, err := h.Sign(c); err != errWiped {
+		t.Errorf("sign after wipe got error %v, want %v", err, errWiped)
+	}
+	if _, err := h.Check(token); err != errWiped {
+		t.Errorf("check after wipe got error %v, want %v", err, errWiped)
+	}
+	if _, err := keys.Check(token); err != ErrSigMiss {
+		t.Errorf("register check after wipe got error %v, want %v", err, ErrSigMiss)
+	}
+}
+
+func TestKeyRegisterWipe(t *testing.T) {
+	secret := []byte("guest")
+	h, err := NewHMAC(HS256, []byte("guest"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	keys := KeyRegister{
+		HMACs:   []*HMAC{h},
+		Secrets: [][]byte{secret},
+		EdDSAs:  []ed25519.PublicKey{testKeyEd25519Public},
+	}
+	keys.Wipe()
+
+	if !bytes.Equal(secret, make([]byte, len(secret))) {
+		t.Errorf("secret not wiped: %q", secret)
+	}
+	if atomic.LoadUint32(&h.wiped) == 0 {
+		t.Error("HMAC not wiped")
+	}
+	if len(keys.HMACs) != 0 || len(keys.Secrets) != 0 {
+		t.Error("register retains symmetric keys")
+	}
+	if len(keys.EdDSAs) != 1 {
+		t.Error("register lost public keys")
+	}
+}
@@ -0,0 +1,309 @@
+// Package federation provides experimental support for trust chains from
+// “OpenID Federation 1.0”. The API may change without notice.
+package federation
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"strings"
+	"time"
+
+	"github.com/pascaldekloe/jwt"
+)
+
+// StatementType is the media type of entity statements, in the "typ" header
+// notation.
+const StatementType = "entity-statement+jwt"
+
+// StatementHeader is the extra JOSE header for entity statements.
+var StatementHeader = json.RawMessage(`{"typ":"` + StatementType + `"}`)
+
+// ConfigurationPath is the well-known location of entity configurations,
+// relative to the entity identifier.
+const ConfigurationPath = "/.well-known/openid-federation"
+
+// StatementLimit is the maximum size of fetched entity statements in bytes.
+var StatementLimit int64 = 1 << 20
+
+var (
+	errType       = errors.New("federation: entity statement without \"typ\" " + StatementType)
+	errNoJWKS     = errors.New("federation: entity statement without \"jwks\"")
+	errNotSelf    = errors.New("federation: entity configuration not self-issued")
+	errSubject    = errors.New("federation: subordinate statement for other subject")
+	errIssuer     = errors.New("federation: subordinate statement from other issuer")
+	errNoFetch    = errors.New("federation: superior without federation_fetch_endpoint")
+	errNoAnchor   = errors.New("federation: no trust chain to any of the trust anchors")
+	errPathLength = errors.New("federation: trust chain exceeds the maximum path length")
+)
+
+// Statement is a verified entity statement. Entity configurations are the
+// self-issued statements, i.e., Issuer equals Subject.
+type Statement struct {
+	*jwt.Claims
+
+	// Keys has the "jwks" content.
+	Keys *jwt.KeyRegister
+
+	// AuthorityHints has the "authority_hints", if any.
+	AuthorityHints []string
+
+	// Metadata has the "metadata" per entity type, if any.
+	Metadata map[string]json.RawMessage
+
+	// Token is the serial form.
+	Token []byte
+}
+
+// ParseStatement verifies an entity statement with keys, and it applies the
+// time constraints with leeway. A nil keys verifies an entity configuration
+// against its own "jwks" claim instead.
+func ParseStatement(token []byte, keys *jwt.KeyRegister, leeway time.Duration) (*Statement, error) {
+	if keys == nil {
+		unverified, err := jwt.ParseWithoutCheck(token)
+		if err != nil {
+			return nil, err
+		}
+		keys, err = loadJWKS(unverified)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	claims, err := keys.Check(token)
+	if err != nil {
+		return nil, err
+	}
+	var header struct {
+		Typ string `json:"typ"`
+	}
+	if err := json.Unmarshal(claims.RawHeader, &header); err != nil {
+		return nil, err
+	}
+	if !strings.EqualFold(strings.TrimPrefix(header.Typ, "application/"), StatementType) {
+		return nil, errType
+	}
+	if err := claims.AcceptTemporal(time.Now(), leeway); err != nil {
+		return nil, err
+	}
+
+	s := &Statement{Claims: claims, Token: token}
+	s.Keys, err = loadJWKS(claims)
+	if err != nil {
+		return nil, err
+	}
+	if raw, ok := claims.Set["authority_hints"]; ok {
+		hints, ok := jwt.ParseScope(raw)
+		if !ok {
+			return nil, errors.New("federation: malformed \"authority_hints\"")
+		}
+		s.AuthorityHints = hints
+	}
+	if raw, ok := claims.Set["metadata"]; ok {
+		bytes, err := json.Marshal(raw)
+		if err != nil {
+			return nil, err
+		}
+		if err := json.Unmarshal(bytes, &s.Metadata); err != nil {
+			return nil, fmt.Errorf("federation: malformed \"metadata\": %w", err)
+		}
+	}
+	return s, nil
+}
+
+func loadJWKS(claims *jwt.Claims) (*jwt.KeyRegister, error) {
+	raw, ok := claims.Set["jwks"]
+	if !ok {
+		return nil, errNoJWKS
+	}
+	bytes, err := json.Marshal(raw)
+	if err != nil {
+		return nil, err
+	}
+	keys := new(jwt.KeyRegister)
+	if _, err := keys.LoadJWK(bytes); err != nil {
+		return nil, fmt.Errorf("federation: \"jwks\" unusable: %w", err)
+	}
+	return keys, nil
+}
+
+// FetchEndpoint returns the "federation_fetch_endpoint" from the metadata of
+// the "federation_entity" type, if any.
+func (s *Statement) FetchEndpoint() (string, bool) {
+	var entity struct {
+		Endpoint string `json:"federation_fetch_endpoint"`
+	}
+	if err := json.Unmarshal(s.Metadata["federation_entity"], &entity); err != nil {
+		return "", false
+	}
+	return entity.Endpoint, entity.Endpoint != ""
+}
+
+// TrustAnchor is a pinned entity.
+type TrustAnchor struct {
+	EntityID string
+	Keys     *jwt.KeyRegister
+}
+
+// Resolver walks trust chains.
+type Resolver struct {
+	// Anchors are the trusted roots.
+	Anchors []TrustAnchor
+
+	// Client is used for fetching. Nil defaults to http.DefaultClient.
+	Client *http.Client
+
+	// MaxPathLength limits the number of superiors in a trust chain.
+	// Zero defaults to 4.
+	MaxPathLength int
+
+	// Leeway controls the tolerance with time constraints.
+	Leeway time.Duration
+}
+
+// Resolve establishes a trust chain from the leaf entity to any of the trust
+// anchors. The keys of the leaf are the ones from the subordinate statement of
+// its immediate superior. The chain starts with the entity configuration of
+// the leaf, followed by the subordinate statements, ending with the one issued
+// by the trust anchor.
+func (r *Resolver) Resolve(ctx context.Context, leaf string) (keys *jwt.KeyRegister, chain []*Statement, err error) {
+	config, err := r.fetchConfiguration(ctx, leaf, nil)
+	if err != nil {
+		return nil, nil, err
+	}
+	chain, err = r.walk(ctx, config, 0)
+	if err != nil {
+		return nil, nil, err
+	}
+	return chain[1].Keys, chain, nil
+}
+
+// Walk returns the chain from config to any of the anchors.
+func (r *Resolver) walk(ctx context.Context, config *Statement, depth int) ([]*Statement, error) {
+	maxPathLength := r.MaxPathLength
+	if maxPathLength == 0 {
+		maxPathLength = 4
+	}
+	if depth >= maxPathLength {
+		return nil, errPathLength
+	}
+
+	var lastErr error = errNoAnchor
+	for _, hint := range config.AuthorityHints {
+		var anchorKeys *jwt.KeyRegister
+		for _, a := range r.Anchors {
+			if a.EntityID == hint {
+				anchorKeys = a.Keys
+				break
+			}
+		}
+
+		superior, err := r.fetchConfiguration(ctx, hint, anchorKeys)
+		if err != nil {
+			lastErr = err
+			continue
+		}
+		statement, err := r.fetchSubordinate(ctx, superior, config.Subject)
+		if err != nil {
+			lastErr = err
+			continue
+		}
+		// configuration must be signed with keys vouched by the superior
+		if _, err := statement.Keys.Check(config.Token); err != nil {
+			lastErr = fmt.Errorf("federation: entity configuration of %q not signed with a key from %q: %w", config.Subject, hint, err)
+			continue
+		}
+
+		if anchorKeys != nil {
+			return []*Statement{config, statement}, nil
+		}
+		rest, err := r.walk(ctx, superior, depth+1)
+		if err != nil {
+			lastErr = err
+			continue
+		}
+		return append([]*Statement{config, statement}, rest[1:]...), nil
+	}
+	return nil, lastErr
+}
+
+// FetchConfiguration gets the entity configuration of entityID, verified with
+// keys, or self-verified when keys is nil.
+func (r *Resolver) fetchConfiguration(ctx context.Context, entityID string, keys *jwt.KeyRegister) (*Statement, error) {
+	token, err := r.fetch(ctx, strings.TrimSuffix(entityID, "/")+ConfigurationPath)
+	if err != nil {
+		return nil, err
+	}
+	s, err := ParseStatement(token, keys, r.Leeway)
+	if err != nil {
+		return nil, fmt.Errorf("federation: entity configuration of %q: %w", entityID, err)
+	}
+	if s.Issuer != entityID || s.Subject != entityID {
+		return nil, errNotSelf
+	}
+	return s, nil
+}
+
+// FetchSubordinate gets the statement of superior about subject.
+func (r *Resolver) fetchSubordinate(ctx context.Context, superior *Statement, subject string) (*Statement, error) {
+	endpoint, ok := superior.FetchEndpoint()
+	if !ok {
+		return nil, errNoFetch
+	}
+	u, err := url.Parse(endpoint)
+	if err != nil {
+		return nil, fmt.Errorf("federation: fetch endpoint of %q: %w", superior.Subject, err)
+	}
+	q := u.Query()
+	q.Set("sub", subject)
+	u.RawQuery = q.Encode()
+
+	token, err := r.fetch(ctx, u.String())
+	if err != nil {
+		return nil, err
+	}
+	s, err := ParseStatement(token, superior.Keys, r.Leeway)
+	if err != nil {
+		return nil, fmt.Errorf("federation: subordinate statement of %q about %q: %w", superior.Subject, subject, err)
+	}
+	if s.Issuer != superior.Subject {
+		return nil, errIssuer
+	}
+	if s.Subject != subject {
+		return nil, errSubject
+	}
+	return s, nil
+}
+
+func (r *Resolver) fetch(ctx context.Context, location string) ([]byte, error) {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, location, nil)
+	if err != nil {
+		return nil, err
+	}
+	req.Header.Set("Accept", "application/"+StatementType)
+
+	client := r.Client
+	if client == nil {
+		client = http.DefaultClient
+	}
+	resp, err := client.Do(req)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("federation: GET %s got HTTP %q", location, resp.Status)
+	}
+	body, err := io.ReadAll(io.LimitReader(resp.Body, StatementLimit+1))
+	if err != nil {
+		return nil, err
+	}
+	if int64(len(body)) > StatementLimit {
+		return nil, fmt.Errorf("federation: GET %s exceeds the statement limit", location)
+	}
+	return []byte(strings.TrimSpace(string(body))), nil
+}
@@ -0,0 +1,130 @@
+package federation
+
+import (
+	"context"
+	"crypto/ed25519"
+	"crypto/rand"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/pascaldekloe/jwt"
+)
+
+type entity struct {
+	id    string
+	key   ed25519.PrivateKey
+	hints []string
+	// subordinates by entity ID
+	subs map[string]*entity
+}
+
+func newEntity(t *testing.T, id string) *entity {
+	_, key, err := ed25519.GenerateKey(rand.Reader)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return &entity{id: id, key: key, subs: make(map[string]*entity)}
+}
+
+func (e *entity) jwks(t *testing.T) interface{} {
+	keys := jwt.KeyRegister{EdDSAs: []ed25519.PublicKey{e.key.Public().(ed25519.PublicKey)}}
+	bytes, err := keys.JWKS()
+	if err != nil {
+		t.Fatal(err)
+	}
+	return json.RawMessage(bytes)
+}
+
+func (e *entity) sign(t *testing.T, subject string, jwks interface{}, set map[string]interface{}) []byte {
+	var c jwt.Claims
+	c.Issuer = e.id
+	c.Subject = subject
+	c.Issued = jwt.NewNumericTime(time.Now().Truncate(time.Second))
+	c.Expires = jwt.NewNumericTime(time.Now().Add(time.Hour).Truncate(time.Second))
+	c.Set = set
+	if c.Set == nil {
+		c.Set = make(map[string]interface{})
+	}
+	c.Set["jwks"] = jwks
+	token, err := c.EdDSASign(e.key, StatementHeader)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return token
+}
+
+func TestResolve(t *testing.T) {
+	mux := http.NewServeMux()
+	srv := httptest.NewServer(mux)
+	defer srv.Close()
+
+	anchor := newEntity(t, srv.URL+"/anchor")
+	intermediate := newEntity(t, srv.URL+"/intermediate")
+	leaf := newEntity(t, srv.URL+"/leaf")
+	intermediate.hints = []string{anchor.id}
+	leaf.hints = []string{intermediate.id}
+	anchor.subs[intermediate.id] = intermediate
+	intermediate.subs[leaf.id] = leaf
+
+	for _, e := range []*entity{anchor, intermediate, leaf} {
+		e := e
+		path := e.id[len(srv.URL):]
+		mux.HandleFunc(path+ConfigurationPath, func(w http.ResponseWriter, r *http.Request) {
+			set := map[string]interface{}{
+				"metadata": map[string]interface{}{
+					"federation_entity": map[string]interface{}{
+						"federation_fetch_endpoint": e.id + "/fetch",
+					},
+				},
+			}
+			if len(e.hints) != 0 {
+				set["authority_hints"] = e.hints
+			}
+			w.Write(e.sign(t, e.id, e.jwks(t), set))
+		})
+		mux.HandleFunc(path+"/fetch", func(w http.ResponseWriter, r *http.Request) {
+			sub, ok := e.subs[r.URL.Query().Get("sub")]
+			if !ok {
+				http.NotFound(w, r)
+				return
+			}
+			w.Write(e.sign(t, sub.id, sub.jwks(t), nil))
+		})
+	}
+
+	resolver := Resolver{Anchors: []TrustAnchor{{
+		EntityID: anchor.id,
+		Keys:     &jwt.KeyRegister{EdDSAs: []ed25519.PublicKey{anchor.key.Public().(ed25519.PublicKey)}},
+	}}}
+	keys, chain, err := resolver.Resolve(context.Background(), leaf.id)
+	if err != nil {
+		t.Fatal("resolve error:", err)
+	}
+	if len(chain) != 3 {
+		t.Fatalf("got chain of %d statements, want 3", len(chain))
+	}
+	for i, want := range [][2]string{{leaf.id, leaf.id}, {intermediate.id, leaf.id}, {anchor.id, intermediate.id}} {
+		if chain[i].Issuer != want[0] || chain[i].Subject != want[1] {
+			t.Errorf("chain %d got issuer %q and subject %q, want %q", i, chain[i].Issuer, chain[i].Subject, want)
+		}
+	}
+	if len(keys.EdDSAs) != 1 || !keys.EdDSAs[0].Equal(leaf.key.Public()) {
+		t.Error("resolved keys do not match the leaf")
+	}
+
+	// anchor key mismatch
+	resolver.Anchors[0].Keys = &jwt.KeyRegister{EdDSAs: []ed25519.PublicKey{leaf.key.Public().(ed25519.PublicKey)}}
+	if _, _, err := resolver.Resolve(context.Background(), leaf.id); err == nil {
+		t.Error("resolve with wrong anchor key got no error")
+	}
+
+	// path length
+	resolver.Anchors[0].Keys = &jwt.KeyRegister{EdDSAs: []ed25519.PublicKey{anchor.key.Public().(ed25519.PublicKey)}}
+	resolver.MaxPathLength = 1
+	if _, _, err := resolver.Resolve(context.Background(), leaf.id); err != errPathLength {
+		t.Errorf("resolve beyond maximum path length got error %v, want %v", err, errPathLength)
+	}
+}